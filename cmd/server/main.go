@@ -37,6 +37,8 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	stopWorkers := app.startWorkers()
+
 	go func() {
 		log.Printf("server starting on :%d", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -51,8 +53,12 @@ func main() {
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("server shutdown error: %v", err)
+		log.Printf("server shutdown error: %v", err)
 	}
 
+	// Requests have drained; the workers flush what they buffered before
+	// the deferred Close shuts the connections
+	stopWorkers()
+
 	log.Println("shutdown complete")
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"drone-delivery/internal/middleware"
 )
 
@@ -8,16 +10,20 @@ func (a *AppContext) setupRoutes() {
 	r := a.Router
 
 	// ── Global Middleware (outermost → innermost) ──
-	r.Use(middleware.Logger())                 // 1. Request logging
-	r.Use(middleware.Recovery())               // 2. Panic recovery
-	r.Use(middleware.RateLimit(a.RateLimiter)) // 3. Per-IP rate limiting
-	r.Use(middleware.Auth(a.JWTService))       // 4. JWT auth (skips /auth/token)
+	r.Use(middleware.Logger())                                      // 1. Request logging
+	r.Use(middleware.Recovery())                                    // 2. Panic recovery
+	r.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits.Global)) // 3. Coarse per-IP ceiling
+	r.Use(middleware.Auth(a.JWTService))                            // 4. JWT auth (skips /auth/token)
+
+	// Past the per-IP ceiling, rate limits are per route group, after the
+	// role guard, so each principal is charged in its own bucket.
 
-	// ── Health (no auth, no rate limit) ──
+	// ── Health (no auth, no group rate limit) ──
 	r.GET("/health", a.healthCheck)
 
-	// ── Auth (no role guard, no idempotency) ──
+	// ── Auth (no role guard, no idempotency; limited per IP) ──
 	authGroup := r.Group("/auth")
+	authGroup.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits.Auth))
 	{
 		authGroup.POST("/token", a.AuthHandler.GenerateToken)
 	}
@@ -25,17 +31,20 @@ func (a *AppContext) setupRoutes() {
 	// ── Enduser Routes (role: enduser) ──
 	enduserGroup := r.Group("")
 	enduserGroup.Use(middleware.RoleGuard("enduser"))
+	enduserGroup.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits.Enduser))
 	{
 		// Read-only endpoints
 		enduserGroup.GET("/orders", a.OrderHandler.ListMyOrders)
 		enduserGroup.GET("/orders/:id", a.OrderHandler.GetOrderDetails)
+		enduserGroup.POST("/orders/quote", a.OrderHandler.QuoteOrder)
 
 		// Mutations get bulkhead + idempotency
 		enduserMutations := enduserGroup.Group("")
 		enduserMutations.Use(middleware.Bulkhead(a.Config.Bulkhead.MutationPool))
-		enduserMutations.Use(middleware.Idempotency(a.IdempotencyStore))
+		enduserMutations.Use(middleware.Idempotency(a.IdempotencyStore, a.Config.Drone.IdempotencyMaxBodyBytes, time.Duration(a.Config.Drone.IdempotencyLockSec)*time.Second))
 		{
 			enduserMutations.POST("/orders", a.OrderHandler.PlaceOrder)
+			enduserMutations.POST("/orders/import", a.BulkHandler.ImportOrders)
 			enduserMutations.DELETE("/orders/:id", a.OrderHandler.WithdrawOrder)
 		}
 	}
@@ -44,37 +53,108 @@ func (a *AppContext) setupRoutes() {
 	droneGroup := r.Group("/drone")
 	droneGroup.Use(middleware.RoleGuard("drone"))
 	{
-		// Heartbeat gets its own bulkhead pool (high concurrency)
+		// Heartbeat gets its own bulkhead pool (high concurrency) and its
+		// own rate limit, so reporting in never eats the drone's budget
 		heartbeat := droneGroup.Group("")
+		heartbeat.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits.Heartbeat))
 		heartbeat.Use(middleware.Bulkhead(a.Config.Bulkhead.HeartbeatPool))
 		{
 			heartbeat.POST("/me/heartbeat", a.DroneHandler.Heartbeat)
+			heartbeat.POST("/me/telemetry", a.DroneHandler.Telemetry)
 		}
 
+		// Everything else shares the drone rate limit
+		droneAPI := droneGroup.Group("")
+		droneAPI.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits.Drone))
+
 		// Read-only endpoints
-		droneGroup.GET("/jobs", a.JobHandler.ListOpenJobs)
-		droneGroup.GET("/me/order", a.DroneHandler.GetCurrentOrder)
+		droneAPI.GET("/jobs", a.JobHandler.ListOpenJobs)
+		droneAPI.GET("/me/order", a.DroneHandler.GetCurrentOrder)
+		droneAPI.GET("/me/commands", a.CommandHandler.Poll) // long-poll, kept out of the bulkheads
+		droneAPI.GET("/me/airspace", a.AirspaceHandler.Mine)
 
 		// Mutations get the mutation pool
-		mutations := droneGroup.Group("")
+		mutations := droneAPI.Group("")
 		mutations.Use(middleware.Bulkhead(a.Config.Bulkhead.MutationPool))
-		mutations.Use(middleware.Idempotency(a.IdempotencyStore))
+		mutations.Use(middleware.Idempotency(a.IdempotencyStore, a.Config.Drone.IdempotencyMaxBodyBytes, time.Duration(a.Config.Drone.IdempotencyLockSec)*time.Second))
 		{
 			mutations.POST("/jobs/reserve", a.JobHandler.ReserveJob)
 			mutations.POST("/orders/:id/grab", a.JobHandler.GrabOrder)
 			mutations.PATCH("/orders/:id/complete", a.JobHandler.CompleteDelivery)
 			mutations.POST("/me/broken", a.DroneHandler.ReportBroken)
+			mutations.POST("/me/commands/:id/ack", a.CommandHandler.Acknowledge)
 		}
 	}
 
 	// ── Admin Routes (role: admin) ──
 	adminGroup := r.Group("/admin")
 	adminGroup.Use(middleware.RoleGuard("admin"))
+	adminGroup.Use(middleware.RateLimit(a.RateLimiter, a.RateLimits.Admin))
 	adminGroup.Use(middleware.Bulkhead(a.Config.Bulkhead.AdminPool))
 	{
 		adminGroup.GET("/orders", a.AdminHandler.ListOrders)
+		adminGroup.GET("/orders/:id", a.AdminHandler.GetOrder)
 		adminGroup.PATCH("/orders/:id", a.AdminHandler.UpdateOrder)
 		adminGroup.GET("/drones", a.AdminHandler.ListDrones)
+		adminGroup.GET("/drones/nearby", a.AdminHandler.NearbyDrones)
+		adminGroup.GET("/drones/:id", a.AdminHandler.GetDrone)
 		adminGroup.PATCH("/drones/:id/status", a.AdminHandler.UpdateDroneStatus)
+		adminGroup.POST("/drones", a.AdminHandler.RegisterDrone)
+		adminGroup.PATCH("/drones/:id", a.AdminHandler.UpdateDrone)
+		adminGroup.DELETE("/drones/:id", a.AdminHandler.RetireDrone)
+
+		// Manual dispatch
+		adminGroup.GET("/jobs", a.JobHandler.ListJobs)
+		adminGroup.GET("/jobs/:id", a.JobHandler.GetJob)
+		adminGroup.POST("/jobs/:id/assign", a.AdminHandler.AssignJob)
+		adminGroup.PATCH("/jobs/:id/priority", a.AdminHandler.SetJobPriority)
+		adminGroup.POST("/orders/:id/unassign", a.AdminHandler.UnassignOrder)
+		adminGroup.POST("/orders/:id/reassign", a.AdminHandler.ReassignOrder)
+
+		// Maintenance
+		adminGroup.GET("/maintenance/schedules", a.MaintenanceHandler.ListSchedules)
+		adminGroup.PUT("/maintenance/schedules/:model", a.MaintenanceHandler.SetSchedule)
+		adminGroup.GET("/work-orders", a.MaintenanceHandler.ListWorkOrders)
+		adminGroup.POST("/drones/:id/work-orders", a.MaintenanceHandler.OpenWorkOrder)
+		adminGroup.POST("/work-orders/:id/complete", a.MaintenanceHandler.CompleteWorkOrder)
+
+		// Telemetry replay
+		adminGroup.GET("/drones/:id/track", a.TelemetryHandler.DroneTrack)
+		adminGroup.GET("/orders/:id/track", a.TelemetryHandler.OrderTrack)
+
+		// Heartbeat anomalies and alerts
+		adminGroup.GET("/drones/:id/anomalies", a.AdminHandler.ListDroneAnomalies)
+		adminGroup.POST("/drones/:id/quarantine", a.AdminHandler.QuarantineDrone)
+		adminGroup.DELETE("/drones/:id/quarantine", a.AdminHandler.LiftQuarantine)
+		adminGroup.GET("/alerts", a.AlertHandler.List)
+		adminGroup.POST("/alerts/:id/acknowledge", a.AlertHandler.Acknowledge)
+
+		// Ground-control commands
+		adminGroup.POST("/drones/:id/commands", a.CommandHandler.Issue)
+		adminGroup.GET("/drones/:id/commands", a.CommandHandler.List)
+
+		// Groundings (emergency stop / weather hold)
+		adminGroup.POST("/groundings", a.GroundingHandler.Ground)
+		adminGroup.GET("/groundings", a.GroundingHandler.List)
+		adminGroup.DELETE("/groundings/:id", a.GroundingHandler.Lift)
+
+		// Weather
+		adminGroup.GET("/zones", a.WeatherHandler.Zones)
+
+		// Airspace
+		adminGroup.GET("/airspace/reservations", a.AirspaceHandler.ListActive)
+
+		// Delivery SLAs
+		adminGroup.GET("/sla", a.SLAHandler.Report)
+
+		// Operational reports (JSON, or CSV with format=csv)
+		adminGroup.GET("/reports/summary", a.ReportHandler.Summary)
+		adminGroup.GET("/reports/daily", a.ReportHandler.Daily)
+		adminGroup.GET("/reports/drones", a.ReportHandler.Drones)
+		adminGroup.GET("/reports/demand", a.ReportHandler.Demand)
+
+		// Bulk export (CSV, or NDJSON with format=ndjson), streamed
+		adminGroup.GET("/orders/export", a.BulkHandler.ExportOrders)
+		adminGroup.GET("/drones/export", a.BulkHandler.ExportDrones)
 	}
 }
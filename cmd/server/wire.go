@@ -4,17 +4,34 @@ import (
 	"context"
 	"drone-delivery/config"
 	"drone-delivery/internal/admin"
-	pgmigrate "drone-delivery/internal/repo/postgres"
+	"drone-delivery/internal/admission"
+	"drone-delivery/internal/airspace"
+	"drone-delivery/internal/alert"
 	"drone-delivery/internal/auth"
+	"drone-delivery/internal/bulk"
+	"drone-delivery/internal/command"
 	"drone-delivery/internal/common"
 	"drone-delivery/internal/delivery"
 	"drone-delivery/internal/drone"
+	"drone-delivery/internal/gateway"
+	"drone-delivery/internal/grounding"
 	"drone-delivery/internal/job"
 	"drone-delivery/internal/jwt"
+	"drone-delivery/internal/maintenance"
 	"drone-delivery/internal/order"
+	"drone-delivery/internal/payment"
+	"drone-delivery/internal/pkg/ratelimit"
+	"drone-delivery/internal/pricing"
 	"drone-delivery/internal/redis"
+	pgmigrate "drone-delivery/internal/repo/postgres"
+	"drone-delivery/internal/report"
+	"drone-delivery/internal/sla"
+	"drone-delivery/internal/telemetry"
+	"drone-delivery/internal/weather"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	goredis "github.com/redis/go-redis/v9"
@@ -32,8 +49,21 @@ type AppContext struct {
 	JWTService       *jwt.Service
 	DroneCache       *redis.DroneLocationCache
 	IdempotencyStore *redis.IdempotencyStore
-	RateLimiter      *redis.RateLimiter
+	RateLimiter      ratelimit.Limiter
+	RateLimits       ratePolicies
 	MapboxClient     *common.MapboxClient
+	PaymentProvider  payment.Provider
+
+	// Background workers
+	PaymentDispatcher     *payment.Dispatcher
+	TelemetryRecorder     *telemetry.Recorder
+	TelemetryPartitionMgr *telemetry.PartitionManager
+	HeartbeatWriter       *drone.HeartbeatWriter
+	IndexPruner           *drone.IndexPruner
+	LeaseSweeper          *delivery.LeaseSweeper
+	WaitlistPromoter      *delivery.WaitlistPromoter
+	SLAEvaluator          *sla.Evaluator
+	MQTTGateway           *gateway.Gateway // nil unless MQTT_ENABLED
 
 	OrderHandler *order.Handler
 	DroneHandler *drone.Handler
@@ -41,10 +71,24 @@ type AppContext struct {
 	AdminHandler *admin.Handler
 	AuthHandler  *auth.Handler
 
-	OrderService order.Service
-	DroneService drone.Service
-	JobService   job.Service
-	AdminService admin.Service
+	MaintenanceHandler *maintenance.Handler
+	TelemetryHandler   *telemetry.Handler
+	AlertHandler       *alert.Handler
+	CommandHandler     *command.Handler
+	GroundingHandler   *grounding.Handler
+	WeatherHandler     *weather.Handler
+	AirspaceHandler    *airspace.Handler
+	SLAHandler         *sla.Handler
+	ReportHandler      *report.Handler
+	BulkHandler        *bulk.Handler
+
+	OrderService   order.Service
+	DroneService   drone.Service
+	JobService     job.Service
+	AdminService   admin.Service
+	PricingService pricing.Service
+
+	MaintenanceService maintenance.Service
 
 	OrderRepo order.Repository
 	DroneRepo drone.Repository
@@ -61,6 +105,51 @@ func (a *orderQueryAdapter) GetByDroneID(ctx context.Context, droneID string) (a
 	return a.svc.GetByDroneID(ctx, droneID)
 }
 
+// commandInboxAdapter bridges command.Service to drone.CommandInbox so the
+// drone handler doesn't import the command package.
+type commandInboxAdapter struct {
+	svc command.Service
+}
+
+func (a *commandInboxAdapter) Deliver(ctx context.Context, droneID string) (any, error) {
+	return a.svc.Deliver(ctx, droneID)
+}
+
+// ratePolicies is the rate limit of each route group.
+type ratePolicies struct {
+	Global    ratelimit.Policy
+	Auth      ratelimit.Policy
+	Enduser   ratelimit.Policy
+	Heartbeat ratelimit.Policy
+	Drone     ratelimit.Policy
+	Admin     ratelimit.Policy
+}
+
+func newRatePolicies(cfg config.RateLimiterConfig) (ratePolicies, error) {
+	var p ratePolicies
+	var err error
+	if p.Auth, err = ratelimit.NewPolicy("auth", "ip", cfg.MaxRequests, cfg.WindowSeconds, 0); err != nil {
+		return p, err
+	}
+	groups := []struct {
+		name   string
+		cfg    config.RateLimitPolicy
+		policy *ratelimit.Policy
+	}{
+		{"global", cfg.Global, &p.Global},
+		{"enduser", cfg.Enduser, &p.Enduser},
+		{"heartbeat", cfg.Heartbeat, &p.Heartbeat},
+		{"drone", cfg.Drone, &p.Drone},
+		{"admin", cfg.Admin, &p.Admin},
+	}
+	for _, g := range groups {
+		if *g.policy, err = ratelimit.NewPolicy(g.name, g.cfg.Key, g.cfg.Requests, g.cfg.WindowSeconds, g.cfg.Burst); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
 func wireApp(cfg *config.Config) (*AppContext, error) {
 	// ── Postgres ──
 	db, err := sqlx.Connect("postgres", cfg.Postgres.DSN())
@@ -94,15 +183,90 @@ func wireApp(cfg *config.Config) (*AppContext, error) {
 	// ── Infrastructure ──
 	jwtService := jwt.NewService(cfg.JWT.Secret, cfg.JWT.ExpiryHours)
 	droneCache := redis.NewDroneLocationCache(rdb, cfg.Drone.LocationCacheTTLSec)
-	idempotencyStore := redis.NewIdempotencyStore(rdb, cfg.Drone.IdempotencyTTLSec)
-	rateLimiter := redis.NewRateLimiter(rdb, cfg.RateLimiter.MaxRequests, cfg.RateLimiter.WindowSeconds)
+	idempotencyStore := redis.NewIdempotencyStore(rdb, cfg.Drone.IdempotencyTTLSec, cfg.Drone.IdempotencyLockSec)
+	// Limits fall back to per-instance buckets while Redis is unreachable
+	rateLimiter := ratelimit.NewFallback(redis.NewRateLimiter(rdb), ratelimit.NewMemory())
+	rateLimits, err := newRatePolicies(cfg.RateLimiter)
+	if err != nil {
+		return nil, fmt.Errorf("rate limits: %w", err)
+	}
 	mapboxClient := common.NewMapboxClient(cfg.Mapbox.BaseURL, cfg.Mapbox.AccessToken)
+	paymentProvider := payment.NewFakeProvider()
+	weatherFixture, err := common.NewFixtureWeatherProvider(cfg.Weather.FixtureFile)
+	if err != nil {
+		return nil, fmt.Errorf("weather: %w", err)
+	}
+	weatherProvider := redis.NewWeatherCache(rdb, weatherFixture, cfg.Weather.CacheTTLSec, cfg.Weather.GridDeg)
+	weatherLimits := weather.Limits{
+		MaxWindKMH:      cfg.Weather.MaxWindKMH,
+		MinVisibilityKM: cfg.Weather.MinVisibilityKM,
+	}
+
+	telemetryPartitions := telemetry.NewPartitionManager(db, telemetry.RetentionConfig{
+		RetentionDays:   cfg.Telemetry.RetentionDays,
+		PartitionsAhead: cfg.Telemetry.PartitionsAhead,
+		Interval:        cfg.Telemetry.MaintenanceInterval,
+	})
+	if err := telemetryPartitions.Maintain(context.Background(), time.Now()); err != nil {
+		return nil, fmt.Errorf("telemetry partitions: %w", err)
+	}
 
 	// ── Repositories ──
 	orderRepo := order.NewRepository()
 	droneRepo := drone.NewRepository()
 	jobRepo := job.NewRepository()
-	deliveryRepo := delivery.NewRepository(orderRepo, jobRepo, droneRepo)
+	paymentRepo := payment.NewRepository()
+	telemetryRepo := telemetry.NewRepository()
+	alertRepo := alert.NewRepository()
+	commandRepo := command.NewRepository()
+	groundingRepo := grounding.NewRepository()
+	maintenanceRepo := maintenance.NewRepository(maintenance.Schedule{
+		MaxFlightHours: cfg.Maintenance.DefaultMaxFlightHours,
+		MaxFlightKM:    cfg.Maintenance.DefaultMaxFlightKM,
+		MaxCycles:      cfg.Maintenance.DefaultMaxCycles,
+	})
+	airspaceService := airspace.NewService(db, airspace.NewRepository(), airspace.Config{
+		Lanes:        cfg.Airspace.Lanes,
+		LaneBaseM:    cfg.Airspace.LaneBaseM,
+		LaneHeightM:  cfg.Airspace.LaneHeightM,
+		SeparationKM: cfg.Airspace.SeparationKM,
+		SpeedKMH:     cfg.Airspace.SpeedKMH,
+		Buffer:       cfg.Airspace.Buffer,
+		MaxDelay:     cfg.Airspace.MaxDelay,
+	})
+	// The guard only reads groundings, so dispatch can consult it before the
+	// grounding service (which issues commands) exists.
+	groundingGuard := grounding.NewGuard(db, groundingRepo)
+	// Delivery transitions check SLAs (and raise alerts) in their own
+	// transactions, so both services exist before the delivery repository.
+	alertService := alert.NewService(db, alertRepo)
+	slaService := sla.NewService(db, sla.NewRepository(), alertService, sla.Config{
+		Tiers: map[order.ServiceTier]sla.Targets{
+			order.TierStandard: {
+				Assign:   cfg.SLA.Standard.Assign,
+				Pickup:   cfg.SLA.Standard.Pickup,
+				Delivery: cfg.SLA.Standard.Delivery,
+			},
+			order.TierExpress: {
+				Assign:   cfg.SLA.Express.Assign,
+				Pickup:   cfg.SLA.Express.Pickup,
+				Delivery: cfg.SLA.Express.Delivery,
+			},
+		},
+		AtRiskRatio: cfg.SLA.AtRiskRatio,
+	})
+	// Status changes index the drone at its buffered heartbeat, which the
+	// drones table only catches up with on the next flush.
+	heartbeatWriter := drone.NewHeartbeatWriter(db, droneRepo, drone.WriterConfig{
+		FlushInterval: cfg.Ingest.FlushInterval,
+	})
+	droneIndex := heartbeatWriter.BufferedIndex(droneCache)
+	deliveryRepo := delivery.NewRepository(orderRepo, jobRepo, droneRepo, paymentRepo, maintenanceRepo, droneIndex, payment.RefundPolicy{
+		FailedRefundPercent: cfg.Payment.FailedRefundPercent,
+	}, delivery.FlightGuards{groundingGuard, weather.NewGate(weatherProvider, weatherLimits)}, airspaceService, job.LeasePolicy{
+		SpeedKMH: cfg.Lease.SpeedKMH,
+		Grace:    cfg.Lease.Grace,
+	}, slaService)
 
 	// ── Services ──
 	orderService := order.NewOrderService(orderRepo, db, order.ZoneConfig{
@@ -111,20 +275,130 @@ func wireApp(cfg *config.Config) (*AppContext, error) {
 		RadiusKM:  cfg.Zone.RadiusKM,
 	}, mapboxClient)
 
+	telemetryRecorder := telemetry.NewRecorder(db, telemetryRepo, telemetry.RecorderConfig{
+		BufferSize:    cfg.Telemetry.BufferSize,
+		BatchSize:     cfg.Telemetry.BatchSize,
+		FlushInterval: cfg.Telemetry.FlushInterval,
+	})
+	telemetryService := telemetry.NewService(db, telemetryRepo)
+
+	// The gateway is created before the services so delivery can notify it;
+	// telemetry is routed once the drone service exists.
+	var mqttGateway *gateway.Gateway
+	var dispatchNotifier delivery.Notifier
+	var commandPublisher command.Publisher
+	if cfg.MQTT.Enabled {
+		mqttGateway, err = gateway.New(gateway.Config{Addr: cfg.MQTT.Addr}, jwtService)
+		if err != nil {
+			return nil, fmt.Errorf("mqtt gateway: %w", err)
+		}
+		dispatchNotifier = mqttGateway
+		commandPublisher = mqttGateway
+	}
+	deliveryService := delivery.NewService(db, deliveryRepo, paymentProvider, dispatchNotifier)
+
 	zoneCenter := common.NewLocation(cfg.Zone.CenterLat, cfg.Zone.CenterLng)
-	droneService := drone.NewDroneService(droneRepo, db, droneCache, zoneCenter, cfg.Zone.RadiusKM)
-	jobService := job.NewService(jobRepo, db)
-	deliveryService := delivery.NewService(db, deliveryRepo)
-	adminService := admin.NewService(orderService, droneService, deliveryService)
+	droneService := drone.NewDroneService(droneRepo, db, droneCache, telemetryRecorder, alertService, deliveryService, deliveryService, heartbeatWriter, zoneCenter, cfg.Zone.RadiusKM, drone.HeartbeatPolicy{
+		MaxSpeedKMH:     cfg.Drone.MaxSpeedKMH,
+		MaxClockSkew:    cfg.Drone.HeartbeatMaxClockSkew,
+		RequireSequence: cfg.Drone.HeartbeatRequireSequence,
+		AutoQuarantine:  cfg.Drone.AutoQuarantine,
+
+		CriticalFaultCodes: cfg.Drone.CriticalFaultCodes,
+		MaxBatchSamples:    cfg.Ingest.MaxSamples,
+	})
+	if mqttGateway != nil {
+		if err := mqttGateway.RouteTelemetry(droneService); err != nil {
+			return nil, fmt.Errorf("mqtt gateway: %w", err)
+		}
+	}
+	pricingRules, err := pricing.LoadRules(cfg.Pricing.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("pricing: %w", err)
+	}
+	pricingService := pricing.NewService(pricingRules, droneService, pricing.Config{
+		Secret:       cfg.Pricing.QuoteSecret,
+		QuoteTTL:     cfg.Pricing.QuoteTTL,
+		RequireQuote: cfg.Pricing.RequireQuote,
+	})
+	jobService := job.NewService(jobRepo, db, job.QueuePolicy{AgingStep: cfg.Queue.AgingStep}, 2*cfg.Zone.RadiusKM)
+	admissionPolicy := admission.Policy(cfg.Admission.Policy)
+	if !admissionPolicy.Valid() {
+		return nil, fmt.Errorf("admission: unknown policy %q", cfg.Admission.Policy)
+	}
+	admissionService := admission.NewService(droneService, jobService, orderService, admission.Config{
+		SLA:         cfg.Admission.SLA,
+		Policy:      admissionPolicy,
+		ServiceTime: cfg.Admission.ServiceTime,
+		MinSamples:  cfg.Admission.MinSamples,
+		SampleSize:  cfg.Admission.SampleSize,
+		PickupLead:  cfg.Admission.PickupLead,
+		SpeedKMH:    cfg.Admission.SpeedKMH,
+	})
+	commandService := command.NewService(db, commandRepo, droneService, deliveryService, alertService, commandPublisher, command.Config{
+		TTL: cfg.Command.TTL,
+	})
+	groundingService := grounding.NewService(db, groundingRepo, droneService, commandService, grounding.Config{
+		OrderPolicy: grounding.OrderPolicy(cfg.Grounding.OrderPolicy),
+		DroneAction: command.Type(cfg.Grounding.DroneAction),
+	})
+	maintenanceService := maintenance.NewService(db, maintenanceRepo, droneRepo, droneIndex)
+	adminService := admin.NewService(orderService, droneService, deliveryService, maintenanceService)
 	authService := auth.NewAuthService(jwtService)
 
+	// ── Workers ──
+	indexPruner := drone.NewIndexPruner(droneCache, drone.IndexPrunerConfig{
+		Interval: cfg.Drone.GeoPruneInterval,
+		MaxAge:   time.Duration(cfg.Drone.LocationCacheTTLSec) * time.Second,
+	})
+	leaseSweeper := delivery.NewLeaseSweeper(db, deliveryRepo, jobRepo, commandService, delivery.LeaseSweeperConfig{
+		Interval:  cfg.Lease.SweepInterval,
+		BatchSize: cfg.Lease.BatchSize,
+	})
+	waitlistPromoter := delivery.NewWaitlistPromoter(db, deliveryRepo, orderRepo, admissionService, delivery.WaitlistPromoterConfig{
+		Interval:  cfg.Admission.PromoteInterval,
+		BatchSize: cfg.Admission.PromoteBatch,
+	})
+	slaEvaluator := sla.NewEvaluator(db, orderRepo, slaService, sla.EvaluatorConfig{
+		Interval:  cfg.SLA.EvalInterval,
+		BatchSize: cfg.SLA.EvalBatch,
+	})
+	paymentDispatcher := payment.NewDispatcher(db, paymentRepo, paymentProvider, payment.DispatcherConfig{
+		PollInterval: cfg.Payment.OutboxPollInterval,
+		BatchSize:    cfg.Payment.OutboxBatchSize,
+		MaxAttempts:  cfg.Payment.OutboxMaxAttempts,
+		ClaimTimeout: cfg.Payment.OutboxClaimTimeout,
+	})
+
 	// ── Handlers ──
 
 	authHandler := auth.NewHandler(authService)
-	orderHandler := order.NewHandler(orderService, deliveryService, droneService)
-	droneHandler := drone.NewHandler(droneService, &orderQueryAdapter{svc: orderService}, deliveryService)
-	jobHandler := job.NewHandler(jobService, deliveryService)
+	orderHandler := order.NewHandler(orderService, deliveryService, droneService, pricingService, groundingGuard, weatherProvider, admissionService)
+	droneHandler := drone.NewHandler(droneService, &orderQueryAdapter{svc: orderService}, deliveryService, &commandInboxAdapter{svc: commandService}, cfg.Ingest.MaxBodyBytes)
+	jobHandler := job.NewHandler(jobService, deliveryService, droneService, groundingGuard, airspaceService)
 	adminHandler := admin.NewHandler(adminService, orderService, droneService)
+	maintenanceHandler := maintenance.NewHandler(maintenanceService)
+	telemetryHandler := telemetry.NewHandler(telemetryService)
+	alertHandler := alert.NewHandler(alertService)
+	commandHandler := command.NewHandler(commandService, cfg.Command.MaxWait)
+	groundingHandler := grounding.NewHandler(groundingService)
+	airspaceHandler := airspace.NewHandler(airspaceService)
+	slaHandler := sla.NewHandler(slaService)
+	zones := []weather.Zone{{
+		Name:     "delivery",
+		Center:   zoneCenter,
+		RadiusKM: cfg.Zone.RadiusKM,
+	}}
+	weatherHandler := weather.NewHandler(weatherProvider, weatherLimits, zones)
+	reportHandler := report.NewHandler(report.NewService(db, report.NewRepository()), zones)
+	bulkHandler := bulk.NewHandler(
+		bulk.NewExporter(db, orderRepo, droneRepo),
+		bulk.NewImporter(orderService, deliveryService, pricingService, groundingGuard, admissionService, bulk.ImportConfig{
+			MaxRows:   cfg.Bulk.ImportMaxRows,
+			BatchSize: cfg.Bulk.ImportBatchSize,
+		}),
+		cfg.Bulk.ImportMaxBytes,
+	)
 
 	return &AppContext{
 		Config: cfg,
@@ -136,24 +410,87 @@ func wireApp(cfg *config.Config) (*AppContext, error) {
 		DroneCache:       droneCache,
 		IdempotencyStore: idempotencyStore,
 		RateLimiter:      rateLimiter,
+		RateLimits:       rateLimits,
 		MapboxClient:     mapboxClient,
+		PaymentProvider:  paymentProvider,
+
+		PaymentDispatcher:     paymentDispatcher,
+		TelemetryRecorder:     telemetryRecorder,
+		TelemetryPartitionMgr: telemetryPartitions,
+		HeartbeatWriter:       heartbeatWriter,
+		IndexPruner:           indexPruner,
+		LeaseSweeper:          leaseSweeper,
+		WaitlistPromoter:      waitlistPromoter,
+		SLAEvaluator:          slaEvaluator,
+		MQTTGateway:           mqttGateway,
 
 		OrderRepo: orderRepo,
 		DroneRepo: droneRepo,
 		JobRepo:   jobRepo,
 
-		OrderService: orderService,
-		DroneService: droneService,
-		JobService:   jobService,
-		AdminService: adminService,
+		OrderService:   orderService,
+		DroneService:   droneService,
+		JobService:     jobService,
+		AdminService:   adminService,
+		PricingService: pricingService,
+
+		MaintenanceService: maintenanceService,
 
 		AuthHandler:  authHandler,
 		OrderHandler: orderHandler,
 		DroneHandler: droneHandler,
 		JobHandler:   jobHandler,
 		AdminHandler: adminHandler,
+
+		MaintenanceHandler: maintenanceHandler,
+		TelemetryHandler:   telemetryHandler,
+		AlertHandler:       alertHandler,
+		CommandHandler:     commandHandler,
+		GroundingHandler:   groundingHandler,
+		WeatherHandler:     weatherHandler,
+		AirspaceHandler:    airspaceHandler,
+		SLAHandler:         slaHandler,
+		ReportHandler:      reportHandler,
+		BulkHandler:        bulkHandler,
 	}, nil
 }
+
+// startWorkers launches background loops. The returned func stops them and
+// waits for them to return: the MQTT gateway first, since it feeds the
+// heartbeat and telemetry buffers, then the rest, whose final flushes need
+// the database still open.
+func (a *AppContext) startWorkers() (stop func()) {
+	ingressCtx, stopIngress := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	var ingress, workers sync.WaitGroup
+	run := func(wg *sync.WaitGroup, ctx context.Context, worker func(context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker(ctx)
+		}()
+	}
+
+	if a.MQTTGateway != nil {
+		run(&ingress, ingressCtx, a.MQTTGateway.Run)
+	}
+	run(&workers, ctx, a.PaymentDispatcher.Run)
+	run(&workers, ctx, a.TelemetryRecorder.Run)
+	run(&workers, ctx, a.HeartbeatWriter.Run)
+	run(&workers, ctx, a.IndexPruner.Run)
+	run(&workers, ctx, a.LeaseSweeper.Run)
+	run(&workers, ctx, a.WaitlistPromoter.Run)
+	run(&workers, ctx, a.SLAEvaluator.Run)
+	run(&workers, ctx, a.TelemetryPartitionMgr.Run)
+
+	return func() {
+		stopIngress()
+		ingress.Wait()
+		cancel()
+		workers.Wait()
+	}
+}
+
 func (a *AppContext) Close() {
 	a.DB.Close()
 	a.Redis.Close()
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -20,6 +21,21 @@ type Config struct {
 	Zone           ZoneConfig
 	Drone          DroneConfig
 	Mapbox         MapboxConfig
+	Pricing        PricingConfig
+	Payment        PaymentConfig
+	Maintenance    MaintenanceConfig
+	Telemetry      TelemetryConfig
+	Ingest         IngestConfig
+	MQTT           MQTTConfig
+	Command        CommandConfig
+	Grounding      GroundingConfig
+	Weather        WeatherConfig
+	Airspace       AirspaceConfig
+	Lease          LeaseConfig
+	Queue          QueueConfig
+	Admission      AdmissionConfig
+	SLA            SLAConfig
+	Bulk           BulkConfig
 }
 
 type ServerConfig struct {
@@ -50,9 +66,27 @@ type RedisConfig struct {
 	DB       int
 }
 
+// RateLimiterConfig holds one quota per route group. MaxRequests and
+// WindowSeconds are the per-IP limit on /auth, where no caller is known yet.
+// Global is a coarse per-IP ceiling charged before authentication, so
+// requests with forged or junk tokens are shed before they cost a JWT check.
 type RateLimiterConfig struct {
 	MaxRequests   int
 	WindowSeconds int
+	Global        RateLimitPolicy
+	Enduser       RateLimitPolicy
+	Heartbeat     RateLimitPolicy
+	Drone         RateLimitPolicy
+	Admin         RateLimitPolicy
+}
+
+// RateLimitPolicy is a token bucket per Key (ip, sub, role or tenant)
+// refilling at Requests per WindowSeconds; Burst 0 means Requests.
+type RateLimitPolicy struct {
+	Key           string
+	Requests      int
+	WindowSeconds int
+	Burst         int
 }
 
 type CircuitBreakerConfig struct {
@@ -75,7 +109,24 @@ type ZoneConfig struct {
 type DroneConfig struct {
 	SpeedKMH            float64
 	LocationCacheTTLSec int
-	IdempotencyTTLSec   int
+	// Drones silent for LocationCacheTTLSec are pruned from the GEO index
+	// every GeoPruneInterval.
+	GeoPruneInterval  time.Duration
+	IdempotencyTTLSec int
+	// An in-flight Idempotency-Key is freed after IdempotencyLockSec should
+	// its request die; a running request renews it. Bodies are
+	// fingerprinted up to the max.
+	IdempotencyLockSec      int
+	IdempotencyMaxBodyBytes int64
+
+	// Heartbeat validation
+	MaxSpeedKMH              float64 // movement faster than this is flagged; 0 disables
+	HeartbeatMaxClockSkew    time.Duration
+	HeartbeatRequireSequence bool
+	AutoQuarantine           bool
+
+	// Heartbeat fault codes that put the drone through the broken flow
+	CriticalFaultCodes []string
 }
 
 type MapboxConfig struct {
@@ -83,6 +134,137 @@ type MapboxConfig struct {
 	AccessToken string
 }
 
+type PricingConfig struct {
+	RulesFile    string // JSON rule table; built-in defaults when empty
+	QuoteSecret  string
+	QuoteTTL     time.Duration
+	RequireQuote bool
+}
+
+type PaymentConfig struct {
+	FailedRefundPercent int
+	OutboxPollInterval  time.Duration
+	OutboxBatchSize     int
+	OutboxMaxAttempts   int
+	OutboxClaimTimeout  time.Duration
+}
+
+// MaintenanceConfig is the service interval for drone models without a
+// stored schedule. Zero disables a limit.
+type MaintenanceConfig struct {
+	DefaultMaxFlightHours float64
+	DefaultMaxFlightKM    float64
+	DefaultMaxCycles      int
+}
+
+type TelemetryConfig struct {
+	BufferSize          int
+	BatchSize           int
+	FlushInterval       time.Duration
+	RetentionDays       int
+	PartitionsAhead     int
+	MaintenanceInterval time.Duration
+}
+
+// IngestConfig covers batched heartbeat ingestion (POST /drone/me/telemetry).
+type IngestConfig struct {
+	MaxSamples    int   // samples per batch
+	MaxBodyBytes  int64 // body size after decompression
+	FlushInterval time.Duration
+}
+
+// MQTTConfig controls the embedded MQTT gateway for drones.
+type MQTTConfig struct {
+	Enabled bool
+	Addr    string
+}
+
+// CommandConfig covers the ground-control command queue.
+type CommandConfig struct {
+	TTL     time.Duration // unacknowledged commands expire after this
+	MaxWait time.Duration // cap on a long-poll for commands
+}
+
+// GroundingConfig holds the defaults for groundings that don't set them.
+type GroundingConfig struct {
+	OrderPolicy string // QUEUE or REJECT
+	DroneAction string // RETURN_TO_BASE or LAND_IMMEDIATELY
+}
+
+// WeatherConfig covers the weather provider, its cache and flight limits.
+type WeatherConfig struct {
+	FixtureFile     string  // JSON conditions for local runs; calm when empty
+	CacheTTLSec     int     // how long a grid cell's conditions are cached
+	GridDeg         float64 // grid cell size in degrees (0.1° ≈ 11 km)
+	MaxWindKMH      float64
+	MinVisibilityKM float64
+}
+
+// AirspaceConfig describes the altitude lanes used for corridor bookings.
+type AirspaceConfig struct {
+	Lanes        int
+	LaneBaseM    float64
+	LaneHeightM  float64
+	SeparationKM float64
+	SpeedKMH     float64
+	Buffer       time.Duration
+	MaxDelay     time.Duration // a departure pushed back further is refused
+}
+
+// LeaseConfig sizes job reservation leases: a drone must reach the pickup
+// by distance/SpeedKMH + Grace after reserving, or the job is reopened.
+type LeaseConfig struct {
+	SpeedKMH      float64
+	Grace         time.Duration
+	SweepInterval time.Duration
+	BatchSize     int
+}
+
+// QueueConfig orders open jobs for dispatch. A waiting job gains one
+// priority level every AgingStep.
+type QueueConfig struct {
+	AgingStep time.Duration
+}
+
+// AdmissionConfig sizes the delivery promise made at order placement and
+// what happens to orders that can't meet it.
+type AdmissionConfig struct {
+	SLA             time.Duration // 0 disables admission control
+	Policy          string        // WAITLIST or REJECT
+	ServiceTime     time.Duration // per-order drone time until enough deliveries are measured
+	MinSamples      int
+	SampleSize      int
+	PickupLead      time.Duration
+	SpeedKMH        float64
+	PromoteInterval time.Duration
+	PromoteBatch    int
+}
+
+// SLATargets are the longest times from placement to assignment, pickup
+// and delivery for one service tier; 0 leaves a milestone untracked.
+type SLATargets struct {
+	Assign   time.Duration
+	Pickup   time.Duration
+	Delivery time.Duration
+}
+
+// SLAConfig holds the delivery SLA targets per tier and the evaluator that
+// flags open orders.
+type SLAConfig struct {
+	Standard     SLATargets
+	Express      SLATargets
+	AtRiskRatio  float64 // share of a target elapsed before an order is at risk
+	EvalInterval time.Duration
+	EvalBatch    int
+}
+
+// BulkConfig bounds order imports.
+type BulkConfig struct {
+	ImportMaxRows   int
+	ImportBatchSize int
+	ImportMaxBytes  int64
+}
+
 func getenv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -114,6 +296,46 @@ func getenvFloat(key string, fallback float64) float64 {
 	return v
 }
 
+func getenvBool(key string, fallback bool) bool {
+	s := os.Getenv(key)
+	if s == "" {
+		return fallback
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return fallback
+	}
+	return v
+}
+
+// getenvRateLimit reads RATE_LIMIT_<group>_KEY, _REQUESTS, _WINDOW_SECONDS
+// and _BURST over fallback.
+func getenvRateLimit(group string, fallback RateLimitPolicy) RateLimitPolicy {
+	prefix := "RATE_LIMIT_" + group + "_"
+	return RateLimitPolicy{
+		Key:           getenv(prefix+"KEY", fallback.Key),
+		Requests:      getenvInt(prefix+"REQUESTS", fallback.Requests),
+		WindowSeconds: getenvInt(prefix+"WINDOW_SECONDS", fallback.WindowSeconds),
+		Burst:         getenvInt(prefix+"BURST", fallback.Burst),
+	}
+}
+
+// getenvList splits a comma-separated value, dropping empty entries. An
+// unset variable yields fallback; a set but empty one yields no entries.
+func getenvList(key string, fallback []string) []string {
+	s, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
@@ -145,6 +367,11 @@ func Load() (*Config, error) {
 		RateLimiter: RateLimiterConfig{
 			MaxRequests:   getenvInt("RATE_LIMIT_MAX_REQUESTS", 100),
 			WindowSeconds: getenvInt("RATE_LIMIT_WINDOW_SECONDS", 60),
+			Global:        getenvRateLimit("GLOBAL", RateLimitPolicy{Key: "ip", Requests: 3000, WindowSeconds: 60, Burst: 500}),
+			Enduser:       getenvRateLimit("ENDUSER", RateLimitPolicy{Key: "sub", Requests: 100, WindowSeconds: 60, Burst: 20}),
+			Heartbeat:     getenvRateLimit("HEARTBEAT", RateLimitPolicy{Key: "sub", Requests: 240, WindowSeconds: 60, Burst: 20}),
+			Drone:         getenvRateLimit("DRONE", RateLimitPolicy{Key: "sub", Requests: 300, WindowSeconds: 60, Burst: 50}),
+			Admin:         getenvRateLimit("ADMIN", RateLimitPolicy{Key: "sub", Requests: 600, WindowSeconds: 60}),
 		},
 		CircuitBreaker: CircuitBreakerConfig{
 			FailureThreshold: getenvInt("CB_FAILURE_THRESHOLD", 5),
@@ -161,9 +388,21 @@ func Load() (*Config, error) {
 			RadiusKM:  getenvFloat("ZONE_RADIUS_KM", 50),
 		},
 		Drone: DroneConfig{
-			SpeedKMH:            getenvFloat("DRONE_SPEED_KMH", 50),
-			LocationCacheTTLSec: getenvInt("DRONE_LOCATION_CACHE_TTL_SECONDS", 60),
-			IdempotencyTTLSec:   getenvInt("IDEMPOTENCY_TTL_SECONDS", 300),
+			SpeedKMH:                getenvFloat("DRONE_SPEED_KMH", 50),
+			LocationCacheTTLSec:     getenvInt("DRONE_LOCATION_CACHE_TTL_SECONDS", 60),
+			GeoPruneInterval:        time.Duration(getenvInt("DRONE_GEO_PRUNE_INTERVAL_SECONDS", 30)) * time.Second,
+			IdempotencyTTLSec:       getenvInt("IDEMPOTENCY_TTL_SECONDS", 300),
+			IdempotencyLockSec:      getenvInt("IDEMPOTENCY_LOCK_SECONDS", 30),
+			IdempotencyMaxBodyBytes: int64(getenvInt("IDEMPOTENCY_MAX_BODY_KB", 4096)) * 1024,
+
+			MaxSpeedKMH:              getenvFloat("DRONE_MAX_SPEED_KMH", 120),
+			HeartbeatMaxClockSkew:    time.Duration(getenvInt("HEARTBEAT_MAX_CLOCK_SKEW_SECONDS", 30)) * time.Second,
+			HeartbeatRequireSequence: getenvBool("HEARTBEAT_REQUIRE_SEQUENCE", false),
+			AutoQuarantine:           getenvBool("DRONE_AUTO_QUARANTINE", false),
+
+			CriticalFaultCodes: getenvList("DRONE_CRITICAL_FAULT_CODES", []string{
+				"MOTOR_FAILURE", "BATTERY_CRITICAL", "GPS_FAILURE", "FLIGHT_CONTROLLER_FAILURE",
+			}),
 		},
 		Mapbox: MapboxConfig{
 			BaseURL:     getenv("MAPBOX_BASE_URL", "https://api.mapbox.com"),
@@ -171,6 +410,120 @@ func Load() (*Config, error) {
 		},
 	}
 
+	cfg.Pricing = PricingConfig{
+		RulesFile:    getenv("PRICING_RULES_FILE", ""),
+		QuoteSecret:  getenv("PRICING_QUOTE_SECRET", cfg.JWT.Secret),
+		QuoteTTL:     time.Duration(getenvInt("PRICING_QUOTE_TTL_MINUTES", 10)) * time.Minute,
+		RequireQuote: getenvBool("PRICING_REQUIRE_QUOTE", false),
+	}
+
+	cfg.Payment = PaymentConfig{
+		FailedRefundPercent: getenvInt("PAYMENT_FAILED_REFUND_PERCENT", 100),
+		OutboxPollInterval:  time.Duration(getenvInt("PAYMENT_OUTBOX_POLL_MS", 1000)) * time.Millisecond,
+		OutboxBatchSize:     getenvInt("PAYMENT_OUTBOX_BATCH_SIZE", 50),
+		OutboxMaxAttempts:   getenvInt("PAYMENT_OUTBOX_MAX_ATTEMPTS", 10),
+		OutboxClaimTimeout:  time.Duration(getenvInt("PAYMENT_OUTBOX_CLAIM_SECONDS", 60)) * time.Second,
+	}
+
+	cfg.Maintenance = MaintenanceConfig{
+		DefaultMaxFlightHours: getenvFloat("MAINTENANCE_DEFAULT_MAX_FLIGHT_HOURS", 50),
+		DefaultMaxFlightKM:    getenvFloat("MAINTENANCE_DEFAULT_MAX_FLIGHT_KM", 1500),
+		DefaultMaxCycles:      getenvInt("MAINTENANCE_DEFAULT_MAX_CYCLES", 200),
+	}
+
+	cfg.Telemetry = TelemetryConfig{
+		BufferSize:          getenvInt("TELEMETRY_BUFFER_SIZE", 10000),
+		BatchSize:           getenvInt("TELEMETRY_BATCH_SIZE", 500),
+		FlushInterval:       time.Duration(getenvInt("TELEMETRY_FLUSH_MS", 1000)) * time.Millisecond,
+		RetentionDays:       getenvInt("TELEMETRY_RETENTION_DAYS", 30),
+		PartitionsAhead:     getenvInt("TELEMETRY_PARTITIONS_AHEAD", 3),
+		MaintenanceInterval: time.Duration(getenvInt("TELEMETRY_MAINTENANCE_MINUTES", 60)) * time.Minute,
+	}
+
+	cfg.Ingest = IngestConfig{
+		MaxSamples:    getenvInt("INGEST_MAX_SAMPLES", 500),
+		MaxBodyBytes:  int64(getenvInt("INGEST_MAX_BODY_KB", 1024)) * 1024,
+		FlushInterval: time.Duration(getenvInt("INGEST_FLUSH_MS", 1000)) * time.Millisecond,
+	}
+
+	cfg.MQTT = MQTTConfig{
+		Enabled: getenvBool("MQTT_ENABLED", false),
+		Addr:    getenv("MQTT_ADDR", ":1883"),
+	}
+
+	cfg.Command = CommandConfig{
+		TTL:     time.Duration(getenvInt("COMMAND_TTL_SECONDS", 300)) * time.Second,
+		MaxWait: time.Duration(getenvInt("COMMAND_MAX_WAIT_SECONDS", 30)) * time.Second,
+	}
+
+	cfg.Grounding = GroundingConfig{
+		OrderPolicy: getenv("GROUNDING_ORDER_POLICY", "QUEUE"),
+		DroneAction: getenv("GROUNDING_DRONE_ACTION", "RETURN_TO_BASE"),
+	}
+
+	cfg.Weather = WeatherConfig{
+		FixtureFile:     getenv("WEATHER_FIXTURE_FILE", ""),
+		CacheTTLSec:     getenvInt("WEATHER_CACHE_TTL_SECONDS", 300),
+		GridDeg:         getenvFloat("WEATHER_GRID_DEG", 0.1),
+		MaxWindKMH:      getenvFloat("WEATHER_MAX_WIND_KMH", 35),
+		MinVisibilityKM: getenvFloat("WEATHER_MIN_VISIBILITY_KM", 1),
+	}
+
+	cfg.Airspace = AirspaceConfig{
+		Lanes:        getenvInt("AIRSPACE_LANES", 4),
+		LaneBaseM:    getenvFloat("AIRSPACE_LANE_BASE_M", 60),
+		LaneHeightM:  getenvFloat("AIRSPACE_LANE_HEIGHT_M", 20),
+		SeparationKM: getenvFloat("AIRSPACE_SEPARATION_KM", 0.2),
+		SpeedKMH:     getenvFloat("AIRSPACE_SPEED_KMH", 30),
+		Buffer:       time.Duration(getenvInt("AIRSPACE_BUFFER_SECONDS", 300)) * time.Second,
+		MaxDelay:     time.Duration(getenvInt("AIRSPACE_MAX_DELAY_SECONDS", 600)) * time.Second,
+	}
+
+	cfg.Lease = LeaseConfig{
+		SpeedKMH:      getenvFloat("JOB_LEASE_SPEED_KMH", 30),
+		Grace:         time.Duration(getenvInt("JOB_LEASE_GRACE_SECONDS", 600)) * time.Second,
+		SweepInterval: time.Duration(getenvInt("JOB_LEASE_SWEEP_INTERVAL_SECONDS", 30)) * time.Second,
+		BatchSize:     getenvInt("JOB_LEASE_SWEEP_BATCH", 100),
+	}
+
+	cfg.Queue = QueueConfig{
+		AgingStep: time.Duration(getenvInt("JOB_PRIORITY_AGING_SECONDS", 120)) * time.Second,
+	}
+
+	cfg.Admission = AdmissionConfig{
+		SLA:             time.Duration(getenvInt("ADMISSION_SLA_MINUTES", 120)) * time.Minute,
+		Policy:          getenv("ADMISSION_POLICY", "WAITLIST"),
+		ServiceTime:     time.Duration(getenvInt("ADMISSION_SERVICE_MINUTES", 30)) * time.Minute,
+		MinSamples:      getenvInt("ADMISSION_MIN_SAMPLES", 5),
+		SampleSize:      getenvInt("ADMISSION_SAMPLE_SIZE", 50),
+		PickupLead:      time.Duration(getenvInt("ADMISSION_PICKUP_LEAD_MINUTES", 10)) * time.Minute,
+		SpeedKMH:        getenvFloat("ADMISSION_SPEED_KMH", 30),
+		PromoteInterval: time.Duration(getenvInt("ADMISSION_PROMOTE_INTERVAL_SECONDS", 30)) * time.Second,
+		PromoteBatch:    getenvInt("ADMISSION_PROMOTE_BATCH", 50),
+	}
+
+	cfg.SLA = SLAConfig{
+		Standard: SLATargets{
+			Assign:   time.Duration(getenvInt("SLA_STANDARD_ASSIGN_MINUTES", 30)) * time.Minute,
+			Pickup:   time.Duration(getenvInt("SLA_STANDARD_PICKUP_MINUTES", 60)) * time.Minute,
+			Delivery: time.Duration(getenvInt("SLA_STANDARD_DELIVERY_MINUTES", 120)) * time.Minute,
+		},
+		Express: SLATargets{
+			Assign:   time.Duration(getenvInt("SLA_EXPRESS_ASSIGN_MINUTES", 10)) * time.Minute,
+			Pickup:   time.Duration(getenvInt("SLA_EXPRESS_PICKUP_MINUTES", 30)) * time.Minute,
+			Delivery: time.Duration(getenvInt("SLA_EXPRESS_DELIVERY_MINUTES", 60)) * time.Minute,
+		},
+		AtRiskRatio:  getenvFloat("SLA_AT_RISK_RATIO", 0.8),
+		EvalInterval: time.Duration(getenvInt("SLA_EVAL_INTERVAL_SECONDS", 60)) * time.Second,
+		EvalBatch:    getenvInt("SLA_EVAL_BATCH", 200),
+	}
+
+	cfg.Bulk = BulkConfig{
+		ImportMaxRows:   getenvInt("IMPORT_MAX_ROWS", 1000),
+		ImportBatchSize: getenvInt("IMPORT_BATCH_SIZE", 100),
+		ImportMaxBytes:  int64(getenvInt("IMPORT_MAX_BODY_KB", 2048)) * 1024,
+	}
+
 	return cfg, nil
 }
 
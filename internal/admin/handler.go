@@ -8,6 +8,8 @@ import (
 	"drone-delivery/internal/drone"
 	"drone-delivery/internal/order"
 	"drone-delivery/internal/pkg/apperrors"
+	"drone-delivery/internal/pkg/etag"
+	"drone-delivery/internal/pkg/listing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -23,30 +25,58 @@ func NewHandler(adminService Service, orderService order.Service, droneService d
 	return &Handler{adminService: adminService, orderService: orderService, droneService: droneService}
 }
 
+// ListOrders pages through all orders, newest first by default. See
+// listing.Parse for the filters, sort and cursor.
 func (h *Handler) ListOrders(c *gin.Context) {
-	page, limit := parsePagination(c)
+	spec, err := listing.Parse(c.Request.URL.Query())
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
 
-	var statusPtr *order.Status
-	if s := c.Query("status"); s != "" {
-		st := order.Status(s)
-		statusPtr = &st
+	orders, next, total, err := h.adminService.ListOrders(c.Request.Context(), spec)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if orders == nil {
+		orders = []*order.Order{}
 	}
 
-	orders, total, err := h.adminService.ListOrders(c.Request.Context(), statusPtr, page, limit)
+	c.JSON(http.StatusOK, gin.H{"orders": orders, "total": total, "limit": spec.Limit, "next_cursor": next})
+}
+
+// GetOrder returns any order with its version as the ETag.
+func (h *Handler) GetOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid order id"}})
+		return
+	}
+
+	o, err := h.adminService.GetOrder(c.Request.Context(), id)
 	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"orders": orders, "total": total, "page": page, "limit": limit})
+	c.Header("ETag", etag.Format(o.Version))
+	c.JSON(http.StatusOK, gin.H{"order": o})
 }
 
+// UpdateOrder moves an order's pickup or drop-off. With If-Match it is
+// refused with 412 unless the order is still at that version.
 func (h *Handler) UpdateOrder(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid order id"}})
 		return
 	}
+	ifMatch, err := etag.ParseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
 
 	var req struct {
 		Origin      *common.Location `json:"origin"`
@@ -57,35 +87,89 @@ func (h *Handler) UpdateOrder(c *gin.Context) {
 		return
 	}
 
-	o, err := h.adminService.UpdateOrder(c.Request.Context(), id, req.Origin, req.Destination)
+	o, err := h.adminService.UpdateOrder(c.Request.Context(), id, req.Origin, req.Destination, ifMatch)
 	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
 	}
 
+	c.Header("ETag", etag.Format(o.Version))
 	c.JSON(http.StatusOK, gin.H{"order": o})
 }
 
+// ListDrones pages through the fleet, newest first by default.
 func (h *Handler) ListDrones(c *gin.Context) {
-	page, limit := parsePagination(c)
+	spec, err := listing.Parse(c.Request.URL.Query())
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	drones, next, total, err := h.adminService.ListDrones(c.Request.Context(), spec)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if drones == nil {
+		drones = []*drone.Drone{}
+	}
 
-	var statusPtr *drone.Status
+	c.JSON(http.StatusOK, gin.H{"drones": drones, "total": total, "limit": spec.Limit, "next_cursor": next})
+}
+
+// NearbyDrones lists drones within radius km of (lat, lng), nearest first.
+// status defaults to IDLE; radius defaults to 5 km.
+func (h *Handler) NearbyDrones(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	if errLat != nil || errLng != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "lat and lng are required numbers"}})
+		return
+	}
+	radius := 5.0
+	if r := c.Query("radius"); r != "" {
+		v, err := strconv.ParseFloat(r, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "radius must be a number"}})
+			return
+		}
+		radius = v
+	}
+	status := drone.StatusIdle
 	if s := c.Query("status"); s != "" {
-		st := drone.Status(s)
-		statusPtr = &st
+		status = drone.Status(s)
 	}
 
-	drones, total, err := h.adminService.ListDrones(c.Request.Context(), statusPtr, page, limit)
+	drones, err := h.adminService.FindNearbyDrones(c.Request.Context(), common.NewLocation(lat, lng), radius, status)
 	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"drones": drones, "total": total, "page": page, "limit": limit})
+	c.JSON(http.StatusOK, gin.H{"drones": drones})
 }
 
+// GetDrone returns a drone with its version as the ETag.
+func (h *Handler) GetDrone(c *gin.Context) {
+	d, err := h.adminService.GetDrone(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Format(d.Version))
+	c.JSON(http.StatusOK, gin.H{"drone": d})
+}
+
+// UpdateDroneStatus marks a drone broken or fixed. With If-Match it is
+// refused with 412 unless the drone is still at that version.
 func (h *Handler) UpdateDroneStatus(c *gin.Context) {
 	droneID := c.Param("id")
+	ifMatch, err := etag.ParseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
 
 	var req struct {
 		Status string `json:"status" binding:"required"`
@@ -95,26 +179,190 @@ func (h *Handler) UpdateDroneStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.adminService.UpdateDroneStatus(c.Request.Context(), droneID, req.Status); err != nil {
+	d, err := h.adminService.UpdateDroneStatus(c.Request.Context(), droneID, req.Status, c.GetString("sub"), ifMatch)
+	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
 	}
 
+	c.Header("ETag", etag.Format(d.Version))
 	c.JSON(http.StatusOK, gin.H{"message": "drone status updated", "status": req.Status})
 }
 
-func parsePagination(c *gin.Context) (int, int) {
-	page := 1
-	limit := 20
-	if p := c.Query("page"); p != "" {
-		if v, err := strconv.Atoi(p); err == nil && v > 0 {
-			page = v
-		}
+func (h *Handler) RegisterDrone(c *gin.Context) {
+	var req drone.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
 	}
-	if l := c.Query("limit"); l != "" {
-		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= 100 {
-			limit = v
-		}
+
+	d, err := h.adminService.RegisterDrone(c.Request.Context(), req.ID, req.Profile)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"drone": d})
+}
+
+// UpdateDrone edits a drone's profile. With If-Match it is refused with
+// 412 unless the drone is still at that version.
+func (h *Handler) UpdateDrone(c *gin.Context) {
+	droneID := c.Param("id")
+	ifMatch, err := etag.ParseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	var req drone.Profile
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	d, err := h.adminService.UpdateDrone(c.Request.Context(), droneID, req, ifMatch)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Format(d.Version))
+	c.JSON(http.StatusOK, gin.H{"drone": d})
+}
+
+func (h *Handler) RetireDrone(c *gin.Context) {
+	droneID := c.Param("id")
+
+	d, err := h.adminService.RetireDrone(c.Request.Context(), droneID)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "drone retired", "drone": d})
+}
+
+func (h *Handler) QuarantineDrone(c *gin.Context) {
+	var req drone.QuarantineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	d, err := h.adminService.QuarantineDrone(c.Request.Context(), c.Param("id"), req.Reason)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "drone quarantined", "drone": d})
+}
+
+func (h *Handler) LiftQuarantine(c *gin.Context) {
+	d, err := h.adminService.LiftQuarantine(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "quarantine lifted", "drone": d})
+}
+
+func (h *Handler) ListDroneAnomalies(c *gin.Context) {
+	anomalies, err := h.adminService.ListDroneAnomalies(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"anomalies": anomalies})
+}
+
+func (h *Handler) AssignJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	var req struct {
+		DroneID string `json:"drone_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	j, err := h.adminService.AssignJob(c.Request.Context(), jobID, req.DroneID)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": j})
+}
+
+// SetJobPriority bumps (or lowers) an open or reserved job in the dispatch
+// queue.
+func (h *Handler) SetJobPriority(c *gin.Context) {
+	jobID := c.Param("id")
+	ifMatch, err := etag.ParseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	var req struct {
+		Priority *int `json:"priority" binding:"required"`
 	}
-	return page, limit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	j, err := h.adminService.SetJobPriority(c.Request.Context(), jobID, *req.Priority, ifMatch)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag.Format(j.Version))
+	c.JSON(http.StatusOK, gin.H{"job": j})
+}
+
+func (h *Handler) UnassignOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid order id"}})
+		return
+	}
+
+	o, err := h.adminService.UnassignOrder(c.Request.Context(), id)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": o})
+}
+
+func (h *Handler) ReassignOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid order id"}})
+		return
+	}
+
+	var req struct {
+		DroneID string `json:"drone_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	o, err := h.adminService.ReassignOrder(c.Request.Context(), id, req.DroneID)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": o})
 }
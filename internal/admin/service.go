@@ -7,64 +7,152 @@ import (
 	"drone-delivery/internal/delivery"
 	"drone-delivery/internal/drone"
 	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/job"
+	"drone-delivery/internal/maintenance"
 	"drone-delivery/internal/order"
+	"drone-delivery/internal/pkg/listing"
 
 	"github.com/google/uuid"
 )
 
 type Service interface {
-	HandleDroneBroken(ctx context.Context, droneID string) error
-	MarkDroneFixed(ctx context.Context, droneID string) error
-	ListOrders(ctx context.Context, status *order.Status, page, limit int) ([]*order.Order, int, error)
-	UpdateOrder(ctx context.Context, orderID uuid.UUID, origin, dest *common.Location) (*order.Order, error)
-	ListDrones(ctx context.Context, status *drone.Status, page, limit int) ([]*drone.Drone, int, error)
-	UpdateDroneStatus(ctx context.Context, droneID, status string) error
+	HandleDroneBroken(ctx context.Context, droneID string, ifMatch *int64) (*drone.Drone, error)
+	MarkDroneFixed(ctx context.Context, droneID, actor string, ifMatch *int64) (*drone.Drone, error)
+	ListOrders(ctx context.Context, spec listing.Spec) ([]*order.Order, string, int, error)
+	GetOrder(ctx context.Context, orderID uuid.UUID) (*order.Order, error)
+	UpdateOrder(ctx context.Context, orderID uuid.UUID, origin, dest *common.Location, ifMatch *int64) (*order.Order, error)
+	ListDrones(ctx context.Context, spec listing.Spec) ([]*drone.Drone, string, int, error)
+	FindNearbyDrones(ctx context.Context, loc common.Location, radiusKM float64, status drone.Status) ([]*drone.NearbyDrone, error)
+	GetDrone(ctx context.Context, droneID string) (*drone.Drone, error)
+	UpdateDroneStatus(ctx context.Context, droneID, status, actor string, ifMatch *int64) (*drone.Drone, error)
+	RegisterDrone(ctx context.Context, droneID string, p drone.Profile) (*drone.Drone, error)
+	UpdateDrone(ctx context.Context, droneID string, p drone.Profile, ifMatch *int64) (*drone.Drone, error)
+	RetireDrone(ctx context.Context, droneID string) (*drone.Drone, error)
+	QuarantineDrone(ctx context.Context, droneID, reason string) (*drone.Drone, error)
+	LiftQuarantine(ctx context.Context, droneID string) (*drone.Drone, error)
+	ListDroneAnomalies(ctx context.Context, droneID string) ([]*drone.Anomaly, error)
+	AssignJob(ctx context.Context, jobID, droneID string) (*job.Job, error)
+	UnassignOrder(ctx context.Context, orderID uuid.UUID) (*order.Order, error)
+	ReassignOrder(ctx context.Context, orderID uuid.UUID, droneID string) (*order.Order, error)
+	SetJobPriority(ctx context.Context, jobID string, priority int, ifMatch *int64) (*job.Job, error)
 }
 
 type service struct {
-	orderService    order.Service
-	droneService    drone.Service
-	deliveryService delivery.Service
+	orderService       order.Service
+	droneService       drone.Service
+	deliveryService    delivery.Service
+	maintenanceService maintenance.Service
 }
 
-func NewService(orderService order.Service, droneService drone.Service, deliveryService delivery.Service) Service {
-	return &service{orderService: orderService, droneService: droneService, deliveryService: deliveryService}
+func NewService(orderService order.Service, droneService drone.Service, deliveryService delivery.Service, maintenanceService maintenance.Service) Service {
+	return &service{
+		orderService:       orderService,
+		droneService:       droneService,
+		deliveryService:    deliveryService,
+		maintenanceService: maintenanceService,
+	}
+}
+
+func (s *service) HandleDroneBroken(ctx context.Context, droneID string, ifMatch *int64) (*drone.Drone, error) {
+	return s.deliveryService.MarkDroneBroken(ctx, droneID, ifMatch)
 }
 
-func (s *service) HandleDroneBroken(ctx context.Context, droneID string) error {
-	return s.deliveryService.HandleDroneBroken(ctx, droneID)
+// MarkDroneFixed also closes the repair work order opened when the drone broke.
+func (s *service) MarkDroneFixed(ctx context.Context, droneID, actor string, ifMatch *int64) (*drone.Drone, error) {
+	return s.maintenanceService.MarkFixed(ctx, droneID, actor, ifMatch)
 }
 
-func (s *service) MarkDroneFixed(ctx context.Context, droneID string) error {
-	d, err := s.droneService.GetByID(ctx, droneID)
+// ListOrders returns a page of orders, the cursor for the next and the
+// number matching the filters.
+func (s *service) ListOrders(ctx context.Context, spec listing.Spec) ([]*order.Order, string, int, error) {
+	orders, next, err := s.orderService.List(ctx, spec)
 	if err != nil {
-		return err
+		return nil, "", 0, err
 	}
-	if err := d.MarkFixed(); err != nil {
-		return err
+	total, err := s.orderService.Count(ctx, spec)
+	if err != nil {
+		return nil, "", 0, err
 	}
-	return s.droneService.UpdateStatus(ctx, d)
+	return orders, next, total, nil
+}
+
+func (s *service) GetOrder(ctx context.Context, orderID uuid.UUID) (*order.Order, error) {
+	return s.orderService.GetOrder(ctx, orderID)
+}
+
+func (s *service) UpdateOrder(ctx context.Context, orderID uuid.UUID, origin, dest *common.Location, ifMatch *int64) (*order.Order, error) {
+	return s.orderService.AdminUpdateOrder(ctx, orderID, origin, dest, ifMatch)
 }
 
-func (s *service) ListOrders(ctx context.Context, status *order.Status, page, limit int) ([]*order.Order, int, error) {
-	return s.orderService.ListAll(ctx, status, page, limit)
+// ListDrones returns a page of drones, the cursor for the next and the
+// number matching the filters.
+func (s *service) ListDrones(ctx context.Context, spec listing.Spec) ([]*drone.Drone, string, int, error) {
+	drones, next, err := s.droneService.List(ctx, spec)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	total, err := s.droneService.Count(ctx, spec)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return drones, next, total, nil
 }
 
-func (s *service) UpdateOrder(ctx context.Context, orderID uuid.UUID, origin, dest *common.Location) (*order.Order, error) {
-	return s.orderService.AdminUpdateOrder(ctx, orderID, origin, dest)
+func (s *service) FindNearbyDrones(ctx context.Context, loc common.Location, radiusKM float64, status drone.Status) ([]*drone.NearbyDrone, error) {
+	return s.droneService.FindNearby(ctx, loc, radiusKM, status)
 }
 
-func (s *service) ListDrones(ctx context.Context, status *drone.Status, page, limit int) ([]*drone.Drone, int, error) {
-	return s.droneService.ListAll(ctx, status, page, limit)
+func (s *service) GetDrone(ctx context.Context, droneID string) (*drone.Drone, error) {
+	return s.droneService.GetByID(ctx, droneID)
 }
 
-func (s *service) UpdateDroneStatus(ctx context.Context, droneID, status string) error {
+func (s *service) UpdateDroneStatus(ctx context.Context, droneID, status, actor string, ifMatch *int64) (*drone.Drone, error) {
 	switch status {
 	case "broken":
-		return s.HandleDroneBroken(ctx, droneID)
+		return s.HandleDroneBroken(ctx, droneID, ifMatch)
 	case "fixed":
-		return s.MarkDroneFixed(ctx, droneID)
+		return s.MarkDroneFixed(ctx, droneID, actor, ifMatch)
 	default:
-		return domainerrors.NewValidation("status must be 'broken' or 'fixed'")
+		return nil, domainerrors.NewValidation("status must be 'broken' or 'fixed'")
 	}
 }
+
+func (s *service) RegisterDrone(ctx context.Context, droneID string, p drone.Profile) (*drone.Drone, error) {
+	return s.droneService.Register(ctx, droneID, p)
+}
+
+func (s *service) UpdateDrone(ctx context.Context, droneID string, p drone.Profile, ifMatch *int64) (*drone.Drone, error) {
+	return s.droneService.UpdateProfile(ctx, droneID, p, ifMatch)
+}
+
+func (s *service) RetireDrone(ctx context.Context, droneID string) (*drone.Drone, error) {
+	return s.droneService.Retire(ctx, droneID)
+}
+
+func (s *service) QuarantineDrone(ctx context.Context, droneID, reason string) (*drone.Drone, error) {
+	return s.droneService.Quarantine(ctx, droneID, reason)
+}
+
+func (s *service) LiftQuarantine(ctx context.Context, droneID string) (*drone.Drone, error) {
+	return s.droneService.LiftQuarantine(ctx, droneID)
+}
+
+func (s *service) ListDroneAnomalies(ctx context.Context, droneID string) ([]*drone.Anomaly, error) {
+	return s.droneService.ListAnomalies(ctx, droneID)
+}
+
+func (s *service) AssignJob(ctx context.Context, jobID, droneID string) (*job.Job, error) {
+	return s.deliveryService.AssignJobToDrone(ctx, jobID, droneID)
+}
+
+func (s *service) UnassignOrder(ctx context.Context, orderID uuid.UUID) (*order.Order, error) {
+	return s.deliveryService.UnassignOrder(ctx, orderID)
+}
+
+func (s *service) ReassignOrder(ctx context.Context, orderID uuid.UUID, droneID string) (*order.Order, error) {
+	return s.deliveryService.ReassignOrder(ctx, orderID, droneID)
+}
+
+func (s *service) SetJobPriority(ctx context.Context, jobID string, priority int, ifMatch *int64) (*job.Job, error) {
+	return s.deliveryService.SetJobPriority(ctx, jobID, priority, ifMatch)
+}
@@ -0,0 +1,82 @@
+package admission
+
+import (
+	"time"
+
+	"drone-delivery/internal/common"
+)
+
+// Policy decides what happens to an order the fleet can't deliver within
+// the SLA.
+type Policy string
+
+const (
+	// PolicyWaitlist accepts the order as WAITLISTED; it is released for
+	// dispatch once the promise fits the SLA again.
+	PolicyWaitlist Policy = "WAITLIST"
+	// PolicyReject refuses the order.
+	PolicyReject Policy = "REJECT"
+)
+
+func (p Policy) Valid() bool {
+	return p == PolicyWaitlist || p == PolicyReject
+}
+
+// Config sizes the promise made at placement.
+type Config struct {
+	SLA         time.Duration // longest acceptable placement-to-delivery promise; 0 disables admission control
+	Policy      Policy
+	ServiceTime time.Duration // per-order drone time used until enough deliveries have been measured
+	MinSamples  int           // deliveries needed before the measured average replaces ServiceTime
+	SampleSize  int           // recent deliveries averaged
+	PickupLead  time.Duration // from assignment to the drone reaching the pickup
+	SpeedKMH    float64       // cruise speed for the pickup-to-drop-off leg
+}
+
+// Capacity is the fleet's state as seen by a new order.
+type Capacity struct {
+	QueueAhead  int           // open jobs and waitlisted orders the order would wait behind
+	IdleDrones  int           // drones free to take a job now
+	Fleet       int           // drones idle or on a mission
+	ServiceTime time.Duration // how long each order occupies a drone
+}
+
+// Promise is the pickup and delivery window given to the customer. The
+// times are nil when no drone is in service.
+type Promise struct {
+	PickupBy   *time.Time `json:"pickup_by,omitempty"`
+	DeliveryBy *time.Time `json:"delivery_by,omitempty"`
+	QueueAhead int        `json:"queue_ahead"`
+	IdleDrones int        `json:"idle_drones"`
+	Waitlisted bool       `json:"waitlisted"`
+}
+
+// Estimate promises an order from origin to dest placed at now. Orders
+// ahead of it first take the idle drones; the rest are served in rounds,
+// one per drone in the fleet, each round lasting c.ServiceTime.
+func Estimate(c Capacity, origin, dest common.Location, now time.Time, cfg Config) *Promise {
+	p := &Promise{QueueAhead: c.QueueAhead, IdleDrones: c.IdleDrones}
+	if c.Fleet == 0 {
+		return p
+	}
+
+	var wait time.Duration
+	if c.QueueAhead >= c.IdleDrones {
+		rounds := (c.QueueAhead-c.IdleDrones)/c.Fleet + 1
+		wait = time.Duration(rounds) * c.ServiceTime
+	}
+	pickupBy := now.Add(wait + cfg.PickupLead)
+	flight := time.Duration(common.FlightETAMinutes(origin, dest, cfg.SpeedKMH, nil) * float64(time.Minute))
+	deliveryBy := pickupBy.Add(flight)
+	p.PickupBy, p.DeliveryBy = &pickupBy, &deliveryBy
+	return p
+}
+
+// Exceeds reports whether p breaks the SLA for an order placed at now. With
+// admission control on, a promise without times always does.
+func (cfg Config) Exceeds(p *Promise, now time.Time) bool {
+	if cfg.SLA <= 0 {
+		return false
+	}
+	return p.DeliveryBy == nil || p.DeliveryBy.Sub(now) > cfg.SLA
+}
@@ -0,0 +1,115 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Service interface {
+	Promise(ctx context.Context, origin, dest common.Location, priority int) (*Promise, error)
+	Repromise(ctx context.Context, origin, dest common.Location, priority int) (*Promise, error)
+}
+
+// FleetStats is satisfied by drone.Service.
+type FleetStats interface {
+	CountByStatus(ctx context.Context) (map[drone.Status]int, error)
+}
+
+// Backlog is satisfied by job.Service.
+type Backlog interface {
+	CountOpenAhead(ctx context.Context, priority int) (int, error)
+}
+
+// Orders is satisfied by order.Service.
+type Orders interface {
+	AverageServiceTime(ctx context.Context, sample int) (time.Duration, int, error)
+	CountWaitlisted(ctx context.Context) (int, error)
+}
+
+type service struct {
+	fleet   FleetStats
+	backlog Backlog
+	orders  Orders
+	cfg     Config
+}
+
+func NewService(fleet FleetStats, backlog Backlog, orders Orders, cfg Config) Service {
+	return &service{fleet: fleet, backlog: backlog, orders: orders, cfg: cfg}
+}
+
+// --------------------------------------------------------------
+// Promise estimates when a new order at priority would be picked up and
+// delivered. Waitlisted orders are promoted first, so they count as ahead
+// of it. If that breaks the SLA the order is refused under the REJECT
+// policy, or the promise is marked waitlisted.
+func (s *service) Promise(ctx context.Context, origin, dest common.Location, priority int) (*Promise, error) {
+	c, err := s.capacity(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := s.orders.CountWaitlisted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.QueueAhead += waiting
+	return s.promise(c, origin, dest)
+}
+
+// --------------------------------------------------------------
+// Repromise estimates the oldest waitlisted order again. The orders still
+// waitlisted are behind it, so only open jobs count as ahead.
+func (s *service) Repromise(ctx context.Context, origin, dest common.Location, priority int) (*Promise, error) {
+	c, err := s.capacity(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	return s.promise(c, origin, dest)
+}
+
+func (s *service) promise(c Capacity, origin, dest common.Location) (*Promise, error) {
+	now := time.Now()
+	p := Estimate(c, origin, dest, now, s.cfg)
+	if !s.cfg.Exceeds(p, now) {
+		return p, nil
+	}
+	if s.cfg.Policy == PolicyReject {
+		if p.DeliveryBy == nil {
+			return nil, domainerrors.OverCapacity("no drones are in service")
+		}
+		return nil, domainerrors.OverCapacity(fmt.Sprintf("earliest delivery is %s, beyond the %s promise",
+			p.DeliveryBy.UTC().Format(time.RFC3339), s.cfg.SLA))
+	}
+	p.Waitlisted = true
+	return p, nil
+}
+
+func (s *service) capacity(ctx context.Context, priority int) (Capacity, error) {
+	counts, err := s.fleet.CountByStatus(ctx)
+	if err != nil {
+		return Capacity{}, domainerrors.NewInternal("failed to read fleet status", err)
+	}
+	ahead, err := s.backlog.CountOpenAhead(ctx, priority)
+	if err != nil {
+		return Capacity{}, err
+	}
+	serviceTime, n, err := s.orders.AverageServiceTime(ctx, s.cfg.SampleSize)
+	if err != nil {
+		return Capacity{}, err
+	}
+	if n < s.cfg.MinSamples || serviceTime <= 0 {
+		serviceTime = s.cfg.ServiceTime
+	}
+
+	idle := counts[drone.StatusIdle]
+	return Capacity{
+		QueueAhead:  ahead,
+		IdleDrones:  idle,
+		Fleet:       idle + counts[drone.StatusEnRoutePickup] + counts[drone.StatusEnRouteDelivery],
+		ServiceTime: serviceTime,
+	}, nil
+}
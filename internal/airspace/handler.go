@@ -0,0 +1,44 @@
+package airspace
+
+import (
+	"net/http"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// --------------------------------------------------------------
+// ListActive returns the corridors currently booked, earliest departure
+// first.
+func (h *Handler) ListActive(c *gin.Context) {
+	rs, err := h.service.ListActive(c.Request.Context())
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if rs == nil {
+		rs = []*Reservation{}
+	}
+	c.JSON(http.StatusOK, gin.H{"reservations": rs})
+}
+
+// --------------------------------------------------------------
+// Mine returns the calling drone's corridor: its altitude band and when it
+// may depart.
+func (h *Handler) Mine(c *gin.Context) {
+	r, err := h.service.ActiveForDrone(c.Request.Context(), c.GetString("sub"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reservation": r})
+}
@@ -0,0 +1,179 @@
+package airspace
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// Config describes the altitude lanes and how corridors are booked.
+type Config struct {
+	Lanes        int           // number of altitude bands
+	LaneBaseM    float64       // floor of the lowest band
+	LaneHeightM  float64       // thickness of each band
+	SeparationKM float64       // minimum horizontal gap between corridors in one band
+	SpeedKMH     float64       // cruise speed used to size the time window
+	Buffer       time.Duration // added to each window for takeoff, pickup and landing
+	MaxDelay     time.Duration // how far a departure may be pushed back before refusing
+}
+
+// Reservation books a drone's corridor (start -> pickup -> dropoff) in one
+// altitude band for a time window.
+type Reservation struct {
+	ID           uuid.UUID  `db:"id" json:"id"`
+	OrderID      uuid.UUID  `db:"order_id" json:"order_id"`
+	DroneID      string     `db:"drone_id" json:"drone_id"`
+	StartLat     float64    `db:"start_lat" json:"start_lat"`
+	StartLng     float64    `db:"start_lng" json:"start_lng"`
+	PickupLat    float64    `db:"pickup_lat" json:"pickup_lat"`
+	PickupLng    float64    `db:"pickup_lng" json:"pickup_lng"`
+	DropoffLat   float64    `db:"dropoff_lat" json:"dropoff_lat"`
+	DropoffLng   float64    `db:"dropoff_lng" json:"dropoff_lng"`
+	Band         int        `db:"band" json:"band"`
+	MinAltitudeM float64    `db:"min_altitude_m" json:"min_altitude_m"`
+	MaxAltitudeM float64    `db:"max_altitude_m" json:"max_altitude_m"`
+	StartsAt     time.Time  `db:"starts_at" json:"starts_at"`
+	EndsAt       time.Time  `db:"ends_at" json:"ends_at"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	ReleasedAt   *time.Time `db:"released_at" json:"released_at,omitempty"`
+}
+
+// Path returns the corridor's waypoints: start, pickup, dropoff.
+func (r *Reservation) Path() []common.Location {
+	return []common.Location{
+		common.NewLocation(r.StartLat, r.StartLng),
+		common.NewLocation(r.PickupLat, r.PickupLng),
+		common.NewLocation(r.DropoffLat, r.DropoffLng),
+	}
+}
+
+func (r *Reservation) overlaps(start, end time.Time) bool {
+	return r.StartsAt.Before(end) && start.Before(r.EndsAt)
+}
+
+// Plan books the corridor start -> pickup -> dropoff against the active
+// reservations. It takes the lowest free band at now; if every band is taken
+// it tries again as each conflicting reservation ends, up to cfg.MaxDelay.
+func Plan(active []*Reservation, orderID uuid.UUID, droneID string, start, pickup, dropoff common.Location, now time.Time, cfg Config) (*Reservation, error) {
+	r := &Reservation{
+		ID:         uuid.New(),
+		OrderID:    orderID,
+		DroneID:    droneID,
+		StartLat:   start.Lat,
+		StartLng:   start.Lng,
+		PickupLat:  pickup.Lat,
+		PickupLng:  pickup.Lng,
+		DropoffLat: dropoff.Lat,
+		DropoffLng: dropoff.Lng,
+		CreatedAt:  now,
+	}
+	path := r.Path()
+	km := common.HaversineDistance(start, pickup) + common.HaversineDistance(pickup, dropoff)
+	window := time.Duration(km/cfg.SpeedKMH*float64(time.Hour)) + cfg.Buffer
+
+	// Only reservations whose corridor comes too close can block this one
+	var crossing []*Reservation
+	for _, a := range active {
+		if a.DroneID != droneID && corridorGapKM(path, a.Path()) < cfg.SeparationKM {
+			crossing = append(crossing, a)
+		}
+	}
+
+	for _, departAt := range departureTimes(crossing, now, now.Add(cfg.MaxDelay)) {
+		end := departAt.Add(window)
+		for band := 0; band < cfg.Lanes; band++ {
+			if bandFree(crossing, band, departAt, end) {
+				r.Band = band
+				r.MinAltitudeM = cfg.LaneBaseM + float64(band)*cfg.LaneHeightM
+				r.MaxAltitudeM = r.MinAltitudeM + cfg.LaneHeightM
+				r.StartsAt, r.EndsAt = departAt, end
+				return r, nil
+			}
+		}
+	}
+	return nil, domainerrors.AirspaceCongested()
+}
+
+// departureTimes is now followed by each crossing reservation's end, in
+// order, up to latest.
+func departureTimes(crossing []*Reservation, now, latest time.Time) []time.Time {
+	times := []time.Time{now}
+	for _, a := range crossing {
+		if a.EndsAt.After(now) && !a.EndsAt.After(latest) {
+			times = append(times, a.EndsAt)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func bandFree(crossing []*Reservation, band int, start, end time.Time) bool {
+	for _, a := range crossing {
+		if a.Band == band && a.overlaps(start, end) {
+			return false
+		}
+	}
+	return true
+}
+
+// --------------------------------------------------------------
+// Corridor geometry. Corridors span a few km, so points are projected onto a
+// flat plane (km) around the first waypoint.
+
+type point struct{ x, y float64 }
+
+const kmPerDegLat = 110.574
+
+func project(origin, loc common.Location) point {
+	kmPerDegLng := 111.320 * math.Cos(origin.Lat*math.Pi/180)
+	return point{x: (loc.Lng - origin.Lng) * kmPerDegLng, y: (loc.Lat - origin.Lat) * kmPerDegLat}
+}
+
+// corridorGapKM is the smallest distance between any leg of a and any leg
+// of b.
+func corridorGapKM(a, b []common.Location) float64 {
+	origin := a[0]
+	gap := math.Inf(1)
+	for i := 0; i+1 < len(a); i++ {
+		p1, p2 := project(origin, a[i]), project(origin, a[i+1])
+		for j := 0; j+1 < len(b); j++ {
+			q1, q2 := project(origin, b[j]), project(origin, b[j+1])
+			gap = math.Min(gap, segmentGap(p1, p2, q1, q2))
+		}
+	}
+	return gap
+}
+
+func segmentGap(p1, p2, q1, q2 point) float64 {
+	if segmentsCross(p1, p2, q1, q2) {
+		return 0
+	}
+	return math.Min(
+		math.Min(pointSegmentGap(p1, q1, q2), pointSegmentGap(p2, q1, q2)),
+		math.Min(pointSegmentGap(q1, p1, p2), pointSegmentGap(q2, p1, p2)),
+	)
+}
+
+func pointSegmentGap(p, a, b point) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	t := 0.0
+	if l2 := dx*dx + dy*dy; l2 > 0 {
+		t = math.Max(0, math.Min(1, ((p.x-a.x)*dx+(p.y-a.y)*dy)/l2))
+	}
+	return math.Hypot(p.x-(a.x+t*dx), p.y-(a.y+t*dy))
+}
+
+func segmentsCross(p1, p2, q1, q2 point) bool {
+	d1, d2 := cross(q1, q2, p1), cross(q1, q2, p2)
+	d3, d4 := cross(p1, p2, q1), cross(p1, p2, q2)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+func cross(a, b, c point) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+}
@@ -0,0 +1,82 @@
+package airspace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const columns = `id, order_id, drone_id, start_lat, start_lng, pickup_lat, pickup_lng,
+	dropoff_lat, dropoff_lng, band, min_altitude_m, max_altitude_m, starts_at, ends_at,
+	created_at, released_at`
+
+// reservationLock serialises bookings so two transactions can't both see a
+// band as free. Taken with pg_advisory_xact_lock, released on commit.
+const reservationLock = 0x41495253 // "AIRS"
+
+type Repository interface {
+	Lock(ctx context.Context, ext sqlx.ExtContext) error
+	Create(ctx context.Context, ext sqlx.ExtContext, r *Reservation) error
+	ListActive(ctx context.Context, ext sqlx.ExtContext, now time.Time) ([]*Reservation, error)
+	ActiveForDrone(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) (*Reservation, error)
+	ReleaseByDrone(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) error
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+func (r *repo) Lock(ctx context.Context, ext sqlx.ExtContext) error {
+	_, err := ext.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, reservationLock)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, res *Reservation) error {
+	const query = `INSERT INTO airspace_reservations (id, order_id, drone_id, start_lat, start_lng,
+			pickup_lat, pickup_lng, dropoff_lat, dropoff_lng, band, min_altitude_m, max_altitude_m,
+			starts_at, ends_at, created_at)
+		VALUES (:id, :order_id, :drone_id, :start_lat, :start_lng, :pickup_lat, :pickup_lng,
+			:dropoff_lat, :dropoff_lng, :band, :min_altitude_m, :max_altitude_m, :starts_at, :ends_at,
+			:created_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, res)
+	return err
+}
+
+// --------------------------------------------------------------
+// ListActive returns unreleased reservations that haven't ended, earliest
+// departure first.
+func (r *repo) ListActive(ctx context.Context, ext sqlx.ExtContext, now time.Time) ([]*Reservation, error) {
+	var out []*Reservation
+	query := fmt.Sprintf(`SELECT %s FROM airspace_reservations
+		WHERE released_at IS NULL AND ends_at > $1 ORDER BY starts_at`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &out, query, now); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) ActiveForDrone(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) (*Reservation, error) {
+	var res Reservation
+	query := fmt.Sprintf(`SELECT %s FROM airspace_reservations
+		WHERE drone_id = $1 AND released_at IS NULL AND ends_at > $2
+		ORDER BY starts_at DESC LIMIT 1`, columns)
+	if err := sqlx.GetContext(ctx, ext, &res, query, droneID, now); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) ReleaseByDrone(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) error {
+	const query = `UPDATE airspace_reservations SET released_at = $2
+		WHERE drone_id = $1 AND released_at IS NULL`
+	_, err := ext.ExecContext(ctx, query, droneID, now)
+	return err
+}
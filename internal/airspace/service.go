@@ -0,0 +1,84 @@
+package airspace
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Service interface {
+	Reserve(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string, start, pickup, dropoff common.Location) (*Reservation, error)
+	Release(ctx context.Context, ext sqlx.ExtContext, droneID string) error
+	ListActive(ctx context.Context) ([]*Reservation, error)
+	ActiveForDrone(ctx context.Context, droneID string) (*Reservation, error)
+}
+
+type service struct {
+	db   *sqlx.DB
+	repo Repository
+	cfg  Config
+}
+
+func NewService(db *sqlx.DB, repo Repository, cfg Config) Service {
+	return &service{db: db, repo: repo, cfg: cfg}
+}
+
+// --------------------------------------------------------------
+// Reserve books the drone's corridor inside tx, which holds the booking lock
+// until it commits. Fails with a conflict when no band frees up within the
+// configured delay.
+func (s *service) Reserve(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string, start, pickup, dropoff common.Location) (*Reservation, error) {
+	if err := s.repo.Lock(ctx, tx); err != nil {
+		return nil, domainerrors.NewInternal("failed to lock airspace", err)
+	}
+	now := time.Now()
+	active, err := s.repo.ListActive(ctx, tx, now)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load airspace reservations", err)
+	}
+	r, err := Plan(active, orderID, droneID, start, pickup, dropoff, now, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, tx, r); err != nil {
+		return nil, domainerrors.NewInternal("failed to reserve airspace", err)
+	}
+	return r, nil
+}
+
+// --------------------------------------------------------------
+// Release frees the drone's corridor inside ext. A drone without one is fine.
+func (s *service) Release(ctx context.Context, ext sqlx.ExtContext, droneID string) error {
+	if err := s.repo.ReleaseByDrone(ctx, ext, droneID, time.Now()); err != nil {
+		return domainerrors.NewInternal("failed to release airspace", err)
+	}
+	return nil
+}
+
+// --------------------------------------------------------------
+func (s *service) ListActive(ctx context.Context) ([]*Reservation, error) {
+	rs, err := s.repo.ListActive(ctx, s.db, time.Now())
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list airspace reservations", err)
+	}
+	return rs, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) ActiveForDrone(ctx context.Context, droneID string) (*Reservation, error) {
+	r, err := s.repo.ActiveForDrone(ctx, s.db, droneID, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domainerrors.NewNotFound("airspace reservation for drone", droneID)
+	}
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load airspace reservation", err)
+	}
+	return r, nil
+}
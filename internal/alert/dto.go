@@ -0,0 +1,8 @@
+package alert
+
+// Filter narrows an alert listing. Nil fields match everything.
+type Filter struct {
+	DroneID        *string
+	Kind           *string
+	Unacknowledged bool
+}
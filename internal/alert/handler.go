@@ -0,0 +1,55 @@
+package alert
+
+import (
+	"net/http"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// --------------------------------------------------------------
+// List returns alerts newest first, filterable by drone_id, kind and
+// unacknowledged=true.
+func (h *Handler) List(c *gin.Context) {
+	var f Filter
+	if d := c.Query("drone_id"); d != "" {
+		f.DroneID = &d
+	}
+	if k := c.Query("kind"); k != "" {
+		f.Kind = &k
+	}
+	f.Unacknowledged = c.Query("unacknowledged") == "true"
+
+	alerts, err := h.service.List(c.Request.Context(), f)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) Acknowledge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid alert id"}})
+		return
+	}
+
+	a, err := h.service.Acknowledge(c.Request.Context(), id, c.GetString("sub"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alert": a})
+}
@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Severity string
+
+const (
+	SeverityInfo     Severity = "INFO"
+	SeverityWarning  Severity = "WARNING"
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// Alert is an operator-facing notification. Kind is set by the raising
+// package (e.g. IMPOSSIBLE_SPEED); DroneID is nil for fleet-wide alerts.
+type Alert struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	Kind           string     `db:"kind" json:"kind"`
+	Severity       Severity   `db:"severity" json:"severity"`
+	DroneID        *string    `db:"drone_id" json:"drone_id,omitempty"`
+	Message        string     `db:"message" json:"message"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	AcknowledgedAt *time.Time `db:"acknowledged_at" json:"acknowledged_at,omitempty"`
+	AcknowledgedBy *string    `db:"acknowledged_by" json:"acknowledged_by,omitempty"`
+}
+
+func New(kind string, severity Severity, droneID *string, message string) *Alert {
+	return &Alert{
+		ID:        uuid.New(),
+		Kind:      kind,
+		Severity:  severity,
+		DroneID:   droneID,
+		Message:   message,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (a *Alert) Acknowledge(by string) error {
+	if a.AcknowledgedAt != nil {
+		return domainerrors.AlertAlreadyAcknowledged(a.ID.String())
+	}
+	now := time.Now()
+	a.AcknowledgedAt = &now
+	a.AcknowledgedBy = &by
+	return nil
+}
@@ -0,0 +1,77 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const columns = `id, kind, severity, drone_id, message, created_at, acknowledged_at, acknowledged_by`
+
+// maxListed caps a list query; alerts are read newest first.
+const maxListed = 500
+
+type Repository interface {
+	Create(ctx context.Context, ext sqlx.ExtContext, a *Alert) error
+	GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Alert, error)
+	Update(ctx context.Context, ext sqlx.ExtContext, a *Alert) error
+	List(ctx context.Context, ext sqlx.ExtContext, f Filter) ([]*Alert, error)
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, a *Alert) error {
+	const query = `INSERT INTO alerts (id, kind, severity, drone_id, message, created_at)
+		VALUES (:id, :kind, :severity, :drone_id, :message, :created_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, a)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Alert, error) {
+	var a Alert
+	query := fmt.Sprintf(`SELECT %s FROM alerts WHERE id = $1 FOR UPDATE`, columns)
+	if err := sqlx.GetContext(ctx, ext, &a, query, id); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, a *Alert) error {
+	const query = `UPDATE alerts SET acknowledged_at = :acknowledged_at, acknowledged_by = :acknowledged_by
+		WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, a)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) List(ctx context.Context, ext sqlx.ExtContext, f Filter) ([]*Alert, error) {
+	args := []any{}
+	where := " WHERE TRUE"
+	if f.DroneID != nil {
+		args = append(args, *f.DroneID)
+		where += fmt.Sprintf(" AND drone_id = $%d", len(args))
+	}
+	if f.Kind != nil {
+		args = append(args, *f.Kind)
+		where += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+	if f.Unacknowledged {
+		where += " AND acknowledged_at IS NULL"
+	}
+
+	var alerts []*Alert
+	query := fmt.Sprintf(`SELECT %s FROM alerts%s ORDER BY created_at DESC LIMIT %d`, columns, where, maxListed)
+	if err := sqlx.SelectContext(ctx, ext, &alerts, query, args...); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
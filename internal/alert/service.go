@@ -0,0 +1,69 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Service interface {
+	Raise(ctx context.Context, ext sqlx.ExtContext, kind, severity, droneID, message string) error
+	List(ctx context.Context, f Filter) ([]*Alert, error)
+	Acknowledge(ctx context.Context, id uuid.UUID, by string) (*Alert, error)
+}
+
+type service struct {
+	db   *sqlx.DB
+	repo Repository
+}
+
+func NewService(db *sqlx.DB, repo Repository) Service {
+	return &service{db: db, repo: repo}
+}
+
+// --------------------------------------------------------------
+// Raise records an alert inside ext so it commits or rolls back with the
+// change that caused it. An empty droneID raises a fleet-wide alert.
+func (s *service) Raise(ctx context.Context, ext sqlx.ExtContext, kind, severity, droneID, message string) error {
+	var id *string
+	if droneID != "" {
+		id = &droneID
+	}
+	return s.repo.Create(ctx, ext, New(kind, Severity(severity), id, message))
+}
+
+// --------------------------------------------------------------
+func (s *service) List(ctx context.Context, f Filter) ([]*Alert, error) {
+	alerts, err := s.repo.List(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list alerts", err)
+	}
+	return alerts, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) Acknowledge(ctx context.Context, id uuid.UUID, by string) (*Alert, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	a, err := s.repo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, domainerrors.AlertNotFound(id.String())
+	}
+	if err := a.Acknowledge(by); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, tx, a); err != nil {
+		return nil, domainerrors.NewInternal("failed to acknowledge alert", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	return a, nil
+}
@@ -17,12 +17,13 @@ func NewHandler(authService Service) *Handler {
 func (h *Handler) GenerateToken(c *gin.Context) {
 	name := c.PostForm("name")
 	role := c.PostForm("role")
+	tenant := c.PostForm("tenant") // optional
 	if name == "" || role == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "name and role are required"})
 		return
 	}
 
-	token, err := h.authService.GenerateToken(name, role)
+	token, err := h.authService.GenerateToken(name, role, tenant)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -3,7 +3,7 @@ package auth
 import "drone-delivery/internal/jwt"
 
 type Service interface {
-	GenerateToken(name, role string) (string, error)
+	GenerateToken(name, role, tenant string) (string, error)
 }
 
 type authService struct {
@@ -14,6 +14,6 @@ func NewAuthService(jwt *jwt.Service) Service {
 	return &authService{jwt: jwt}
 }
 
-func (s *authService) GenerateToken(name, role string) (string, error) {
-	return s.jwt.GenerateToken(name, role)
+func (s *authService) GenerateToken(name, role, tenant string) (string, error) {
+	return s.jwt.GenerateTenantToken(name, role, tenant)
 }
@@ -0,0 +1,184 @@
+package bulk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/drone"
+	"drone-delivery/internal/order"
+)
+
+// flushEvery is how many rows are written between flushes to the client.
+const flushEvery = 500
+
+// Flusher pushes buffered output to the client. Satisfied by
+// gin.ResponseWriter.
+type Flusher interface {
+	Flush()
+}
+
+// Exporter streams orders and drones row by row, never holding more than
+// one in memory.
+type Exporter struct {
+	db     *sqlx.DB
+	orders order.Repository
+	drones drone.Repository
+}
+
+func NewExporter(db *sqlx.DB, orders order.Repository, drones drone.Repository) *Exporter {
+	return &Exporter{db: db, orders: orders, drones: drones}
+}
+
+var orderHeader = []string{"id", "submitted_by", "status", "service_tier", "priority",
+	"origin_lat", "origin_lng", "dest_lat", "dest_lng", "payload_kg",
+	"price_amount", "price_currency", "quote_id", "assigned_drone_id",
+	"promised_pickup_at", "promised_delivery_at", "repromised_pickup_at", "repromised_delivery_at", "assigned_at", "picked_up_at", "delivered_at",
+	"created_at", "updated_at"}
+
+func orderRecord(o *order.Order) []string {
+	return []string{o.ID.String(), cell(o.SubmittedBy), string(o.Status), string(o.ServiceTier), strconv.Itoa(o.Priority),
+		ftoa(o.OriginLat), ftoa(o.OriginLng), ftoa(o.DestLat), ftoa(o.DestLng), ftoa(o.PayloadKG),
+		strconv.FormatInt(o.PriceAmount, 10), o.PriceCurrency, cell(deref(o.QuoteID)), cell(deref(o.AssignedDroneID)),
+		ts(o.PromisedPickupAt), ts(o.PromisedDeliveryAt), ts(o.RepromisedPickupAt), ts(o.RepromisedDeliveryAt), ts(o.AssignedAt), ts(o.PickedUpAt), ts(o.DeliveredAt),
+		o.CreatedAt.UTC().Format(time.RFC3339), o.UpdatedAt.UTC().Format(time.RFC3339)}
+}
+
+var droneHeader = []string{"id", "status", "model", "capabilities", "latitude", "longitude",
+	"home_lat", "home_lng", "current_order_id", "last_heartbeat", "battery_pct",
+	"service_hours", "flight_km", "flight_cycles", "maintenance_due", "quarantined",
+	"retired_at", "created_at", "updated_at"}
+
+func droneRecord(d *drone.Drone) []string {
+	var orderID, battery, homeLat, homeLng string
+	if d.CurrentOrderID != nil {
+		orderID = d.CurrentOrderID.String()
+	}
+	if d.BatteryPct != nil {
+		battery = strconv.Itoa(*d.BatteryPct)
+	}
+	if d.HomeLat != nil && d.HomeLng != nil {
+		homeLat, homeLng = ftoa(*d.HomeLat), ftoa(*d.HomeLng)
+	}
+	return []string{cell(d.ID), string(d.Status), cell(d.Model), cell(strings.Join(d.Capabilities, ";")), ftoa(d.Latitude), ftoa(d.Longitude),
+		homeLat, homeLng, orderID, ts(d.LastHeartbeat), battery,
+		ftoa(d.ServiceHours), ftoa(d.FlightKM), strconv.Itoa(d.FlightCycles), strconv.FormatBool(d.MaintenanceDue), strconv.FormatBool(d.Quarantined),
+		ts(d.RetiredAt), d.CreatedAt.UTC().Format(time.RFC3339), d.UpdatedAt.UTC().Format(time.RFC3339)}
+}
+
+// --------------------------------------------------------------
+// ExportOrders writes the orders matching f to w, oldest first.
+func (e *Exporter) ExportOrders(ctx context.Context, w io.Writer, format Format, f order.ExportFilter) error {
+	enc := newEncoder(w, format, orderHeader)
+	return enc.finish(e.orders.Each(ctx, e.db, f, func(o *order.Order) error {
+		return enc.write(o, func() []string { return orderRecord(o) })
+	}))
+}
+
+// --------------------------------------------------------------
+// ExportDrones writes the drones, optionally of one status, to w by id.
+func (e *Exporter) ExportDrones(ctx context.Context, w io.Writer, format Format, status *drone.Status) error {
+	enc := newEncoder(w, format, droneHeader)
+	return enc.finish(e.drones.Each(ctx, e.db, status, func(d *drone.Drone) error {
+		return enc.write(d, func() []string { return droneRecord(d) })
+	}))
+}
+
+// encoder writes rows as CSV (header first) or one JSON object per line,
+// flushing every flushEvery rows.
+type encoder struct {
+	w      io.Writer
+	format Format
+	header []string
+	csv    *csv.Writer
+	json   *json.Encoder
+	rows   int
+}
+
+func newEncoder(w io.Writer, format Format, header []string) *encoder {
+	e := &encoder{w: w, format: format, header: header}
+	if format == FormatCSV {
+		e.csv = csv.NewWriter(w)
+	} else {
+		e.json = json.NewEncoder(w)
+	}
+	return e
+}
+
+func (e *encoder) write(v any, record func() []string) error {
+	if e.csv != nil {
+		if e.rows == 0 {
+			if err := e.csv.Write(e.header); err != nil {
+				return err
+			}
+		}
+		if err := e.csv.Write(record()); err != nil {
+			return err
+		}
+	} else if err := e.json.Encode(v); err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%flushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+// finish writes the CSV header for an empty export and flushes.
+func (e *encoder) finish(err error) error {
+	if err != nil {
+		return err
+	}
+	if e.csv != nil && e.rows == 0 {
+		if err := e.csv.Write(e.header); err != nil {
+			return err
+		}
+	}
+	e.flush()
+	if e.csv != nil {
+		return e.csv.Error()
+	}
+	return nil
+}
+
+func (e *encoder) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	if f, ok := e.w.(Flusher); ok {
+		f.Flush()
+	}
+}
+
+// cell makes free text safe to open in a spreadsheet: text starting with a
+// formula character is prefixed with ' so it isn't evaluated. Numbers we
+// format ourselves are left alone, so negative coordinates stay numeric.
+func cell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func ftoa(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func ts(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
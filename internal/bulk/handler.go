@@ -0,0 +1,136 @@
+package bulk
+
+import (
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"drone-delivery/internal/drone"
+	"drone-delivery/internal/order"
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	exporter     *Exporter
+	importer     *Importer
+	maxBodyBytes int64
+}
+
+func NewHandler(exporter *Exporter, importer *Importer, maxBodyBytes int64) *Handler {
+	return &Handler{exporter: exporter, importer: importer, maxBodyBytes: maxBodyBytes}
+}
+
+// --------------------------------------------------------------
+// ExportOrders streams orders as CSV (default) or NDJSON, optionally
+// filtered by status and by UTC placement date (from and to, inclusive).
+func (h *Handler) ExportOrders(c *gin.Context) {
+	format, ok := parseFormat(c, c.Query("format"))
+	if !ok {
+		return
+	}
+	var f order.ExportFilter
+	if s := c.Query("status"); s != "" {
+		st := order.Status(s)
+		f.Status = &st
+	}
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "from must be a date (YYYY-MM-DD)"}})
+			return
+		}
+		f.From = &t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "to must be a date (YYYY-MM-DD)"}})
+			return
+		}
+		end := t.AddDate(0, 0, 1)
+		f.To = &end
+	}
+
+	startStream(c, "orders", format)
+	if err := h.exporter.ExportOrders(c.Request.Context(), c.Writer, format, f); err != nil {
+		// The status line is already sent; the client sees a truncated file
+		slog.ErrorContext(c.Request.Context(), "order export failed", slog.String("error", err.Error()))
+	}
+}
+
+// --------------------------------------------------------------
+// ExportDrones streams drones as CSV (default) or NDJSON, optionally
+// filtered by status.
+func (h *Handler) ExportDrones(c *gin.Context) {
+	format, ok := parseFormat(c, c.Query("format"))
+	if !ok {
+		return
+	}
+	var statusPtr *drone.Status
+	if s := c.Query("status"); s != "" {
+		st := drone.Status(s)
+		statusPtr = &st
+	}
+
+	startStream(c, "drones", format)
+	if err := h.exporter.ExportDrones(c.Request.Context(), c.Writer, format, statusPtr); err != nil {
+		slog.ErrorContext(c.Request.Context(), "drone export failed", slog.String("error", err.Error()))
+	}
+}
+
+// --------------------------------------------------------------
+// ImportOrders places orders for the caller from a CSV or NDJSON upload
+// and reports the outcome of every row. The format comes from the format
+// query parameter or the Content-Type; every row is paid for with the
+// payment_method query parameter, as a single placement would be.
+func (h *Handler) ImportOrders(c *gin.Context) {
+	name := c.Query("format")
+	if name == "" {
+		name = formatOf(c.ContentType())
+	}
+	format, ok := parseFormat(c, name)
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodyBytes)
+	report, err := h.importer.ImportOrders(c.Request.Context(), c.GetString("sub"), c.Query("payment_method"), body, format)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"import": report})
+}
+
+// parseFormat defaults an empty name to CSV. It writes the 400 itself.
+func parseFormat(c *gin.Context, name string) (Format, bool) {
+	if name == "" {
+		return FormatCSV, true
+	}
+	f := Format(name)
+	if !f.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "format must be csv or ndjson"}})
+		return "", false
+	}
+	return f, true
+}
+
+func formatOf(contentType string) string {
+	switch mt, _, _ := mime.ParseMediaType(contentType); mt {
+	case "application/x-ndjson", "application/ndjson":
+		return string(FormatNDJSON)
+	case "text/csv":
+		return string(FormatCSV)
+	}
+	return ""
+}
+
+func startStream(c *gin.Context, name string, format Format) {
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Status(http.StatusOK)
+}
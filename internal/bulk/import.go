@@ -0,0 +1,286 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"drone-delivery/internal/admission"
+	"drone-delivery/internal/delivery"
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/order"
+	"drone-delivery/internal/pricing"
+)
+
+// Importer creates orders in bulk, each through the same checks as a
+// single placement: zone, grounding, pricing, admission and payment
+// authorization.
+type Importer struct {
+	orders   order.Service
+	delivery delivery.Service
+	pricing  pricing.Service
+	guard    order.Admission
+	capacity admission.Service
+	cfg      ImportConfig
+}
+
+func NewImporter(orders order.Service, deliveryService delivery.Service, pricingService pricing.Service, guard order.Admission, capacity admission.Service, cfg ImportConfig) *Importer {
+	return &Importer{orders: orders, delivery: deliveryService, pricing: pricingService, guard: guard, capacity: capacity, cfg: cfg}
+}
+
+// --------------------------------------------------------------
+// ImportOrders reads every row from r, refusing the upload if it has more
+// than MaxRows or a CSV header is missing a required column, then creates
+// the orders for submittedBy, each authorized against paymentMethod. Rows
+// are written BatchSize at a time, one transaction per batch; a row that
+// fails its checks doesn't stop the others, and each gets a result.
+func (im *Importer) ImportOrders(ctx context.Context, submittedBy, paymentMethod string, r io.Reader, format Format) (*ImportReport, error) {
+	rows, err := im.readRows(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Total: len(rows), Results: make([]*RowResult, 0, len(rows))}
+	for start := 0; start < len(rows); start += im.cfg.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+im.cfg.BatchSize, len(rows))
+		im.importBatch(ctx, submittedBy, paymentMethod, rows[start:end], start, report)
+	}
+	return report, nil
+}
+
+// importBatch checks each row, then creates the orders that passed in one
+// transaction. first is the index of the batch's first row.
+func (im *Importer) importBatch(ctx context.Context, submittedBy, paymentMethod string, rows []*ImportRow, first int, report *ImportReport) {
+	results := make([]*RowResult, len(rows))
+	var (
+		orders  []*order.Order
+		pending []*RowResult
+	)
+	for i, row := range rows {
+		results[i] = &RowResult{Row: first + i + 1, Reference: row.Reference}
+		o, err := im.prepareOrder(ctx, submittedBy, row)
+		if err != nil {
+			results[i].Error = rowError(ctx, err)
+			continue
+		}
+		orders = append(orders, o)
+		pending = append(pending, results[i])
+	}
+
+	if len(orders) > 0 {
+		for i, err := range im.delivery.CreateOrdersAndJobs(ctx, orders, paymentMethod) {
+			if err != nil {
+				pending[i].Error = rowError(ctx, err)
+				continue
+			}
+			pending[i].OrderID, pending[i].OrderStatus = &orders[i].ID, orders[i].Status
+		}
+	}
+
+	for _, res := range results {
+		if res.Error != nil {
+			report.Failed++
+		} else {
+			report.Created++
+		}
+		report.Results = append(report.Results, res)
+	}
+}
+
+// prepareOrder builds the row's order through the checks of a single
+// placement. The capacity promise counts the backlog as committed, so it
+// doesn't see earlier rows of the same batch.
+func (im *Importer) prepareOrder(ctx context.Context, submittedBy string, row *ImportRow) (*order.Order, error) {
+	if row.parseErr != nil {
+		return nil, row.parseErr
+	}
+	if row.ServiceTier != "" && row.ServiceTier != order.TierStandard && row.ServiceTier != order.TierExpress {
+		return nil, domainerrors.NewValidation(fmt.Sprintf("unknown service_tier %q", row.ServiceTier))
+	}
+	if row.PayloadKG < 0 {
+		return nil, domainerrors.NewValidation("payload_kg must not be negative")
+	}
+	if err := im.orders.ValidateLocation(row.Origin, "origin"); err != nil {
+		return nil, err
+	}
+	if err := im.orders.ValidateLocation(row.Destination, "destination"); err != nil {
+		return nil, err
+	}
+
+	o := order.NewOrder(submittedBy, row.Origin, row.Destination)
+	o.PayloadKG = row.PayloadKG
+	o.SetServiceTier(row.ServiceTier)
+
+	if err := im.guard.AdmitOrder(ctx, o.Origin(), o.Destination()); err != nil {
+		return nil, err
+	}
+	q, err := im.pricing.Quote(ctx, submittedBy, pricing.Request{
+		Origin:      row.Origin,
+		Destination: row.Destination,
+		PayloadKG:   row.PayloadKG,
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.ApplyPrice(q.Amount, q.Currency, q.ID)
+
+	promise, err := im.capacity.Promise(ctx, o.Origin(), o.Destination(), o.Priority)
+	if err != nil {
+		return nil, err
+	}
+	o.SetPromise(promise.PickupBy, promise.DeliveryBy)
+	if promise.Waitlisted {
+		if err := o.Waitlist(); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// rowError reports a domain error as is and hides anything else.
+func rowError(ctx context.Context, err error) *RowError {
+	var domainErr *domainerrors.DomainError
+	if errors.As(err, &domainErr) && domainErr.Code != domainerrors.ErrInternal {
+		return &RowError{Code: domainErr.Code, Message: domainErr.Message}
+	}
+	slog.ErrorContext(ctx, "bulk import row failed", slog.String("error", err.Error()))
+	return &RowError{Code: domainerrors.ErrInternal, Message: "an unexpected error occurred"}
+}
+
+// --------------------------------------------------------------
+// Parsing. Rows that can't be read are kept with parseErr so they get a
+// result in the same position.
+
+func (im *Importer) readRows(r io.Reader, format Format) ([]*ImportRow, error) {
+	if format == FormatCSV {
+		return im.readCSV(r)
+	}
+	return im.readNDJSON(r)
+}
+
+var requiredColumns = []string{"origin_lat", "origin_lng", "dest_lat", "dest_lng"}
+
+func (im *Importer) readCSV(r io.Reader) ([]*ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, domainerrors.NewValidation("the upload is empty")
+	}
+	if err != nil {
+		return nil, domainerrors.NewValidation(fmt.Sprintf("invalid CSV header: %v", err))
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := col[name]; !ok {
+			return nil, domainerrors.NewValidation(fmt.Sprintf("CSV header is missing %s", name))
+		}
+	}
+
+	var rows []*ImportRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if len(rows) == im.cfg.MaxRows {
+			return nil, im.tooManyRows()
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, domainerrors.NewValidation(fmt.Sprintf("invalid CSV: %v", err))
+			}
+			rows = append(rows, &ImportRow{parseErr: domainerrors.NewValidation(parseErr.Err.Error())})
+			continue
+		}
+		rows = append(rows, csvRow(record, col))
+	}
+}
+
+func csvRow(record []string, col map[string]int) *ImportRow {
+	field := func(name string) string {
+		if i, ok := col[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row := &ImportRow{
+		ServiceTier: order.ServiceTier(strings.ToUpper(field("service_tier"))),
+		Reference:   field("reference"),
+	}
+	nums := []struct {
+		name     string
+		dst      *float64
+		optional bool
+	}{
+		{"origin_lat", &row.Origin.Lat, false},
+		{"origin_lng", &row.Origin.Lng, false},
+		{"dest_lat", &row.Destination.Lat, false},
+		{"dest_lng", &row.Destination.Lng, false},
+		{"payload_kg", &row.PayloadKG, true},
+	}
+	for _, n := range nums {
+		v := field(n.name)
+		if v == "" && n.optional {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			row.parseErr = domainerrors.NewValidation(fmt.Sprintf("%s must be a number", n.name))
+			return row
+		}
+		*n.dst = f
+	}
+	return row
+}
+
+func (im *Importer) readNDJSON(r io.Reader) ([]*ImportRow, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []*ImportRow
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if len(rows) == im.cfg.MaxRows {
+			return nil, im.tooManyRows()
+		}
+		var row ImportRow
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&row); err != nil {
+			row = ImportRow{parseErr: domainerrors.NewValidation(fmt.Sprintf("invalid JSON: %v", err))}
+		}
+		rows = append(rows, &row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, domainerrors.NewValidation(fmt.Sprintf("invalid NDJSON: %v", err))
+	}
+	if len(rows) == 0 {
+		return nil, domainerrors.NewValidation("the upload is empty")
+	}
+	return rows, nil
+}
+
+func (im *Importer) tooManyRows() error {
+	return domainerrors.NewValidation(fmt.Sprintf("an import may have at most %d rows", im.cfg.MaxRows))
+}
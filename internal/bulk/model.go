@@ -0,0 +1,72 @@
+package bulk
+
+import (
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/order"
+)
+
+// Format is the encoding of an export or import.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+func (f Format) Valid() bool {
+	return f == FormatCSV || f == FormatNDJSON
+}
+
+func (f Format) ContentType() string {
+	if f == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ImportConfig bounds a bulk import.
+type ImportConfig struct {
+	MaxRows   int // an upload with more rows is refused before anything is created
+	BatchSize int // orders created per transaction
+}
+
+// ImportRow is one order to create. Its NDJSON form mirrors the place-order
+// request; in CSV the locations are split into origin_lat, origin_lng,
+// dest_lat and dest_lng columns.
+type ImportRow struct {
+	Origin      common.Location   `json:"origin"`
+	Destination common.Location   `json:"destination"`
+	PayloadKG   float64           `json:"payload_kg"`
+	ServiceTier order.ServiceTier `json:"service_tier"`
+	// Reference is the uploader's own identifier, echoed in the result.
+	Reference string `json:"reference"`
+
+	// parseErr is set when the row couldn't be read; it fails without
+	// being attempted.
+	parseErr error
+}
+
+// RowError explains why a row wasn't imported.
+type RowError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// RowResult is the outcome of one row. Row numbers count data rows from 1.
+type RowResult struct {
+	Row         int          `json:"row"`
+	Reference   string       `json:"reference,omitempty"`
+	OrderID     *uuid.UUID   `json:"order_id,omitempty"`
+	OrderStatus order.Status `json:"order_status,omitempty"`
+	Error       *RowError    `json:"error,omitempty"`
+}
+
+// ImportReport is the per-row outcome of an import.
+type ImportReport struct {
+	Total   int          `json:"total"`
+	Created int          `json:"created"`
+	Failed  int          `json:"failed"`
+	Results []*RowResult `json:"results"`
+}
@@ -0,0 +1,11 @@
+package command
+
+type IssueRequest struct {
+	Type   Type   `json:"type" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+type AckRequest struct {
+	Result  Status `json:"result" binding:"required"`
+	Message string `json:"message"`
+}
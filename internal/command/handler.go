@@ -0,0 +1,99 @@
+package command
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service Service
+	maxWait time.Duration
+}
+
+// NewHandler builds the command handler. maxWait caps the long-poll wait a
+// drone may ask for.
+func NewHandler(service Service, maxWait time.Duration) *Handler {
+	return &Handler{service: service, maxWait: maxWait}
+}
+
+// --------------------------------------------------------------
+func (h *Handler) Issue(c *gin.Context) {
+	var req IssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	cmd, err := h.service.Issue(c.Request.Context(), c.Param("id"), req, c.GetString("sub"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"command": cmd})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) List(c *gin.Context) {
+	cmds, err := h.service.List(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"commands": cmds})
+}
+
+// --------------------------------------------------------------
+// Poll returns the calling drone's outstanding commands. With wait=<seconds>
+// it long-polls until a command arrives or the wait runs out.
+func (h *Handler) Poll(c *gin.Context) {
+	wait := time.Duration(0)
+	if s := c.Query("wait"); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil || secs < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "wait must be a non-negative number of seconds"}})
+			return
+		}
+		wait = min(time.Duration(secs)*time.Second, h.maxWait)
+	}
+
+	droneID := c.GetString("sub")
+	var cmds []*Command
+	var err error
+	if wait > 0 {
+		cmds, err = h.service.Wait(c.Request.Context(), droneID, wait)
+	} else {
+		cmds, err = h.service.Deliver(c.Request.Context(), droneID)
+	}
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"commands": cmds})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) Acknowledge(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid command id"}})
+		return
+	}
+	var req AckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	cmd, err := h.service.Acknowledge(c.Request.Context(), c.GetString("sub"), id, req)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"command": cmd})
+}
@@ -0,0 +1,117 @@
+package command
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Type string
+
+const (
+	// TypeAbortMission drops the drone's current order: an assigned order
+	// goes back to PENDING, a picked-up one fails.
+	TypeAbortMission Type = "ABORT_MISSION"
+	// TypeReturnToBase sends the drone home, dropping its order like an abort.
+	TypeReturnToBase Type = "RETURN_TO_BASE"
+	// TypeHoldPosition makes the drone hover; its order is kept.
+	TypeHoldPosition Type = "HOLD_POSITION"
+	// TypeLandImmediately puts the drone down where it is; it is handled as
+	// broken so its order is handed off.
+	TypeLandImmediately Type = "LAND_IMMEDIATELY"
+)
+
+func (t Type) Valid() bool {
+	switch t {
+	case TypeAbortMission, TypeReturnToBase, TypeHoldPosition, TypeLandImmediately:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+const (
+	StatusPending   Status = "PENDING"
+	StatusDelivered Status = "DELIVERED" // seen by the drone, not yet acknowledged
+	StatusExecuted  Status = "EXECUTED"
+	StatusRejected  Status = "REJECTED"
+	StatusExpired   Status = "EXPIRED"
+)
+
+// IsOutstanding reports whether the drone still has to acknowledge the
+// command.
+func (s Status) IsOutstanding() bool {
+	return s == StatusPending || s == StatusDelivered
+}
+
+// Command is an instruction from ground control to one drone. OrderID is the
+// drone's order when the command was issued; outcomes only touch that order.
+type Command struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	DroneID        string     `db:"drone_id" json:"drone_id"`
+	Type           Type       `db:"type" json:"type"`
+	Status         Status     `db:"status" json:"status"`
+	OrderID        *uuid.UUID `db:"order_id" json:"order_id,omitempty"`
+	Reason         string     `db:"reason" json:"reason,omitempty"`
+	IssuedBy       string     `db:"issued_by" json:"issued_by"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+	DeliveredAt    *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+	AcknowledgedAt *time.Time `db:"acknowledged_at" json:"acknowledged_at,omitempty"`
+	ResultMessage  string     `db:"result_message" json:"result_message,omitempty"`
+}
+
+func New(droneID string, typ Type, orderID *uuid.UUID, reason, issuedBy string, ttl time.Duration) *Command {
+	now := time.Now()
+	return &Command{
+		ID:        uuid.New(),
+		DroneID:   droneID,
+		Type:      typ,
+		Status:    StatusPending,
+		OrderID:   orderID,
+		Reason:    reason,
+		IssuedBy:  issuedBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+func (c *Command) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// MarkDelivered records the first time the drone was handed the command.
+func (c *Command) MarkDelivered(now time.Time) {
+	if c.Status != StatusPending {
+		return
+	}
+	c.Status = StatusDelivered
+	c.DeliveredAt = &now
+}
+
+// Acknowledge records the drone's result, EXECUTED or REJECTED.
+func (c *Command) Acknowledge(result Status, message string, now time.Time) error {
+	if !c.Status.IsOutstanding() {
+		return domainerrors.CommandAlreadyAcknowledged(c.ID.String())
+	}
+	if result != StatusExecuted && result != StatusRejected {
+		return domainerrors.NewValidation("result must be EXECUTED or REJECTED")
+	}
+	if c.Expired(now) {
+		return domainerrors.CommandExpired(c.ID.String())
+	}
+	c.Status = result
+	c.AcknowledgedAt = &now
+	c.ResultMessage = message
+	return nil
+}
+
+// Expire closes an outstanding command the drone never acknowledged.
+func (c *Command) Expire() {
+	if c.Status.IsOutstanding() {
+		c.Status = StatusExpired
+	}
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const columns = `id, drone_id, type, status, order_id, reason, issued_by, created_at, expires_at,
+	delivered_at, acknowledged_at, result_message`
+
+// maxListed caps a drone's command history; read newest first.
+const maxListed = 200
+
+type Repository interface {
+	Create(ctx context.Context, ext sqlx.ExtContext, c *Command) error
+	GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Command, error)
+	Update(ctx context.Context, ext sqlx.ExtContext, c *Command) error
+	ListByDrone(ctx context.Context, ext sqlx.ExtContext, droneID string) ([]*Command, error)
+	Outstanding(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) ([]*Command, error)
+	MarkDelivered(ctx context.Context, ext sqlx.ExtContext, ids []uuid.UUID, now time.Time) error
+	ExpireStale(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) error
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, c *Command) error {
+	const query = `INSERT INTO drone_commands (id, drone_id, type, status, order_id, reason, issued_by, created_at, expires_at)
+		VALUES (:id, :drone_id, :type, :status, :order_id, :reason, :issued_by, :created_at, :expires_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, c)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Command, error) {
+	var c Command
+	query := fmt.Sprintf(`SELECT %s FROM drone_commands WHERE id = $1 FOR UPDATE`, columns)
+	if err := sqlx.GetContext(ctx, ext, &c, query, id); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, c *Command) error {
+	const query = `UPDATE drone_commands SET status = :status, delivered_at = :delivered_at,
+		acknowledged_at = :acknowledged_at, result_message = :result_message
+		WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, c)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) ListByDrone(ctx context.Context, ext sqlx.ExtContext, droneID string) ([]*Command, error) {
+	var cmds []*Command
+	query := fmt.Sprintf(`SELECT %s FROM drone_commands WHERE drone_id = $1 ORDER BY created_at DESC LIMIT %d`, columns, maxListed)
+	if err := sqlx.SelectContext(ctx, ext, &cmds, query, droneID); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+// --------------------------------------------------------------
+// Outstanding returns the drone's unexpired, unacknowledged commands, oldest
+// first so the drone applies them in issue order.
+func (r *repo) Outstanding(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) ([]*Command, error) {
+	var cmds []*Command
+	query := fmt.Sprintf(`SELECT %s FROM drone_commands
+		WHERE drone_id = $1 AND status IN ('PENDING', 'DELIVERED') AND expires_at > $2
+		ORDER BY created_at`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &cmds, query, droneID, now); err != nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) MarkDelivered(ctx context.Context, ext sqlx.ExtContext, ids []uuid.UUID, now time.Time) error {
+	const query = `UPDATE drone_commands SET status = 'DELIVERED', delivered_at = $2
+		WHERE id = ANY($1::uuid[]) AND status = 'PENDING'`
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	_, err := ext.ExecContext(ctx, query, pq.Array(strs), now)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) ExpireStale(ctx context.Context, ext sqlx.ExtContext, droneID string, now time.Time) error {
+	const query = `UPDATE drone_commands SET status = 'EXPIRED'
+		WHERE drone_id = $1 AND status IN ('PENDING', 'DELIVERED') AND expires_at <= $2`
+	_, err := ext.ExecContext(ctx, query, droneID, now)
+	return err
+}
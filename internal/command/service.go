@@ -0,0 +1,268 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// pollInterval bounds how long a long-poll can miss a command issued through
+// another server instance, which doesn't wake local waiters.
+const pollInterval = 2 * time.Second
+
+type Config struct {
+	TTL time.Duration
+}
+
+type Service interface {
+	Issue(ctx context.Context, droneID string, req IssueRequest, issuedBy string) (*Command, error)
+	List(ctx context.Context, droneID string) ([]*Command, error)
+	Deliver(ctx context.Context, droneID string) ([]*Command, error)
+	Wait(ctx context.Context, droneID string, timeout time.Duration) ([]*Command, error)
+	Acknowledge(ctx context.Context, droneID string, id uuid.UUID, req AckRequest) (*Command, error)
+}
+
+// DroneFinder avoids importing the drone service. Satisfied by drone.Service.
+type DroneFinder interface {
+	GetByID(ctx context.Context, id string) (*drone.Drone, error)
+}
+
+// Executor applies executed commands to the order and drone state machines
+// inside the acknowledging tx. The returned func publishes the change and
+// runs once tx commits. Satisfied by delivery.Service.
+type Executor interface {
+	AbortMissionWithTx(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string) (func(), error)
+	HandleDroneBrokenWithTx(ctx context.Context, tx *sqlx.Tx, droneID string) (func(), error)
+}
+
+// Alerter raises an operator alert inside ext. Satisfied by alert.Service.
+type Alerter interface {
+	Raise(ctx context.Context, ext sqlx.ExtContext, kind, severity, droneID, message string) error
+}
+
+// Publisher pushes new commands to connected drones, e.g. over MQTT.
+type Publisher interface {
+	CommandIssued(ctx context.Context, c *Command)
+}
+
+type service struct {
+	db        *sqlx.DB
+	repo      Repository
+	drones    DroneFinder
+	executor  Executor
+	alerts    Alerter
+	publisher Publisher // nil when no gateway is running
+	cfg       Config
+
+	mu      sync.Mutex
+	waiters map[string]chan struct{} // closed when a command is issued for the drone
+}
+
+func NewService(db *sqlx.DB, repo Repository, drones DroneFinder, executor Executor, alerts Alerter, publisher Publisher, cfg Config) Service {
+	return &service{
+		db:        db,
+		repo:      repo,
+		drones:    drones,
+		executor:  executor,
+		alerts:    alerts,
+		publisher: publisher,
+		cfg:       cfg,
+		waiters:   make(map[string]chan struct{}),
+	}
+}
+
+// --------------------------------------------------------------
+// Issue queues a command for the drone. The drone's current order is
+// recorded so a late acknowledgement can't affect a later mission.
+func (s *service) Issue(ctx context.Context, droneID string, req IssueRequest, issuedBy string) (*Command, error) {
+	if !req.Type.Valid() {
+		return nil, domainerrors.NewValidation(fmt.Sprintf("unknown command type %q", req.Type))
+	}
+	d, err := s.drones.GetByID(ctx, droneID)
+	if err != nil {
+		return nil, err
+	}
+	if d.IsRetired() {
+		return nil, domainerrors.DroneRetired(droneID)
+	}
+	if req.Type == TypeAbortMission && d.CurrentOrderID == nil {
+		return nil, domainerrors.NewConflict("drone has no mission to abort")
+	}
+
+	c := New(droneID, req.Type, d.CurrentOrderID, req.Reason, issuedBy, s.cfg.TTL)
+	if err := s.repo.Create(ctx, s.db, c); err != nil {
+		return nil, domainerrors.NewInternal("failed to queue command", err)
+	}
+	s.wake(droneID)
+	if s.publisher != nil {
+		s.publisher.CommandIssued(ctx, c)
+	}
+	return c, nil
+}
+
+// --------------------------------------------------------------
+// List returns the drone's commands newest first, closing expired ones.
+func (s *service) List(ctx context.Context, droneID string) ([]*Command, error) {
+	if err := s.repo.ExpireStale(ctx, s.db, droneID, time.Now()); err != nil {
+		return nil, domainerrors.NewInternal("failed to expire commands", err)
+	}
+	cmds, err := s.repo.ListByDrone(ctx, s.db, droneID)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list commands", err)
+	}
+	return cmds, nil
+}
+
+// --------------------------------------------------------------
+// Deliver hands the drone every command it has yet to acknowledge, oldest
+// first. Commands stay outstanding until acknowledged, so one lost response
+// doesn't lose them; drones dedupe by ID.
+func (s *service) Deliver(ctx context.Context, droneID string) ([]*Command, error) {
+	now := time.Now()
+	cmds, err := s.repo.Outstanding(ctx, s.db, droneID, now)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load commands", err)
+	}
+
+	var fresh []uuid.UUID
+	for _, c := range cmds {
+		if c.Status == StatusPending {
+			fresh = append(fresh, c.ID)
+		}
+	}
+	if len(fresh) > 0 {
+		if err := s.repo.MarkDelivered(ctx, s.db, fresh, now); err != nil {
+			return nil, domainerrors.NewInternal("failed to mark commands delivered", err)
+		}
+		for _, c := range cmds {
+			c.MarkDelivered(now)
+		}
+	}
+	if cmds == nil {
+		cmds = []*Command{}
+	}
+	return cmds, nil
+}
+
+// --------------------------------------------------------------
+// Wait is Deliver as a long-poll: it returns as soon as the drone has
+// outstanding commands, or empty once timeout passes.
+func (s *service) Wait(ctx context.Context, droneID string, timeout time.Duration) ([]*Command, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Subscribe before reading so a command issued in between wakes us
+		woken := s.subscribe(droneID)
+		cmds, err := s.Deliver(ctx, droneID)
+		if err != nil || len(cmds) > 0 {
+			return cmds, err
+		}
+		select {
+		case <-woken:
+		case <-ticker.C:
+		case <-deadline.C:
+			return cmds, nil
+		case <-ctx.Done():
+			return cmds, nil
+		}
+	}
+}
+
+func (s *service) subscribe(droneID string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.waiters[droneID]
+	if !ok {
+		ch = make(chan struct{})
+		s.waiters[droneID] = ch
+	}
+	return ch
+}
+
+func (s *service) wake(droneID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.waiters[droneID]; ok {
+		close(ch)
+		delete(s.waiters, droneID)
+	}
+}
+
+// --------------------------------------------------------------
+// Acknowledge records the drone's result. An EXECUTED command is applied to
+// the order and drone in the same transaction as the ack, so it is applied
+// exactly once; if that fails the command stays outstanding so the drone can
+// retry. A REJECTED command raises an alert.
+func (s *service) Acknowledge(ctx context.Context, droneID string, id uuid.UUID, req AckRequest) (*Command, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	c, err := s.repo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil || c.DroneID != droneID {
+		return nil, domainerrors.CommandNotFound(id.String())
+	}
+
+	now := time.Now()
+	if c.Status.IsOutstanding() && c.Expired(now) {
+		c.Expire()
+		if err := s.repo.Update(ctx, tx, c); err != nil {
+			return nil, domainerrors.NewInternal("failed to expire command", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, domainerrors.NewInternal("failed to commit transaction", err)
+		}
+		return nil, domainerrors.CommandExpired(id.String())
+	}
+	if err := c.Acknowledge(req.Result, req.Message, now); err != nil {
+		return nil, err
+	}
+
+	executed := func() {}
+	switch c.Status {
+	case StatusExecuted:
+		if executed, err = s.execute(ctx, tx, c); err != nil {
+			return nil, err
+		}
+	case StatusRejected:
+		msg := fmt.Sprintf("drone %s rejected %s: %s", droneID, c.Type, req.Message)
+		if err := s.alerts.Raise(ctx, tx, "COMMAND_REJECTED", "WARNING", droneID, msg); err != nil {
+			return nil, domainerrors.NewInternal("failed to raise alert", err)
+		}
+	}
+
+	if err := s.repo.Update(ctx, tx, c); err != nil {
+		return nil, domainerrors.NewInternal("failed to acknowledge command", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	executed()
+	return c, nil
+}
+
+// execute feeds an executed command into the delivery state machine inside
+// tx. The returned func runs the post-commit side effects.
+func (s *service) execute(ctx context.Context, tx *sqlx.Tx, c *Command) (func(), error) {
+	switch c.Type {
+	case TypeAbortMission, TypeReturnToBase:
+		if c.OrderID != nil {
+			return s.executor.AbortMissionWithTx(ctx, tx, *c.OrderID, c.DroneID)
+		}
+	case TypeLandImmediately:
+		return s.executor.HandleDroneBrokenWithTx(ctx, tx, c.DroneID)
+	}
+	return func() {}, nil
+}
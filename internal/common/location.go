@@ -98,6 +98,19 @@ func HaversineDistance(a, b Location) float64 {
 	return earthRadiusKM * c
 }
 
+// BoundingBox returns the south-west and north-east corners of a box that
+// contains every point within radiusKM of center. It does not wrap around
+// the antimeridian.
+func BoundingBox(center Location, radiusKM float64) (Location, Location) {
+	angle := radiusKM / earthRadiusKM
+	dLat := angle * 180 / math.Pi
+	dLng := 180.0
+	if s := math.Sin(angle) / math.Cos(degreesToRadians(center.Lat)); s >= 0 && s < 1 {
+		dLng = math.Asin(s) * 180 / math.Pi
+	}
+	return NewLocation(center.Lat-dLat, center.Lng-dLng), NewLocation(center.Lat+dLat, center.Lng+dLng)
+}
+
 func degreesToRadians(d float64) float64 {
 	return d * math.Pi / 180
 }
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Weather is the surface conditions at a point. WindFromDeg is the compass
+// direction the wind blows from, as in METAR reports.
+type Weather struct {
+	WindSpeedKMH float64   `json:"wind_speed_kmh"`
+	WindFromDeg  float64   `json:"wind_from_deg"`
+	VisibilityKM float64   `json:"visibility_km"`
+	ObservedAt   time.Time `json:"observed_at"`
+}
+
+// HeadwindKMH is the wind component against a flight from -> to. Negative
+// values are a tailwind.
+func (w Weather) HeadwindKMH(from, to Location) float64 {
+	angle := degreesToRadians(w.WindFromDeg - BearingDegrees(from, to))
+	return w.WindSpeedKMH * math.Cos(angle)
+}
+
+// WeatherProvider reports current conditions at a location.
+type WeatherProvider interface {
+	GetWeather(ctx context.Context, loc Location) (*Weather, error)
+}
+
+// WeatherArea overrides the fixture's default conditions inside a circle.
+type WeatherArea struct {
+	Name     string   `json:"name"`
+	Center   Location `json:"center"`
+	RadiusKM float64  `json:"radius_km"`
+	Weather  Weather  `json:"weather"`
+}
+
+// FixtureWeatherProvider serves conditions from a JSON file for local runs
+// and tests. The first area containing the location wins; otherwise Default
+// applies.
+type FixtureWeatherProvider struct {
+	Default Weather       `json:"default"`
+	Areas   []WeatherArea `json:"areas"`
+}
+
+// NewFixtureWeatherProvider loads path. An empty path gives calm weather
+// everywhere.
+func NewFixtureWeatherProvider(path string) (*FixtureWeatherProvider, error) {
+	p := &FixtureWeatherProvider{Default: Weather{VisibilityKM: 10}}
+	if path == "" {
+		return p, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read weather fixture: %w", err)
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("parse weather fixture: %w", err)
+	}
+	return p, nil
+}
+
+func (p *FixtureWeatherProvider) GetWeather(ctx context.Context, loc Location) (*Weather, error) {
+	w := p.Default
+	for _, a := range p.Areas {
+		if HaversineDistance(a.Center, loc) <= a.RadiusKM {
+			w = a.Weather
+			break
+		}
+	}
+	w.ObservedAt = time.Now()
+	return &w, nil
+}
+
+// BearingDegrees is the initial compass bearing from a to b, in [0, 360).
+func BearingDegrees(a, b Location) float64 {
+	aLat := degreesToRadians(a.Lat)
+	bLat := degreesToRadians(b.Lat)
+	dLng := degreesToRadians(b.Lng - a.Lng)
+
+	y := math.Sin(dLng) * math.Cos(bLat)
+	x := math.Cos(aLat)*math.Sin(bLat) - math.Sin(aLat)*math.Cos(bLat)*math.Cos(dLng)
+	deg := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(deg+360, 360)
+}
+
+// FlightETAMinutes estimates a straight-line flight from -> to at airspeed,
+// slowed by a headwind or sped up by a tailwind when w is known. Ground speed
+// never drops below a quarter of airspeed so a strong headwind doesn't give
+// an unbounded ETA.
+func FlightETAMinutes(from, to Location, airspeedKMH float64, w *Weather) float64 {
+	speed := airspeedKMH
+	if w != nil {
+		speed = math.Max(airspeedKMH-w.HeadwindKMH(from, to), airspeedKMH/4)
+	}
+	return HaversineDistance(from, to) / speed * 60
+}
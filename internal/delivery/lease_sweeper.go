@@ -0,0 +1,102 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/command"
+	"drone-delivery/internal/job"
+	"drone-delivery/internal/maintenance"
+)
+
+type LeaseSweeperConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Commander queues commands for drones. Satisfied by command.Service.
+type Commander interface {
+	Issue(ctx context.Context, droneID string, req command.IssueRequest, issuedBy string) (*command.Command, error)
+}
+
+// LeaseSweeper reopens jobs whose drone never reached the pickup before its
+// lease ran out. Each job is reverted in its own transaction, after which
+// the drone is sent home so it stops flying to a pickup that is no longer
+// its own.
+type LeaseSweeper struct {
+	db       *sqlx.DB
+	repo     Repository
+	jobs     job.Repository
+	commands Commander
+	cfg      LeaseSweeperConfig
+}
+
+func NewLeaseSweeper(db *sqlx.DB, repo Repository, jobs job.Repository, commands Commander, cfg LeaseSweeperConfig) *LeaseSweeper {
+	return &LeaseSweeper{db: db, repo: repo, jobs: jobs, commands: commands, cfg: cfg}
+}
+
+// Run sweeps expired leases until ctx is cancelled.
+func (s *LeaseSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "lease sweep failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// Sweep reverts one batch of reservations whose lease expired before now and
+// returns how many were reverted. A job that fails to revert is logged and
+// retried on the next sweep.
+func (s *LeaseSweeper) Sweep(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.jobs.ListExpiredLeases(ctx, s.db, now, s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list expired leases: %w", err)
+	}
+
+	reverted := 0
+	for _, id := range ids {
+		droneID, err := s.repo.ExpireLease(ctx, s.db, id, now)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to expire job lease",
+				slog.String("job_id", id),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if droneID != "" {
+			slog.InfoContext(ctx, "job lease expired; reservation reverted", slog.String("job_id", id))
+			s.recall(ctx, droneID, id)
+			reverted++
+		}
+	}
+	return reverted, nil
+}
+
+// recall tells the drone its job is gone. The command reaches it over MQTT
+// and in its next heartbeat response; if it can't be queued the reversion
+// stands and the drone learns from GET /drone/me/order.
+func (s *LeaseSweeper) recall(ctx context.Context, droneID, jobID string) {
+	req := command.IssueRequest{
+		Type:   command.TypeReturnToBase,
+		Reason: "lease expired: job " + jobID + " was released",
+	}
+	if _, err := s.commands.Issue(ctx, droneID, req, maintenance.SystemActor); err != nil {
+		slog.WarnContext(ctx, "failed to recall drone after lease expiry",
+			slog.String("drone_id", droneID),
+			slog.String("job_id", jobID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
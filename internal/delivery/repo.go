@@ -2,59 +2,153 @@ package delivery
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"drone-delivery/internal/airspace"
+	"drone-delivery/internal/common"
 	"drone-delivery/internal/drone"
 	domainerrors "drone-delivery/internal/errors"
 	"drone-delivery/internal/job"
+	"drone-delivery/internal/maintenance"
 	"drone-delivery/internal/order"
+	"drone-delivery/internal/payment"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type Repository interface {
-	CreateOrderAndJob(ctx context.Context, db *sqlx.DB, o *order.Order) error
+	CreateOrderAndJob(ctx context.Context, db *sqlx.DB, o *order.Order, p *payment.Payment) error
+	CreateOrdersAndJobs(ctx context.Context, db *sqlx.DB, orders []*order.Order, payments []*payment.Payment) error
 	CancelOrderAndJob(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, submittedBy string) error
 	ReserveJobAndAssign(ctx context.Context, db *sqlx.DB, jobID, droneID string) (*job.Job, error)
 	GrabOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string) error
 	CompleteDelivery(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string, delivered bool) error
-	HandleDroneBroken(ctx context.Context, db *sqlx.DB, droneID string) error
+	HandleDroneBroken(ctx context.Context, db *sqlx.DB, droneID string, ifMatch *int64) (*drone.Drone, error)
+	HandleDroneBrokenWithTx(ctx context.Context, tx *sqlx.Tx, droneID string) (func(), error)
+	AssignJobToDrone(ctx context.Context, db *sqlx.DB, jobID, droneID string) (*job.Job, error)
+	UnassignOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID) (*order.Order, *job.Job, string, error)
+	ReassignOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string) (*order.Order, *job.Job, string, error)
+	AbortMission(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string) error
+	AbortMissionWithTx(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string) (func(), error)
+	ExtendLease(ctx context.Context, db *sqlx.DB, droneID string, loc common.Location) error
+	ExpireLease(ctx context.Context, db *sqlx.DB, jobID string, now time.Time) (string, error)
+	SetJobPriority(ctx context.Context, db *sqlx.DB, jobID string, priority int, ifMatch *int64) (*job.Job, error)
+	PromoteWaitlisted(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, pickupBy, deliveryBy *time.Time) (bool, error)
 }
 
 type repo struct {
-	orderRepo order.Repository
-	jobRepo   job.Repository
-	droneRepo drone.Repository
+	orderRepo       order.Repository
+	jobRepo         job.Repository
+	droneRepo       drone.Repository
+	paymentRepo     payment.Repository
+	maintenanceRepo maintenance.Repository
+	index           drone.LocationIndex
+	refundPolicy    payment.RefundPolicy
+	guard           FlightGuard
+	airspace        airspace.Service
+	lease           job.LeasePolicy
+	sla             SLAMonitor
 }
 
-func NewRepository(orderRepo order.Repository, jobRepo job.Repository, droneRepo drone.Repository) Repository {
-	return &repo{orderRepo: orderRepo, jobRepo: jobRepo, droneRepo: droneRepo}
+// FlightGuard refuses unsafe or banned flights. Defined here so delivery
+// doesn't import the grounding or weather packages; satisfied by
+// grounding.Guard and weather.Gate.
+type FlightGuard interface {
+	CheckFlight(ctx context.Context, points ...common.Location) error
+}
+
+// SLAMonitor checks an order against its SLA targets inside the caller's
+// transaction. Satisfied by sla.Service.
+type SLAMonitor interface {
+	Check(ctx context.Context, ext sqlx.ExtContext, o *order.Order, now time.Time) (int, error)
+}
+
+// FlightGuards checks each guard in order and returns the first refusal.
+type FlightGuards []FlightGuard
+
+func (gs FlightGuards) CheckFlight(ctx context.Context, points ...common.Location) error {
+	for _, g := range gs {
+		if err := g.CheckFlight(ctx, points...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewRepository(orderRepo order.Repository, jobRepo job.Repository, droneRepo drone.Repository, paymentRepo payment.Repository, maintenanceRepo maintenance.Repository, index drone.LocationIndex, refundPolicy payment.RefundPolicy, guard FlightGuard, airspace airspace.Service, lease job.LeasePolicy, sla SLAMonitor) Repository {
+	return &repo{
+		orderRepo:       orderRepo,
+		jobRepo:         jobRepo,
+		droneRepo:       droneRepo,
+		paymentRepo:     paymentRepo,
+		maintenanceRepo: maintenanceRepo,
+		index:           index,
+		refundPolicy:    refundPolicy,
+		guard:           guard,
+		airspace:        airspace,
+		lease:           lease,
+		sla:             sla,
+	}
 }
 
 // --------------------------------------------------------------
-// CreateOrderAndJob persists the order and creates its job in one transaction.
-func (r *repo) CreateOrderAndJob(ctx context.Context, db *sqlx.DB, o *order.Order) error {
+// CreateOrderAndJob persists the order, its job (unless it is waitlisted)
+// and (when the order is paid) its authorized payment in one transaction.
+func (r *repo) CreateOrderAndJob(ctx context.Context, db *sqlx.DB, o *order.Order, p *payment.Payment) error {
+	return r.CreateOrdersAndJobs(ctx, db, []*order.Order{o}, []*payment.Payment{p})
+}
+
+// --------------------------------------------------------------
+// CreateOrdersAndJobs is CreateOrderAndJob for a batch: every order, with
+// the payment at the same index (nil if unpaid), in one transaction.
+func (r *repo) CreateOrdersAndJobs(ctx context.Context, db *sqlx.DB, orders []*order.Order, payments []*payment.Payment) error {
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
 		return domainerrors.NewInternal("failed to begin transaction", err)
 	}
 	defer tx.Rollback()
 
-	if err := r.orderRepo.Create(ctx, tx, o); err != nil {
+	for i, o := range orders {
+		if err := r.createOrderAndJob(ctx, tx, o, payments[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *repo) createOrderAndJob(ctx context.Context, tx *sqlx.Tx, o *order.Order, p *payment.Payment) error {
+	created, err := r.orderRepo.Create(ctx, tx, o)
+	if err != nil {
 		return domainerrors.NewInternal("failed to create order", err)
 	}
+	if !created {
+		return domainerrors.QuoteUsed()
+	}
 
-	j := job.NewJob(o.ID.String())
-	if err := r.jobRepo.Create(ctx, tx, j); err != nil {
-		return domainerrors.NewInternal("failed to create job", err)
+	// A waitlisted order gets its job when it is promoted
+	if o.Status != order.StatusWaitlisted {
+		j := job.NewJob(o.ID.String())
+		j.Priority = o.Priority
+		if err := r.jobRepo.Create(ctx, tx, j); err != nil {
+			return domainerrors.NewInternal("failed to create job", err)
+		}
 	}
 
-	return tx.Commit()
+	if p != nil {
+		if err := r.paymentRepo.Create(ctx, tx, p); err != nil {
+			return domainerrors.NewInternal("failed to record payment", err)
+		}
+	}
+	return nil
 }
 
 // --------------------------------------------------------------
-// CancelOrderAndJob cancels the order and its job in one transaction.
+// CancelOrderAndJob withdraws the order, cancels its job and voids its
+// payment authorization in one transaction.
 func (r *repo) CancelOrderAndJob(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, submittedBy string) error {
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -62,12 +156,32 @@ func (r *repo) CancelOrderAndJob(ctx context.Context, db *sqlx.DB, orderID uuid.
 	}
 	defer tx.Rollback()
 
-	if err := r.orderRepo.Cancel(ctx, tx, orderID, submittedBy); err != nil {
+	// 1. Withdraw order
+	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return domainerrors.OrderNotFound(orderID.String())
+	}
+	if o.SubmittedBy != submittedBy {
+		return domainerrors.OrderNotOwner()
+	}
+	waitlisted := o.Status == order.StatusWaitlisted
+	if err := o.Withdraw(); err != nil {
+		return err
+	}
+	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
 		return domainerrors.NewInternal("failed to cancel order", err)
 	}
 
-	if err := r.jobRepo.CancelByOrderID(ctx, tx, orderID.String()); err != nil {
-		return domainerrors.NewInternal("failed to cancel job", err)
+	// 2. Cancel job (a waitlisted order has none yet)
+	if !waitlisted {
+		if err := r.jobRepo.CancelByOrderID(ctx, tx, orderID.String()); err != nil {
+			return domainerrors.NewInternal("failed to cancel job", err)
+		}
+	}
+
+	// 3. Void payment
+	if err := r.settlePayment(ctx, tx, orderID, (*payment.Payment).Void); err != nil {
+		return err
 	}
 
 	return tx.Commit()
@@ -83,50 +197,347 @@ func (r *repo) ReserveJobAndAssign(ctx context.Context, db *sqlx.DB, jobID, dron
 	}
 	defer tx.Rollback()
 
+	j, d, err := r.reserveAndAssign(ctx, tx, jobID, droneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return j, nil
+}
+
+// --------------------------------------------------------------
+// reserveAndAssign reserves the job for the drone, assigns its order and
+// reserves the drone inside tx, booking its corridor in the airspace and
+// leasing the job until the drone should reach the pickup. Refused while the
+// drone's position, the pickup or the drop-off is grounded or has unsafe
+// weather, or when the airspace is congested.
+func (r *repo) reserveAndAssign(ctx context.Context, tx *sqlx.Tx, jobID, droneID string) (*job.Job, *drone.Drone, error) {
 	// 1. Reserve job
 	j, err := r.jobRepo.GetByIDForUpdate(ctx, tx, jobID)
 	if err != nil {
-		return nil, domainerrors.JobNotFound(jobID)
+		return nil, nil, domainerrors.JobNotFound(jobID)
 	}
 	if err := j.Reserve(droneID); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
-		return nil, domainerrors.NewInternal("failed to reserve job", err)
+		return nil, nil, domainerrors.NewInternal("failed to reserve job", err)
 	}
 
 	// 2. Assign order to drone
 	orderID, err := uuid.Parse(j.OrderID)
 	if err != nil {
-		return nil, domainerrors.NewValidation("invalid order id in job")
+		return nil, nil, domainerrors.NewValidation("invalid order id in job")
 	}
 	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
 	if err != nil {
-		return nil, domainerrors.NewNotFound("order", orderID.String())
+		return nil, nil, domainerrors.NewNotFound("order", orderID.String())
 	}
 	if err := o.Assign(droneID); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
-		return nil, domainerrors.NewInternal("failed to assign order", err)
+		return nil, nil, domainerrors.NewInternal("failed to assign order", err)
+	}
+	if _, err := r.sla.Check(ctx, tx, o, time.Now()); err != nil {
+		return nil, nil, err
 	}
 
 	// 3. Ensure drone exists, then reserve it
 	if _, err := r.droneRepo.GetByID(ctx, tx, droneID); err != nil {
 		newDrone := drone.New(droneID)
 		if err := r.droneRepo.Upsert(ctx, tx, newDrone); err != nil {
-			return nil, domainerrors.NewInternal("failed to ensure drone exists", err)
+			return nil, nil, domainerrors.NewInternal("failed to ensure drone exists", err)
 		}
 	}
 	d, err := r.droneRepo.GetByIDForUpdate(ctx, tx, droneID)
 	if err != nil {
-		return nil, domainerrors.NewNotFound("drone", droneID)
+		return nil, nil, domainerrors.NewNotFound("drone", droneID)
+	}
+	if err := r.guard.CheckFlight(ctx, d.Location(), o.Origin(), o.Destination()); err != nil {
+		return nil, nil, err
 	}
 	if err := d.Reserve(orderID); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := r.droneRepo.Update(ctx, tx, d); err != nil {
-		return nil, domainerrors.NewInternal("failed to reserve drone", err)
+		return nil, nil, domainerrors.NewInternal("failed to reserve drone", err)
+	}
+
+	// 4. Book the corridor from the drone's last known position (the pickup
+	// before its first heartbeat) through pickup to drop-off
+	start := d.Location()
+	if d.LastHeartbeat == nil {
+		start = o.Origin()
+	}
+	corridor, err := r.airspace.Reserve(ctx, tx, orderID, droneID, start, o.Origin(), o.Destination())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 5. Lease the job until the drone should have reached the pickup,
+	// counting from its departure slot rather than now
+	distKM := common.HaversineDistance(start, o.Origin())
+	j.Lease(distKM, r.lease.Deadline(distKM, corridor.StartsAt))
+	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
+		return nil, nil, domainerrors.NewInternal("failed to lease job", err)
+	}
+
+	return j, d, nil
+}
+
+// --------------------------------------------------------------
+// AssignJobToDrone is the admin override of ReserveJobAndAssign: it hands an
+// open job to a specific registered drone, which must be idle.
+func (r *repo) AssignJobToDrone(ctx context.Context, db *sqlx.DB, jobID, droneID string) (*job.Job, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := r.droneRepo.GetByID(ctx, tx, droneID); err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+
+	j, d, err := r.reserveAndAssign(ctx, tx, jobID, droneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return j, nil
+}
+
+// --------------------------------------------------------------
+// UnassignOrder puts an assigned order back to pending (or awaiting handoff,
+// if it came from one), frees its drone and reopens its job — all in one
+// transaction.
+// UnassignOrder takes an assigned order away from its drone and reopens its
+// job. Returns the reopened job and the drone it was taken from.
+func (r *repo) UnassignOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID) (*order.Order, *job.Job, string, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, "", domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	o, j, d, err := r.unassign(ctx, tx, orderID, "unassigned by admin")
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, "", domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return o, j, d.ID, nil
+}
+
+// --------------------------------------------------------------
+// AbortMission takes the order away from the drone: an assigned order is
+// unassigned, a picked-up one fails as an undelivered flight. Nothing
+// happens if the order is no longer the drone's.
+func (r *repo) AbortMission(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	synced, err := r.AbortMissionWithTx(ctx, tx, orderID, droneID)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	synced()
+	return nil
+}
+
+// AbortMissionWithTx is AbortMission inside the caller's tx. The returned
+// func refreshes the location index and must run after tx commits.
+func (r *repo) AbortMissionWithTx(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string) (func(), error) {
+	o, err := r.orderRepo.GetByID(ctx, tx, orderID)
+	if err != nil {
+		return nil, domainerrors.OrderNotFound(orderID.String())
+	}
+	if o.AssignedDroneID == nil || *o.AssignedDroneID != droneID {
+		return func() {}, nil
+	}
+
+	var d *drone.Drone
+	switch o.Status {
+	case order.StatusAssigned:
+		_, _, d, err = r.unassign(ctx, tx, orderID, "mission aborted")
+	case order.StatusPickedUp:
+		d, err = r.completeDelivery(ctx, tx, orderID, droneID, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() { drone.SyncIndex(ctx, r.index, d) }, nil
+}
+
+// --------------------------------------------------------------
+// ExtendLease pushes out the lease on the job the drone is flying to, as
+// long as the drone, now at loc, has got closer to the pickup. A drone
+// without a leased job is left alone.
+func (r *repo) ExtendLease(ctx context.Context, db *sqlx.DB, droneID string, loc common.Location) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	j, err := r.jobRepo.GetLeasedByDroneForUpdate(ctx, tx, droneID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return domainerrors.NewInternal("failed to load leased job", err)
+	}
+	orderID, err := uuid.Parse(j.OrderID)
+	if err != nil {
+		return domainerrors.NewValidation("invalid order id in job")
+	}
+	o, err := r.orderRepo.GetByID(ctx, tx, orderID)
+	if err != nil {
+		return domainerrors.OrderNotFound(orderID.String())
+	}
+
+	distKM := common.HaversineDistance(loc, o.Origin())
+	if !j.ExtendLease(distKM, r.lease.Deadline(distKM, time.Now())) {
+		return nil
+	}
+	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
+		return domainerrors.NewInternal("failed to extend lease", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// --------------------------------------------------------------
+// ExpireLease reverts the job's reservation if its lease ran out before now:
+// job open, order pending, drone idle, corridor released. It returns the
+// drone the job was taken from, or "" if the lease was extended or ended in
+// the meantime.
+func (r *repo) ExpireLease(ctx context.Context, db *sqlx.DB, jobID string, now time.Time) (string, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	j, err := r.jobRepo.GetByIDForUpdate(ctx, tx, jobID)
+	if err != nil {
+		return "", domainerrors.JobNotFound(jobID)
+	}
+	if !j.LeaseExpired(now) {
+		return "", nil
+	}
+	orderID, err := uuid.Parse(j.OrderID)
+	if err != nil {
+		return "", domainerrors.NewValidation("invalid order id in job")
+	}
+	reason := fmt.Sprintf("lease expired: drone %s did not reach the pickup by %s",
+		*j.ReservedByDroneID, j.LeaseExpiresAt.UTC().Format(time.RFC3339))
+	_, _, d, err := r.unassign(ctx, tx, orderID, reason)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return d.ID, nil
+}
+
+// --------------------------------------------------------------
+// PromoteWaitlisted moves a waitlisted order to pending with a new promise
+// and creates its job — all in one transaction. It reports false if the
+// order is no longer waitlisted, e.g. because it was withdrawn.
+func (r *repo) PromoteWaitlisted(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, pickupBy, deliveryBy *time.Time) (bool, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return false, domainerrors.OrderNotFound(orderID.String())
+	}
+	if o.Status != order.StatusWaitlisted {
+		return false, nil
+	}
+	if err := o.Promote(pickupBy, deliveryBy); err != nil {
+		return false, err
+	}
+	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
+		return false, domainerrors.NewInternal("failed to update order", err)
+	}
+
+	j := job.NewJob(o.ID.String())
+	j.Priority = o.Priority
+	if err := r.jobRepo.Create(ctx, tx, j); err != nil {
+		return false, domainerrors.NewInternal("failed to create job", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	return true, nil
+}
+
+// --------------------------------------------------------------
+// SetJobPriority changes an unfinished job's priority and records it on the
+// order too, so a handoff job keeps it — all in one transaction. Refused if
+// ifMatch names a job version other than the current one.
+func (r *repo) SetJobPriority(ctx context.Context, db *sqlx.DB, jobID string, priority int, ifMatch *int64) (*job.Job, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	j, err := r.jobRepo.GetByIDForUpdate(ctx, tx, jobID)
+	if err != nil {
+		return nil, domainerrors.JobNotFound(jobID)
+	}
+	if err := j.CheckVersion(ifMatch); err != nil {
+		return nil, err
+	}
+	if err := j.SetPriority(priority); err != nil {
+		return nil, err
+	}
+	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
+		return nil, domainerrors.NewInternal("failed to update job", err)
+	}
+
+	orderID, err := uuid.Parse(j.OrderID)
+	if err != nil {
+		return nil, domainerrors.NewValidation("invalid order id in job")
+	}
+	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return nil, domainerrors.OrderNotFound(orderID.String())
+	}
+	o.SetPriority(priority)
+	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
+		return nil, domainerrors.NewInternal("failed to update order", err)
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -136,8 +547,98 @@ func (r *repo) ReserveJobAndAssign(ctx context.Context, db *sqlx.DB, jobID, dron
 }
 
 // --------------------------------------------------------------
-// GrabOrder marks the order as picked up and transitions the drone
-// to delivering — all in one transaction.
+// ReassignOrder moves an assigned order from its current drone to another
+// idle drone — all in one transaction. Returns the job, now leased to the
+// new drone, and the drone the order was taken from.
+func (r *repo) ReassignOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string) (*order.Order, *job.Job, string, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, "", domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := r.droneRepo.GetByID(ctx, tx, droneID); err != nil {
+		return nil, nil, "", domainerrors.DroneNotFound(droneID)
+	}
+
+	_, j, prev, err := r.unassign(ctx, tx, orderID, "reassigned to drone "+droneID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	j, next, err := r.reserveAndAssign(ctx, tx, j.ID, droneID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	// reserveAndAssign updated its own copy of the order
+	o, err := r.orderRepo.GetByID(ctx, tx, orderID)
+	if err != nil {
+		return nil, nil, "", domainerrors.NewInternal("failed to reload order", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, "", domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, prev, next)
+	return o, j, prev.ID, nil
+}
+
+// --------------------------------------------------------------
+// unassign reverts an assignment inside tx: order back to pending or awaiting
+// handoff, drone idle, job reopened with reason recorded. Locks are taken job → order →
+// drone, the same order as reserveAndAssign.
+func (r *repo) unassign(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, reason string) (*order.Order, *job.Job, *drone.Drone, error) {
+	// 1. Reopen job
+	j, err := r.jobRepo.GetByOrderIDForUpdate(ctx, tx, orderID.String())
+	if err != nil {
+		return nil, nil, nil, domainerrors.OrderNotFound(orderID.String())
+	}
+	if err := j.Release(reason); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
+		return nil, nil, nil, domainerrors.NewInternal("failed to reopen job", err)
+	}
+
+	// 2. Order back to pending, or to awaiting handoff
+	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
+	if err != nil {
+		return nil, nil, nil, domainerrors.OrderNotFound(orderID.String())
+	}
+	if o.AssignedDroneID == nil {
+		return nil, nil, nil, domainerrors.OrderInvalidTransition(string(o.Status), string(order.StatusPending))
+	}
+	droneID := *o.AssignedDroneID
+	if err := o.Unassign(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
+		return nil, nil, nil, domainerrors.NewInternal("failed to unassign order", err)
+	}
+
+	// 3. Free the drone
+	d, err := r.droneRepo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, nil, nil, domainerrors.DroneNotFound(droneID)
+	}
+	if err := d.Unassign(orderID); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := r.droneRepo.Update(ctx, tx, d); err != nil {
+		return nil, nil, nil, domainerrors.NewInternal("failed to update drone", err)
+	}
+	if err := r.airspace.Release(ctx, tx, droneID); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return o, j, d, nil
+}
+
+// --------------------------------------------------------------
+// GrabOrder marks the order as picked up, ends the job's lease and
+// transitions the drone to delivering — all in one transaction. Refused
+// while the drone's position or the drop-off is grounded or has unsafe
+// weather.
 func (r *repo) GrabOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string) error {
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -145,7 +646,13 @@ func (r *repo) GrabOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, dr
 	}
 	defer tx.Rollback()
 
-	// 1. Mark order picked up
+	// 1. The pickup ends the job's lease
+	j, err := r.jobRepo.GetByOrderIDForUpdate(ctx, tx, orderID.String())
+	if err != nil {
+		return domainerrors.OrderNotFound(orderID.String())
+	}
+
+	// 2. Mark order picked up
 	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
 	if err != nil {
 		return domainerrors.NewNotFound("order", orderID.String())
@@ -159,12 +666,22 @@ func (r *repo) GrabOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, dr
 	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
 		return domainerrors.NewInternal("failed to update order", err)
 	}
+	if _, err := r.sla.Check(ctx, tx, o, time.Now()); err != nil {
+		return err
+	}
+	j.EndLease()
+	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
+		return domainerrors.NewInternal("failed to update job", err)
+	}
 
-	// 2. Drone starts delivery
+	// 3. Drone starts delivery
 	d, err := r.droneRepo.GetByIDForUpdate(ctx, tx, droneID)
 	if err != nil {
 		return domainerrors.NewNotFound("drone", droneID)
 	}
+	if err := r.guard.CheckFlight(ctx, d.Location(), o.Destination()); err != nil {
+		return err
+	}
 	if err := d.StartDelivery(); err != nil {
 		return err
 	}
@@ -172,12 +689,17 @@ func (r *repo) GrabOrder(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, dr
 		return domainerrors.NewInternal("failed to update drone", err)
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return nil
 }
 
 // --------------------------------------------------------------
 // CompleteDelivery marks the order as delivered/failed, idles the drone,
-// and completes the job — all in one transaction.
+// completes the job and adds the flight to the drone's maintenance counters
+// — all in one transaction.
 func (r *repo) CompleteDelivery(ctx context.Context, db *sqlx.DB, orderID uuid.UUID, droneID string, delivered bool) error {
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -185,101 +707,212 @@ func (r *repo) CompleteDelivery(ctx context.Context, db *sqlx.DB, orderID uuid.U
 	}
 	defer tx.Rollback()
 
+	d, err := r.completeDelivery(ctx, tx, orderID, droneID, delivered)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return nil
+}
+
+// completeDelivery does CompleteDelivery's work inside tx and returns the
+// drone, for the caller to index once tx commits.
+func (r *repo) completeDelivery(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string, delivered bool) (*drone.Drone, error) {
 	// 1. Update order status
 	o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, orderID)
 	if err != nil {
-		return domainerrors.NewNotFound("order", orderID.String())
+		return nil, domainerrors.NewNotFound("order", orderID.String())
 	}
 	if o.AssignedDroneID == nil || *o.AssignedDroneID != droneID {
-		return domainerrors.NewForbidden("drone is not assigned to this order")
+		return nil, domainerrors.NewForbidden("drone is not assigned to this order")
 	}
+	settle := (*payment.Payment).Capture
 	if delivered {
 		if err := o.MarkDelivered(); err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		if err := o.MarkFailed(); err != nil {
-			return err
+			return nil, err
+		}
+		settle = func(p *payment.Payment) (*payment.OutboxEntry, error) {
+			return p.SettleFailed(r.refundPolicy)
 		}
 	}
 	if err := r.orderRepo.Update(ctx, tx, o); err != nil {
-		return domainerrors.NewInternal("failed to update order", err)
+		return nil, domainerrors.NewInternal("failed to update order", err)
+	}
+	if _, err := r.sla.Check(ctx, tx, o, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := r.settlePayment(ctx, tx, orderID, settle); err != nil {
+		return nil, err
 	}
 
 	// 2. Drone goes idle
 	d, err := r.droneRepo.GetByIDForUpdate(ctx, tx, droneID)
 	if err != nil {
-		return domainerrors.NewNotFound("drone", droneID)
+		return nil, domainerrors.NewNotFound("drone", droneID)
 	}
 	d.GoIdle()
 	if err := r.droneRepo.Update(ctx, tx, d); err != nil {
-		return domainerrors.NewInternal("failed to update drone", err)
+		return nil, domainerrors.NewInternal("failed to update drone", err)
+	}
+	if err := r.airspace.Release(ctx, tx, droneID); err != nil {
+		return nil, err
 	}
 
 	// 3. Complete the job
 	j, err := r.jobRepo.GetByOrderIDForUpdate(ctx, tx, orderID.String())
 	if err != nil {
-		return domainerrors.NewInternal(fmt.Sprintf("failed to find job for order %s", orderID), err)
+		return nil, domainerrors.NewInternal(fmt.Sprintf("failed to find job for order %s", orderID), err)
 	}
 	if err := j.Complete(); err != nil {
-		return fmt.Errorf("failed to complete job for order %s: %w", orderID, err)
+		return nil, fmt.Errorf("failed to complete job for order %s: %w", orderID, err)
 	}
 	if err := r.jobRepo.Update(ctx, tx, j); err != nil {
-		return domainerrors.NewInternal(fmt.Sprintf("failed to update job for order %s", orderID), err)
+		return nil, domainerrors.NewInternal(fmt.Sprintf("failed to update job for order %s", orderID), err)
 	}
 
-	return tx.Commit()
+	// 4. Record flight usage and check the maintenance schedule
+	km := common.HaversineDistance(o.Origin(), o.Destination())
+	d.RecordFlight(km, j.FlightTime(time.Now()).Hours())
+	schedule, err := r.maintenanceRepo.ScheduleFor(ctx, tx, d.Model)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load maintenance schedule", err)
+	}
+	if schedule.IsDue(d) {
+		d.FlagMaintenanceDue()
+	}
+	if err := r.droneRepo.UpdateUsage(ctx, tx, d); err != nil {
+		return nil, domainerrors.NewInternal("failed to record drone usage", err)
+	}
+
+	return d, nil
+}
+
+// --------------------------------------------------------------
+// settlePayment applies a payment transition for the order and queues the
+// matching provider call in the outbox. Unpaid orders have no payment row.
+func (r *repo) settlePayment(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, transition func(*payment.Payment) (*payment.OutboxEntry, error)) error {
+	p, err := r.paymentRepo.GetByOrderIDForUpdate(ctx, tx, orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return domainerrors.NewInternal("failed to load payment", err)
+	}
+
+	entry, err := transition(p)
+	if err != nil {
+		return err
+	}
+	if err := r.paymentRepo.Update(ctx, tx, p); err != nil {
+		return domainerrors.NewInternal("failed to update payment", err)
+	}
+	if entry != nil {
+		if err := r.paymentRepo.Enqueue(ctx, tx, entry); err != nil {
+			return domainerrors.NewInternal("failed to queue payment operation", err)
+		}
+	}
+	return nil
 }
 
 // --------------------------------------------------------------
-// HandleDroneBroken marks the drone as broken, transitions its order to
-// awaiting handoff, and creates a new job — all in one transaction.
-func (r *repo) HandleDroneBroken(ctx context.Context, db *sqlx.DB, droneID string) error {
+// HandleDroneBroken marks the drone as broken, opens a repair work order,
+// transitions its order to awaiting handoff, and creates a new job — all in
+// one transaction. A non-nil ifMatch refuses the change with a
+// VersionMismatch unless the drone is still at that version.
+func (r *repo) HandleDroneBroken(ctx context.Context, db *sqlx.DB, droneID string, ifMatch *int64) (*drone.Drone, error) {
 	tx, err := db.BeginTxx(ctx, nil)
 	if err != nil {
-		return domainerrors.NewInternal("failed to begin transaction", err)
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
 	}
 	defer tx.Rollback()
 
+	d, err := r.handleDroneBroken(ctx, tx, droneID, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, r.index, d)
+	return d, nil
+}
+
+// HandleDroneBrokenWithTx is HandleDroneBroken inside the caller's tx. The
+// returned func refreshes the location index and must run after tx commits.
+func (r *repo) HandleDroneBrokenWithTx(ctx context.Context, tx *sqlx.Tx, droneID string) (func(), error) {
+	d, err := r.handleDroneBroken(ctx, tx, droneID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return func() { drone.SyncIndex(ctx, r.index, d) }, nil
+}
+
+func (r *repo) handleDroneBroken(ctx context.Context, tx *sqlx.Tx, droneID string, ifMatch *int64) (*drone.Drone, error) {
 	// 1. Get drone and mark broken
 	d, err := r.droneRepo.GetByIDForUpdate(ctx, tx, droneID)
 	if err != nil {
-		return domainerrors.DroneNotFound(droneID)
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if err := d.CheckVersion(ifMatch); err != nil {
+		return nil, err
 	}
 
 	event, err := d.MarkBroken()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := r.droneRepo.Update(ctx, tx, d); err != nil {
-		return domainerrors.NewInternal("failed to update drone", err)
+		return nil, domainerrors.NewInternal("failed to update drone", err)
+	}
+	if err := r.airspace.Release(ctx, tx, droneID); err != nil {
+		return nil, err
 	}
 
-	// 2. If drone had an order, await handoff and create new job
+	// 2. Open a repair work order; MarkFixed closes it
+	if _, err := r.maintenanceRepo.GetOpenRepairForUpdate(ctx, tx, droneID); errors.Is(err, sql.ErrNoRows) {
+		w := maintenance.NewWorkOrder(droneID, maintenance.KindRepair, "drone reported broken", maintenance.SystemActor)
+		if err := r.maintenanceRepo.CreateWorkOrder(ctx, tx, w); err != nil {
+			return nil, domainerrors.NewInternal("failed to open repair work order", err)
+		}
+	} else if err != nil {
+		return nil, domainerrors.NewInternal("failed to load repair work order", err)
+	}
+
+	// 3. If drone had an order, await handoff and create new job
 	if event.OrderID != nil {
 		o, err := r.orderRepo.GetByIDForUpdate(ctx, tx, *event.OrderID)
 		if err != nil {
-			return domainerrors.NewNotFound("order", event.OrderID.String())
+			return nil, domainerrors.NewNotFound("order", event.OrderID.String())
 		}
 
 		if err := o.AwaitHandoff(); err != nil {
-			return err
+			return nil, err
 		}
 
 		if err := r.orderRepo.Update(ctx, tx, o); err != nil {
-			return domainerrors.NewInternal("failed to update order", err)
+			return nil, domainerrors.NewInternal("failed to update order", err)
 		}
 
 		if err := r.jobRepo.CancelByOrderID(ctx, tx, event.OrderID.String()); err != nil {
-			return domainerrors.NewInternal("failed to cancel job", err)
+			return nil, domainerrors.NewInternal("failed to cancel job", err)
 		}
 
 		j := job.NewJob(event.OrderID.String())
+		j.Priority = o.Priority
 		if err := r.jobRepo.Create(ctx, tx, j); err != nil {
-			return domainerrors.NewInternal("failed to create handoff job", err)
+			return nil, domainerrors.NewInternal("failed to create handoff job", err)
 		}
 	}
 
-	return tx.Commit()
+	return d, nil
 }
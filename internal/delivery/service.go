@@ -2,34 +2,139 @@ package delivery
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
 	"drone-delivery/internal/job"
 	"drone-delivery/internal/order"
+	"drone-delivery/internal/payment"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
 type Service interface {
-	CreateOrderAndJob(ctx context.Context, o *order.Order) error
+	CreateOrderAndJob(ctx context.Context, o *order.Order, paymentMethod string) error
+	CreateOrdersAndJobs(ctx context.Context, orders []*order.Order, paymentMethod string) []error
 	CancelOrderAndJob(ctx context.Context, orderID uuid.UUID, submittedBy string) error
 	ReserveJobAndAssign(ctx context.Context, jobID, droneID string) (*job.Job, error)
 	GrabOrder(ctx context.Context, orderID uuid.UUID, droneID string) error
 	CompleteDelivery(ctx context.Context, orderID uuid.UUID, droneID string, delivered bool) error
 	HandleDroneBroken(ctx context.Context, droneID string) error
+	HandleDroneBrokenWithTx(ctx context.Context, tx *sqlx.Tx, droneID string) (func(), error)
+	MarkDroneBroken(ctx context.Context, droneID string, ifMatch *int64) (*drone.Drone, error)
+	AssignJobToDrone(ctx context.Context, jobID, droneID string) (*job.Job, error)
+	UnassignOrder(ctx context.Context, orderID uuid.UUID) (*order.Order, error)
+	ReassignOrder(ctx context.Context, orderID uuid.UUID, droneID string) (*order.Order, error)
+	AbortMission(ctx context.Context, orderID uuid.UUID, droneID string) error
+	AbortMissionWithTx(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string) (func(), error)
+	ExtendLease(ctx context.Context, droneID string, loc common.Location) error
+	SetJobPriority(ctx context.Context, jobID string, priority int, ifMatch *int64) (*job.Job, error)
+}
+
+// Notifier hears about dispatch events after they commit, e.g. to push them
+// to drones. Defined here so delivery doesn't import the MQTT gateway.
+type Notifier interface {
+	JobAssigned(ctx context.Context, droneID string, j *job.Job)
+	JobReleased(ctx context.Context, droneID string, j *job.Job)
+	HandoffStarted(ctx context.Context, droneID string)
 }
 
 type service struct {
-	db   *sqlx.DB
-	repo Repository
+	db       *sqlx.DB
+	repo     Repository
+	payments payment.Provider
+	notifier Notifier // nil when no gateway is running
+}
+
+func NewService(db *sqlx.DB, repo Repository, payments payment.Provider, notifier Notifier) Service {
+	return &service{db: db, repo: repo, payments: payments, notifier: notifier}
+}
+
+// CreateOrderAndJob authorizes the order's price with the provider before
+// persisting it. If persisting fails the authorization is released again.
+func (s *service) CreateOrderAndJob(ctx context.Context, o *order.Order, paymentMethod string) error {
+	p, err := s.authorize(ctx, o, paymentMethod)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateOrderAndJob(ctx, s.db, o, p); err != nil {
+		s.voidOrphan(ctx, p)
+		return err
+	}
+	return nil
+}
+
+// CreateOrdersAndJobs is CreateOrderAndJob for a batch. Each order is
+// authorized on its own, and those authorized are persisted together in one
+// transaction. It returns each order's error, nil for those persisted; if
+// the transaction fails, every authorized order gets its error and its
+// authorization is released.
+func (s *service) CreateOrdersAndJobs(ctx context.Context, orders []*order.Order, paymentMethod string) []error {
+	errs := make([]error, len(orders))
+	var (
+		authorized []int
+		batch      []*order.Order
+		payments   []*payment.Payment
+	)
+	for i, o := range orders {
+		p, err := s.authorize(ctx, o, paymentMethod)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		authorized = append(authorized, i)
+		batch = append(batch, o)
+		payments = append(payments, p)
+	}
+	if len(batch) == 0 {
+		return errs
+	}
+
+	if err := s.repo.CreateOrdersAndJobs(ctx, s.db, batch, payments); err != nil {
+		for n, i := range authorized {
+			errs[i] = err
+			s.voidOrphan(ctx, payments[n])
+		}
+	}
+	return errs
 }
 
-func NewService(db *sqlx.DB, repo Repository) Service {
-	return &service{db: db, repo: repo}
+// authorize holds the order's price with the provider. An unpriced order
+// needs no payment and gets nil.
+func (s *service) authorize(ctx context.Context, o *order.Order, paymentMethod string) (*payment.Payment, error) {
+	if o.PriceAmount <= 0 {
+		return nil, nil
+	}
+	ref, err := s.payments.Authorize(ctx, payment.AuthorizeRequest{
+		OrderID:       o.ID,
+		Amount:        o.PriceAmount,
+		Currency:      o.PriceCurrency,
+		PaymentMethod: paymentMethod,
+	})
+	if errors.Is(err, payment.ErrDeclined) {
+		return nil, domainerrors.PaymentDeclined()
+	}
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to authorize payment", err)
+	}
+	return payment.NewAuthorized(o.ID, s.payments.Name(), ref, o.PriceAmount, o.PriceCurrency), nil
 }
 
-func (s *service) CreateOrderAndJob(ctx context.Context, o *order.Order) error {
-	return s.repo.CreateOrderAndJob(ctx, s.db, o)
+// voidOrphan releases the authorization of an order that wasn't persisted.
+func (s *service) voidOrphan(ctx context.Context, p *payment.Payment) {
+	if p == nil {
+		return
+	}
+	if err := s.payments.Void(ctx, p.ProviderRef, "orphan-"+p.ID.String()); err != nil {
+		slog.ErrorContext(ctx, "failed to void orphaned authorization",
+			slog.String("provider_ref", p.ProviderRef),
+			slog.String("error", err.Error()),
+		)
+	}
 }
 
 func (s *service) CancelOrderAndJob(ctx context.Context, orderID uuid.UUID, submittedBy string) error {
@@ -37,7 +142,11 @@ func (s *service) CancelOrderAndJob(ctx context.Context, orderID uuid.UUID, subm
 }
 
 func (s *service) ReserveJobAndAssign(ctx context.Context, jobID, droneID string) (*job.Job, error) {
-	return s.repo.ReserveJobAndAssign(ctx, s.db, jobID, droneID)
+	j, err := s.repo.ReserveJobAndAssign(ctx, s.db, jobID, droneID)
+	if err == nil && s.notifier != nil {
+		s.notifier.JobAssigned(ctx, droneID, j)
+	}
+	return j, err
 }
 
 func (s *service) GrabOrder(ctx context.Context, orderID uuid.UUID, droneID string) error {
@@ -49,5 +158,76 @@ func (s *service) CompleteDelivery(ctx context.Context, orderID uuid.UUID, drone
 }
 
 func (s *service) HandleDroneBroken(ctx context.Context, droneID string) error {
-	return s.repo.HandleDroneBroken(ctx, s.db, droneID)
+	_, err := s.MarkDroneBroken(ctx, droneID, nil)
+	return err
+}
+
+// MarkDroneBroken is HandleDroneBroken for an admin holding an ETag: with
+// ifMatch it is refused unless the drone is still at that version.
+func (s *service) MarkDroneBroken(ctx context.Context, droneID string, ifMatch *int64) (*drone.Drone, error) {
+	d, err := s.repo.HandleDroneBroken(ctx, s.db, droneID, ifMatch)
+	if err == nil && s.notifier != nil {
+		s.notifier.HandoffStarted(ctx, droneID)
+	}
+	return d, err
+}
+
+// HandleDroneBrokenWithTx marks the drone broken inside the caller's tx.
+// The returned func publishes the change and must run after tx commits.
+func (s *service) HandleDroneBrokenWithTx(ctx context.Context, tx *sqlx.Tx, droneID string) (func(), error) {
+	synced, err := s.repo.HandleDroneBrokenWithTx(ctx, tx, droneID)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		synced()
+		if s.notifier != nil {
+			s.notifier.HandoffStarted(ctx, droneID)
+		}
+	}, nil
+}
+
+func (s *service) AssignJobToDrone(ctx context.Context, jobID, droneID string) (*job.Job, error) {
+	j, err := s.repo.AssignJobToDrone(ctx, s.db, jobID, droneID)
+	if err == nil && s.notifier != nil {
+		s.notifier.JobAssigned(ctx, droneID, j)
+	}
+	return j, err
+}
+
+// UnassignOrder tells the drone the order was taken from that its job is
+// gone.
+func (s *service) UnassignOrder(ctx context.Context, orderID uuid.UUID) (*order.Order, error) {
+	o, j, prev, err := s.repo.UnassignOrder(ctx, s.db, orderID)
+	if err == nil && s.notifier != nil {
+		s.notifier.JobReleased(ctx, prev, j)
+	}
+	return o, err
+}
+
+// ReassignOrder tells the previous drone its job is gone and the new one
+// that it holds it.
+func (s *service) ReassignOrder(ctx context.Context, orderID uuid.UUID, droneID string) (*order.Order, error) {
+	o, j, prev, err := s.repo.ReassignOrder(ctx, s.db, orderID, droneID)
+	if err == nil && s.notifier != nil {
+		s.notifier.JobReleased(ctx, prev, j)
+		s.notifier.JobAssigned(ctx, droneID, j)
+	}
+	return o, err
+}
+
+func (s *service) AbortMission(ctx context.Context, orderID uuid.UUID, droneID string) error {
+	return s.repo.AbortMission(ctx, s.db, orderID, droneID)
+}
+
+func (s *service) AbortMissionWithTx(ctx context.Context, tx *sqlx.Tx, orderID uuid.UUID, droneID string) (func(), error) {
+	return s.repo.AbortMissionWithTx(ctx, tx, orderID, droneID)
+}
+
+func (s *service) ExtendLease(ctx context.Context, droneID string, loc common.Location) error {
+	return s.repo.ExtendLease(ctx, s.db, droneID, loc)
+}
+
+func (s *service) SetJobPriority(ctx context.Context, jobID string, priority int, ifMatch *int64) (*job.Job, error) {
+	return s.repo.SetJobPriority(ctx, s.db, jobID, priority, ifMatch)
 }
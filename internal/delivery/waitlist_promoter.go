@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/admission"
+	"drone-delivery/internal/order"
+)
+
+type WaitlistPromoterConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// WaitlistPromoter releases waitlisted orders for dispatch, oldest first,
+// once the fleet can deliver them within the SLA again.
+type WaitlistPromoter struct {
+	db       *sqlx.DB
+	repo     Repository
+	orders   order.Repository
+	capacity admission.Service
+	cfg      WaitlistPromoterConfig
+}
+
+func NewWaitlistPromoter(db *sqlx.DB, repo Repository, orders order.Repository, capacity admission.Service, cfg WaitlistPromoterConfig) *WaitlistPromoter {
+	return &WaitlistPromoter{db: db, repo: repo, orders: orders, capacity: capacity, cfg: cfg}
+}
+
+// Run promotes waitlisted orders until ctx is cancelled.
+func (p *WaitlistPromoter) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Promote(ctx); err != nil {
+				slog.ErrorContext(ctx, "waitlist promotion failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// Promote re-promises waitlisted orders in placement order and promotes
+// each one that now fits the SLA. It stops at the first that doesn't, so
+// later orders never overtake earlier ones. Returns how many were promoted.
+func (p *WaitlistPromoter) Promote(ctx context.Context) (int, error) {
+	waiting, err := p.orders.ListWaitlisted(ctx, p.db, p.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list waitlisted orders: %w", err)
+	}
+
+	promoted := 0
+	for _, o := range waiting {
+		promise, err := p.capacity.Repromise(ctx, o.Origin(), o.Destination(), o.Priority)
+		if err != nil || promise.Waitlisted {
+			break
+		}
+		ok, err := p.repo.PromoteWaitlisted(ctx, p.db, o.ID, promise.PickupBy, promise.DeliveryBy)
+		if err != nil {
+			return promoted, fmt.Errorf("promote order %s: %w", o.ID, err)
+		}
+		if ok {
+			slog.InfoContext(ctx, "waitlisted order promoted", slog.String("order_id", o.ID.String()))
+			promoted++
+		}
+	}
+	return promoted, nil
+}
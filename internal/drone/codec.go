@@ -0,0 +1,72 @@
+package drone
+
+import (
+	"fmt"
+	"time"
+
+	"drone-delivery/internal/drone/dronepb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate protoc --go_out=. --go_opt=module=drone-delivery/internal/drone heartbeat.proto
+
+// Protobuf codec for the messages in heartbeat.proto, converting between the
+// generated dronepb types and the request/response DTOs. Unknown fields are
+// skipped so newer clients can add fields.
+
+// UnmarshalBatch decodes a HeartbeatBatch message.
+func UnmarshalBatch(b []byte) ([]HeartbeatRequest, error) {
+	var batch dronepb.HeartbeatBatch
+	if err := proto.Unmarshal(b, &batch); err != nil {
+		return nil, fmt.Errorf("malformed protobuf: %w", err)
+	}
+	samples := make([]HeartbeatRequest, 0, len(batch.Samples))
+	for _, s := range batch.Samples {
+		samples = append(samples, heartbeatFromProto(s))
+	}
+	return samples, nil
+}
+
+func heartbeatFromProto(s *dronepb.Heartbeat) HeartbeatRequest {
+	hb := HeartbeatRequest{
+		Latitude:       s.Latitude,
+		Longitude:      s.Longitude,
+		Sequence:       s.Sequence,
+		AltitudeM:      s.AltitudeM,
+		HeadingDeg:     s.HeadingDeg,
+		GroundSpeedKMH: s.GroundSpeedKmh,
+		FaultCodes:     s.FaultCodes,
+		BootID:         s.BootId,
+	}
+	if s.TimestampMs != 0 {
+		ts := time.UnixMilli(s.TimestampMs).UTC()
+		hb.Timestamp = &ts
+	}
+	if s.BatteryPct != nil {
+		pct := int(*s.BatteryPct)
+		hb.BatteryPct = &pct
+	}
+	return hb
+}
+
+// MarshalAck encodes a BatchAck message.
+func MarshalAck(ack *BatchAck) ([]byte, error) {
+	m := &dronepb.BatchAck{
+		Accepted:    uint32(ack.Accepted),
+		Rejected:    uint32(ack.Rejected),
+		DroneStatus: string(ack.DroneStatus),
+	}
+	if ack.CurrentOrderID != nil {
+		m.CurrentOrderId = *ack.CurrentOrderID
+	}
+	for _, a := range ack.Acks {
+		m.Acks = append(m.Acks, &dronepb.SampleAck{
+			Index:    uint32(a.Index),
+			Sequence: a.Sequence,
+			Accepted: a.Accepted,
+			Reason:   a.Reason,
+		})
+	}
+	return proto.Marshal(m)
+}
@@ -0,0 +1,428 @@
+// Wire format of POST /drone/me/telemetry with
+// Content-Type: application/x-protobuf. The Go types in dronepb are generated
+// from this file; run go generate ./internal/drone after changing it.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: heartbeat.proto
+
+package dronepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Heartbeat struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Latitude       float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude      float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Sequence       *int64                 `protobuf:"varint,3,opt,name=sequence,proto3,oneof" json:"sequence,omitempty"`
+	TimestampMs    int64                  `protobuf:"varint,4,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"` // device clock, Unix milliseconds
+	AltitudeM      *float64               `protobuf:"fixed64,5,opt,name=altitude_m,json=altitudeM,proto3,oneof" json:"altitude_m,omitempty"`
+	HeadingDeg     *float64               `protobuf:"fixed64,6,opt,name=heading_deg,json=headingDeg,proto3,oneof" json:"heading_deg,omitempty"`
+	GroundSpeedKmh *float64               `protobuf:"fixed64,7,opt,name=ground_speed_kmh,json=groundSpeedKmh,proto3,oneof" json:"ground_speed_kmh,omitempty"`
+	BatteryPct     *int32                 `protobuf:"varint,8,opt,name=battery_pct,json=batteryPct,proto3,oneof" json:"battery_pct,omitempty"`
+	FaultCodes     []string               `protobuf:"bytes,9,rep,name=fault_codes,json=faultCodes,proto3" json:"fault_codes,omitempty"`
+	BootId         *string                `protobuf:"bytes,10,opt,name=boot_id,json=bootId,proto3,oneof" json:"boot_id,omitempty"` // firmware session; a new one restarts sequence and clock
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_heartbeat_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_heartbeat_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_heartbeat_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Heartbeat) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetSequence() int64 {
+	if x != nil && x.Sequence != nil {
+		return *x.Sequence
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetAltitudeM() float64 {
+	if x != nil && x.AltitudeM != nil {
+		return *x.AltitudeM
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetHeadingDeg() float64 {
+	if x != nil && x.HeadingDeg != nil {
+		return *x.HeadingDeg
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetGroundSpeedKmh() float64 {
+	if x != nil && x.GroundSpeedKmh != nil {
+		return *x.GroundSpeedKmh
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetBatteryPct() int32 {
+	if x != nil && x.BatteryPct != nil {
+		return *x.BatteryPct
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetFaultCodes() []string {
+	if x != nil {
+		return x.FaultCodes
+	}
+	return nil
+}
+
+func (x *Heartbeat) GetBootId() string {
+	if x != nil && x.BootId != nil {
+		return *x.BootId
+	}
+	return ""
+}
+
+type HeartbeatBatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Samples       []*Heartbeat           `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatBatch) Reset() {
+	*x = HeartbeatBatch{}
+	mi := &file_heartbeat_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatBatch) ProtoMessage() {}
+
+func (x *HeartbeatBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_heartbeat_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatBatch.ProtoReflect.Descriptor instead.
+func (*HeartbeatBatch) Descriptor() ([]byte, []int) {
+	return file_heartbeat_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HeartbeatBatch) GetSamples() []*Heartbeat {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+type SampleAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Sequence      *int64                 `protobuf:"varint,2,opt,name=sequence,proto3,oneof" json:"sequence,omitempty"`
+	Accepted      bool                   `protobuf:"varint,3,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SampleAck) Reset() {
+	*x = SampleAck{}
+	mi := &file_heartbeat_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SampleAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SampleAck) ProtoMessage() {}
+
+func (x *SampleAck) ProtoReflect() protoreflect.Message {
+	mi := &file_heartbeat_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SampleAck.ProtoReflect.Descriptor instead.
+func (*SampleAck) Descriptor() ([]byte, []int) {
+	return file_heartbeat_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SampleAck) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SampleAck) GetSequence() int64 {
+	if x != nil && x.Sequence != nil {
+		return *x.Sequence
+	}
+	return 0
+}
+
+func (x *SampleAck) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *SampleAck) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type BatchAck struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Accepted       uint32                 `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected       uint32                 `protobuf:"varint,2,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	Acks           []*SampleAck           `protobuf:"bytes,3,rep,name=acks,proto3" json:"acks,omitempty"`
+	DroneStatus    string                 `protobuf:"bytes,4,opt,name=drone_status,json=droneStatus,proto3" json:"drone_status,omitempty"`
+	CurrentOrderId string                 `protobuf:"bytes,5,opt,name=current_order_id,json=currentOrderId,proto3" json:"current_order_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BatchAck) Reset() {
+	*x = BatchAck{}
+	mi := &file_heartbeat_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAck) ProtoMessage() {}
+
+func (x *BatchAck) ProtoReflect() protoreflect.Message {
+	mi := &file_heartbeat_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAck.ProtoReflect.Descriptor instead.
+func (*BatchAck) Descriptor() ([]byte, []int) {
+	return file_heartbeat_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchAck) GetAccepted() uint32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *BatchAck) GetRejected() uint32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *BatchAck) GetAcks() []*SampleAck {
+	if x != nil {
+		return x.Acks
+	}
+	return nil
+}
+
+func (x *BatchAck) GetDroneStatus() string {
+	if x != nil {
+		return x.DroneStatus
+	}
+	return ""
+}
+
+func (x *BatchAck) GetCurrentOrderId() string {
+	if x != nil {
+		return x.CurrentOrderId
+	}
+	return ""
+}
+
+var File_heartbeat_proto protoreflect.FileDescriptor
+
+const file_heartbeat_proto_rawDesc = "" +
+	"\n" +
+	"\x0fheartbeat.proto\x12\x05drone\"\xc4\x03\n" +
+	"\tHeartbeat\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x1f\n" +
+	"\bsequence\x18\x03 \x01(\x03H\x00R\bsequence\x88\x01\x01\x12!\n" +
+	"\ftimestamp_ms\x18\x04 \x01(\x03R\vtimestampMs\x12\"\n" +
+	"\n" +
+	"altitude_m\x18\x05 \x01(\x01H\x01R\taltitudeM\x88\x01\x01\x12$\n" +
+	"\vheading_deg\x18\x06 \x01(\x01H\x02R\n" +
+	"headingDeg\x88\x01\x01\x12-\n" +
+	"\x10ground_speed_kmh\x18\a \x01(\x01H\x03R\x0egroundSpeedKmh\x88\x01\x01\x12$\n" +
+	"\vbattery_pct\x18\b \x01(\x05H\x04R\n" +
+	"batteryPct\x88\x01\x01\x12\x1f\n" +
+	"\vfault_codes\x18\t \x03(\tR\n" +
+	"faultCodes\x12\x1c\n" +
+	"\aboot_id\x18\n" +
+	" \x01(\tH\x05R\x06bootId\x88\x01\x01B\v\n" +
+	"\t_sequenceB\r\n" +
+	"\v_altitude_mB\x0e\n" +
+	"\f_heading_degB\x13\n" +
+	"\x11_ground_speed_kmhB\x0e\n" +
+	"\f_battery_pctB\n" +
+	"\n" +
+	"\b_boot_id\"<\n" +
+	"\x0eHeartbeatBatch\x12*\n" +
+	"\asamples\x18\x01 \x03(\v2\x10.drone.HeartbeatR\asamples\"\x83\x01\n" +
+	"\tSampleAck\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x1f\n" +
+	"\bsequence\x18\x02 \x01(\x03H\x00R\bsequence\x88\x01\x01\x12\x1a\n" +
+	"\baccepted\x18\x03 \x01(\bR\baccepted\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reasonB\v\n" +
+	"\t_sequence\"\xb5\x01\n" +
+	"\bBatchAck\x12\x1a\n" +
+	"\baccepted\x18\x01 \x01(\rR\baccepted\x12\x1a\n" +
+	"\brejected\x18\x02 \x01(\rR\brejected\x12$\n" +
+	"\x04acks\x18\x03 \x03(\v2\x10.drone.SampleAckR\x04acks\x12!\n" +
+	"\fdrone_status\x18\x04 \x01(\tR\vdroneStatus\x12(\n" +
+	"\x10current_order_id\x18\x05 \x01(\tR\x0ecurrentOrderIdB'Z%drone-delivery/internal/drone/dronepbb\x06proto3"
+
+var (
+	file_heartbeat_proto_rawDescOnce sync.Once
+	file_heartbeat_proto_rawDescData []byte
+)
+
+func file_heartbeat_proto_rawDescGZIP() []byte {
+	file_heartbeat_proto_rawDescOnce.Do(func() {
+		file_heartbeat_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_heartbeat_proto_rawDesc), len(file_heartbeat_proto_rawDesc)))
+	})
+	return file_heartbeat_proto_rawDescData
+}
+
+var file_heartbeat_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_heartbeat_proto_goTypes = []any{
+	(*Heartbeat)(nil),      // 0: drone.Heartbeat
+	(*HeartbeatBatch)(nil), // 1: drone.HeartbeatBatch
+	(*SampleAck)(nil),      // 2: drone.SampleAck
+	(*BatchAck)(nil),       // 3: drone.BatchAck
+}
+var file_heartbeat_proto_depIdxs = []int32{
+	0, // 0: drone.HeartbeatBatch.samples:type_name -> drone.Heartbeat
+	2, // 1: drone.BatchAck.acks:type_name -> drone.SampleAck
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_heartbeat_proto_init() }
+func file_heartbeat_proto_init() {
+	if File_heartbeat_proto != nil {
+		return
+	}
+	file_heartbeat_proto_msgTypes[0].OneofWrappers = []any{}
+	file_heartbeat_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_heartbeat_proto_rawDesc), len(file_heartbeat_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_heartbeat_proto_goTypes,
+		DependencyIndexes: file_heartbeat_proto_depIdxs,
+		MessageInfos:      file_heartbeat_proto_msgTypes,
+	}.Build()
+	File_heartbeat_proto = out.File
+	file_heartbeat_proto_goTypes = nil
+	file_heartbeat_proto_depIdxs = nil
+}
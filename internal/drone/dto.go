@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Status string
@@ -14,6 +15,7 @@ const (
 	StatusEnRoutePickup   Status = "EN_ROUTE_PICKUP"
 	StatusEnRouteDelivery Status = "EN_ROUTE_DELIVERY"
 	StatusBroken          Status = "BROKEN"
+	StatusRetired         Status = "RETIRED"
 )
 
 type Drone struct {
@@ -23,8 +25,66 @@ type Drone struct {
 	Longitude      float64    `db:"longitude" json:"longitude"`
 	CurrentOrderID *uuid.UUID `db:"current_order_id" json:"current_order_id,omitempty"`
 	LastHeartbeat  *time.Time `db:"last_heartbeat" json:"last_heartbeat,omitempty"`
-	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+
+	Model            string         `db:"model" json:"model"`
+	Capabilities     pq.StringArray `db:"capabilities" json:"capabilities"`
+	HomeLat          *float64       `db:"home_lat" json:"home_lat,omitempty"`
+	HomeLng          *float64       `db:"home_lng" json:"home_lng,omitempty"`
+	MaintenanceNotes string         `db:"maintenance_notes" json:"maintenance_notes"`
+	ServiceHours     float64        `db:"service_hours" json:"service_hours"`
+	RetiredAt        *time.Time     `db:"retired_at" json:"retired_at,omitempty"`
+
+	// Usage counters. ServiceHours is the lifetime flight-hours total; the
+	// *SinceService counters reset when routine maintenance is completed.
+	FlightKM           float64 `db:"flight_km" json:"flight_km"`
+	FlightCycles       int     `db:"flight_cycles" json:"flight_cycles"`
+	HoursSinceService  float64 `db:"hours_since_service" json:"hours_since_service"`
+	KMSinceService     float64 `db:"km_since_service" json:"km_since_service"`
+	CyclesSinceService int     `db:"cycles_since_service" json:"cycles_since_service"`
+	MaintenanceDue     bool    `db:"maintenance_due" json:"maintenance_due"`
+
+	// Heartbeat ordering. Both are nil until the drone sends a sequenced,
+	// timestamped heartbeat. They restart with every new LastBootID.
+	LastHeartbeatSeq *int64     `db:"last_heartbeat_seq" json:"last_heartbeat_seq,omitempty"`
+	LastDeviceTime   *time.Time `db:"last_device_time" json:"last_device_time,omitempty"`
+	LastBootID       *string    `db:"last_boot_id" json:"last_boot_id,omitempty"`
+
+	Quarantined      bool   `db:"quarantined" json:"quarantined"`
+	QuarantineReason string `db:"quarantine_reason" json:"quarantine_reason,omitempty"`
+
+	// Flight state from the latest heartbeat; nil when not reported.
+	AltitudeM      *float64       `db:"altitude_m" json:"altitude_m,omitempty"`
+	HeadingDeg     *float64       `db:"heading_deg" json:"heading_deg,omitempty"`
+	GroundSpeedKMH *float64       `db:"ground_speed_kmh" json:"ground_speed_kmh,omitempty"`
+	BatteryPct     *int           `db:"battery_pct" json:"battery_pct,omitempty"`
+	FaultCodes     pq.StringArray `db:"fault_codes" json:"fault_codes"`
+
+	// Version counts writes to the row; Update refuses a copy that is behind.
+	Version int64 `db:"version" json:"version"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Profile is the admin-managed metadata of a drone. Nil fields are left
+// unchanged on update.
+type Profile struct {
+	Model            *string          `json:"model"`
+	Capabilities     []string         `json:"capabilities"`
+	HomeBase         *common.Location `json:"home_base"`
+	MaintenanceNotes *string          `json:"maintenance_notes"`
+	ServiceHours     *float64         `json:"service_hours" binding:"omitempty,gte=0"`
+}
+
+type RegisterRequest struct {
+	ID string `json:"id" binding:"required"`
+	Profile
+}
+
+// NearbyDrone is a drone returned by a proximity search.
+type NearbyDrone struct {
+	*Drone
+	DistanceKM float64 `json:"distance_km"`
 }
 
 type DroneBrokenEvent struct {
@@ -33,12 +93,80 @@ type DroneBrokenEvent struct {
 	OrderID  *uuid.UUID
 }
 
+// HeartbeatRequest is a position report. Sequence must increase with every
+// heartbeat and Timestamp is the device clock; both are optional unless
+// HeartbeatPolicy.RequireSequence is set. The flight state fields are
+// optional; omitted ones are stored as unknown.
 type HeartbeatRequest struct {
-	Latitude  float64 `json:"latitude" binding:"required"`
-	Longitude float64 `json:"longitude" binding:"required"`
+	Latitude  float64    `json:"latitude" binding:"required"`
+	Longitude float64    `json:"longitude" binding:"required"`
+	Sequence  *int64     `json:"sequence" binding:"omitempty,gte=0"`
+	Timestamp *time.Time `json:"timestamp"`
+	// BootID names the firmware session. A new value after a reboot starts
+	// the sequence and device clock afresh.
+	BootID *string `json:"boot_id"`
+
+	AltitudeM      *float64 `json:"altitude_m"`
+	HeadingDeg     *float64 `json:"heading_deg"`
+	GroundSpeedKMH *float64 `json:"ground_speed_kmh"`
+	BatteryPct     *int     `json:"battery_pct"`
+	FaultCodes     []string `json:"fault_codes"`
+}
+
+type AnomalyKind string
+
+const (
+	AnomalyReplay          AnomalyKind = "REPLAY"
+	AnomalyOutOfOrder      AnomalyKind = "OUT_OF_ORDER"
+	AnomalyImpossibleSpeed AnomalyKind = "IMPOSSIBLE_SPEED"
+)
+
+// Anomaly is a suspicious heartbeat. Rejected anomalies were refused; the
+// others were accepted but flagged.
+type Anomaly struct {
+	ID            uuid.UUID   `db:"id" json:"id"`
+	DroneID       string      `db:"drone_id" json:"drone_id"`
+	Kind          AnomalyKind `db:"kind" json:"kind"`
+	Rejected      bool        `db:"rejected" json:"rejected"`
+	Sequence      *int64      `db:"sequence" json:"sequence,omitempty"`
+	DeviceTime    *time.Time  `db:"device_time" json:"device_time,omitempty"`
+	PrevLatitude  *float64    `db:"prev_latitude" json:"prev_latitude,omitempty"`
+	PrevLongitude *float64    `db:"prev_longitude" json:"prev_longitude,omitempty"`
+	Latitude      float64     `db:"latitude" json:"latitude"`
+	Longitude     float64     `db:"longitude" json:"longitude"`
+	SpeedKMH      *float64    `db:"speed_kmh" json:"speed_kmh,omitempty"`
+	DetectedAt    time.Time   `db:"detected_at" json:"detected_at"`
+}
+
+type QuarantineRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// TelemetryBatch is a batch of heartbeat samples, oldest first. Every sample
+// must carry a device timestamp.
+type TelemetryBatch struct {
+	Samples []HeartbeatRequest `json:"samples"`
+}
+
+// SampleAck reports the outcome of one sample, by its index in the batch.
+type SampleAck struct {
+	Index    int    `json:"index"`
+	Sequence *int64 `json:"sequence,omitempty"`
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// BatchAck is the response to a telemetry batch.
+type BatchAck struct {
+	Accepted       int         `json:"accepted"`
+	Rejected       int         `json:"rejected"`
+	Acks           []SampleAck `json:"acks"`
+	DroneStatus    Status      `json:"drone_status"`
+	CurrentOrderID *string     `json:"current_order_id,omitempty"`
 }
 
 type HeartbeatResponse struct {
 	DroneStatus    Status  `json:"drone_status"`
 	CurrentOrderID *string `json:"current_order_id,omitempty"`
+	Commands       any     `json:"commands,omitempty"` // outstanding ground-control commands
 }
@@ -1,9 +1,17 @@
 package drone
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"strings"
 
+	domainerrors "drone-delivery/internal/errors"
 	"drone-delivery/internal/pkg/apperrors"
 
 	"github.com/gin-gonic/gin"
@@ -19,14 +27,23 @@ type BrokenHandler interface {
 	HandleDroneBroken(ctx context.Context, droneID string) error
 }
 
+// CommandInbox avoids importing command package (circular dep prevention).
+type CommandInbox interface {
+	Deliver(ctx context.Context, droneID string) (any, error)
+}
+
 type Handler struct {
 	service       Service
 	orderQuery    OrderQuerier
 	brokenHandler BrokenHandler
+	commands      CommandInbox
+	maxBatchBytes int64
 }
 
-func NewHandler(service Service, orderQuery OrderQuerier, brokenHandler BrokenHandler) *Handler {
-	return &Handler{service: service, orderQuery: orderQuery, brokenHandler: brokenHandler}
+// NewHandler builds the drone handler. maxBatchBytes caps a telemetry batch
+// body after decompression.
+func NewHandler(service Service, orderQuery OrderQuerier, brokenHandler BrokenHandler, commands CommandInbox, maxBatchBytes int64) *Handler {
+	return &Handler{service: service, orderQuery: orderQuery, brokenHandler: brokenHandler, commands: commands, maxBatchBytes: maxBatchBytes}
 }
 
 // --------------------------------------------------------------
@@ -38,7 +55,7 @@ func (h *Handler) Heartbeat(c *gin.Context) {
 	}
 
 	droneID := c.GetString("sub")
-	d, err := h.service.Heartbeat(c.Request.Context(), droneID, req.Latitude, req.Longitude)
+	d, err := h.service.Heartbeat(c.Request.Context(), droneID, req)
 	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
@@ -49,9 +66,98 @@ func (h *Handler) Heartbeat(c *gin.Context) {
 		s := d.CurrentOrderID.String()
 		resp.CurrentOrderID = &s
 	}
+	// The heartbeat is already accepted; a failed lookup only delays commands
+	// to the next heartbeat.
+	cmds, err := h.commands.Deliver(c.Request.Context(), droneID)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "failed to deliver commands",
+			slog.String("drone_id", droneID),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		resp.Commands = cmds
+	}
 	c.JSON(http.StatusOK, resp)
 }
 
+// --------------------------------------------------------------
+// Telemetry ingests a batch of heartbeat samples. The body is JSON or, with
+// Content-Type application/x-protobuf, a HeartbeatBatch message; either may
+// be gzip-compressed (Content-Encoding: gzip). The ack uses the request's
+// format.
+func (h *Handler) Telemetry(c *gin.Context) {
+	body, err := h.readBatchBody(c)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errBatchTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	protobuf := isProtobuf(c.ContentType())
+	var samples []HeartbeatRequest
+	if protobuf {
+		samples, err = UnmarshalBatch(body)
+	} else {
+		var batch TelemetryBatch
+		err = json.Unmarshal(body, &batch)
+		samples = batch.Samples
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid telemetry batch: " + err.Error()}})
+		return
+	}
+
+	ack, err := h.service.Ingest(c.Request.Context(), c.GetString("sub"), samples)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if protobuf {
+		b, err := MarshalAck(ack)
+		if err != nil {
+			apperrors.ToHTTPError(c, domainerrors.NewInternal("failed to encode batch ack", err))
+			return
+		}
+		c.Data(http.StatusOK, protobufContentType, b)
+		return
+	}
+	c.JSON(http.StatusOK, ack)
+}
+
+const protobufContentType = "application/x-protobuf"
+
+var errBatchTooLarge = errors.New("telemetry batch is too large")
+
+func isProtobuf(contentType string) bool {
+	return contentType == protobufContentType || contentType == "application/protobuf"
+}
+
+// readBatchBody reads the request body, gunzipping it if needed, and fails
+// once more than maxBatchBytes have been decoded.
+func (h *Handler) readBatchBody(c *gin.Context) ([]byte, error) {
+	var r io.Reader = c.Request.Body
+	if strings.EqualFold(c.GetHeader("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, h.maxBatchBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if int64(len(body)) > h.maxBatchBytes {
+		return nil, errBatchTooLarge
+	}
+	return body, nil
+}
+
 // --------------------------------------------------------------
 func (h *Handler) GetCurrentOrder(c *gin.Context) {
 	droneID := c.GetString("sub")
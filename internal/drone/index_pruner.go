@@ -0,0 +1,65 @@
+package drone
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"drone-delivery/internal/redis"
+)
+
+type IndexPrunerConfig struct {
+	Interval time.Duration
+	// MaxAge is how long a drone stays in the GEO index after its last
+	// heartbeat; the location cache entry expires after the same time.
+	MaxAge time.Duration
+}
+
+// pruneBatch caps the drones removed by one script call, so a large backlog
+// doesn't block Redis.
+const pruneBatch = 500
+
+// IndexPruner drops drones that stopped sending heartbeats from the GEO
+// index, so proximity search doesn't keep returning their last position.
+type IndexPruner struct {
+	cache *redis.DroneLocationCache
+	cfg   IndexPrunerConfig
+}
+
+func NewIndexPruner(cache *redis.DroneLocationCache, cfg IndexPrunerConfig) *IndexPruner {
+	return &IndexPruner{cache: cache, cfg: cfg}
+}
+
+// Run prunes the index until ctx is cancelled.
+func (p *IndexPruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Prune(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "drone index prune failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// Prune removes every drone whose last heartbeat is more than MaxAge before
+// now and returns how many were removed.
+func (p *IndexPruner) Prune(ctx context.Context, now time.Time) (int, error) {
+	before := now.Add(-p.cfg.MaxAge)
+	total := 0
+	for {
+		n, err := p.cache.Prune(ctx, before, pruneBatch)
+		total += n
+		if err != nil || n < pruneBatch {
+			if total > 0 {
+				slog.InfoContext(ctx, "pruned silent drones from the GEO index", slog.Int("count", total))
+			}
+			return total, err
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package drone
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,15 +12,96 @@ import (
 	domainerrors "drone-delivery/internal/errors"
 )
 
+const (
+	// gpsToleranceKM is position noise ignored by the speed check.
+	gpsToleranceKM = 0.05
+	// minSpeedSample floors the time between heartbeats so near-simultaneous
+	// reports don't turn GPS noise into enormous speeds.
+	minSpeedSample = time.Second
+
+	// Accepted ranges for reported flight state.
+	minAltitudeM  = -500.0
+	maxAltitudeM  = 10000.0
+	maxFaultCodes = 32
+	maxFaultCode  = 64
+	maxBootID     = 64
+)
 
 func New(id string) *Drone {
 	now := time.Now()
 	return &Drone{
-		ID:        id,
-		Status:    StatusIdle,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:           id,
+		Status:       StatusIdle,
+		Capabilities: []string{},
+		FaultCodes:   []string{},
+		Version:      1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// CheckVersion refuses an edit based on a version other than the current
+// one. A nil want accepts any version.
+func (d *Drone) CheckVersion(want *int64) error {
+	if want != nil && *want != d.Version {
+		return domainerrors.VersionMismatch("drone", d.ID, d.Version)
+	}
+	return nil
+}
+
+// Register creates an explicitly onboarded drone with its profile applied.
+func Register(id string, p Profile) *Drone {
+	d := New(id)
+	d.ApplyProfile(p)
+	return d
+}
+
+// ApplyProfile overwrites the metadata fields that are set in p.
+func (d *Drone) ApplyProfile(p Profile) {
+	if p.Model != nil {
+		d.Model = *p.Model
+	}
+	if p.Capabilities != nil {
+		d.Capabilities = p.Capabilities
 	}
+	if p.HomeBase != nil {
+		d.HomeLat = &p.HomeBase.Lat
+		d.HomeLng = &p.HomeBase.Lng
+	}
+	if p.MaintenanceNotes != nil {
+		d.MaintenanceNotes = *p.MaintenanceNotes
+	}
+	if p.ServiceHours != nil {
+		d.ServiceHours = *p.ServiceHours
+	}
+	d.UpdatedAt = time.Now()
+}
+
+// HomeBase returns the drone's home base, if one is configured.
+func (d *Drone) HomeBase() *common.Location {
+	if d.HomeLat == nil || d.HomeLng == nil {
+		return nil
+	}
+	loc := common.NewLocation(*d.HomeLat, *d.HomeLng)
+	return &loc
+}
+
+// Retire decommissions the drone. The row is kept for history; only idle or
+// broken drones can be retired.
+func (d *Drone) Retire() error {
+	if d.Status != StatusIdle && d.Status != StatusBroken {
+		return domainerrors.DroneInvalidTransition(string(d.Status), string(StatusRetired))
+	}
+	now := time.Now()
+	d.Status = StatusRetired
+	d.CurrentOrderID = nil
+	d.RetiredAt = &now
+	d.UpdatedAt = now
+	return nil
+}
+
+func (d *Drone) IsRetired() bool {
+	return d.Status == StatusRetired
 }
 
 func (d *Drone) Location() common.Location {
@@ -25,6 +109,15 @@ func (d *Drone) Location() common.Location {
 }
 
 func (d *Drone) Reserve(orderID uuid.UUID) error {
+	if d.IsRetired() {
+		return domainerrors.DroneRetired(d.ID)
+	}
+	if d.Quarantined {
+		return domainerrors.DroneQuarantined(d.ID)
+	}
+	if d.MaintenanceDue {
+		return domainerrors.DroneMaintenanceDue(d.ID)
+	}
 	if d.Status != StatusIdle {
 		return domainerrors.DroneInvalidTransition(string(d.Status), string(StatusEnRoutePickup))
 	}
@@ -43,6 +136,18 @@ func (d *Drone) StartDelivery() error {
 	return nil
 }
 
+// Unassign frees a drone that has not yet picked up its order.
+func (d *Drone) Unassign(orderID uuid.UUID) error {
+	if d.Status != StatusEnRoutePickup {
+		return domainerrors.DroneInvalidTransition(string(d.Status), string(StatusIdle))
+	}
+	if d.CurrentOrderID == nil || *d.CurrentOrderID != orderID {
+		return domainerrors.DroneNotAssigned()
+	}
+	d.GoIdle()
+	return nil
+}
+
 func (d *Drone) GoIdle() {
 	d.Status = StatusIdle
 	d.CurrentOrderID = nil
@@ -50,6 +155,9 @@ func (d *Drone) GoIdle() {
 }
 
 func (d *Drone) MarkBroken() (*DroneBrokenEvent, error) {
+	if d.IsRetired() {
+		return nil, domainerrors.DroneRetired(d.ID)
+	}
 	if d.Status == StatusBroken {
 		return nil, domainerrors.DroneAlreadyBroken()
 	}
@@ -74,6 +182,188 @@ func (d *Drone) MarkFixed() error {
 	return nil
 }
 
+// RecordFlight adds one completed delivery to the usage counters.
+func (d *Drone) RecordFlight(km, hours float64) {
+	d.ServiceHours += hours
+	d.FlightKM += km
+	d.FlightCycles++
+	d.HoursSinceService += hours
+	d.KMSinceService += km
+	d.CyclesSinceService++
+	d.UpdatedAt = time.Now()
+}
+
+// FlagMaintenanceDue locks the drone out of new reservations until
+// maintenance is completed.
+func (d *Drone) FlagMaintenanceDue() {
+	d.MaintenanceDue = true
+	d.UpdatedAt = time.Now()
+}
+
+// CompleteMaintenance resets the since-service counters and lifts the lockout.
+func (d *Drone) CompleteMaintenance() {
+	d.HoursSinceService = 0
+	d.KMSinceService = 0
+	d.CyclesSinceService = 0
+	d.MaintenanceDue = false
+	d.UpdatedAt = time.Now()
+}
+
+// InspectHeartbeat compares hb with the last accepted heartbeat. A reused
+// sequence number or a device timestamp older than the last one yields a
+// rejected anomaly, unless hb opens a new boot; moving faster than
+// maxSpeedKMH since the last position yields an accepted one. The speed uses
+// device timestamps when both heartbeats carry one from the same boot and
+// server receive times otherwise. Returns nil for a clean heartbeat.
+func (d *Drone) InspectHeartbeat(hb HeartbeatRequest, now time.Time, maxSpeedKMH float64) *Anomaly {
+	newAnomaly := func(kind AnomalyKind, rejected bool) *Anomaly {
+		return &Anomaly{
+			ID:         uuid.New(),
+			DroneID:    d.ID,
+			Kind:       kind,
+			Rejected:   rejected,
+			Sequence:   hb.Sequence,
+			DeviceTime: hb.Timestamp,
+			Latitude:   hb.Latitude,
+			Longitude:  hb.Longitude,
+			DetectedAt: now,
+		}
+	}
+
+	sameBoot := !d.NewBoot(hb)
+	if sameBoot && hb.Sequence != nil && d.LastHeartbeatSeq != nil && *hb.Sequence <= *d.LastHeartbeatSeq {
+		return newAnomaly(AnomalyReplay, true)
+	}
+	if sameBoot && hb.Timestamp != nil && d.LastDeviceTime != nil && hb.Timestamp.Before(*d.LastDeviceTime) {
+		return newAnomaly(AnomalyOutOfOrder, true)
+	}
+
+	if d.LastHeartbeat == nil || maxSpeedKMH <= 0 {
+		return nil
+	}
+	from, to := *d.LastHeartbeat, now
+	if sameBoot && hb.Timestamp != nil && d.LastDeviceTime != nil {
+		from, to = *d.LastDeviceTime, *hb.Timestamp
+	}
+	km := common.HaversineDistance(d.Location(), common.NewLocation(hb.Latitude, hb.Longitude))
+	if km < gpsToleranceKM {
+		return nil
+	}
+	elapsed := max(to.Sub(from), minSpeedSample)
+	speed := km / elapsed.Hours()
+	if speed <= maxSpeedKMH {
+		return nil
+	}
+
+	a := newAnomaly(AnomalyImpossibleSpeed, false)
+	prevLat, prevLng := d.Latitude, d.Longitude
+	a.PrevLatitude, a.PrevLongitude = &prevLat, &prevLng
+	a.SpeedKMH = &speed
+	return a
+}
+
+// NewBoot reports whether hb names a boot other than the drone's last one.
+// Heartbeats without a boot ID continue the current boot.
+func (d *Drone) NewBoot(hb HeartbeatRequest) bool {
+	return hb.BootID != nil && (d.LastBootID == nil || *hb.BootID != *d.LastBootID)
+}
+
+// Describe is a one-line summary for operator alerts.
+func (a *Anomaly) Describe() string {
+	switch a.Kind {
+	case AnomalyReplay:
+		return fmt.Sprintf("drone %s replayed heartbeat sequence %d", a.DroneID, *a.Sequence)
+	case AnomalyOutOfOrder:
+		return fmt.Sprintf("drone %s sent a heartbeat older than its last one", a.DroneID)
+	case AnomalyImpossibleSpeed:
+		return fmt.Sprintf("drone %s moved at an impossible %.0f km/h", a.DroneID, *a.SpeedKMH)
+	}
+	return fmt.Sprintf("drone %s heartbeat anomaly %s", a.DroneID, a.Kind)
+}
+
+// ValidateFlightState checks the optional flight state fields of hb.
+func (hb HeartbeatRequest) ValidateFlightState() error {
+	if hb.AltitudeM != nil && (*hb.AltitudeM < minAltitudeM || *hb.AltitudeM > maxAltitudeM) {
+		return fmt.Errorf("altitude_m must be between %.0f and %.0f", minAltitudeM, maxAltitudeM)
+	}
+	if hb.HeadingDeg != nil && (*hb.HeadingDeg < 0 || *hb.HeadingDeg >= 360) {
+		return fmt.Errorf("heading_deg must be in [0, 360)")
+	}
+	if hb.GroundSpeedKMH != nil && *hb.GroundSpeedKMH < 0 {
+		return fmt.Errorf("ground_speed_kmh must not be negative")
+	}
+	if hb.BatteryPct != nil && (*hb.BatteryPct < 0 || *hb.BatteryPct > 100) {
+		return fmt.Errorf("battery_pct must be between 0 and 100")
+	}
+	if hb.BootID != nil && (*hb.BootID == "" || len(*hb.BootID) > maxBootID) {
+		return fmt.Errorf("boot_id must be 1 to %d characters", maxBootID)
+	}
+	if len(hb.FaultCodes) > maxFaultCodes {
+		return fmt.Errorf("at most %d fault_codes are allowed", maxFaultCodes)
+	}
+	for _, c := range hb.FaultCodes {
+		if c == "" || len(c) > maxFaultCode {
+			return fmt.Errorf("fault codes must be 1 to %d characters", maxFaultCode)
+		}
+	}
+	return nil
+}
+
+// AcceptHeartbeat records hb as the drone's latest position and flight
+// state, and advances the sequence and device clock; a new boot restarts
+// them from hb. Flight state fields hb omits are cleared rather than left
+// stale.
+func (d *Drone) AcceptHeartbeat(hb HeartbeatRequest) {
+	d.UpdateLocation(hb.Latitude, hb.Longitude)
+	if d.NewBoot(hb) {
+		d.LastBootID = hb.BootID
+		d.LastHeartbeatSeq = nil
+		d.LastDeviceTime = nil
+	}
+	if hb.Sequence != nil {
+		d.LastHeartbeatSeq = hb.Sequence
+	}
+	if hb.Timestamp != nil {
+		d.LastDeviceTime = hb.Timestamp
+	}
+	d.AltitudeM = hb.AltitudeM
+	d.HeadingDeg = hb.HeadingDeg
+	d.GroundSpeedKMH = hb.GroundSpeedKMH
+	d.BatteryPct = hb.BatteryPct
+	d.FaultCodes = make([]string, 0, len(hb.FaultCodes))
+	for _, c := range hb.FaultCodes {
+		d.FaultCodes = append(d.FaultCodes, strings.ToUpper(c))
+	}
+}
+
+// CriticalFault returns the first reported fault code listed in critical.
+func (d *Drone) CriticalFault(critical []string) (string, bool) {
+	for _, c := range d.FaultCodes {
+		if slices.Contains(critical, c) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Quarantine blocks new reservations until an admin lifts it. A drone
+// already on a job keeps flying it.
+func (d *Drone) Quarantine(reason string) {
+	d.Quarantined = true
+	d.QuarantineReason = reason
+	d.UpdatedAt = time.Now()
+}
+
+func (d *Drone) LiftQuarantine() error {
+	if !d.Quarantined {
+		return domainerrors.DroneNotQuarantined(d.ID)
+	}
+	d.Quarantined = false
+	d.QuarantineReason = ""
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
 func (d *Drone) UpdateLocation(lat, lng float64) {
 	d.Latitude = lat
 	d.Longitude = lng
@@ -5,16 +5,41 @@ import (
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 )
 
-const columns = `id, status, latitude, longitude, current_order_id, last_heartbeat, created_at, updated_at`
+const columns = `id, status, latitude, longitude, current_order_id, last_heartbeat,
+	model, capabilities, home_lat, home_lng, maintenance_notes, service_hours, retired_at,
+	flight_km, flight_cycles, hours_since_service, km_since_service, cycles_since_service, maintenance_due,
+	last_heartbeat_seq, last_device_time, last_boot_id, quarantined, quarantine_reason,
+	altitude_m, heading_deg, ground_speed_kmh, battery_pct, fault_codes,
+	version, created_at, updated_at`
+
+const anomalyColumns = `id, drone_id, kind, rejected, sequence, device_time, prev_latitude, prev_longitude,
+	latitude, longitude, speed_kmh, detected_at`
+
+// maxAnomaliesListed caps an anomaly listing; newest first.
+const maxAnomaliesListed = 200
 
 type Repository interface {
+	Create(ctx context.Context, ext sqlx.ExtContext, d *Drone) (bool, error)
 	Upsert(ctx context.Context, ext sqlx.ExtContext, d *Drone) error
 	GetByID(ctx context.Context, ext sqlx.ExtContext, id string) (*Drone, error)
 	GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id string) (*Drone, error)
 	Update(ctx context.Context, ext sqlx.ExtContext, d *Drone) error
-	ListAll(ctx context.Context, ext sqlx.ExtContext, status *Status, page, limit int) ([]*Drone, int, error)
+	UpdateProfile(ctx context.Context, ext sqlx.ExtContext, d *Drone) error
+	UpdateUsage(ctx context.Context, ext sqlx.ExtContext, d *Drone) error
+	UpdateHeartbeat(ctx context.Context, ext sqlx.ExtContext, d *Drone) error
+	List(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) ([]*Drone, string, error)
+	Count(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) (int, error)
+	Each(ctx context.Context, ext sqlx.ExtContext, status *Status, fn func(*Drone) error) error
+	ListByIDs(ctx context.Context, ext sqlx.ExtContext, ids []string) ([]*Drone, error)
+	CountByStatus(ctx context.Context, ext sqlx.ExtContext) (map[Status]int, error)
+	CreateAnomaly(ctx context.Context, ext sqlx.ExtContext, a *Anomaly) error
+	ListAnomalies(ctx context.Context, ext sqlx.ExtContext, droneID string) ([]*Anomaly, error)
 }
 
 type repo struct{}
@@ -23,6 +48,24 @@ func NewRepository() Repository {
 	return &repo{}
 }
 
+// Create inserts a registered drone. It reports false if the id is taken.
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, d *Drone) (bool, error) {
+	const query = `INSERT INTO drones (id, status, latitude, longitude, model, capabilities, home_lat, home_lng,
+			maintenance_notes, service_hours, created_at, updated_at)
+		VALUES (:id, :status, :latitude, :longitude, :model, :capabilities, :home_lat, :home_lng,
+			:maintenance_notes, :service_hours, :created_at, :updated_at)
+		ON CONFLICT (id) DO NOTHING`
+	res, err := sqlx.NamedExecContext(ctx, ext, query, d)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
 func (r *repo) Upsert(ctx context.Context, ext sqlx.ExtContext, d *Drone) error {
 	const query = `INSERT INTO drones (id, status, latitude, longitude, current_order_id, last_heartbeat, created_at, updated_at)
 		VALUES (:id, :status, :latitude, :longitude, :current_order_id, :last_heartbeat, :created_at, :updated_at)
@@ -32,7 +75,8 @@ func (r *repo) Upsert(ctx context.Context, ext sqlx.ExtContext, d *Drone) error
 			longitude = EXCLUDED.longitude,
 			current_order_id = EXCLUDED.current_order_id,
 			last_heartbeat = EXCLUDED.last_heartbeat,
-			updated_at = EXCLUDED.updated_at`
+			updated_at = EXCLUDED.updated_at,
+			version = drones.version + 1`
 	_, err := sqlx.NamedExecContext(ctx, ext, query, d)
 	return err
 }
@@ -57,39 +101,201 @@ func (r *repo) GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id str
 	return &d, nil
 }
 
+// Update writes d's status and location over the row it was read from.
+// Like UpdateProfile and UpdateUsage it is refused with a StaleWrite if the
+// row has been written since.
 func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, d *Drone) error {
 	const query = `UPDATE drones SET status = :status, latitude = :latitude, longitude = :longitude,
-		current_order_id = :current_order_id, last_heartbeat = :last_heartbeat, updated_at = :updated_at
-		WHERE id = :id`
+		current_order_id = :current_order_id, last_heartbeat = :last_heartbeat, retired_at = :retired_at,
+		last_heartbeat_seq = :last_heartbeat_seq, last_device_time = :last_device_time, last_boot_id = :last_boot_id,
+		quarantined = :quarantined, quarantine_reason = :quarantine_reason,
+		altitude_m = :altitude_m, heading_deg = :heading_deg, ground_speed_kmh = :ground_speed_kmh,
+		battery_pct = :battery_pct, fault_codes = :fault_codes,
+		updated_at = :updated_at, version = version + 1
+		WHERE id = :id AND version = :version`
+	return updateVersioned(ctx, ext, query, d)
+}
+
+// UpdateProfile writes only the admin-managed metadata.
+func (r *repo) UpdateProfile(ctx context.Context, ext sqlx.ExtContext, d *Drone) error {
+	const query = `UPDATE drones SET model = :model, capabilities = :capabilities, home_lat = :home_lat,
+		home_lng = :home_lng, maintenance_notes = :maintenance_notes, service_hours = :service_hours,
+		updated_at = :updated_at, version = version + 1
+		WHERE id = :id AND version = :version`
+	return updateVersioned(ctx, ext, query, d)
+}
+
+// UpdateHeartbeat writes only the heartbeat-owned columns, and only if the
+// row doesn't already hold a later heartbeat. It leaves the version alone:
+// telemetry is not an edit, and bumping it would fail every admin If-Match
+// on a flying drone. Status changes go through Update.
+func (r *repo) UpdateHeartbeat(ctx context.Context, ext sqlx.ExtContext, d *Drone) error {
+	const query = `UPDATE drones SET latitude = :latitude, longitude = :longitude,
+		last_heartbeat = :last_heartbeat, last_heartbeat_seq = :last_heartbeat_seq,
+		last_device_time = :last_device_time, last_boot_id = :last_boot_id,
+		altitude_m = :altitude_m, heading_deg = :heading_deg, ground_speed_kmh = :ground_speed_kmh,
+		battery_pct = :battery_pct, fault_codes = :fault_codes,
+		updated_at = :updated_at
+		WHERE id = :id AND (last_heartbeat IS NULL OR last_heartbeat <= :last_heartbeat)`
 	_, err := sqlx.NamedExecContext(ctx, ext, query, d)
 	return err
 }
 
-func (r *repo) ListAll(ctx context.Context, ext sqlx.ExtContext, status *Status, page, limit int) ([]*Drone, int, error) {
-	offset := (page - 1) * limit
-	args := []any{}
-	argIdx := 1
+// UpdateUsage writes the flight counters and maintenance lockout.
+func (r *repo) UpdateUsage(ctx context.Context, ext sqlx.ExtContext, d *Drone) error {
+	const query = `UPDATE drones SET service_hours = :service_hours, flight_km = :flight_km,
+		flight_cycles = :flight_cycles, hours_since_service = :hours_since_service,
+		km_since_service = :km_since_service, cycles_since_service = :cycles_since_service,
+		maintenance_due = :maintenance_due, updated_at = :updated_at, version = version + 1
+		WHERE id = :id AND version = :version`
+	return updateVersioned(ctx, ext, query, d)
+}
 
-	where := ""
-	if status != nil {
-		where = fmt.Sprintf(" WHERE status = $%d", argIdx)
-		args = append(args, *status)
-		argIdx++
+// updateVersioned runs a version-checked update of d and advances d's
+// version to match the row.
+func updateVersioned(ctx context.Context, ext sqlx.ExtContext, query string, d *Drone) error {
+	res, err := sqlx.NamedExecContext(ctx, ext, query, d)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domainerrors.StaleWrite("drone", d.ID)
+	}
+	d.Version++
+	return nil
+}
+
+// listSchema maps list filters and sorts onto drones; the bounding box
+// applies to the last reported position.
+var listSchema = listing.Schema{
+	Name:      "drones",
+	ID:        "id",
+	Status:    "status",
+	CreatedAt: "created_at",
+	Lat:       "latitude",
+	Lng:       "longitude",
+	Sorts: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"id":         "id",
+	},
+	DefaultSort: listing.Sort{Field: "created_at", Desc: true},
+}
+
+// List returns one page of drones and the cursor for the next, or "" on
+// the last page.
+func (r *repo) List(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) ([]*Drone, string, error) {
+	q, err := listSchema.Build(spec)
+	if err != nil {
+		return nil, "", err
 	}
+	var drones []*Drone
+	query := fmt.Sprintf(`SELECT %s FROM drones%s`, columns, q.Page())
+	if err := sqlx.SelectContext(ctx, ext, &drones, query, q.PageArgs()...); err != nil {
+		return nil, "", err
+	}
+	drones, next := listing.Next(q, drones, sortKeys)
+	return drones, next, nil
+}
 
+// Count returns how many drones match spec's filters.
+func (r *repo) Count(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) (int, error) {
+	q, err := listSchema.Build(spec)
+	if err != nil {
+		return 0, err
+	}
 	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM drones%s`, where)
-	if err := sqlx.GetContext(ctx, ext, &total, countQuery, args...); err != nil {
-		return nil, 0, err
+	if err := sqlx.GetContext(ctx, ext, &total, `SELECT COUNT(*) FROM drones`+q.Where(), q.Args()...); err != nil {
+		return 0, err
 	}
+	return total, nil
+}
 
-	dataQuery := fmt.Sprintf(`SELECT %s FROM drones%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, columns, where, argIdx, argIdx+1)
-	args = append(args, limit, offset)
+func sortKeys(d *Drone, field string) (string, string) {
+	switch field {
+	case "updated_at":
+		return listing.FormatTime(d.UpdatedAt), d.ID
+	case "id":
+		return d.ID, d.ID
+	}
+	return listing.FormatTime(d.CreatedAt), d.ID
+}
 
+func (r *repo) ListByIDs(ctx context.Context, ext sqlx.ExtContext, ids []string) ([]*Drone, error) {
 	var drones []*Drone
-	if err := sqlx.SelectContext(ctx, ext, &drones, dataQuery, args...); err != nil {
-		return nil, 0, err
+	query := fmt.Sprintf(`SELECT %s FROM drones WHERE id = ANY($1)`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &drones, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	return drones, nil
+}
+
+// CountByStatus counts the drones in each status that can take jobs;
+// quarantined drones can't, so they are left out.
+func (r *repo) CountByStatus(ctx context.Context, ext sqlx.ExtContext) (map[Status]int, error) {
+	var rows []struct {
+		Status Status `db:"status"`
+		Count  int    `db:"count"`
+	}
+	const query = `SELECT status, COUNT(*) AS count FROM drones WHERE NOT quarantined GROUP BY status`
+	if err := sqlx.SelectContext(ctx, ext, &rows, query); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[Status]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *repo) CreateAnomaly(ctx context.Context, ext sqlx.ExtContext, a *Anomaly) error {
+	const query = `INSERT INTO drone_anomalies (id, drone_id, kind, rejected, sequence, device_time,
+		prev_latitude, prev_longitude, latitude, longitude, speed_kmh, detected_at)
+		VALUES (:id, :drone_id, :kind, :rejected, :sequence, :device_time,
+		:prev_latitude, :prev_longitude, :latitude, :longitude, :speed_kmh, :detected_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, a)
+	return err
+}
+
+func (r *repo) ListAnomalies(ctx context.Context, ext sqlx.ExtContext, droneID string) ([]*Anomaly, error) {
+	var anomalies []*Anomaly
+	query := fmt.Sprintf(`SELECT %s FROM drone_anomalies WHERE drone_id = $1 ORDER BY detected_at DESC LIMIT %d`,
+		anomalyColumns, maxAnomaliesListed)
+	if err := sqlx.SelectContext(ctx, ext, &anomalies, query, droneID); err != nil {
+		return nil, err
+	}
+	return anomalies, nil
+}
+
+// Each streams drones, optionally of one status, by id, calling fn for each
+// row as it is read. It stops at fn's first error.
+func (r *repo) Each(ctx context.Context, ext sqlx.ExtContext, status *Status, fn func(*Drone) error) error {
+	query := fmt.Sprintf(`SELECT %s FROM drones`, columns)
+	var args []any
+	if status != nil {
+		query += ` WHERE status = $1`
+		args = append(args, *status)
 	}
+	query += ` ORDER BY id`
 
-	return drones, total, nil
+	rows, err := ext.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d Drone
+		if err := rows.StructScan(&d); err != nil {
+			return err
+		}
+		if err := fn(&d); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
@@ -2,38 +2,128 @@ package drone
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"drone-delivery/internal/common"
 	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 	"drone-delivery/internal/redis"
 )
 
 type Service interface {
 	EnsureExists(ctx context.Context, droneID string) (*Drone, error)
 	GetByID(ctx context.Context, id string) (*Drone, error)
-	Heartbeat(ctx context.Context, droneID string, lat, lng float64) (*Drone, error)
+	Heartbeat(ctx context.Context, droneID string, hb HeartbeatRequest) (*Drone, error)
+	Ingest(ctx context.Context, droneID string, samples []HeartbeatRequest) (*BatchAck, error)
 	GetDroneLocation(ctx context.Context, droneID string) (*common.Location, error)
-	ListAll(ctx context.Context, status *Status, page, limit int) ([]*Drone, int, error)
+	List(ctx context.Context, spec listing.Spec) ([]*Drone, string, error)
+	Count(ctx context.Context, spec listing.Spec) (int, error)
 	UpdateStatus(ctx context.Context, d *Drone) error
+	CountByStatus(ctx context.Context) (map[Status]int, error)
+	Register(ctx context.Context, droneID string, p Profile) (*Drone, error)
+	UpdateProfile(ctx context.Context, droneID string, p Profile, ifMatch *int64) (*Drone, error)
+	Retire(ctx context.Context, droneID string) (*Drone, error)
+	FindNearby(ctx context.Context, loc common.Location, radiusKM float64, status Status) ([]*NearbyDrone, error)
+	Quarantine(ctx context.Context, droneID, reason string) (*Drone, error)
+	LiftQuarantine(ctx context.Context, droneID string) (*Drone, error)
+	ListAnomalies(ctx context.Context, droneID string) ([]*Anomaly, error)
 }
 
+// HeartbeatPolicy configures heartbeat validation.
+type HeartbeatPolicy struct {
+	MaxSpeedKMH     float64       // faster movement between heartbeats is an anomaly; 0 disables
+	MaxClockSkew    time.Duration // how far ahead of server time a device timestamp may be
+	RequireSequence bool          // reject heartbeats without sequence and timestamp
+	AutoQuarantine  bool          // quarantine drones on an impossible-speed anomaly
+
+	// CriticalFaultCodes are fault codes that mark the reporting drone broken.
+	CriticalFaultCodes []string
+
+	MaxBatchSamples int // samples accepted in one Ingest call
+}
+
+// TelemetryRecorder receives every accepted heartbeat position. Defined here
+// so the drone package doesn't import telemetry.
+type TelemetryRecorder interface {
+	Record(droneID string, orderID *uuid.UUID, loc common.Location, at time.Time)
+}
+
+// Alerter raises operator alerts inside the caller's transaction. Defined
+// here so the drone package doesn't import alert.
+type Alerter interface {
+	Raise(ctx context.Context, ext sqlx.ExtContext, kind, severity, droneID, message string) error
+}
+
+// LeaseExtender pushes back the reservation lease of a drone flying to a
+// pickup. Defined here so the drone package doesn't import delivery.
+type LeaseExtender interface {
+	ExtendLease(ctx context.Context, droneID string, loc common.Location) error
+}
+
+// LocationIndex is the GEO index of drone positions, one set per status.
+// Callers that change a drone's status outside this service re-index it
+// after their transaction commits (see SyncIndex). seenAt is the drone's
+// last heartbeat, which orders positions, and IndexPruner drops drones that
+// stop sending them; version is the drone row's, which orders statuses.
+type LocationIndex interface {
+	Index(ctx context.Context, droneID, status string, loc common.Location, seenAt time.Time, version int64) error
+	Unindex(ctx context.Context, droneID string) error
+}
+
+// SyncIndex moves committed drones to the GEO set of their current status.
+// The index is derived data and FindNearby re-checks status against
+// Postgres, so failures are logged rather than returned. Drones that have
+// never sent a heartbeat have no position to index.
+func SyncIndex(ctx context.Context, index LocationIndex, drones ...*Drone) {
+	for _, d := range drones {
+		if d == nil || d.LastHeartbeat == nil {
+			continue
+		}
+		if err := index.Index(ctx, d.ID, string(d.Status), d.Location(), *d.LastHeartbeat, d.Version); err != nil {
+			slog.WarnContext(ctx, "failed to index drone location",
+				slog.String("drone_id", d.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// maxNearbyResults caps a proximity search.
+const maxNearbyResults = 100
+
 type service struct {
 	repo       Repository
 	db         *sqlx.DB
 	cache      *redis.DroneLocationCache
+	telemetry  TelemetryRecorder
+	alerter    Alerter
+	broken     BrokenHandler
+	leases     LeaseExtender
+	writer     *HeartbeatWriter
 	zoneCenter common.Location
 	zoneRadius float64
+	policy     HeartbeatPolicy
 }
 
-func NewDroneService(repo Repository, db *sqlx.DB, cache *redis.DroneLocationCache, zoneCenter common.Location, zoneRadius float64) Service {
+func NewDroneService(repo Repository, db *sqlx.DB, cache *redis.DroneLocationCache, telemetry TelemetryRecorder, alerter Alerter, broken BrokenHandler, leases LeaseExtender, writer *HeartbeatWriter, zoneCenter common.Location, zoneRadius float64, policy HeartbeatPolicy) Service {
 	return &service{
 		repo:       repo,
 		db:         db,
 		cache:      cache,
+		telemetry:  telemetry,
+		alerter:    alerter,
+		broken:     broken,
+		leases:     leases,
+		writer:     writer,
 		zoneCenter: zoneCenter,
 		zoneRadius: zoneRadius,
+		policy:     policy,
 	}
 }
 
@@ -59,32 +149,292 @@ func (s *service) GetByID(ctx context.Context, id string) (*Drone, error) {
 }
 
 // --------------------------------------------------------------
-func (s *service) Heartbeat(ctx context.Context, droneID string, lat, lng float64) (*Drone, error) {
-	if err := common.ValidateLatLng(lat, lng); err != nil {
-		return nil, domainerrors.NewValidation(err.Error())
+// Heartbeat records the drone's position and flight state. Replayed and
+// out-of-order heartbeats are refused; impossible movement is accepted but
+// recorded as an anomaly with an alert, and quarantines the drone when the
+// policy says so. A critical fault code runs the broken flow once the
+// heartbeat is stored. The drone row is locked so concurrent heartbeats are
+// ordered.
+func (s *service) Heartbeat(ctx context.Context, droneID string, hb HeartbeatRequest) (*Drone, error) {
+	now := time.Now()
+	if err := s.validateHeartbeat(hb, now); err != nil {
+		return nil, err
 	}
-	loc := common.NewLocation(lat, lng)
-	if err := common.ValidateInZone(loc, s.zoneCenter, s.zoneRadius); err != nil {
-		return nil, domainerrors.NewOutOfZone("heartbeat location is outside the delivery zone")
+	loc := common.NewLocation(hb.Latitude, hb.Longitude)
+
+	if _, err := s.EnsureExists(ctx, droneID); err != nil {
+		return nil, err
 	}
 
-	d, err := s.EnsureExists(ctx, droneID)
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
 	}
+	defer tx.Rollback()
 
-	d.UpdateLocation(lat, lng)
-	if err := s.repo.Update(ctx, s.db, d); err != nil {
-		return nil, domainerrors.NewInternal("failed to update drone location", err)
+	d, err := s.repo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if d.IsRetired() {
+		return nil, domainerrors.DroneRetired(droneID)
 	}
+	s.writer.Overlay(d)
 
-	if err = s.cache.Set(ctx, droneID, loc); err != nil {
+	anomaly := d.InspectHeartbeat(hb, now, s.policy.MaxSpeedKMH)
+	if anomaly != nil && anomaly.Rejected {
+		// Release the row lock first; the record outlives the refusal
+		_ = tx.Rollback()
+		if err := s.recordAnomaly(ctx, s.db, anomaly); err != nil {
+			return nil, err
+		}
+		return nil, rejectionError(anomaly)
+	}
+
+	d.AcceptHeartbeat(hb)
+	// Only a quarantine is an edit worth a new version
+	write := s.repo.UpdateHeartbeat
+	if anomaly != nil {
+		if err := s.recordAnomaly(ctx, tx, anomaly); err != nil {
+			return nil, err
+		}
+		if s.policy.AutoQuarantine && !d.Quarantined {
+			d.Quarantine(anomaly.Describe())
+			write = s.repo.Update
+		}
+	}
+	if err := write(ctx, tx, d); err != nil {
 		return nil, domainerrors.NewInternal("failed to update drone location", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+
+	if err := s.cacheState(ctx, d); err != nil {
+		return nil, err
+	}
+	s.telemetry.Record(droneID, d.CurrentOrderID, loc, *d.LastHeartbeat)
+	s.extendLease(ctx, d)
 
+	if code, ok := d.CriticalFault(s.policy.CriticalFaultCodes); ok && d.Status != StatusBroken {
+		return s.groundOnFault(ctx, d, code)
+	}
 	return d, nil
 }
 
+// --------------------------------------------------------------
+// Ingest applies a batch of samples in order against the drone's latest
+// state, acknowledging each one. Rejected samples don't stop the batch.
+// Accepted state goes to Redis right away and to Postgres through the
+// write-behind buffer; anomalies, quarantine and critical faults are handled
+// as for a single heartbeat. As there, the drone row is locked while the
+// samples are inspected, so concurrent batches and heartbeats are ordered.
+func (s *service) Ingest(ctx context.Context, droneID string, samples []HeartbeatRequest) (*BatchAck, error) {
+	if len(samples) == 0 {
+		return nil, domainerrors.NewValidation("samples must not be empty")
+	}
+	if s.policy.MaxBatchSamples > 0 && len(samples) > s.policy.MaxBatchSamples {
+		return nil, domainerrors.NewValidation(fmt.Sprintf("at most %d samples per batch", s.policy.MaxBatchSamples))
+	}
+
+	if _, err := s.EnsureExists(ctx, droneID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	d, err := s.repo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if d.IsRetired() {
+		return nil, domainerrors.DroneRetired(droneID)
+	}
+	s.writer.Overlay(d)
+
+	now := time.Now()
+	ack := &BatchAck{Acks: make([]SampleAck, 0, len(samples))}
+	var accepted []HeartbeatRequest
+	var speeding *Anomaly
+	for i, hb := range samples {
+		sa := SampleAck{Index: i, Sequence: hb.Sequence}
+		err := s.validateHeartbeat(hb, now)
+		if err == nil && hb.Timestamp == nil {
+			err = domainerrors.NewValidation("timestamp is required for batched samples")
+		}
+		if err == nil {
+			if a := d.InspectHeartbeat(hb, now, s.policy.MaxSpeedKMH); a != nil {
+				if rerr := s.recordAnomaly(ctx, tx, a); rerr != nil {
+					return nil, rerr
+				}
+				if a.Rejected {
+					err = rejectionError(a)
+				} else {
+					speeding = a
+				}
+			}
+		}
+		if err != nil {
+			sa.Reason = errorReason(err)
+			ack.Rejected++
+			ack.Acks = append(ack.Acks, sa)
+			continue
+		}
+
+		d.AcceptHeartbeat(hb)
+		accepted = append(accepted, hb)
+		sa.Accepted = true
+		ack.Accepted++
+		ack.Acks = append(ack.Acks, sa)
+	}
+
+	// Buffer the state before the lock is released, so the next heartbeat
+	// or batch inspects against it
+	if len(accepted) > 0 {
+		s.writer.Put(d)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+
+	if len(accepted) > 0 {
+		if err := s.cacheState(ctx, d); err != nil {
+			return nil, err
+		}
+		for _, hb := range accepted {
+			s.telemetry.Record(droneID, d.CurrentOrderID, common.NewLocation(hb.Latitude, hb.Longitude), *hb.Timestamp)
+		}
+		s.extendLease(ctx, d)
+	}
+	if speeding != nil && s.policy.AutoQuarantine && !d.Quarantined {
+		if _, err := s.Quarantine(ctx, droneID, speeding.Describe()); err != nil {
+			return nil, err
+		}
+	}
+	if code, ok := d.CriticalFault(s.policy.CriticalFaultCodes); ok && d.Status != StatusBroken {
+		if d, err = s.groundOnFault(ctx, d, code); err != nil {
+			return nil, err
+		}
+	}
+
+	ack.DroneStatus = d.Status
+	if d.CurrentOrderID != nil {
+		id := d.CurrentOrderID.String()
+		ack.CurrentOrderID = &id
+	}
+	return ack, nil
+}
+
+// extendLease gives a drone heading to its pickup more time while it keeps
+// closing in. The lease sweeper is the backstop, so failures are logged.
+func (s *service) extendLease(ctx context.Context, d *Drone) {
+	if d.Status != StatusEnRoutePickup {
+		return
+	}
+	if err := s.leases.ExtendLease(ctx, d.ID, d.Location()); err != nil {
+		slog.WarnContext(ctx, "failed to extend job lease",
+			slog.String("drone_id", d.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// validateHeartbeat runs the checks that don't depend on the drone's state.
+func (s *service) validateHeartbeat(hb HeartbeatRequest, now time.Time) error {
+	if err := common.ValidateLatLng(hb.Latitude, hb.Longitude); err != nil {
+		return domainerrors.NewValidation(err.Error())
+	}
+	loc := common.NewLocation(hb.Latitude, hb.Longitude)
+	if err := common.ValidateInZone(loc, s.zoneCenter, s.zoneRadius); err != nil {
+		return domainerrors.NewOutOfZone("heartbeat location is outside the delivery zone")
+	}
+	if err := hb.ValidateFlightState(); err != nil {
+		return domainerrors.NewValidation(err.Error())
+	}
+	if s.policy.RequireSequence && (hb.Sequence == nil || hb.Timestamp == nil) {
+		return domainerrors.NewValidation("heartbeat sequence and timestamp are required")
+	}
+	if hb.Timestamp != nil && hb.Timestamp.After(now.Add(s.policy.MaxClockSkew)) {
+		return domainerrors.NewValidation("heartbeat timestamp is in the future")
+	}
+	return nil
+}
+
+// cacheState writes the drone's latest position and flight state to the
+// location cache and GEO index.
+func (s *service) cacheState(ctx context.Context, d *Drone) error {
+	loc := d.Location()
+	if err := s.cache.SetState(ctx, d.ID, redis.CachedDroneLocation{
+		Lat:            loc.Lat,
+		Lng:            loc.Lng,
+		AltitudeM:      d.AltitudeM,
+		HeadingDeg:     d.HeadingDeg,
+		GroundSpeedKMH: d.GroundSpeedKMH,
+		BatteryPct:     d.BatteryPct,
+		FaultCodes:     d.FaultCodes,
+	}); err != nil {
+		return domainerrors.NewInternal("failed to update drone location", err)
+	}
+	if err := s.cache.Index(ctx, d.ID, string(d.Status), loc, *d.LastHeartbeat, d.Version); err != nil {
+		return domainerrors.NewInternal("failed to update drone location", err)
+	}
+	return nil
+}
+
+// rejectionError is the error returned for a rejected anomaly.
+func rejectionError(a *Anomaly) error {
+	if a.Kind == AnomalyReplay {
+		return domainerrors.HeartbeatReplayed(*a.Sequence)
+	}
+	return domainerrors.HeartbeatOutOfOrder()
+}
+
+// errorReason is the client-facing message of err.
+func errorReason(err error) string {
+	var de *domainerrors.DomainError
+	if errors.As(err, &de) {
+		return de.Message
+	}
+	return err.Error()
+}
+
+// groundOnFault puts a drone that reported a critical fault through the
+// broken flow and raises an alert. The heartbeat itself is already stored,
+// so a failure here surfaces as an error and the next faulted heartbeat
+// retries.
+func (s *service) groundOnFault(ctx context.Context, d *Drone, code string) (*Drone, error) {
+	if err := s.broken.HandleDroneBroken(ctx, d.ID); err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("drone %s reported critical fault %s and was marked broken", d.ID, code)
+	if err := s.alerter.Raise(ctx, s.db, "CRITICAL_FAULT", "CRITICAL", d.ID, msg); err != nil {
+		slog.WarnContext(ctx, "failed to raise fault alert",
+			slog.String("drone_id", d.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return s.GetByID(ctx, d.ID)
+}
+
+// recordAnomaly stores the anomaly and raises a matching alert in ext.
+func (s *service) recordAnomaly(ctx context.Context, ext sqlx.ExtContext, a *Anomaly) error {
+	if err := s.repo.CreateAnomaly(ctx, ext, a); err != nil {
+		return domainerrors.NewInternal("failed to record heartbeat anomaly", err)
+	}
+	severity := "CRITICAL"
+	if a.Rejected {
+		severity = "WARNING"
+	}
+	if err := s.alerter.Raise(ctx, ext, string(a.Kind), severity, a.DroneID, a.Describe()); err != nil {
+		return domainerrors.NewInternal("failed to raise alert", err)
+	}
+	return nil
+}
+
 // --------------------------------------------------------------
 func (s *service) GetDroneLocation(ctx context.Context, droneID string) (*common.Location, error) {
 	cached, err := s.cache.Get(ctx, droneID)
@@ -107,11 +457,214 @@ func (s *service) GetDroneLocation(ctx context.Context, droneID string) (*common
 }
 
 // --------------------------------------------------------------
-func (s *service) ListAll(ctx context.Context, status *Status, page, limit int) ([]*Drone, int, error) {
-	return s.repo.ListAll(ctx, s.db, status, page, limit)
+func (s *service) List(ctx context.Context, spec listing.Spec) ([]*Drone, string, error) {
+	return s.repo.List(ctx, s.db, spec)
+}
+
+// --------------------------------------------------------------
+func (s *service) Count(ctx context.Context, spec listing.Spec) (int, error) {
+	return s.repo.Count(ctx, s.db, spec)
 }
 
 // --------------------------------------------------------------
 func (s *service) UpdateStatus(ctx context.Context, d *Drone) error {
 	return s.repo.Update(ctx, s.db, d)
 }
+
+// --------------------------------------------------------------
+func (s *service) CountByStatus(ctx context.Context) (map[Status]int, error) {
+	return s.repo.CountByStatus(ctx, s.db)
+}
+
+// --------------------------------------------------------------
+func (s *service) Register(ctx context.Context, droneID string, p Profile) (*Drone, error) {
+	if err := s.validateProfile(p); err != nil {
+		return nil, err
+	}
+	d := Register(droneID, p)
+	created, err := s.repo.Create(ctx, s.db, d)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to register drone", err)
+	}
+	if !created {
+		return nil, domainerrors.DroneAlreadyExists(droneID)
+	}
+	return d, nil
+}
+
+// --------------------------------------------------------------
+// UpdateProfile applies p under a row lock, refusing if ifMatch names a
+// version other than the current one.
+func (s *service) UpdateProfile(ctx context.Context, droneID string, p Profile, ifMatch *int64) (*Drone, error) {
+	if err := s.validateProfile(p); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	d, err := s.repo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if err := d.CheckVersion(ifMatch); err != nil {
+		return nil, err
+	}
+	d.ApplyProfile(p)
+	if err := s.repo.UpdateProfile(ctx, tx, d); err != nil {
+		return nil, domainerrors.NewInternal("failed to update drone", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	return d, nil
+}
+
+// --------------------------------------------------------------
+// Retire soft-deletes the drone under a row lock so it cannot race with a
+// reservation.
+func (s *service) Retire(ctx context.Context, droneID string) (*Drone, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	d, err := s.repo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if err := d.Retire(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, tx, d); err != nil {
+		return nil, domainerrors.NewInternal("failed to retire drone", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+
+	if err := s.cache.Unindex(ctx, droneID); err != nil {
+		slog.WarnContext(ctx, "failed to unindex retired drone",
+			slog.String("drone_id", droneID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return d, nil
+}
+
+// --------------------------------------------------------------
+// FindNearby returns drones in the given status within radiusKM of loc,
+// nearest first. Hits whose status in Postgres no longer matches the index
+// are re-indexed and left out.
+func (s *service) FindNearby(ctx context.Context, loc common.Location, radiusKM float64, status Status) ([]*NearbyDrone, error) {
+	if err := common.ValidateLatLng(loc.Lat, loc.Lng); err != nil {
+		return nil, domainerrors.NewValidation(err.Error())
+	}
+	if radiusKM <= 0 || radiusKM > 2*s.zoneRadius {
+		return nil, domainerrors.NewValidation("radius must be greater than 0 and at most the zone diameter")
+	}
+
+	hits, err := s.cache.Nearby(ctx, string(status), loc, radiusKM, maxNearbyResults)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to search drone locations", err)
+	}
+	if len(hits) == 0 {
+		return []*NearbyDrone{}, nil
+	}
+
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.DroneID
+	}
+	drones, err := s.repo.ListByIDs(ctx, s.db, ids)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load drones", err)
+	}
+	byID := make(map[string]*Drone, len(drones))
+	for _, d := range drones {
+		byID[d.ID] = d
+	}
+
+	out := make([]*NearbyDrone, 0, len(hits))
+	for _, h := range hits {
+		d, ok := byID[h.DroneID]
+		switch {
+		case !ok || d.IsRetired():
+			_ = s.cache.Unindex(ctx, h.DroneID)
+		case d.Status != status:
+			s.writer.Overlay(d)
+			SyncIndex(ctx, s.cache, d)
+		default:
+			out = append(out, &NearbyDrone{Drone: d, DistanceKM: h.DistanceKM})
+		}
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) Quarantine(ctx context.Context, droneID, reason string) (*Drone, error) {
+	return s.updateLocked(ctx, droneID, func(d *Drone) error {
+		if d.IsRetired() {
+			return domainerrors.DroneRetired(droneID)
+		}
+		d.Quarantine(reason)
+		return nil
+	})
+}
+
+// --------------------------------------------------------------
+func (s *service) LiftQuarantine(ctx context.Context, droneID string) (*Drone, error) {
+	return s.updateLocked(ctx, droneID, (*Drone).LiftQuarantine)
+}
+
+// --------------------------------------------------------------
+func (s *service) ListAnomalies(ctx context.Context, droneID string) ([]*Anomaly, error) {
+	if _, err := s.repo.GetByID(ctx, s.db, droneID); err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	anomalies, err := s.repo.ListAnomalies(ctx, s.db, droneID)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list anomalies", err)
+	}
+	return anomalies, nil
+}
+
+// updateLocked applies change to the drone under a row lock and saves it.
+func (s *service) updateLocked(ctx context.Context, droneID string, change func(*Drone) error) (*Drone, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	d, err := s.repo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if err := change(d); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, tx, d); err != nil {
+		return nil, domainerrors.NewInternal("failed to update drone", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	return d, nil
+}
+
+func (s *service) validateProfile(p Profile) error {
+	if p.HomeBase == nil {
+		return nil
+	}
+	if err := common.ValidateLatLng(p.HomeBase.Lat, p.HomeBase.Lng); err != nil {
+		return domainerrors.NewValidation(err.Error())
+	}
+	if err := common.ValidateInZone(*p.HomeBase, s.zoneCenter, s.zoneRadius); err != nil {
+		return domainerrors.NewOutOfZone("home base is outside the delivery zone")
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package drone
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/common"
+)
+
+type WriterConfig struct {
+	FlushInterval time.Duration
+}
+
+// HeartbeatWriter is a write-behind buffer for the heartbeat-owned columns
+// of drones (position, sequence, device clock, flight state). Batched
+// ingestion keeps only the latest state per drone, so a drone sending many
+// samples costs one UPDATE per flush. Until a flush commits, Overlay lets
+// readers that need the newest state (integrity checks) see it.
+type HeartbeatWriter struct {
+	db   *sqlx.DB
+	repo Repository
+	cfg  WriterConfig
+
+	mu       sync.Mutex
+	pending  map[string]*Drone
+	inflight map[string]*Drone // being written by the current flush
+}
+
+func NewHeartbeatWriter(db *sqlx.DB, repo Repository, cfg WriterConfig) *HeartbeatWriter {
+	return &HeartbeatWriter{
+		db:      db,
+		repo:    repo,
+		cfg:     cfg,
+		pending: make(map[string]*Drone),
+	}
+}
+
+// Put queues a snapshot of d's heartbeat state, replacing an older one.
+func (w *HeartbeatWriter) Put(d *Drone) {
+	snapshot := *d
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if cur, ok := w.pending[d.ID]; ok && newerHeartbeat(cur, &snapshot) {
+		return
+	}
+	w.pending[d.ID] = &snapshot
+}
+
+// Overlay copies buffered heartbeat state onto d when it is newer than
+// what d was loaded with.
+func (w *HeartbeatWriter) Overlay(d *Drone) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, m := range []map[string]*Drone{w.inflight, w.pending} {
+		if s, ok := m[d.ID]; ok && newerHeartbeat(s, d) {
+			copyHeartbeatState(d, s)
+		}
+	}
+}
+
+// Buffered returns a copy of the newest unflushed heartbeat state of the
+// drone, or nil if none is buffered.
+func (w *HeartbeatWriter) Buffered(droneID string) *Drone {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var latest *Drone
+	for _, m := range []map[string]*Drone{w.inflight, w.pending} {
+		if s, ok := m[droneID]; ok && (latest == nil || newerHeartbeat(s, latest)) {
+			latest = s
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	snapshot := *latest
+	return &snapshot
+}
+
+// BufferedIndex wraps index so a drone read from Postgres is indexed at the
+// newest position the writer holds for it, not the one last flushed.
+func (w *HeartbeatWriter) BufferedIndex(index LocationIndex) LocationIndex {
+	return &bufferedIndex{LocationIndex: index, writer: w}
+}
+
+type bufferedIndex struct {
+	LocationIndex
+	writer *HeartbeatWriter
+}
+
+func (b *bufferedIndex) Index(ctx context.Context, droneID, status string, loc common.Location, seenAt time.Time, version int64) error {
+	if s := b.writer.Buffered(droneID); s != nil && s.LastHeartbeat.After(seenAt) {
+		loc, seenAt = s.Location(), *s.LastHeartbeat
+	}
+	return b.LocationIndex.Index(ctx, droneID, status, loc, seenAt, version)
+}
+
+// Run flushes on every interval until ctx is cancelled, then flushes
+// whatever is left.
+func (w *HeartbeatWriter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			w.flushLogged(shutdownCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			w.flushLogged(ctx)
+		}
+	}
+}
+
+// Flush writes all buffered states in one transaction. A row already
+// holding a newer heartbeat is left alone. On failure the states are put
+// back unless a newer one arrived meanwhile.
+func (w *HeartbeatWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	if len(w.pending) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	batch := w.pending
+	w.inflight = batch
+	w.pending = make(map[string]*Drone)
+	w.mu.Unlock()
+
+	err := w.write(ctx, batch)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.inflight = nil
+	if err != nil {
+		for id, s := range batch {
+			if cur, ok := w.pending[id]; !ok || newerHeartbeat(s, cur) {
+				w.pending[id] = s
+			}
+		}
+	}
+	return err
+}
+
+func (w *HeartbeatWriter) write(ctx context.Context, batch map[string]*Drone) error {
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, d := range batch {
+		if err := w.repo.UpdateHeartbeat(ctx, tx, d); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (w *HeartbeatWriter) flushLogged(ctx context.Context) {
+	if err := w.Flush(ctx); err != nil {
+		slog.ErrorContext(ctx, "heartbeat flush failed", slog.String("error", err.Error()))
+	}
+}
+
+// newerHeartbeat reports whether a holds a later heartbeat than b.
+func newerHeartbeat(a, b *Drone) bool {
+	return a.LastHeartbeat != nil && (b.LastHeartbeat == nil || a.LastHeartbeat.After(*b.LastHeartbeat))
+}
+
+// copyHeartbeatState overwrites the heartbeat-owned fields of d with src's.
+func copyHeartbeatState(d, src *Drone) {
+	d.Latitude = src.Latitude
+	d.Longitude = src.Longitude
+	d.LastHeartbeat = src.LastHeartbeat
+	d.LastHeartbeatSeq = src.LastHeartbeatSeq
+	d.LastDeviceTime = src.LastDeviceTime
+	d.LastBootID = src.LastBootID
+	d.AltitudeM = src.AltitudeM
+	d.HeadingDeg = src.HeadingDeg
+	d.GroundSpeedKMH = src.GroundSpeedKMH
+	d.BatteryPct = src.BatteryPct
+	d.FaultCodes = src.FaultCodes
+}
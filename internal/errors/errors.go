@@ -3,14 +3,19 @@ package errors
 import "fmt"
 
 const (
-	ErrNotFound          = "NOT_FOUND"
-	ErrInvalidTransition = "INVALID_TRANSITION"
-	ErrUnauthorized      = "UNAUTHORIZED"
-	ErrForbidden         = "FORBIDDEN"
-	ErrConflict          = "CONFLICT"
-	ErrValidation        = "VALIDATION"
-	ErrOutOfZone         = "OUT_OF_ZONE"
-	ErrInternal          = "INTERNAL"
+	ErrNotFound           = "NOT_FOUND"
+	ErrInvalidTransition  = "INVALID_TRANSITION"
+	ErrUnauthorized       = "UNAUTHORIZED"
+	ErrForbidden          = "FORBIDDEN"
+	ErrConflict           = "CONFLICT"
+	ErrValidation         = "VALIDATION"
+	ErrOutOfZone          = "OUT_OF_ZONE"
+	ErrPaymentDeclined    = "PAYMENT_DECLINED"
+	ErrGrounded           = "GROUNDED"
+	ErrUnsafeWeather      = "UNSAFE_WEATHER"
+	ErrOverCapacity       = "OVER_CAPACITY"
+	ErrPreconditionFailed = "PRECONDITION_FAILED"
+	ErrInternal           = "INTERNAL"
 )
 
 type DomainError struct {
@@ -100,6 +105,34 @@ func DroneAlreadyBroken() *DomainError {
 	return NewConflict("drone is already broken")
 }
 
+func DroneAlreadyExists(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("drone %s already exists", id))
+}
+
+func DroneMaintenanceDue(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("drone %s is due for maintenance", id))
+}
+
+func DroneRetired(id string) *DomainError {
+	return NewForbidden(fmt.Sprintf("drone %s is retired", id))
+}
+
+func DroneQuarantined(id string) *DomainError {
+	return NewForbidden(fmt.Sprintf("drone %s is quarantined", id))
+}
+
+func DroneNotQuarantined(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("drone %s is not quarantined", id))
+}
+
+func HeartbeatReplayed(seq int64) *DomainError {
+	return NewConflict(fmt.Sprintf("heartbeat sequence %d was already used", seq))
+}
+
+func HeartbeatOutOfOrder() *DomainError {
+	return NewConflict("heartbeat is older than the last accepted heartbeat")
+}
+
 // --- Job ---
 
 func JobNotFound(id string) *DomainError {
@@ -113,3 +146,109 @@ func JobAlreadyReserved() *DomainError {
 func JobInvalidTransition(from, to string) *DomainError {
 	return NewInvalidTransition(from, to)
 }
+
+// --- Maintenance ---
+
+func WorkOrderNotFound(id string) *DomainError {
+	return NewNotFound("work order", id)
+}
+
+func WorkOrderInvalidTransition(from, to string) *DomainError {
+	return NewInvalidTransition(from, to)
+}
+
+// --- Alert ---
+
+func AlertNotFound(id string) *DomainError {
+	return NewNotFound("alert", id)
+}
+
+func AlertAlreadyAcknowledged(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("alert %s is already acknowledged", id))
+}
+
+// --- Command ---
+
+func CommandNotFound(id string) *DomainError {
+	return NewNotFound("command", id)
+}
+
+func CommandAlreadyAcknowledged(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("command %s is already acknowledged", id))
+}
+
+func CommandExpired(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("command %s has expired", id))
+}
+
+// --- Grounding ---
+
+func Grounded(reason string) *DomainError {
+	return &DomainError{Code: ErrGrounded, Message: "flights are grounded: " + reason}
+}
+
+func GroundingNotFound(id string) *DomainError {
+	return NewNotFound("grounding", id)
+}
+
+func GroundingAlreadyLifted(id string) *DomainError {
+	return NewConflict(fmt.Sprintf("grounding %s is already lifted", id))
+}
+
+// --- Weather ---
+
+func UnsafeWeather(reason string) *DomainError {
+	return &DomainError{Code: ErrUnsafeWeather, Message: "weather is unsafe for flight: " + reason}
+}
+
+// --- Airspace ---
+
+func AirspaceCongested() *DomainError {
+	return NewConflict("no altitude band is free along the corridor within the allowed delay")
+}
+
+// --- Admission ---
+
+func OverCapacity(reason string) *DomainError {
+	return &DomainError{Code: ErrOverCapacity, Message: "the fleet cannot take this order right now: " + reason}
+}
+
+// --- Quote ---
+
+func QuoteInvalid() *DomainError {
+	return NewValidation("quote token is invalid")
+}
+
+func QuoteExpired() *DomainError {
+	return NewValidation("quote has expired, request a new quote")
+}
+
+func QuoteMismatch() *DomainError {
+	return NewValidation("order does not match the quoted origin, destination or payload")
+}
+
+func QuoteUsed() *DomainError {
+	return NewConflict("quote has already been used, request a new quote")
+}
+
+// --- Payment ---
+
+func PaymentInvalidTransition(from, to string) *DomainError {
+	return NewInvalidTransition(from, to)
+}
+
+func PaymentDeclined() *DomainError {
+	return &DomainError{Code: ErrPaymentDeclined, Message: "payment authorization was declined"}
+}
+
+// --- Concurrency ---
+
+// StaleWrite is an update whose row changed after it was read.
+func StaleWrite(entity, id string) *DomainError {
+	return NewConflict(fmt.Sprintf("%s %s was modified concurrently, reload and retry", entity, id))
+}
+
+// VersionMismatch is an If-Match naming a version the row has moved past.
+func VersionMismatch(entity, id string, current int64) *DomainError {
+	return &DomainError{Code: ErrPreconditionFailed, Message: fmt.Sprintf("%s %s is at version %d", entity, id, current)}
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"bytes"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+
+	"drone-delivery/internal/jwt"
+)
+
+// TokenValidator checks the bearer tokens drones already use for the REST
+// API. Satisfied by *jwt.Service.
+type TokenValidator interface {
+	ValidateToken(token string) (*jwt.Claims, error)
+}
+
+// droneAuth lets a client connect with its drone id as username and its
+// drone token as password, and confines it to its own topics: it may
+// publish telemetry and subscribe to commands, nothing else.
+type droneAuth struct {
+	mqtt.HookBase
+	tokens TokenValidator
+}
+
+func (h *droneAuth) ID() string {
+	return "drone-auth"
+}
+
+func (h *droneAuth) Provides(b byte) bool {
+	return bytes.Contains([]byte{mqtt.OnConnectAuthenticate, mqtt.OnACLCheck}, []byte{b})
+}
+
+func (h *droneAuth) OnConnectAuthenticate(cl *mqtt.Client, pk packets.Packet) bool {
+	claims, err := h.tokens.ValidateToken(string(pk.Connect.Password))
+	if err != nil {
+		return false
+	}
+	return claims.Role == "drone" && claims.Sub != "" && claims.Sub == string(pk.Connect.Username)
+}
+
+func (h *droneAuth) OnACLCheck(cl *mqtt.Client, topic string, write bool) bool {
+	droneID := string(cl.Properties.Username)
+	if write {
+		return topic == TelemetryTopic(droneID)
+	}
+	return topic == CommandTopic(droneID)
+}
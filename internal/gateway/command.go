@@ -0,0 +1,38 @@
+package gateway
+
+import (
+	"time"
+)
+
+type CommandType string
+
+const (
+	// CommandAssignment tells a drone it now holds a job.
+	CommandAssignment CommandType = "ASSIGNMENT"
+	// CommandRelease tells a drone a job it held was taken away and it
+	// should stop flying to the pickup.
+	CommandRelease CommandType = "RELEASE"
+	// CommandHandoff tells a broken drone its order was released for
+	// another drone to pick up.
+	CommandHandoff CommandType = "HANDOFF"
+)
+
+// Command is the JSON payload published on a drone's command topic.
+// Ground-control commands (ABORT_MISSION etc.) carry CommandID, which the
+// drone acknowledges over REST.
+type Command struct {
+	Type      CommandType `json:"type"`
+	CommandID string      `json:"command_id,omitempty"`
+	OrderID   string      `json:"order_id,omitempty"`
+	JobID     string      `json:"job_id,omitempty"`
+	Reason    string      `json:"reason,omitempty"`
+	IssuedAt  time.Time   `json:"issued_at"`
+}
+
+func TelemetryTopic(droneID string) string {
+	return "drones/" + droneID + "/telemetry"
+}
+
+func CommandTopic(droneID string) string {
+	return "drones/" + droneID + "/commands"
+}
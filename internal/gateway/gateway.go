@@ -0,0 +1,161 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+
+	"drone-delivery/internal/command"
+	"drone-delivery/internal/drone"
+	"drone-delivery/internal/job"
+)
+
+// telemetryFilter matches every drone's telemetry topic.
+const telemetryFilter = "drones/+/telemetry"
+
+// heartbeatTimeout bounds one telemetry message's trip through the service.
+const heartbeatTimeout = 5 * time.Second
+
+type Config struct {
+	Addr string // TCP listen address, e.g. ":1883"
+}
+
+// HeartbeatService receives telemetry published by drones. Satisfied by
+// drone.Service.
+type HeartbeatService interface {
+	Heartbeat(ctx context.Context, droneID string, hb drone.HeartbeatRequest) (*drone.Drone, error)
+}
+
+// Gateway is an embedded MQTT broker for drones that don't speak REST.
+// Drones publish heartbeats to drones/<id>/telemetry and receive commands
+// on drones/<id>/commands.
+type Gateway struct {
+	server *mqtt.Server
+	addr   string
+}
+
+// New creates the broker and binds its listener. Telemetry is not routed
+// until RouteTelemetry is called.
+func New(cfg Config, tokens TokenValidator) (*Gateway, error) {
+	server := mqtt.New(&mqtt.Options{
+		InlineClient: true,
+		Logger:       slog.Default(),
+	})
+	if err := server.AddHook(&droneAuth{tokens: tokens}, nil); err != nil {
+		return nil, fmt.Errorf("add auth hook: %w", err)
+	}
+	tcp := listeners.NewTCP(listeners.Config{ID: "drones", Address: cfg.Addr})
+	if err := server.AddListener(tcp); err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", cfg.Addr, err)
+	}
+	return &Gateway{server: server, addr: tcp.Address()}, nil
+}
+
+// Addr is the bound listen address.
+func (g *Gateway) Addr() string {
+	return g.addr
+}
+
+// RouteTelemetry feeds every telemetry message into svc as a heartbeat. The
+// payload is a JSON drone.HeartbeatRequest. Messages from one connection
+// are handled in order; rejected ones are logged, as MQTT has no reply.
+func (g *Gateway) RouteTelemetry(svc HeartbeatService) error {
+	return g.server.Subscribe(telemetryFilter, 1, func(cl *mqtt.Client, sub packets.Subscription, pk packets.Packet) {
+		droneID, ok := droneIDFromTopic(pk.TopicName)
+		if !ok {
+			return
+		}
+		log := slog.With(slog.String("drone_id", droneID))
+
+		var hb drone.HeartbeatRequest
+		if err := json.Unmarshal(pk.Payload, &hb); err != nil {
+			log.Warn("mqtt telemetry rejected", slog.String("error", "invalid payload: "+err.Error()))
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), heartbeatTimeout)
+		defer cancel()
+		if _, err := svc.Heartbeat(ctx, droneID, hb); err != nil {
+			log.Warn("mqtt telemetry rejected", slog.String("error", err.Error()))
+		}
+	})
+}
+
+// Run serves until ctx is cancelled, then closes the broker.
+func (g *Gateway) Run(ctx context.Context) {
+	if err := g.server.Serve(); err != nil {
+		slog.ErrorContext(ctx, "mqtt gateway failed to start", slog.String("error", err.Error()))
+		return
+	}
+	<-ctx.Done()
+	if err := g.server.Close(); err != nil {
+		slog.Error("mqtt gateway close failed", slog.String("error", err.Error()))
+	}
+}
+
+// Publish sends cmd to the drone's command topic at QoS 1. Drones that are
+// offline miss it; the REST API remains the source of truth.
+func (g *Gateway) Publish(droneID string, cmd Command) error {
+	if cmd.IssuedAt.IsZero() {
+		cmd.IssuedAt = time.Now()
+	}
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return fmt.Errorf("marshal command: %w", err)
+	}
+	return g.server.Publish(CommandTopic(droneID), payload, false, 1)
+}
+
+// JobAssigned implements delivery.Notifier.
+func (g *Gateway) JobAssigned(ctx context.Context, droneID string, j *job.Job) {
+	g.publishLogged(ctx, droneID, Command{Type: CommandAssignment, OrderID: j.OrderID, JobID: j.ID})
+}
+
+// JobReleased implements delivery.Notifier.
+func (g *Gateway) JobReleased(ctx context.Context, droneID string, j *job.Job) {
+	g.publishLogged(ctx, droneID, Command{Type: CommandRelease, OrderID: j.OrderID, JobID: j.ID, Reason: j.ReleaseReason})
+}
+
+// HandoffStarted implements delivery.Notifier.
+func (g *Gateway) HandoffStarted(ctx context.Context, droneID string) {
+	g.publishLogged(ctx, droneID, Command{Type: CommandHandoff})
+}
+
+// CommandIssued implements command.Publisher.
+func (g *Gateway) CommandIssued(ctx context.Context, c *command.Command) {
+	cmd := Command{
+		Type:      CommandType(c.Type),
+		CommandID: c.ID.String(),
+		Reason:    c.Reason,
+		IssuedAt:  c.CreatedAt,
+	}
+	if c.OrderID != nil {
+		cmd.OrderID = c.OrderID.String()
+	}
+	g.publishLogged(ctx, c.DroneID, cmd)
+}
+
+func (g *Gateway) publishLogged(ctx context.Context, droneID string, cmd Command) {
+	if err := g.Publish(droneID, cmd); err != nil {
+		slog.WarnContext(ctx, "mqtt command publish failed",
+			slog.String("drone_id", droneID),
+			slog.String("command", string(cmd.Type)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// droneIDFromTopic extracts <id> from drones/<id>/telemetry.
+func droneIDFromTopic(topic string) (string, bool) {
+	parts := strings.Split(topic, "/")
+	if len(parts) != 3 || parts[0] != "drones" || parts[2] != "telemetry" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
@@ -0,0 +1,25 @@
+package grounding
+
+import (
+	"drone-delivery/internal/command"
+	"drone-delivery/internal/common"
+)
+
+// GroundRequest starts a grounding. Center and RadiusKM are required for
+// AREA scope. OrderPolicy and DroneAction default to the configured ones.
+type GroundRequest struct {
+	Scope       Scope            `json:"scope" binding:"required"`
+	Name        string           `json:"name"`
+	Center      *common.Location `json:"center"`
+	RadiusKM    *float64         `json:"radius_km"`
+	Reason      string           `json:"reason" binding:"required"`
+	OrderPolicy OrderPolicy      `json:"order_policy"`
+	DroneAction command.Type     `json:"drone_action"`
+}
+
+// GroundResponse reports the grounding and the in-flight drones that were
+// sent its drone action.
+type GroundResponse struct {
+	Grounding       *Grounding `json:"grounding"`
+	CommandedDrones []string   `json:"commanded_drones"`
+}
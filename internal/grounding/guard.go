@@ -0,0 +1,51 @@
+package grounding
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// Guard answers whether a flight or an order may proceed under the active
+// groundings. It is separate from Service because dispatch needs it before
+// the command queue, which Service depends on, can be built.
+type Guard struct {
+	db   *sqlx.DB
+	repo Repository
+}
+
+func NewGuard(db *sqlx.DB, repo Repository) *Guard {
+	return &Guard{db: db, repo: repo}
+}
+
+// CheckFlight returns a GROUNDED error when the fleet is grounded or any of
+// points (drone position, pickup, drop-off) lies in a grounded area.
+func (g *Guard) CheckFlight(ctx context.Context, points ...common.Location) error {
+	return g.check(ctx, points, false)
+}
+
+// AdmitOrder returns a GROUNDED error when a REJECT-policy grounding covers
+// the order's origin or destination. Under QUEUE groundings orders are
+// accepted and wait for the grounding to lift.
+func (g *Guard) AdmitOrder(ctx context.Context, origin, destination common.Location) error {
+	return g.check(ctx, []common.Location{origin, destination}, true)
+}
+
+func (g *Guard) check(ctx context.Context, points []common.Location, rejectOnly bool) error {
+	active, err := g.repo.List(ctx, g.db, true)
+	if err != nil {
+		return domainerrors.NewInternal("failed to load groundings", err)
+	}
+	for _, gr := range active {
+		if rejectOnly && gr.OrderPolicy != OrderPolicyReject {
+			continue
+		}
+		if gr.CoversAny(points) {
+			return domainerrors.Grounded(gr.Reason)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package grounding
+
+import (
+	"net/http"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// --------------------------------------------------------------
+func (h *Handler) Ground(c *gin.Context) {
+	var req GroundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	resp, err := h.service.Ground(c.Request.Context(), req, c.GetString("sub"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// --------------------------------------------------------------
+// List returns groundings newest first; active=true hides lifted ones.
+func (h *Handler) List(c *gin.Context) {
+	gs, err := h.service.List(c.Request.Context(), c.Query("active") == "true")
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"groundings": gs})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) Lift(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid grounding id"}})
+		return
+	}
+
+	g, err := h.service.Lift(c.Request.Context(), id, c.GetString("sub"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"grounding": g})
+}
@@ -0,0 +1,127 @@
+package grounding
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/command"
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Scope string
+
+const (
+	ScopeFleet Scope = "FLEET"
+	ScopeArea  Scope = "AREA"
+)
+
+// OrderPolicy decides what happens to orders placed under a grounding.
+type OrderPolicy string
+
+const (
+	// OrderPolicyQueue accepts orders; their jobs wait until the grounding
+	// is lifted.
+	OrderPolicyQueue OrderPolicy = "QUEUE"
+	// OrderPolicyReject refuses orders touching the grounded area.
+	OrderPolicyReject OrderPolicy = "REJECT"
+)
+
+func (p OrderPolicy) Valid() bool {
+	return p == OrderPolicyQueue || p == OrderPolicyReject
+}
+
+// Grounding bans flights fleet-wide or inside a circular area until lifted.
+// DroneAction is the command sent to drones caught in flight.
+type Grounding struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	Scope       Scope        `db:"scope" json:"scope"`
+	Name        string       `db:"name" json:"name,omitempty"`
+	CenterLat   *float64     `db:"center_lat" json:"center_lat,omitempty"`
+	CenterLng   *float64     `db:"center_lng" json:"center_lng,omitempty"`
+	RadiusKM    *float64     `db:"radius_km" json:"radius_km,omitempty"`
+	Reason      string       `db:"reason" json:"reason"`
+	OrderPolicy OrderPolicy  `db:"order_policy" json:"order_policy"`
+	DroneAction command.Type `db:"drone_action" json:"drone_action"`
+	CreatedBy   string       `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time    `db:"created_at" json:"created_at"`
+	LiftedAt    *time.Time   `db:"lifted_at" json:"lifted_at,omitempty"`
+	LiftedBy    *string      `db:"lifted_by" json:"lifted_by,omitempty"`
+}
+
+// New validates req, which must have its defaults filled in.
+func New(req GroundRequest, by string) (*Grounding, error) {
+	g := &Grounding{
+		ID:          uuid.New(),
+		Scope:       req.Scope,
+		Name:        req.Name,
+		Reason:      req.Reason,
+		OrderPolicy: req.OrderPolicy,
+		DroneAction: req.DroneAction,
+		CreatedBy:   by,
+		CreatedAt:   time.Now(),
+	}
+	switch req.Scope {
+	case ScopeFleet:
+		if req.Center != nil || req.RadiusKM != nil {
+			return nil, domainerrors.NewValidation("center and radius_km only apply to AREA groundings")
+		}
+	case ScopeArea:
+		if req.Center == nil || req.RadiusKM == nil {
+			return nil, domainerrors.NewValidation("AREA groundings need center and radius_km")
+		}
+		if err := common.ValidateLatLng(req.Center.Lat, req.Center.Lng); err != nil {
+			return nil, domainerrors.NewValidation(err.Error())
+		}
+		if *req.RadiusKM <= 0 {
+			return nil, domainerrors.NewValidation("radius_km must be greater than 0")
+		}
+		g.CenterLat, g.CenterLng, g.RadiusKM = &req.Center.Lat, &req.Center.Lng, req.RadiusKM
+	default:
+		return nil, domainerrors.NewValidation("scope must be FLEET or AREA")
+	}
+	if !req.OrderPolicy.Valid() {
+		return nil, domainerrors.NewValidation("order_policy must be QUEUE or REJECT")
+	}
+	if req.DroneAction != command.TypeReturnToBase && req.DroneAction != command.TypeLandImmediately {
+		return nil, domainerrors.NewValidation("drone_action must be RETURN_TO_BASE or LAND_IMMEDIATELY")
+	}
+	return g, nil
+}
+
+// Covers reports whether loc is inside the grounding.
+func (g *Grounding) Covers(loc common.Location) bool {
+	if g.Scope == ScopeFleet {
+		return true
+	}
+	center := common.NewLocation(*g.CenterLat, *g.CenterLng)
+	return common.HaversineDistance(center, loc) <= *g.RadiusKM
+}
+
+// CoversAny reports whether any of points is inside the grounding.
+func (g *Grounding) CoversAny(points []common.Location) bool {
+	if g.Scope == ScopeFleet {
+		return true
+	}
+	for _, p := range points {
+		if g.Covers(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Grounding) IsActive() bool {
+	return g.LiftedAt == nil
+}
+
+func (g *Grounding) Lift(by string) error {
+	if !g.IsActive() {
+		return domainerrors.GroundingAlreadyLifted(g.ID.String())
+	}
+	now := time.Now()
+	g.LiftedAt = &now
+	g.LiftedBy = &by
+	return nil
+}
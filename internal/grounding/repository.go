@@ -0,0 +1,69 @@
+package grounding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const columns = `id, scope, name, center_lat, center_lng, radius_km, reason, order_policy, drone_action,
+	created_by, created_at, lifted_at, lifted_by`
+
+// maxListed caps the grounding history; read newest first.
+const maxListed = 200
+
+type Repository interface {
+	Create(ctx context.Context, ext sqlx.ExtContext, g *Grounding) error
+	GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Grounding, error)
+	Update(ctx context.Context, ext sqlx.ExtContext, g *Grounding) error
+	List(ctx context.Context, ext sqlx.ExtContext, activeOnly bool) ([]*Grounding, error)
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, g *Grounding) error {
+	const query = `INSERT INTO groundings (id, scope, name, center_lat, center_lng, radius_km, reason, order_policy,
+			drone_action, created_by, created_at)
+		VALUES (:id, :scope, :name, :center_lat, :center_lng, :radius_km, :reason, :order_policy,
+			:drone_action, :created_by, :created_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, g)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Grounding, error) {
+	var g Grounding
+	query := fmt.Sprintf(`SELECT %s FROM groundings WHERE id = $1 FOR UPDATE`, columns)
+	if err := sqlx.GetContext(ctx, ext, &g, query, id); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, g *Grounding) error {
+	const query = `UPDATE groundings SET lifted_at = :lifted_at, lifted_by = :lifted_by WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, g)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) List(ctx context.Context, ext sqlx.ExtContext, activeOnly bool) ([]*Grounding, error) {
+	where := ""
+	if activeOnly {
+		where = " WHERE lifted_at IS NULL"
+	}
+	var gs []*Grounding
+	query := fmt.Sprintf(`SELECT %s FROM groundings%s ORDER BY created_at DESC LIMIT %d`, columns, where, maxListed)
+	if err := sqlx.SelectContext(ctx, ext, &gs, query); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
@@ -0,0 +1,151 @@
+package grounding
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/command"
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
+)
+
+// fleetPageSize is how many drones are read per page when looking for
+// drones in flight.
+const fleetPageSize = 100
+
+// Config holds the defaults for requests that leave them out.
+type Config struct {
+	OrderPolicy OrderPolicy
+	DroneAction command.Type
+}
+
+type Service interface {
+	Ground(ctx context.Context, req GroundRequest, by string) (*GroundResponse, error)
+	Lift(ctx context.Context, id uuid.UUID, by string) (*Grounding, error)
+	List(ctx context.Context, activeOnly bool) ([]*Grounding, error)
+}
+
+// FleetLister pages through drones by status. Satisfied by drone.Service.
+type FleetLister interface {
+	List(ctx context.Context, spec listing.Spec) ([]*drone.Drone, string, error)
+}
+
+// Commander queues commands for drones. Satisfied by command.Service.
+type Commander interface {
+	Issue(ctx context.Context, droneID string, req command.IssueRequest, issuedBy string) (*command.Command, error)
+}
+
+type service struct {
+	db       *sqlx.DB
+	repo     Repository
+	fleet    FleetLister
+	commands Commander
+	cfg      Config
+}
+
+func NewService(db *sqlx.DB, repo Repository, fleet FleetLister, commands Commander, cfg Config) Service {
+	return &service{db: db, repo: repo, fleet: fleet, commands: commands, cfg: cfg}
+}
+
+// --------------------------------------------------------------
+// Ground records the grounding, which takes effect immediately, then sends
+// its drone action to every drone in flight inside it. A drone that can't be
+// commanded is logged and skipped.
+func (s *service) Ground(ctx context.Context, req GroundRequest, by string) (*GroundResponse, error) {
+	if req.OrderPolicy == "" {
+		req.OrderPolicy = s.cfg.OrderPolicy
+	}
+	if req.DroneAction == "" {
+		req.DroneAction = s.cfg.DroneAction
+	}
+	g, err := New(req, by)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, s.db, g); err != nil {
+		return nil, domainerrors.NewInternal("failed to create grounding", err)
+	}
+
+	flying, err := s.inFlight(ctx, g)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list drones in flight", err)
+	}
+	commanded := []string{}
+	for _, d := range flying {
+		_, err := s.commands.Issue(ctx, d.ID, command.IssueRequest{Type: g.DroneAction, Reason: "grounded: " + g.Reason}, by)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to command grounded drone",
+				slog.String("grounding_id", g.ID.String()),
+				slog.String("drone_id", d.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		commanded = append(commanded, d.ID)
+	}
+	return &GroundResponse{Grounding: g, CommandedDrones: commanded}, nil
+}
+
+// inFlight returns the drones on a mission inside g.
+func (s *service) inFlight(ctx context.Context, g *Grounding) ([]*drone.Drone, error) {
+	var flying []*drone.Drone
+	for _, status := range []drone.Status{drone.StatusEnRoutePickup, drone.StatusEnRouteDelivery} {
+		spec := listing.Spec{Status: string(status), Sort: listing.Sort{Field: "id"}, Limit: fleetPageSize}
+		for {
+			drones, next, err := s.fleet.List(ctx, spec)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range drones {
+				if g.Covers(d.Location()) {
+					flying = append(flying, d)
+				}
+			}
+			if next == "" {
+				break
+			}
+			if spec.Cursor, err = listing.DecodeCursor(next); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return flying, nil
+}
+
+// --------------------------------------------------------------
+// Lift ends the grounding; queued jobs become reservable again.
+func (s *service) Lift(ctx context.Context, id uuid.UUID, by string) (*Grounding, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	g, err := s.repo.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, domainerrors.GroundingNotFound(id.String())
+	}
+	if err := g.Lift(by); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, tx, g); err != nil {
+		return nil, domainerrors.NewInternal("failed to lift grounding", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	return g, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) List(ctx context.Context, activeOnly bool) ([]*Grounding, error) {
+	gs, err := s.repo.List(ctx, s.db, activeOnly)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list groundings", err)
+	}
+	return gs, nil
+}
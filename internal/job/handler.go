@@ -2,9 +2,15 @@ package job
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
+	"drone-delivery/internal/airspace"
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
 	"drone-delivery/internal/pkg/apperrors"
+	"drone-delivery/internal/pkg/etag"
+	"drone-delivery/internal/pkg/listing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,6 +19,9 @@ import (
 type Handler struct {
 	service         Service
 	deliveryManager DeliveryManager
+	droneLocator    DroneLocator
+	guard           FlightGuard
+	corridors       Corridors
 }
 type DeliveryManager interface {
 	ReserveJobAndAssign(ctx context.Context, jobID, droneID string) (*Job, error)
@@ -20,13 +29,90 @@ type DeliveryManager interface {
 	CompleteDelivery(ctx context.Context, orderID uuid.UUID, droneID string, success bool) error
 }
 
-func NewHandler(service Service, deliveryManager DeliveryManager) *Handler {
-	return &Handler{service: service, deliveryManager: deliveryManager}
+// DroneLocator avoids importing drone package (circular dep prevention).
+type DroneLocator interface {
+	GetDroneLocation(ctx context.Context, droneID string) (*common.Location, error)
+}
+
+// FlightGuard avoids importing grounding package (circular dep prevention).
+type FlightGuard interface {
+	CheckFlight(ctx context.Context, points ...common.Location) error
+}
+
+// Corridors looks up the airspace booked with a reservation. Satisfied by
+// airspace.Service.
+type Corridors interface {
+	ActiveForDrone(ctx context.Context, droneID string) (*airspace.Reservation, error)
+}
+
+func NewHandler(service Service, deliveryManager DeliveryManager, droneLocator DroneLocator, guard FlightGuard, corridors Corridors) *Handler {
+	return &Handler{service: service, deliveryManager: deliveryManager, droneLocator: droneLocator, guard: guard, corridors: corridors}
 }
 
 // --------------------------------------------------------------
+// ListOpenJobs pages through open jobs in dispatch order. With
+// ?sort=distance, jobs of equal effective priority are listed nearest first
+// to the calling drone's last reported position (limit only; distances
+// change as the drone flies, so there is no cursor).
+// A grounded drone sees no jobs.
 func (h *Handler) ListOpenJobs(c *gin.Context) {
-	jobs, err := h.service.ListOpenJobs(c.Request.Context())
+	spec, err := listing.Parse(c.Request.URL.Query())
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	sort := spec.Sort.String()
+	if sort != "" && sort != "distance" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "sort must be 'distance'"}})
+		return
+	}
+	if spec.Filtered() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "open jobs can't be filtered"}})
+		return
+	}
+	if sort == "distance" && spec.Cursor != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "sort=distance has no cursor"}})
+		return
+	}
+
+	grounded, err := h.grounded(c)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if grounded {
+		c.JSON(http.StatusOK, gin.H{"jobs": []*Job{}, "next_cursor": ""})
+		return
+	}
+	if sort == "distance" {
+		h.listOpenJobsByDistance(c, spec.Limit)
+		return
+	}
+
+	jobs, next, err := h.service.ListOpenJobs(c.Request.Context(), spec.Cursor, spec.Limit)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []*Job{}
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "next_cursor": next})
+}
+
+func (h *Handler) listOpenJobsByDistance(c *gin.Context, limit int) {
+	droneID := c.GetString("sub")
+	loc, err := h.droneLocator.GetDroneLocation(c.Request.Context(), droneID)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if loc == nil {
+		apperrors.ToHTTPError(c, domainerrors.NewConflict("drone location unknown; send a heartbeat first"))
+		return
+	}
+
+	jobs, err := h.service.ListOpenJobsByDistance(c.Request.Context(), *loc, limit)
 	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
@@ -34,6 +120,59 @@ func (h *Handler) ListOpenJobs(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
 }
 
+// grounded reports whether the fleet is grounded or the calling drone's last
+// position is in a grounded area.
+func (h *Handler) grounded(c *gin.Context) (bool, error) {
+	ctx := c.Request.Context()
+	var points []common.Location
+	if loc, err := h.droneLocator.GetDroneLocation(ctx, c.GetString("sub")); err == nil && loc != nil {
+		points = append(points, *loc)
+	}
+
+	err := h.guard.CheckFlight(ctx, points...)
+	var domainErr *domainerrors.DomainError
+	if errors.As(err, &domainErr) && domainErr.Code == domainerrors.ErrGrounded {
+		return true, nil
+	}
+	return false, err
+}
+
+// --------------------------------------------------------------
+// ListJobs pages through all jobs for admins, newest first by default.
+func (h *Handler) ListJobs(c *gin.Context) {
+	spec, err := listing.Parse(c.Request.URL.Query())
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	jobs, next, err := h.service.ListJobs(c.Request.Context(), spec)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	total, err := h.service.CountJobs(c.Request.Context(), spec)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []*Job{}
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "total": total, "limit": spec.Limit, "next_cursor": next})
+}
+
+// --------------------------------------------------------------
+// GetJob returns a job for admins with its version as the ETag.
+func (h *Handler) GetJob(c *gin.Context) {
+	j, err := h.service.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.Header("ETag", etag.Format(j.Version))
+	c.JSON(http.StatusOK, gin.H{"job": j})
+}
+
 // --------------------------------------------------------------
 func (h *Handler) ReserveJob(c *gin.Context) {
 	var req struct {
@@ -52,7 +191,13 @@ func (h *Handler) ReserveJob(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"job": j, "message": "job reserved"})
+	resp := gin.H{"job": j, "message": "job reserved"}
+	// The corridor tells the drone its altitude band and when to depart;
+	// it's also served by GET /drone/me/airspace if this lookup fails.
+	if r, err := h.corridors.ActiveForDrone(c.Request.Context(), droneID); err == nil {
+		resp["airspace"] = r
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // --------------------------------------------------------------
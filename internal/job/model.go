@@ -1,9 +1,14 @@
 package job
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
+	"drone-delivery/internal/common"
 	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 
 	"github.com/google/uuid"
 )
@@ -18,12 +23,161 @@ const (
 )
 
 type Job struct {
-	ID                string    `db:"id" json:"id"`
-	OrderID           string    `db:"order_id" json:"order_id"`
-	Status            Status    `db:"status" json:"status"`
-	ReservedByDroneID *string   `db:"reserved_by_drone_id" json:"reserved_by_drone_id,omitempty"`
-	CreatedAt         time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	ID                string     `db:"id" json:"id"`
+	OrderID           string     `db:"order_id" json:"order_id"`
+	Status            Status     `db:"status" json:"status"`
+	ReservedByDroneID *string    `db:"reserved_by_drone_id" json:"reserved_by_drone_id,omitempty"`
+	ReservedAt        *time.Time `db:"reserved_at" json:"reserved_at,omitempty"`
+	// Priority starts at the order's service tier; admins may bump it.
+	Priority int `db:"priority" json:"priority"`
+	// The reserving drone must pick up by LeaseExpiresAt; LeaseDistanceKM is
+	// how far from the pickup it was when the lease was last set.
+	LeaseExpiresAt  *time.Time `db:"lease_expires_at" json:"lease_expires_at,omitempty"`
+	LeaseDistanceKM *float64   `db:"lease_distance_km" json:"-"`
+	// ReleaseReason says why the job was last reopened.
+	ReleaseReason string `db:"release_reason" json:"release_reason,omitempty"`
+	// Version counts writes to the row; Update refuses a copy that is behind.
+	Version   int64     `db:"version" json:"version"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// OpenJob is an open job with its order's pickup point and its distance
+// from the requesting drone.
+type OpenJob struct {
+	Job
+	PickupLat  float64  `db:"pickup_lat" json:"pickup_lat"`
+	PickupLng  float64  `db:"pickup_lng" json:"pickup_lng"`
+	DistanceKM *float64 `db:"distance_km" json:"distance_km,omitempty"`
+}
+
+// leaseProgressKM is how much closer to the pickup a drone must get before
+// its lease is extended, so GPS noise while hovering doesn't count.
+const leaseProgressKM = 0.05
+
+// LeasePolicy sizes reservation leases from the distance to the pickup.
+type LeasePolicy struct {
+	SpeedKMH float64
+	Grace    time.Duration
+}
+
+// Deadline is when a drone distanceKM from the pickup should have arrived.
+func (p LeasePolicy) Deadline(distanceKM float64, now time.Time) time.Time {
+	return now.Add(time.Duration(distanceKM/p.SpeedKMH*float64(time.Hour)) + p.Grace)
+}
+
+// MaxPriority bounds the priority an admin may give a job.
+const MaxPriority = 100
+
+// QueuePolicy orders open jobs for dispatch: highest priority first, then
+// oldest first. A waiting job gains one priority level per AgingStep so
+// low-priority work isn't starved; zero disables aging.
+type QueuePolicy struct {
+	AgingStep time.Duration
+}
+
+// EffectivePriority is j's priority plus what it has gained by waiting.
+func (p QueuePolicy) EffectivePriority(j *Job, now time.Time) int {
+	if p.AgingStep <= 0 || !now.After(j.CreatedAt) {
+		return j.Priority
+	}
+	return j.Priority + int(now.Sub(j.CreatedAt)/p.AgingStep)
+}
+
+// Sort orders jobs for dispatch as of now. Jobs created together are
+// ordered by id so the order is total.
+func (p QueuePolicy) Sort(jobs []*Job, now time.Time) {
+	sort.SliceStable(jobs, func(a, b int) bool {
+		return p.before(jobs[a], now, p.EffectivePriority(jobs[b], now), jobs[b].CreatedAt, jobs[b].ID)
+	})
+}
+
+// before reports whether j comes before the position (priority, createdAt,
+// id) in the dispatch order as of now.
+func (p QueuePolicy) before(j *Job, now time.Time, priority int, createdAt time.Time, id string) bool {
+	if ep := p.EffectivePriority(j, now); ep != priority {
+		return ep > priority
+	}
+	if !j.CreatedAt.Equal(createdAt) {
+		return j.CreatedAt.Before(createdAt)
+	}
+	return j.ID < id
+}
+
+// SortDispatch names the dispatch order in list cursors.
+const SortDispatch = "dispatch"
+
+// DispatchKey is a job's position in the dispatch order.
+type DispatchKey struct {
+	Priority  int // effective, as of the page's At
+	CreatedAt time.Time
+	ID        string
+}
+
+// DispatchPage asks for up to Limit open jobs after the position After (nil
+// for the first page) in the dispatch order, aged as of At.
+type DispatchPage struct {
+	At        time.Time
+	AgingStep time.Duration
+	After     *DispatchKey
+	Limit     int
+}
+
+// NearbyPage asks for up to Limit open jobs with a pickup within RadiusKM
+// of From, in dispatch order aged as of At but nearest first among jobs of
+// equal effective priority.
+type NearbyPage struct {
+	From      common.Location
+	RadiusKM  float64
+	At        time.Time
+	AgingStep time.Duration
+	Limit     int
+}
+
+// Nearby is the NearbyPage for a drone at from, aged as of now.
+func (p QueuePolicy) Nearby(from common.Location, radiusKM float64, limit int, now time.Time) NearbyPage {
+	return NearbyPage{From: from, RadiusKM: radiusKM, At: now.Truncate(time.Microsecond), AgingStep: p.AgingStep, Limit: limit}
+}
+
+// PageFor turns a dispatch cursor into the page to read. Priorities are
+// aged as of the first page's time, carried in the cursor, so the order
+// doesn't shift between pages; jobs taken or added meanwhile don't move the
+// others. now is cut to the database's microseconds so both sides age alike.
+func (p QueuePolicy) PageFor(after *listing.Cursor, limit int, now time.Time) (DispatchPage, error) {
+	page := DispatchPage{At: now.Truncate(time.Microsecond), AgingStep: p.AgingStep, Limit: limit}
+	if after == nil {
+		return page, nil
+	}
+	if after.Sort != SortDispatch || after.At == nil || len(after.Keys) != 3 {
+		return page, domainerrors.NewValidation("cursor was issued for a different sort")
+	}
+	priority, err := strconv.Atoi(after.Keys[0])
+	if err != nil {
+		return page, domainerrors.NewValidation("invalid cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, after.Keys[1])
+	if err != nil {
+		return page, domainerrors.NewValidation("invalid cursor")
+	}
+	page.At = after.At.Truncate(time.Microsecond)
+	page.After = &DispatchKey{Priority: priority, CreatedAt: createdAt, ID: after.Keys[2]}
+	return page, nil
+}
+
+// Next trims jobs, read with one row beyond the page's limit, to the limit
+// and returns the cursor for the following page, or "" when this is the last.
+func (p QueuePolicy) Next(page DispatchPage, jobs []*Job) ([]*Job, string) {
+	if len(jobs) <= page.Limit {
+		return jobs, ""
+	}
+	jobs = jobs[:page.Limit]
+	last := jobs[page.Limit-1]
+	c := &listing.Cursor{
+		Sort: SortDispatch,
+		Keys: []string{strconv.Itoa(p.EffectivePriority(last, page.At)), listing.FormatTime(last.CreatedAt), last.ID},
+		At:   &page.At,
+	}
+	return jobs, c.Encode()
 }
 
 func NewJob(orderID string) *Job {
@@ -32,21 +186,96 @@ func NewJob(orderID string) *Job {
 		ID:        uuid.New().String(),
 		OrderID:   orderID,
 		Status:    StatusOpen,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 }
 
+// CheckVersion refuses an edit based on a version other than the current
+// one. A nil want accepts any version.
+func (j *Job) CheckVersion(want *int64) error {
+	if want != nil && *want != j.Version {
+		return domainerrors.VersionMismatch("job", j.ID, j.Version)
+	}
+	return nil
+}
+
 func (j *Job) Reserve(droneID string) error {
 	if j.Status != StatusOpen {
 		return domainerrors.JobAlreadyReserved()
 	}
+	now := time.Now()
 	j.Status = StatusReserved
 	j.ReservedByDroneID = &droneID
+	j.ReservedAt = &now
+	j.UpdatedAt = now
+	return nil
+}
+
+// SetPriority changes the priority of a job that hasn't finished.
+func (j *Job) SetPriority(p int) error {
+	if p < 0 || p > MaxPriority {
+		return domainerrors.NewValidation(fmt.Sprintf("priority must be between 0 and %d", MaxPriority))
+	}
+	if j.Status != StatusOpen && j.Status != StatusReserved {
+		return domainerrors.JobInvalidTransition(string(j.Status), "set_priority")
+	}
+	j.Priority = p
 	j.UpdatedAt = time.Now()
 	return nil
 }
 
+// Release reopens a reserved job so another drone can take it.
+func (j *Job) Release(reason string) error {
+	if j.Status != StatusReserved {
+		return domainerrors.JobInvalidTransition(string(j.Status), string(StatusOpen))
+	}
+	j.Status = StatusOpen
+	j.ReservedByDroneID = nil
+	j.ReservedAt = nil
+	j.LeaseExpiresAt = nil
+	j.LeaseDistanceKM = nil
+	j.ReleaseReason = reason
+	j.UpdatedAt = time.Now()
+	return nil
+}
+
+// Lease gives the reserving drone, distanceKM from the pickup, until
+// deadline to pick up.
+func (j *Job) Lease(distanceKM float64, deadline time.Time) {
+	j.LeaseExpiresAt = &deadline
+	j.LeaseDistanceKM = &distanceKM
+}
+
+// ExtendLease pushes the deadline out to deadline if the drone, now
+// distanceKM from the pickup, has got closer since the lease was last set.
+// It reports whether the lease changed.
+func (j *Job) ExtendLease(distanceKM float64, deadline time.Time) bool {
+	if j.Status != StatusReserved || j.LeaseExpiresAt == nil || j.LeaseDistanceKM == nil {
+		return false
+	}
+	if distanceKM > *j.LeaseDistanceKM-leaseProgressKM {
+		return false
+	}
+	j.LeaseDistanceKM = &distanceKM
+	if deadline.After(*j.LeaseExpiresAt) {
+		j.LeaseExpiresAt = &deadline
+	}
+	j.UpdatedAt = time.Now()
+	return true
+}
+
+// EndLease drops the lease once the order is picked up.
+func (j *Job) EndLease() {
+	j.LeaseExpiresAt = nil
+	j.LeaseDistanceKM = nil
+}
+
+func (j *Job) LeaseExpired(now time.Time) bool {
+	return j.Status == StatusReserved && j.LeaseExpiresAt != nil && now.After(*j.LeaseExpiresAt)
+}
+
 func (j *Job) Complete() error {
 	if j.Status != StatusReserved {
 		return domainerrors.JobInvalidTransition(string(j.Status), string(StatusCompleted))
@@ -56,12 +285,22 @@ func (j *Job) Complete() error {
 	return nil
 }
 
+// FlightTime is how long the job has been held by its drone.
+func (j *Job) FlightTime(now time.Time) time.Duration {
+	if j.ReservedAt == nil {
+		return 0
+	}
+	return now.Sub(*j.ReservedAt)
+}
+
 func (j *Job) Cancel() error {
 	if j.Status == StatusCompleted || j.Status == StatusCancelled {
 		return domainerrors.JobInvalidTransition(string(j.Status), string(StatusCancelled))
 	}
 	j.Status = StatusCancelled
 	j.ReservedByDroneID = nil
+	j.LeaseExpiresAt = nil
+	j.LeaseDistanceKM = nil
 	j.UpdatedAt = time.Now()
 	return nil
 }
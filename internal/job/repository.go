@@ -3,21 +3,33 @@ package job
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 )
 
-const columns = `id, order_id, status, reserved_by_drone_id, created_at, updated_at`
+const columns = `id, order_id, status, reserved_by_drone_id, reserved_at, priority, lease_expires_at, lease_distance_km,
+	release_reason, version, created_at, updated_at`
 
 type Repository interface {
 	Create(ctx context.Context, ext sqlx.ExtContext, j *Job) error
 	GetByID(ctx context.Context, ext sqlx.ExtContext, id string) (*Job, error)
 	GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id string) (*Job, error)
 	Update(ctx context.Context, ext sqlx.ExtContext, j *Job) error
-	ListAll(ctx context.Context, ext sqlx.ExtContext, status *Status, page, limit int) ([]*Job, int, error)
-	ListByStatus(ctx context.Context, ext sqlx.ExtContext, status Status) ([]*Job, error)
+	List(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) ([]*Job, string, error)
+	Count(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) (int, error)
+	ListOpenPage(ctx context.Context, ext sqlx.ExtContext, page DispatchPage) ([]*Job, error)
+	ListOpenNearest(ctx context.Context, ext sqlx.ExtContext, page NearbyPage) ([]*OpenJob, error)
+	CountOpen(ctx context.Context, ext sqlx.ExtContext, minPriority int) (int, error)
 	GetByOrderID(ctx context.Context, ext sqlx.ExtContext, orderID string) (*Job, error)
 	GetByOrderIDForUpdate(ctx context.Context, ext sqlx.ExtContext, orderID string) (*Job, error)
+	GetLeasedByDroneForUpdate(ctx context.Context, ext sqlx.ExtContext, droneID string) (*Job, error)
+	ListExpiredLeases(ctx context.Context, ext sqlx.ExtContext, now time.Time, limit int) ([]string, error)
 	CancelByJobID(ctx context.Context, ext sqlx.ExtContext, id string) error
 	CancelByOrderID(ctx context.Context, ext sqlx.ExtContext, orderID string) error
 }
@@ -30,8 +42,8 @@ func NewRepository() Repository {
 
 // --------------------------------------------------------------
 func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, j *Job) error {
-	const query = `INSERT INTO jobs (id, order_id, status, reserved_by_drone_id, created_at, updated_at)
-		VALUES (:id, :order_id, :status, :reserved_by_drone_id, :created_at, :updated_at)`
+	const query = `INSERT INTO jobs (id, order_id, status, reserved_by_drone_id, priority, created_at, updated_at)
+		VALUES (:id, :order_id, :status, :reserved_by_drone_id, :priority, :created_at, :updated_at)`
 	_, err := sqlx.NamedExecContext(ctx, ext, query, j)
 	return err
 }
@@ -59,53 +71,169 @@ func (r *repo) GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id str
 }
 
 // --------------------------------------------------------------
+// Update writes j over the row it was read from and advances its version.
+// If the row has been written since, nothing is written and the error is
+// a StaleWrite.
 func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, j *Job) error {
-	const query = `UPDATE jobs SET status = :status, reserved_by_drone_id = :reserved_by_drone_id, updated_at = :updated_at WHERE id = :id`
-	_, err := sqlx.NamedExecContext(ctx, ext, query, j)
-	return err
+	const query = `UPDATE jobs SET status = :status, reserved_by_drone_id = :reserved_by_drone_id, reserved_at = :reserved_at,
+		priority = :priority, lease_expires_at = :lease_expires_at, lease_distance_km = :lease_distance_km, release_reason = :release_reason,
+		updated_at = :updated_at, version = version + 1 WHERE id = :id AND version = :version`
+	res, err := sqlx.NamedExecContext(ctx, ext, query, j)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domainerrors.StaleWrite("job", j.ID)
+	}
+	j.Version++
+	return nil
 }
 
 // --------------------------------------------------------------
-func (r *repo) ListAll(ctx context.Context, ext sqlx.ExtContext, status *Status, page, limit int) ([]*Job, int, error) {
-	offset := (page - 1) * limit
-	args := []any{}
-	argIdx := 1
+// listSchema maps list filters and sorts onto jobs. Submitter and bounding
+// box (the pickup) are read from the job's order.
+var listSchema = listing.Schema{
+	Name:        "jobs",
+	ID:          "id",
+	Status:      "status",
+	CreatedAt:   "created_at",
+	DroneID:     "reserved_by_drone_id",
+	SubmittedBy: "(SELECT o.submitted_by FROM orders o WHERE o.id = jobs.order_id)",
+	Lat:         "(SELECT o.origin_lat FROM orders o WHERE o.id = jobs.order_id)",
+	Lng:         "(SELECT o.origin_lng FROM orders o WHERE o.id = jobs.order_id)",
+	Sorts: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"priority":   "priority",
+	},
+	DefaultSort: listing.Sort{Field: "created_at", Desc: true},
+}
 
-	where := ""
-	if status != nil {
-		where = fmt.Sprintf(" WHERE status = $%d", argIdx)
-		args = append(args, *status)
-		argIdx++
+// List returns one page of jobs and the cursor for the next, or "" on the
+// last page.
+func (r *repo) List(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) ([]*Job, string, error) {
+	q, err := listSchema.Build(spec)
+	if err != nil {
+		return nil, "", err
+	}
+	var jobs []*Job
+	query := fmt.Sprintf(`SELECT %s FROM jobs%s`, columns, q.Page())
+	if err := sqlx.SelectContext(ctx, ext, &jobs, query, q.PageArgs()...); err != nil {
+		return nil, "", err
 	}
+	jobs, next := listing.Next(q, jobs, sortKeys)
+	return jobs, next, nil
+}
 
+// --------------------------------------------------------------
+// Count returns how many jobs match spec's filters.
+func (r *repo) Count(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) (int, error) {
+	q, err := listSchema.Build(spec)
+	if err != nil {
+		return 0, err
+	}
 	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM jobs%s`, where)
-	if err := sqlx.GetContext(ctx, ext, &total, countQuery, args...); err != nil {
-		return nil, 0, err
+	if err := sqlx.GetContext(ctx, ext, &total, `SELECT COUNT(*) FROM jobs`+q.Where(), q.Args()...); err != nil {
+		return 0, err
 	}
+	return total, nil
+}
 
-	dataQuery := fmt.Sprintf(`SELECT %s FROM jobs%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, columns, where, argIdx, argIdx+1)
-	args = append(args, limit, offset)
+func sortKeys(j *Job, field string) (string, string) {
+	switch field {
+	case "updated_at":
+		return listing.FormatTime(j.UpdatedAt), j.ID
+	case "priority":
+		return strconv.Itoa(j.Priority), j.ID
+	}
+	return listing.FormatTime(j.CreatedAt), j.ID
+}
 
-	var jobs []*Job
-	if err := sqlx.SelectContext(ctx, ext, &jobs, dataQuery, args...); err != nil {
-		return nil, 0, err
+// --------------------------------------------------------------
+// ListOpenPage reads page's open jobs in dispatch order, plus one more to
+// tell whether another page follows. The aged priority mirrors
+// QueuePolicy.EffectivePriority and is computed in the query, so only the
+// page leaves the database. Without aging it is the priority column itself
+// and idx_jobs_open_dispatch serves the order; with aging Postgres keeps a
+// top-N sort over the open jobs rather than us loading all of them.
+func (r *repo) ListOpenPage(ctx context.Context, ext sqlx.ExtContext, page DispatchPage) ([]*Job, error) {
+	effective := `priority`
+	args := []any{StatusOpen}
+	if page.AgingStep > 0 {
+		args = append(args, page.At, page.AgingStep.Microseconds())
+		effective = `priority + CASE WHEN created_at < $2
+			THEN FLOOR(EXTRACT(EPOCH FROM ($2 - created_at)) * 1000000 / $3)::int ELSE 0 END`
+	}
+	keyset := ""
+	if page.After != nil {
+		n := len(args)
+		keyset = fmt.Sprintf(` WHERE effective_priority < $%d
+			OR (effective_priority = $%d AND (created_at, id) > ($%d, $%d))`, n+1, n+1, n+2, n+3)
+		args = append(args, page.After.Priority, page.After.CreatedAt, page.After.ID)
 	}
+	args = append(args, page.Limit+1)
+	query := fmt.Sprintf(`SELECT %s FROM (
+			SELECT %s, %s AS effective_priority FROM jobs WHERE status = $1
+		) open_jobs%s
+		ORDER BY effective_priority DESC, created_at ASC, id ASC LIMIT $%d`,
+		columns, columns, effective, keyset, len(args))
 
-	return jobs, total, nil
+	var jobs []*Job
+	if err := sqlx.SelectContext(ctx, ext, &jobs, query, args...); err != nil {
+		return nil, err
+	}
+	return jobs, nil
 }
 
 // --------------------------------------------------------------
-func (r *repo) ListByStatus(ctx context.Context, ext sqlx.ExtContext, status Status) ([]*Job, error) {
-	var jobs []*Job
-	query := fmt.Sprintf(`SELECT %s FROM jobs WHERE status = $1 ORDER BY created_at ASC`, columns)
-	err := sqlx.SelectContext(ctx, ext, &jobs, query, status)
-	if err != nil {
+// ListOpenNearest reads page's open jobs with a pickup within its radius,
+// in dispatch priority aged as ListOpenPage does, nearest first among equal
+// priorities. The bounding box lets idx_orders_origin narrow the rows
+// before the haversine distance, which mirrors common.HaversineDistance, is
+// computed for each of them.
+func (r *repo) ListOpenNearest(ctx context.Context, ext sqlx.ExtContext, page NearbyPage) ([]*OpenJob, error) {
+	sw, ne := common.BoundingBox(page.From, page.RadiusKM)
+	args := []any{StatusOpen, page.From.Lat, page.From.Lng, sw.Lat, ne.Lat, sw.Lng, ne.Lng, page.RadiusKM}
+	effective := `j.priority`
+	if page.AgingStep > 0 {
+		args = append(args, page.At, page.AgingStep.Microseconds())
+		effective = `j.priority + CASE WHEN j.created_at < $9
+			THEN FLOOR(EXTRACT(EPOCH FROM ($9 - j.created_at)) * 1000000 / $10)::int ELSE 0 END`
+	}
+	args = append(args, page.Limit)
+	query := fmt.Sprintf(`SELECT %s, pickup_lat, pickup_lng, distance_km FROM (
+			SELECT j.*, o.origin_lat AS pickup_lat, o.origin_lng AS pickup_lng, %s AS effective_priority,
+				2 * 6371.0 * ASIN(LEAST(1, SQRT(
+					POWER(SIN(RADIANS(o.origin_lat - $2) / 2), 2) +
+					COS(RADIANS($2)) * COS(RADIANS(o.origin_lat)) * POWER(SIN(RADIANS(o.origin_lng - $3) / 2), 2)
+				))) AS distance_km
+			FROM jobs j JOIN orders o ON o.id = j.order_id
+			WHERE j.status = $1 AND o.origin_lat BETWEEN $4 AND $5 AND o.origin_lng BETWEEN $6 AND $7
+		) open_jobs
+		WHERE distance_km <= $8
+		ORDER BY effective_priority DESC, distance_km ASC, created_at ASC, id ASC LIMIT $%d`, columns, effective, len(args))
+	var jobs []*OpenJob
+	if err := sqlx.SelectContext(ctx, ext, &jobs, query, args...); err != nil {
 		return nil, err
 	}
 	return jobs, nil
 }
 
+// --------------------------------------------------------------
+// CountOpen counts open jobs at minPriority or above.
+func (r *repo) CountOpen(ctx context.Context, ext sqlx.ExtContext, minPriority int) (int, error) {
+	const query = `SELECT COUNT(*) FROM jobs WHERE status = $1 AND priority >= $2`
+	var n int
+	if err := sqlx.GetContext(ctx, ext, &n, query, StatusOpen, minPriority); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
 // --------------------------------------------------------------
 func (r *repo) GetByOrderID(ctx context.Context, ext sqlx.ExtContext, orderID string) (*Job, error) {
 	var j Job
@@ -128,9 +256,36 @@ func (r *repo) GetByOrderIDForUpdate(ctx context.Context, ext sqlx.ExtContext, o
 	return &j, nil
 }
 
+// --------------------------------------------------------------
+// GetLeasedByDroneForUpdate returns the job the drone holds under a lease,
+// i.e. has reserved but not yet picked up.
+func (r *repo) GetLeasedByDroneForUpdate(ctx context.Context, ext sqlx.ExtContext, droneID string) (*Job, error) {
+	var j Job
+	query := fmt.Sprintf(`SELECT %s FROM jobs
+		WHERE reserved_by_drone_id = $1 AND status = 'RESERVED' AND lease_expires_at IS NOT NULL
+		LIMIT 1 FOR UPDATE`, columns)
+	if err := sqlx.GetContext(ctx, ext, &j, query, droneID); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// --------------------------------------------------------------
+// ListExpiredLeases returns the IDs of reserved jobs whose lease ran out
+// before now, oldest deadline first.
+func (r *repo) ListExpiredLeases(ctx context.Context, ext sqlx.ExtContext, now time.Time, limit int) ([]string, error) {
+	const query = `SELECT id FROM jobs WHERE status = 'RESERVED' AND lease_expires_at < $1
+		ORDER BY lease_expires_at LIMIT $2`
+	var ids []string
+	if err := sqlx.SelectContext(ctx, ext, &ids, query, now, limit); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 // --------------------------------------------------------------
 func (r *repo) CancelByJobID(ctx context.Context, ext sqlx.ExtContext, id string) error {
-	const query = `UPDATE jobs SET status = 'CANCELLED', reserved_by_drone_id = NULL, updated_at = NOW()
+	const query = `UPDATE jobs SET status = 'CANCELLED', reserved_by_drone_id = NULL, updated_at = NOW(), version = version + 1
 		WHERE id = $1 AND status NOT IN ('COMPLETED', 'CANCELLED')`
 	res, err := ext.ExecContext(ctx, query, id)
 	if err != nil {
@@ -148,7 +303,7 @@ func (r *repo) CancelByJobID(ctx context.Context, ext sqlx.ExtContext, id string
 
 // --------------------------------------------------------------
 func (r *repo) CancelByOrderID(ctx context.Context, ext sqlx.ExtContext, orderID string) error {
-	const query = `UPDATE jobs SET status = 'CANCELLED', reserved_by_drone_id = NULL, updated_at = NOW()
+	const query = `UPDATE jobs SET status = 'CANCELLED', reserved_by_drone_id = NULL, updated_at = NOW(), version = version + 1
 		WHERE order_id = $1 AND status NOT IN ('COMPLETED', 'CANCELLED')`
 	res, err := ext.ExecContext(ctx, query, orderID)
 	if err != nil {
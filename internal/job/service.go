@@ -2,8 +2,11 @@ package job
 
 import (
 	"context"
+	"time"
 
+	"drone-delivery/internal/common"
 	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -12,22 +15,29 @@ type Service interface {
 	CreateJob(ctx context.Context, orderID string) error
 	GetJob(ctx context.Context, jobID string) (*Job, error)
 	GetByOrderID(ctx context.Context, orderID string) (*Job, error)
-	ListOpenJobs(ctx context.Context) ([]*Job, error)
+	ListOpenJobs(ctx context.Context, after *listing.Cursor, limit int) ([]*Job, string, error)
+	ListOpenJobsByDistance(ctx context.Context, from common.Location, limit int) ([]*OpenJob, error)
+	CountOpenAhead(ctx context.Context, priority int) (int, error)
 	ReserveJob(ctx context.Context, jobID, droneID string) (*Job, error)
 	CompleteJob(ctx context.Context, jobID string) error
 	CancelJob(ctx context.Context, jobID string) error
 	CancelJobByOrderID(ctx context.Context, orderID string) error
-	ListJobs(ctx context.Context, status *Status, page, limit int) ([]*Job, int, error)
+	ListJobs(ctx context.Context, spec listing.Spec) ([]*Job, string, error)
+	CountJobs(ctx context.Context, spec listing.Spec) (int, error)
 	CreateJobWithTx(ctx context.Context, tx sqlx.ExtContext, orderID string) error
 }
 
 type service struct {
-	db   *sqlx.DB
-	repo Repository
+	db    *sqlx.DB
+	repo  Repository
+	queue QueuePolicy
+	// searchRadiusKM bounds ListOpenJobsByDistance; pickups further from
+	// the drone are not listed.
+	searchRadiusKM float64
 }
 
-func NewService(repo Repository, db *sqlx.DB) Service {
-	return &service{db: db, repo: repo}
+func NewService(repo Repository, db *sqlx.DB, queue QueuePolicy, searchRadiusKM float64) Service {
+	return &service{db: db, repo: repo, queue: queue, searchRadiusKM: searchRadiusKM}
 }
 
 // --------------------------------------------------------------
@@ -52,8 +62,45 @@ func (s *service) GetJob(ctx context.Context, jobID string) (*Job, error) {
 }
 
 // --------------------------------------------------------------
-func (s *service) ListOpenJobs(ctx context.Context) ([]*Job, error) {
-	return s.repo.ListByStatus(ctx, s.db, StatusOpen)
+// ListOpenJobs returns a page of open jobs in dispatch order: highest
+// effective priority first, then oldest first.
+func (s *service) ListOpenJobs(ctx context.Context, after *listing.Cursor, limit int) ([]*Job, string, error) {
+	page, err := s.queue.PageFor(after, limit, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+	jobs, err := s.repo.ListOpenPage(ctx, s.db, page)
+	if err != nil {
+		return nil, "", domainerrors.NewInternal("failed to list open jobs", err)
+	}
+	jobs, next := s.queue.Next(page, jobs)
+	return jobs, next, nil
+}
+
+// --------------------------------------------------------------
+// ListOpenJobsByDistance returns limit open jobs out to the search radius
+// from from: the highest effective priority first, as in ListOpenJobs, and
+// the nearest first among equal priorities.
+func (s *service) ListOpenJobsByDistance(ctx context.Context, from common.Location, limit int) ([]*OpenJob, error) {
+	jobs, err := s.repo.ListOpenNearest(ctx, s.db, s.queue.Nearby(from, s.searchRadiusKM, limit, time.Now()))
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list open jobs", err)
+	}
+	if jobs == nil {
+		jobs = []*OpenJob{}
+	}
+	return jobs, nil
+}
+
+// --------------------------------------------------------------
+// CountOpenAhead counts the open jobs a new job at priority would queue
+// behind. Aging is ignored, so this is an estimate.
+func (s *service) CountOpenAhead(ctx context.Context, priority int) (int, error) {
+	n, err := s.repo.CountOpen(ctx, s.db, priority)
+	if err != nil {
+		return 0, domainerrors.NewInternal("failed to count open jobs", err)
+	}
+	return n, nil
 }
 
 // --------------------------------------------------------------
@@ -103,6 +150,11 @@ func (s *service) GetByOrderID(ctx context.Context, orderID string) (*Job, error
 }
 
 // --------------------------------------------------------------
-func (s *service) ListJobs(ctx context.Context, status *Status, page, limit int) ([]*Job, int, error) {
-	return s.repo.ListAll(ctx, s.db, status, page, limit)
+func (s *service) ListJobs(ctx context.Context, spec listing.Spec) ([]*Job, string, error) {
+	return s.repo.List(ctx, s.db, spec)
+}
+
+// --------------------------------------------------------------
+func (s *service) CountJobs(ctx context.Context, spec listing.Spec) (int, error) {
+	return s.repo.Count(ctx, s.db, spec)
 }
@@ -11,6 +11,8 @@ import (
 type Claims struct {
 	Sub  string `json:"sub"`
 	Role string `json:"role"`
+	// Tenant groups principals that share rate limits; optional.
+	Tenant string `json:"tenant,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -27,10 +29,16 @@ func NewService(secret string, expiry time.Duration) *Service {
 }
 
 func (s *Service) GenerateToken(name, role string) (string, error) {
+	return s.GenerateTenantToken(name, role, "")
+}
+
+// GenerateTenantToken is GenerateToken for a principal belonging to tenant.
+func (s *Service) GenerateTenantToken(name, role, tenant string) (string, error) {
 	now := time.Now()
 	claims := Claims{
-		Sub:  name,
-		Role: role,
+		Sub:    name,
+		Role:   role,
+		Tenant: tenant,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   name,
 			IssuedAt:  jwt.NewNumericDate(now),
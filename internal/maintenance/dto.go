@@ -0,0 +1,16 @@
+package maintenance
+
+type ScheduleRequest struct {
+	MaxFlightHours float64 `json:"max_flight_hours" binding:"gte=0"`
+	MaxFlightKM    float64 `json:"max_flight_km" binding:"gte=0"`
+	MaxCycles      int     `json:"max_cycles" binding:"gte=0"`
+}
+
+type OpenWorkOrderRequest struct {
+	Kind        Kind   `json:"kind" binding:"required"`
+	Description string `json:"description"`
+}
+
+type CompleteWorkOrderRequest struct {
+	Resolution string `json:"resolution"`
+}
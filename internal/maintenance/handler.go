@@ -0,0 +1,104 @@
+package maintenance
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// --------------------------------------------------------------
+func (h *Handler) ListSchedules(c *gin.Context) {
+	schedules, err := h.service.ListSchedules(c.Request.Context())
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) SetSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	sched, err := h.service.SetSchedule(c.Request.Context(), c.Param("model"), req)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule": sched})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) ListWorkOrders(c *gin.Context) {
+	var droneID *string
+	if d := c.Query("drone_id"); d != "" {
+		droneID = &d
+	}
+	var status *Status
+	if s := c.Query("status"); s != "" {
+		st := Status(s)
+		status = &st
+	}
+
+	orders, err := h.service.ListWorkOrders(c.Request.Context(), droneID, status)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"work_orders": orders})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) OpenWorkOrder(c *gin.Context) {
+	var req OpenWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	w, err := h.service.OpenWorkOrder(c.Request.Context(), c.Param("id"), req.Kind, req.Description, c.GetString("sub"))
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"work_order": w})
+}
+
+// --------------------------------------------------------------
+func (h *Handler) CompleteWorkOrder(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid work order id"}})
+		return
+	}
+
+	var req CompleteWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	w, err := h.service.CompleteWorkOrder(c.Request.Context(), id, c.GetString("sub"), req.Resolution)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"work_order": w})
+}
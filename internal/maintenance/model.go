@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Kind string
+
+const (
+	KindRoutine    Kind = "ROUTINE"
+	KindInspection Kind = "INSPECTION"
+	KindRepair     Kind = "REPAIR"
+)
+
+func (k Kind) Valid() bool {
+	return k == KindRoutine || k == KindInspection || k == KindRepair
+}
+
+type Status string
+
+const (
+	StatusOpen      Status = "OPEN"
+	StatusCompleted Status = "COMPLETED"
+)
+
+// SystemActor is recorded as the author of work orders the platform opens
+// or closes on its own.
+const SystemActor = "system"
+
+// Schedule is the service interval for one drone model. A zero limit is not
+// enforced.
+type Schedule struct {
+	Model          string    `db:"model" json:"model"`
+	MaxFlightHours float64   `db:"max_flight_hours" json:"max_flight_hours"`
+	MaxFlightKM    float64   `db:"max_flight_km" json:"max_flight_km"`
+	MaxCycles      int       `db:"max_cycles" json:"max_cycles"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// IsDue reports whether the drone has crossed any limit since its last service.
+func (s Schedule) IsDue(d *drone.Drone) bool {
+	if s.MaxFlightHours > 0 && d.HoursSinceService >= s.MaxFlightHours {
+		return true
+	}
+	if s.MaxFlightKM > 0 && d.KMSinceService >= s.MaxFlightKM {
+		return true
+	}
+	return s.MaxCycles > 0 && d.CyclesSinceService >= s.MaxCycles
+}
+
+type WorkOrder struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	DroneID     string     `db:"drone_id" json:"drone_id"`
+	Kind        Kind       `db:"kind" json:"kind"`
+	Status      Status     `db:"status" json:"status"`
+	Description string     `db:"description" json:"description"`
+	Resolution  string     `db:"resolution" json:"resolution"`
+	OpenedBy    string     `db:"opened_by" json:"opened_by"`
+	CompletedBy *string    `db:"completed_by" json:"completed_by,omitempty"`
+	OpenedAt    time.Time  `db:"opened_at" json:"opened_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+func NewWorkOrder(droneID string, kind Kind, description, openedBy string) *WorkOrder {
+	return &WorkOrder{
+		ID:          uuid.New(),
+		DroneID:     droneID,
+		Kind:        kind,
+		Status:      StatusOpen,
+		Description: description,
+		OpenedBy:    openedBy,
+		OpenedAt:    time.Now(),
+	}
+}
+
+func (w *WorkOrder) Complete(completedBy, resolution string) error {
+	if w.Status != StatusOpen {
+		return domainerrors.WorkOrderInvalidTransition(string(w.Status), string(StatusCompleted))
+	}
+	now := time.Now()
+	w.Status = StatusCompleted
+	w.Resolution = resolution
+	w.CompletedBy = &completedBy
+	w.CompletedAt = &now
+	return nil
+}
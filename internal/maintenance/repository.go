@@ -0,0 +1,138 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const scheduleColumns = `model, max_flight_hours, max_flight_km, max_cycles, updated_at`
+
+const workOrderColumns = `id, drone_id, kind, status, description, resolution, opened_by, completed_by, opened_at, completed_at`
+
+type Repository interface {
+	ScheduleFor(ctx context.Context, ext sqlx.ExtContext, model string) (Schedule, error)
+	UpsertSchedule(ctx context.Context, ext sqlx.ExtContext, s *Schedule) error
+	ListSchedules(ctx context.Context, ext sqlx.ExtContext) ([]*Schedule, error)
+	CreateWorkOrder(ctx context.Context, ext sqlx.ExtContext, w *WorkOrder) error
+	GetWorkOrderForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*WorkOrder, error)
+	GetOpenRepairForUpdate(ctx context.Context, ext sqlx.ExtContext, droneID string) (*WorkOrder, error)
+	UpdateWorkOrder(ctx context.Context, ext sqlx.ExtContext, w *WorkOrder) error
+	ListWorkOrders(ctx context.Context, ext sqlx.ExtContext, droneID *string, status *Status) ([]*WorkOrder, error)
+}
+
+type repo struct {
+	defaultSchedule Schedule
+}
+
+// NewRepository returns a repository that falls back to defaultSchedule for
+// models without a stored schedule.
+func NewRepository(defaultSchedule Schedule) Repository {
+	return &repo{defaultSchedule: defaultSchedule}
+}
+
+// --------------------------------------------------------------
+func (r *repo) ScheduleFor(ctx context.Context, ext sqlx.ExtContext, model string) (Schedule, error) {
+	var s Schedule
+	query := fmt.Sprintf(`SELECT %s FROM maintenance_schedules WHERE model = $1`, scheduleColumns)
+	err := sqlx.GetContext(ctx, ext, &s, query, model)
+	if errors.Is(err, sql.ErrNoRows) {
+		def := r.defaultSchedule
+		def.Model = model
+		return def, nil
+	}
+	if err != nil {
+		return Schedule{}, err
+	}
+	return s, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) UpsertSchedule(ctx context.Context, ext sqlx.ExtContext, s *Schedule) error {
+	const query = `INSERT INTO maintenance_schedules (model, max_flight_hours, max_flight_km, max_cycles, updated_at)
+		VALUES (:model, :max_flight_hours, :max_flight_km, :max_cycles, :updated_at)
+		ON CONFLICT (model) DO UPDATE SET
+			max_flight_hours = EXCLUDED.max_flight_hours,
+			max_flight_km = EXCLUDED.max_flight_km,
+			max_cycles = EXCLUDED.max_cycles,
+			updated_at = EXCLUDED.updated_at`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, s)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) ListSchedules(ctx context.Context, ext sqlx.ExtContext) ([]*Schedule, error) {
+	var schedules []*Schedule
+	query := fmt.Sprintf(`SELECT %s FROM maintenance_schedules ORDER BY model ASC`, scheduleColumns)
+	if err := sqlx.SelectContext(ctx, ext, &schedules, query); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) CreateWorkOrder(ctx context.Context, ext sqlx.ExtContext, w *WorkOrder) error {
+	const query = `INSERT INTO maintenance_work_orders (id, drone_id, kind, status, description, resolution, opened_by, completed_by, opened_at, completed_at)
+		VALUES (:id, :drone_id, :kind, :status, :description, :resolution, :opened_by, :completed_by, :opened_at, :completed_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, w)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetWorkOrderForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*WorkOrder, error) {
+	var w WorkOrder
+	query := fmt.Sprintf(`SELECT %s FROM maintenance_work_orders WHERE id = $1 FOR UPDATE`, workOrderColumns)
+	if err := sqlx.GetContext(ctx, ext, &w, query, id); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetOpenRepairForUpdate(ctx context.Context, ext sqlx.ExtContext, droneID string) (*WorkOrder, error) {
+	var w WorkOrder
+	query := fmt.Sprintf(`SELECT %s FROM maintenance_work_orders
+		WHERE drone_id = $1 AND kind = 'REPAIR' AND status = 'OPEN'
+		ORDER BY opened_at DESC LIMIT 1 FOR UPDATE`, workOrderColumns)
+	if err := sqlx.GetContext(ctx, ext, &w, query, droneID); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) UpdateWorkOrder(ctx context.Context, ext sqlx.ExtContext, w *WorkOrder) error {
+	const query = `UPDATE maintenance_work_orders SET status = :status, resolution = :resolution,
+		completed_by = :completed_by, completed_at = :completed_at WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, w)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) ListWorkOrders(ctx context.Context, ext sqlx.ExtContext, droneID *string, status *Status) ([]*WorkOrder, error) {
+	args := []any{}
+	where := ""
+	if droneID != nil {
+		args = append(args, *droneID)
+		where = fmt.Sprintf(" WHERE drone_id = $%d", len(args))
+	}
+	if status != nil {
+		args = append(args, *status)
+		if where == "" {
+			where = fmt.Sprintf(" WHERE status = $%d", len(args))
+		} else {
+			where += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var orders []*WorkOrder
+	query := fmt.Sprintf(`SELECT %s FROM maintenance_work_orders%s ORDER BY opened_at DESC`, workOrderColumns, where)
+	if err := sqlx.SelectContext(ctx, ext, &orders, query, args...); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
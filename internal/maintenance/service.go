@@ -0,0 +1,170 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Service interface {
+	ListSchedules(ctx context.Context) ([]*Schedule, error)
+	SetSchedule(ctx context.Context, model string, req ScheduleRequest) (*Schedule, error)
+	ListWorkOrders(ctx context.Context, droneID *string, status *Status) ([]*WorkOrder, error)
+	OpenWorkOrder(ctx context.Context, droneID string, kind Kind, description, openedBy string) (*WorkOrder, error)
+	CompleteWorkOrder(ctx context.Context, id uuid.UUID, completedBy, resolution string) (*WorkOrder, error)
+	MarkFixed(ctx context.Context, droneID, completedBy string, ifMatch *int64) (*drone.Drone, error)
+}
+
+type service struct {
+	db        *sqlx.DB
+	repo      Repository
+	droneRepo drone.Repository
+	index     drone.LocationIndex
+}
+
+func NewService(db *sqlx.DB, repo Repository, droneRepo drone.Repository, index drone.LocationIndex) Service {
+	return &service{db: db, repo: repo, droneRepo: droneRepo, index: index}
+}
+
+// --------------------------------------------------------------
+func (s *service) ListSchedules(ctx context.Context) ([]*Schedule, error) {
+	return s.repo.ListSchedules(ctx, s.db)
+}
+
+// --------------------------------------------------------------
+func (s *service) SetSchedule(ctx context.Context, model string, req ScheduleRequest) (*Schedule, error) {
+	sched := &Schedule{
+		Model:          model,
+		MaxFlightHours: req.MaxFlightHours,
+		MaxFlightKM:    req.MaxFlightKM,
+		MaxCycles:      req.MaxCycles,
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.repo.UpsertSchedule(ctx, s.db, sched); err != nil {
+		return nil, domainerrors.NewInternal("failed to save maintenance schedule", err)
+	}
+	return sched, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) ListWorkOrders(ctx context.Context, droneID *string, status *Status) ([]*WorkOrder, error) {
+	return s.repo.ListWorkOrders(ctx, s.db, droneID, status)
+}
+
+// --------------------------------------------------------------
+func (s *service) OpenWorkOrder(ctx context.Context, droneID string, kind Kind, description, openedBy string) (*WorkOrder, error) {
+	if !kind.Valid() {
+		return nil, domainerrors.NewValidation("kind must be ROUTINE, INSPECTION or REPAIR")
+	}
+	if _, err := s.droneRepo.GetByID(ctx, s.db, droneID); err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+
+	w := NewWorkOrder(droneID, kind, description, openedBy)
+	if err := s.repo.CreateWorkOrder(ctx, s.db, w); err != nil {
+		return nil, domainerrors.NewInternal("failed to open work order", err)
+	}
+	return w, nil
+}
+
+// --------------------------------------------------------------
+// CompleteWorkOrder closes the work order and applies its effect on the
+// drone: routine service resets the counters and lifts the maintenance
+// lockout, a repair returns a broken drone to IDLE.
+func (s *service) CompleteWorkOrder(ctx context.Context, id uuid.UUID, completedBy, resolution string) (*WorkOrder, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	w, err := s.repo.GetWorkOrderForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, domainerrors.WorkOrderNotFound(id.String())
+	}
+	if err := w.Complete(completedBy, resolution); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateWorkOrder(ctx, tx, w); err != nil {
+		return nil, domainerrors.NewInternal("failed to complete work order", err)
+	}
+
+	d, err := s.droneRepo.GetByIDForUpdate(ctx, tx, w.DroneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(w.DroneID)
+	}
+	switch w.Kind {
+	case KindRoutine:
+		d.CompleteMaintenance()
+		if err := s.droneRepo.UpdateUsage(ctx, tx, d); err != nil {
+			return nil, domainerrors.NewInternal("failed to reset drone counters", err)
+		}
+	case KindRepair:
+		if d.Status == drone.StatusBroken {
+			if err := d.MarkFixed(); err != nil {
+				return nil, err
+			}
+			if err := s.droneRepo.Update(ctx, tx, d); err != nil {
+				return nil, domainerrors.NewInternal("failed to update drone", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, s.index, d)
+	return w, nil
+}
+
+// --------------------------------------------------------------
+// MarkFixed returns a broken drone to IDLE and closes the repair work order
+// opened when it broke, if there is one. With ifMatch it is refused unless
+// the drone is still at that version.
+func (s *service) MarkFixed(ctx context.Context, droneID, completedBy string, ifMatch *int64) (*drone.Drone, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	d, err := s.droneRepo.GetByIDForUpdate(ctx, tx, droneID)
+	if err != nil {
+		return nil, domainerrors.DroneNotFound(droneID)
+	}
+	if err := d.CheckVersion(ifMatch); err != nil {
+		return nil, err
+	}
+	if err := d.MarkFixed(); err != nil {
+		return nil, err
+	}
+	if err := s.droneRepo.Update(ctx, tx, d); err != nil {
+		return nil, domainerrors.NewInternal("failed to update drone", err)
+	}
+
+	w, err := s.repo.GetOpenRepairForUpdate(ctx, tx, droneID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, domainerrors.NewInternal("failed to load repair work order", err)
+	}
+	if w != nil {
+		if err := w.Complete(completedBy, "marked fixed"); err != nil {
+			return nil, err
+		}
+		if err := s.repo.UpdateWorkOrder(ctx, tx, w); err != nil {
+			return nil, domainerrors.NewInternal("failed to complete work order", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
+	drone.SyncIndex(ctx, s.index, d)
+	return d, nil
+}
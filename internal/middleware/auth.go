@@ -48,6 +48,9 @@ func Auth(jwtService *jwt.Service) gin.HandlerFunc {
 
 		c.Set("sub", claims.Sub)
 		c.Set("role", claims.Role)
+		if claims.Tenant != "" {
+			c.Set("tenant", claims.Tenant)
+		}
 		c.Next()
 	}
 }
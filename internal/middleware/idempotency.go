@@ -3,17 +3,44 @@ package middleware
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"drone-delivery/internal/pkg/apperrors"
 )
 
+// idempotencyStore holds one record per user and key. Claim stores claim
+// unless the key is held and otherwise returns the holder; Extend, Complete
+// and Release act only while claim still holds the key.
 type idempotencyStore interface {
-	Check(ctx context.Context, userID, key string) ([]byte, bool, error)
-	Set(ctx context.Context, userID, key string, response []byte) error
+	Claim(ctx context.Context, userID, key string, claim []byte) ([]byte, bool, error)
+	Extend(ctx context.Context, userID, key string, claim []byte) error
+	Complete(ctx context.Context, userID, key string, claim, record []byte) error
+	Release(ctx context.Context, userID, key string, claim []byte) error
+}
+
+// idempotencyRecord is what a key holds. It is in flight, with a Token
+// unique to the claiming request, until the response is recorded.
+type idempotencyRecord struct {
+	Token       string      `json:"token,omitempty"`
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+func (r *idempotencyRecord) inFlight() bool {
+	return r.Status == 0
 }
 
 // responseRecorder captures the response body so we can store it.
@@ -27,7 +54,17 @@ func (r *responseRecorder) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
-func Idempotency(store idempotencyStore) gin.HandlerFunc {
+// Idempotency makes a request carrying an Idempotency-Key run at most once
+// per caller and key. The first request claims the key; a duplicate that
+// arrives while it runs gets a 409, and one that arrives after it succeeded
+// gets the recorded status, headers and body back. Reusing a key for a
+// different request — another method, path, query or body — is a 422.
+// Failed responses aren't recorded, so the key can be retried. Bodies over
+// maxBodyBytes are refused with a 413, since they are read whole to
+// fingerprint them. The claim expires after claimTTL in the store and is
+// renewed while the handler runs, so a slow request isn't run twice. Store
+// errors fail open.
+func Idempotency(store idempotencyStore, maxBodyBytes int64, claimTTL time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		key := c.GetHeader("Idempotency-Key")
 		if key == "" {
@@ -38,8 +75,24 @@ func Idempotency(store idempotencyStore) gin.HandlerFunc {
 		userID := c.GetString("sub")
 		ctx := c.Request.Context()
 
-		// Check for a cached response.
-		cached, found, err := store.Check(ctx, userID, key)
+		fingerprint, err := fingerprintRequest(c, maxBodyBytes)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortIdempotency(c, http.StatusRequestEntityTooLarge, "VALIDATION", "request body is too large")
+				return
+			}
+			abortIdempotency(c, http.StatusBadRequest, "VALIDATION", "failed to read request body")
+			return
+		}
+
+		claim, err := json.Marshal(idempotencyRecord{Token: newClaimToken(), Fingerprint: fingerprint})
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency claim failed", slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+		held, claimed, err := store.Claim(ctx, userID, key, claim)
 		if err != nil {
 			slog.ErrorContext(ctx, "idempotency check failed",
 				slog.String("error", err.Error()),
@@ -49,27 +102,166 @@ func Idempotency(store idempotencyStore) gin.HandlerFunc {
 			return
 		}
 
-		if found {
-			c.Data(http.StatusOK, "application/json", cached)
-			c.Abort()
+		if !claimed {
+			var rec idempotencyRecord
+			if err := json.Unmarshal(held, &rec); err != nil {
+				slog.ErrorContext(ctx, "unreadable idempotency record",
+					slog.String("error", err.Error()),
+				)
+				c.Next()
+				return
+			}
+			switch {
+			case rec.Fingerprint != fingerprint:
+				abortIdempotency(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+					"Idempotency-Key was already used for a different request")
+			case rec.inFlight():
+				c.Header("Retry-After", "1")
+				abortIdempotency(c, http.StatusConflict, "REQUEST_IN_PROGRESS",
+					"a request with this Idempotency-Key is still being processed")
+			default:
+				replay(c, &rec)
+			}
 			return
 		}
 
+		// The claim is released unless the response is recorded, including
+		// when the handler panics. The request may be gone by then.
+		storeCtx := context.WithoutCancel(ctx)
+		recorded := false
+		defer func() {
+			if recorded {
+				return
+			}
+			if err := store.Release(storeCtx, userID, key, claim); err != nil {
+				slog.ErrorContext(ctx, "idempotency release failed",
+					slog.String("error", err.Error()),
+				)
+			}
+		}()
+
+		stopHolding := holdClaim(storeCtx, store, userID, key, claim, claimTTL)
+		defer stopHolding()
+
 		// Record the response body.
 		rec := &responseRecorder{body: &bytes.Buffer{}, ResponseWriter: c.Writer}
 		c.Writer = rec
 
 		c.Next()
+		stopHolding()
 
 		// Only cache successful responses.
-		if c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
-			if err := store.Set(ctx, userID, key, rec.body.Bytes()); err != nil {
-				slog.ErrorContext(ctx, "idempotency store failed",
-					slog.String("error", err.Error()),
-				)
+		status := c.Writer.Status()
+		if status < 200 || status >= 300 {
+			return
+		}
+		record, err := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      recordedHeader(c.Writer.Header()),
+			Body:        rec.body.Bytes(),
+		})
+		if err == nil {
+			err = store.Complete(storeCtx, userID, key, claim, record)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency store failed",
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+		recorded = true
+	}
+}
+
+// holdClaim renews the claim every third of claimTTL until the returned
+// func is called; calling it again does nothing.
+func holdClaim(ctx context.Context, store idempotencyStore, userID, key string, claim []byte, claimTTL time.Duration) func() {
+	if claimTTL <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		t := time.NewTicker(claimTTL / 3)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				if err := store.Extend(ctx, userID, key, claim); err != nil {
+					slog.ErrorContext(ctx, "idempotency extend failed",
+						slog.String("error", err.Error()),
+					)
+				}
 			}
 		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// fingerprintRequest hashes the method, path, query and body, and puts the
+// body back for the handler.
+func fingerprintRequest(c *gin.Context, maxBodyBytes int64) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+		if err != nil {
+			return "", err
+		}
+		h.Write(body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// perRequestHeaders describe the request that produced a response, not
+// the response, so they are not recorded: a replay carries its own.
+var perRequestHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Date"}
+
+// recordedHeader is the part of h that is replayed with the response.
+func recordedHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range perRequestHeaders {
+		out.Del(name)
+	}
+	return out
+}
+
+// replay writes a recorded response again, marked as a replay. Recorded
+// headers replace any the replaying request has set.
+func replay(c *gin.Context, rec *idempotencyRecord) {
+	for name, values := range recordedHeader(rec.Header) {
+		c.Writer.Header()[name] = values
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Status(rec.Status)
+	c.Writer.Write(rec.Body)
+	c.Abort()
+}
+
+func abortIdempotency(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, apperrors.ErrorResponse{
+		Error: apperrors.ErrorBody{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
+func newClaimToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
 // RequireIdempotencyKey rejects requests without an Idempotency-Key header.
@@ -3,36 +3,61 @@ package middleware
 import (
 	"context"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"drone-delivery/internal/pkg/apperrors"
+	"drone-delivery/internal/pkg/ratelimit"
 )
 
 type rateLimiter interface {
-	Allow(ctx context.Context, ip string) (bool, error)
+	Take(ctx context.Context, key string, l ratelimit.Limit) (ratelimit.Decision, error)
 }
 
-func RateLimit(limiter rateLimiter) gin.HandlerFunc {
+// RateLimit charges each request to a token bucket chosen by policy: the
+// caller's IP, JWT subject, role or tenant. It must run after Auth for any
+// key but the IP; a request without the claim falls back to its subject and
+// then its IP. Every response carries RateLimit-Limit, -Remaining, -Reset
+// and -Policy headers, and a refused one a 429 with Retry-After. Limiter
+// errors fail open — wrap the limiter in a ratelimit.Fallback to keep
+// limiting while the store is down.
+func RateLimit(limiter rateLimiter, policy ratelimit.Policy) gin.HandlerFunc {
+	l := policy.Limit
+	policyHeader := strconv.Itoa(l.Requests) + ";w=" + strconv.Itoa(int(l.Window/time.Second))
+	if l.Burst > 0 && l.Burst != l.Requests {
+		policyHeader += ";burst=" + strconv.Itoa(l.Burst)
+	}
+
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
+		who := rateLimitKey(c, policy.Key)
 
-		allowed, err := limiter.Allow(c.Request.Context(), ip)
+		d, err := limiter.Take(c.Request.Context(), policy.Name+":"+who, l)
 		if err != nil {
 			slog.ErrorContext(c.Request.Context(), "rate limiter error",
-				slog.String("ip", ip),
+				slog.String("policy", policy.Name),
+				slog.String("key", who),
 				slog.String("error", err.Error()),
 			)
-			// fail open — don't block requests if Redis is down
 			c.Next()
 			return
 		}
 
-		if !allowed {
+		h := c.Writer.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(d.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+		h.Set("RateLimit-Policy", policyHeader)
+
+		if !d.Allowed {
 			slog.WarnContext(c.Request.Context(), "rate limit exceeded",
-				slog.String("ip", ip),
+				slog.String("policy", policy.Name),
+				slog.String("key", who),
 			)
+			h.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(d.RetryAfter))))
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, apperrors.ErrorResponse{
 				Error: apperrors.ErrorBody{
 					Code:    "RATE_LIMITED",
@@ -45,3 +70,28 @@ func RateLimit(limiter rateLimiter) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// rateLimitKey is who the request is charged to, prefixed with what was
+// used so an IP can never share a bucket with a subject of the same name.
+func rateLimitKey(c *gin.Context, by ratelimit.KeyBy) string {
+	switch by {
+	case ratelimit.KeyTenant:
+		if tenant := c.GetString("tenant"); tenant != "" {
+			return "tenant:" + tenant
+		}
+		fallthrough
+	case ratelimit.KeySub:
+		if sub := c.GetString("sub"); sub != "" {
+			return "sub:" + sub
+		}
+	case ratelimit.KeyRole:
+		if role := c.GetString("role"); role != "" {
+			return "role:" + role
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
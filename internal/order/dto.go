@@ -1,7 +1,9 @@
 package order
 
 import (
+	"drone-delivery/internal/admission"
 	"drone-delivery/internal/common"
+	"drone-delivery/internal/pricing"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,27 +19,76 @@ const (
 	StatusFailed          Status = "FAILED"
 	StatusWithdrawn       Status = "WITHDRAWN"
 	StatusAwaitingHandoff Status = "AWAITING_HANDOFF"
+	StatusWaitlisted      Status = "WAITLISTED"
+)
+
+// ServiceTier is the level of service bought with the order. It sets the
+// starting priority of the order's job.
+type ServiceTier string
+
+const (
+	TierStandard ServiceTier = "STANDARD"
+	TierExpress  ServiceTier = "EXPRESS"
 )
 
 type Order struct {
-	ID              uuid.UUID `db:"id" json:"id"`
-	SubmittedBy     string    `db:"submitted_by" json:"submitted_by"`
-	OriginLat       float64   `db:"origin_lat" json:"origin_lat"`
-	OriginLng       float64   `db:"origin_lng" json:"origin_lng"`
-	DestLat         float64   `db:"dest_lat" json:"dest_lat"`
-	DestLng         float64   `db:"dest_lng" json:"dest_lng"`
-	Status          Status    `db:"status" json:"status"`
-	AssignedDroneID *string   `db:"assigned_drone_id" json:"assigned_drone_id,omitempty"`
-	CreatedAt       time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	ID              uuid.UUID   `db:"id" json:"id"`
+	SubmittedBy     string      `db:"submitted_by" json:"submitted_by"`
+	OriginLat       float64     `db:"origin_lat" json:"origin_lat"`
+	OriginLng       float64     `db:"origin_lng" json:"origin_lng"`
+	DestLat         float64     `db:"dest_lat" json:"dest_lat"`
+	DestLng         float64     `db:"dest_lng" json:"dest_lng"`
+	Status          Status      `db:"status" json:"status"`
+	AssignedDroneID *string     `db:"assigned_drone_id" json:"assigned_drone_id,omitempty"`
+	PayloadKG       float64     `db:"payload_kg" json:"payload_kg"`
+	PriceAmount     int64       `db:"price_amount" json:"price_amount"`
+	PriceCurrency   string      `db:"price_currency" json:"price_currency"`
+	QuoteID         *string     `db:"quote_id" json:"quote_id,omitempty"`
+	ServiceTier     ServiceTier `db:"service_tier" json:"service_tier"`
+	Priority        int         `db:"priority" json:"priority"`
+	// HandedOff is set once a broken drone has given the order up; it then
+	// returns to AWAITING_HANDOFF rather than PENDING when unassigned.
+	HandedOff bool `db:"handed_off" json:"handed_off"`
+
+	// Promised at placement; the actual times are filled in as the order
+	// progresses.
+	PromisedPickupAt   *time.Time `db:"promised_pickup_at" json:"promised_pickup_at,omitempty"`
+	PromisedDeliveryAt *time.Time `db:"promised_delivery_at" json:"promised_delivery_at,omitempty"`
+	// Promised again when a waitlisted order is released for dispatch.
+	RepromisedPickupAt   *time.Time `db:"repromised_pickup_at" json:"repromised_pickup_at,omitempty"`
+	RepromisedDeliveryAt *time.Time `db:"repromised_delivery_at" json:"repromised_delivery_at,omitempty"`
+	AssignedAt           *time.Time `db:"assigned_at" json:"assigned_at,omitempty"`
+	PickedUpAt           *time.Time `db:"picked_up_at" json:"picked_up_at,omitempty"`
+	DeliveredAt          *time.Time `db:"delivered_at" json:"delivered_at,omitempty"`
+
+	// Version counts writes to the row; Update refuses a copy that is behind.
+	Version int64 `db:"version" json:"version"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 type PlaceOrderRequest struct {
+	Origin        common.Location `json:"origin" binding:"required"`
+	Destination   common.Location `json:"destination" binding:"required"`
+	PayloadKG     float64         `json:"payload_kg" binding:"gte=0"`
+	QuoteToken    string          `json:"quote_token"`
+	PaymentMethod string          `json:"payment_method"`
+	ServiceTier   ServiceTier     `json:"service_tier" binding:"omitempty,oneof=STANDARD EXPRESS"`
+}
+
+type QuoteRequest struct {
 	Origin      common.Location `json:"origin" binding:"required"`
 	Destination common.Location `json:"destination" binding:"required"`
+	PayloadKG   float64         `json:"payload_kg" binding:"gte=0"`
 }
 
 type OrderResponse struct {
-	Order *Order `json:"order"`
+	Order   *Order             `json:"order"`
+	Promise *admission.Promise `json:"promise,omitempty"`
+}
+
+type QuoteResponse struct {
+	Quote *pricing.Quote `json:"quote"`
 }
 
 type OrderDetailResponse struct {
@@ -45,3 +96,11 @@ type OrderDetailResponse struct {
 	DroneLocation *common.Location `json:"drone_location,omitempty"`
 	ETAMinutes    *float64         `json:"eta_minutes,omitempty"`
 }
+
+// ExportFilter narrows a bulk export. Nil fields match everything; From and
+// To bound the placement time as [From, To).
+type ExportFilter struct {
+	Status *Status
+	From   *time.Time
+	To     *time.Time
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"net/http"
 
+	"drone-delivery/internal/admission"
 	"drone-delivery/internal/common"
 	"drone-delivery/internal/pkg/apperrors"
+	"drone-delivery/internal/pkg/etag"
+	"drone-delivery/internal/pkg/listing"
+	"drone-delivery/internal/pricing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,7 +17,7 @@ import (
 
 // DeliveryManager avoids importing the delivery package (circular dep prevention).
 type DeliveryManager interface {
-	CreateOrderAndJob(ctx context.Context, o *Order) error
+	CreateOrderAndJob(ctx context.Context, o *Order, paymentMethod string) error
 	CancelOrderAndJob(ctx context.Context, orderID uuid.UUID, submittedBy string) error
 }
 
@@ -22,14 +26,52 @@ type DroneLocator interface {
 	GetDroneLocation(ctx context.Context, droneID string) (*common.Location, error)
 }
 
+// Admission avoids importing the grounding package (circular dep prevention).
+type Admission interface {
+	AdmitOrder(ctx context.Context, origin, destination common.Location) error
+}
+
 type Handler struct {
 	service         Service
 	deliveryService DeliveryManager
 	droneLocator    DroneLocator
+	pricing         pricing.Service
+	admission       Admission
+	weather         common.WeatherProvider
+	capacity        admission.Service
+}
+
+func NewHandler(service Service, deliveryService DeliveryManager, droneLocator DroneLocator, pricingService pricing.Service, admission Admission, weather common.WeatherProvider, capacity admission.Service) *Handler {
+	return &Handler{service: service, deliveryService: deliveryService, droneLocator: droneLocator, pricing: pricingService, admission: admission, weather: weather, capacity: capacity}
 }
 
-func NewHandler(service Service, deliveryService DeliveryManager, droneLocator DroneLocator) *Handler {
-	return &Handler{service: service, deliveryService: deliveryService, droneLocator: droneLocator}
+// -------------------------------------------------------------------------------------------------
+func (h *Handler) QuoteOrder(c *gin.Context) {
+	var req QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	if err := h.service.ValidateLocation(req.Origin, "origin"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+	if err := h.service.ValidateLocation(req.Destination, "destination"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
+		return
+	}
+
+	q, err := h.pricing.Quote(c.Request.Context(), c.GetString("sub"), pricing.Request{
+		Origin:      req.Origin,
+		Destination: req.Destination,
+		PayloadKG:   req.PayloadKG,
+	})
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, QuoteResponse{Quote: q})
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -42,6 +84,8 @@ func (h *Handler) PlaceOrder(c *gin.Context) {
 
 	sub := c.GetString("sub")
 	o := NewOrder(sub, req.Origin, req.Destination)
+	o.PayloadKG = req.PayloadKG
+	o.SetServiceTier(req.ServiceTier)
 
 	if err := h.service.ValidateLocation(req.Origin, "origin"); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": err.Error()}})
@@ -52,11 +96,44 @@ func (h *Handler) PlaceOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.deliveryService.CreateOrderAndJob(c.Request.Context(), o); err != nil {
+	// Under a REJECT-policy grounding the order is refused; otherwise it is
+	// accepted and its job waits for the grounding to lift.
+	if err := h.admission.AdmitOrder(c.Request.Context(), o.Origin(), o.Destination()); err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, OrderResponse{Order: o})
+
+	q, err := h.pricing.Agree(c.Request.Context(), sub, req.QuoteToken, pricing.Request{
+		Origin:      req.Origin,
+		Destination: req.Destination,
+		PayloadKG:   req.PayloadKG,
+	})
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	o.ApplyPrice(q.Amount, q.Currency, q.ID)
+
+	// Promise a delivery window from the current backlog; past the SLA the
+	// order is refused or waitlisted without a job.
+	promise, err := h.capacity.Promise(c.Request.Context(), o.Origin(), o.Destination(), o.Priority)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	o.SetPromise(promise.PickupBy, promise.DeliveryBy)
+	if promise.Waitlisted {
+		if err := o.Waitlist(); err != nil {
+			apperrors.ToHTTPError(c, err)
+			return
+		}
+	}
+
+	if err := h.deliveryService.CreateOrderAndJob(c.Request.Context(), o, req.PaymentMethod); err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, OrderResponse{Order: o, Promise: promise})
 }
 
 // -------------------------------------------------------------------------------------------------
@@ -94,15 +171,17 @@ func (h *Handler) GetOrderDetails(c *gin.Context) {
 	}
 
 	resp := OrderDetailResponse{Order: o}
+	c.Header("ETag", etag.Format(o.Version))
 
 	if o.AssignedDroneID != nil {
 		loc, err := h.droneLocator.GetDroneLocation(ctx, *o.AssignedDroneID)
 		if err == nil && loc != nil {
 			resp.DroneLocation = loc
 			dest := o.Destination()
-			distKM := common.HaversineDistance(*loc, dest)
-			const droneSpeedKMPerMin = 0.5 // ~30 km/h
-			eta := distKM / droneSpeedKMPerMin
+			const droneAirspeedKMH = 30
+			// Without weather the ETA assumes still air
+			w, _ := h.weather.GetWeather(ctx, *loc)
+			eta := common.FlightETAMinutes(*loc, dest, droneAirspeedKMH, w)
 			resp.ETAMinutes = &eta
 		}
 	}
@@ -111,12 +190,20 @@ func (h *Handler) GetOrderDetails(c *gin.Context) {
 }
 
 // -------------------------------------------------------------------------------------------------
+// ListMyOrders pages through the caller's orders, newest first by default.
 func (h *Handler) ListMyOrders(c *gin.Context) {
-	sub := c.GetString("sub")
-	orders, err := h.service.ListMyOrders(c.Request.Context(), sub)
+	spec, err := listing.Parse(c.Request.URL.Query())
 	if err != nil {
 		apperrors.ToHTTPError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"orders": orders})
+	orders, next, err := h.service.ListMyOrders(c.Request.Context(), c.GetString("sub"), spec)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if orders == nil {
+		orders = []*Order{}
+	}
+	c.JSON(http.StatusOK, gin.H{"orders": orders, "next_cursor": next})
 }
@@ -9,12 +9,10 @@ import (
 	domainerrors "drone-delivery/internal/errors"
 )
 
-
 func (s Status) IsTerminal() bool {
 	return s == StatusDelivered || s == StatusFailed || s == StatusWithdrawn
 }
 
-
 func NewOrder(submittedBy string, origin, destination common.Location) *Order {
 	now := time.Now()
 	return &Order{
@@ -25,11 +23,47 @@ func NewOrder(submittedBy string, origin, destination common.Location) *Order {
 		DestLat:     destination.Lat,
 		DestLng:     destination.Lng,
 		Status:      StatusPending,
+		ServiceTier: TierStandard,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 }
 
+// CheckVersion refuses an edit based on a version other than the current
+// one. A nil want accepts any version.
+func (o *Order) CheckVersion(want *int64) error {
+	if want != nil && *want != o.Version {
+		return domainerrors.VersionMismatch("order", o.ID.String(), o.Version)
+	}
+	return nil
+}
+
+// Priority is the dispatch priority a tier starts at.
+func (t ServiceTier) Priority() int {
+	if t == TierExpress {
+		return 10
+	}
+	return 0
+}
+
+// SetServiceTier sets the tier and resets the priority to the tier's.
+// An empty tier is standard.
+func (o *Order) SetServiceTier(t ServiceTier) {
+	if t == "" {
+		t = TierStandard
+	}
+	o.ServiceTier = t
+	o.Priority = t.Priority()
+	o.UpdatedAt = time.Now()
+}
+
+// SetPriority records a priority an admin gave the order's job.
+func (o *Order) SetPriority(p int) {
+	o.Priority = p
+	o.UpdatedAt = time.Now()
+}
+
 func (o *Order) Origin() common.Location {
 	return common.NewLocation(o.OriginLat, o.OriginLng)
 }
@@ -38,8 +72,48 @@ func (o *Order) Destination() common.Location {
 	return common.NewLocation(o.DestLat, o.DestLng)
 }
 
-func (o *Order) Withdraw() error {
+// ApplyPrice records the agreed price on a pending order.
+func (o *Order) ApplyPrice(amount int64, currency, quoteID string) {
+	o.PriceAmount = amount
+	o.PriceCurrency = currency
+	o.QuoteID = &quoteID
+	o.UpdatedAt = time.Now()
+}
+
+// SetPromise records the pickup and delivery times promised to the
+// customer. Either may be nil when no estimate could be made.
+func (o *Order) SetPromise(pickupBy, deliveryBy *time.Time) {
+	o.PromisedPickupAt = pickupBy
+	o.PromisedDeliveryAt = deliveryBy
+	o.UpdatedAt = time.Now()
+}
+
+// Waitlist holds a new order back from dispatch because the fleet can't
+// deliver it within the SLA yet.
+func (o *Order) Waitlist() error {
 	if o.Status != StatusPending {
+		return domainerrors.OrderInvalidTransition(string(o.Status), string(StatusWaitlisted))
+	}
+	o.Status = StatusWaitlisted
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+// Promote releases a waitlisted order for dispatch with a fresh promise,
+// kept apart from the one it was placed with.
+func (o *Order) Promote(pickupBy, deliveryBy *time.Time) error {
+	if o.Status != StatusWaitlisted {
+		return domainerrors.OrderInvalidTransition(string(o.Status), string(StatusPending))
+	}
+	o.Status = StatusPending
+	o.RepromisedPickupAt = pickupBy
+	o.RepromisedDeliveryAt = deliveryBy
+	o.UpdatedAt = time.Now()
+	return nil
+}
+
+func (o *Order) Withdraw() error {
+	if o.Status != StatusPending && o.Status != StatusWaitlisted {
 		return domainerrors.OrderInvalidTransition(string(o.Status), string(StatusWithdrawn))
 	}
 	o.Status = StatusWithdrawn
@@ -51,8 +125,28 @@ func (o *Order) Assign(droneID string) error {
 	if o.Status != StatusPending && o.Status != StatusAwaitingHandoff {
 		return domainerrors.OrderInvalidTransition(string(o.Status), string(StatusAssigned))
 	}
+	now := time.Now()
 	o.Status = StatusAssigned
 	o.AssignedDroneID = &droneID
+	o.AssignedAt = &now
+	o.UpdatedAt = now
+	return nil
+}
+
+// Unassign takes an assigned order away from its drone and puts it back in
+// the pending pool, or back to awaiting handoff if it came from one: the
+// parcel is then no longer at the origin and can't be withdrawn.
+func (o *Order) Unassign() error {
+	next := StatusPending
+	if o.HandedOff {
+		next = StatusAwaitingHandoff
+	}
+	if o.Status != StatusAssigned {
+		return domainerrors.OrderInvalidTransition(string(o.Status), string(next))
+	}
+	o.Status = next
+	o.AssignedDroneID = nil
+	o.AssignedAt = nil
 	o.UpdatedAt = time.Now()
 	return nil
 }
@@ -61,8 +155,10 @@ func (o *Order) MarkPickedUp() error {
 	if o.Status != StatusAssigned {
 		return domainerrors.OrderInvalidTransition(string(o.Status), string(StatusPickedUp))
 	}
+	now := time.Now()
 	o.Status = StatusPickedUp
-	o.UpdatedAt = time.Now()
+	o.PickedUpAt = &now
+	o.UpdatedAt = now
 	return nil
 }
 
@@ -70,9 +166,11 @@ func (o *Order) MarkDelivered() error {
 	if o.Status != StatusPickedUp {
 		return domainerrors.OrderInvalidTransition(string(o.Status), string(StatusDelivered))
 	}
+	now := time.Now()
 	o.Status = StatusDelivered
 	o.AssignedDroneID = nil
-	o.UpdatedAt = time.Now()
+	o.DeliveredAt = &now
+	o.UpdatedAt = now
 	return nil
 }
 
@@ -92,6 +190,7 @@ func (o *Order) AwaitHandoff() error {
 	}
 	o.Status = StatusAwaitingHandoff
 	o.AssignedDroneID = nil
+	o.HandedOff = true
 	o.UpdatedAt = time.Now()
 	return nil
 }
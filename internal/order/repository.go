@@ -3,22 +3,34 @@ package order
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 )
 
-const columns = `id, submitted_by, origin_lat, origin_lng, dest_lat, dest_lng, status, assigned_drone_id, created_at, updated_at`
+const columns = `id, submitted_by, origin_lat, origin_lng, dest_lat, dest_lng, status, assigned_drone_id, payload_kg, price_amount, price_currency, quote_id, service_tier, priority, handed_off,
+	promised_pickup_at, promised_delivery_at, repromised_pickup_at, repromised_delivery_at, assigned_at, picked_up_at, delivered_at, version, created_at, updated_at`
 
 type Repository interface {
-	Create(ctx context.Context, ext sqlx.ExtContext, o *Order) error
+	Create(ctx context.Context, ext sqlx.ExtContext, o *Order) (bool, error)
 	GetByID(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Order, error)
 	GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Order, error)
 	Update(ctx context.Context, ext sqlx.ExtContext, o *Order) error
-	ListBySubmitter(ctx context.Context, ext sqlx.ExtContext, submittedBy string) ([]*Order, error)
-	ListAll(ctx context.Context, ext sqlx.ExtContext, status *Status, page, limit int) ([]*Order, int, error)
+	List(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) ([]*Order, string, error)
+	Count(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) (int, error)
 	GetByDroneID(ctx context.Context, ext sqlx.ExtContext, droneID string) (*Order, error)
 	Cancel(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID, submittedBy string) error
+	ListWaitlisted(ctx context.Context, ext sqlx.ExtContext, limit int) ([]*Order, error)
+	CountWaitlisted(ctx context.Context, ext sqlx.ExtContext) (int, error)
+	AverageServiceTime(ctx context.Context, ext sqlx.ExtContext, sample int) (time.Duration, int, error)
+	ListOpen(ctx context.Context, ext sqlx.ExtContext, after *Order, limit int) ([]*Order, error)
+	Each(ctx context.Context, ext sqlx.ExtContext, f ExportFilter, fn func(*Order) error) error
 }
 
 type repo struct{}
@@ -27,12 +39,22 @@ func NewRepository() Repository {
 	return &repo{}
 }
 
-func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, o *Order) error {
-	const query = `INSERT INTO orders (id, submitted_by, origin_lat, origin_lng, dest_lat, dest_lng, status, assigned_drone_id, created_at, updated_at)
-		VALUES (:id, :submitted_by, :origin_lat, :origin_lng, :dest_lat, :dest_lng, :status, :assigned_drone_id, :created_at, :updated_at)`
+// Create inserts a new order. It reports false if the order's quote has
+// already been used by another order.
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, o *Order) (bool, error) {
+	const query = `INSERT INTO orders (id, submitted_by, origin_lat, origin_lng, dest_lat, dest_lng, status, assigned_drone_id, payload_kg, price_amount, price_currency, quote_id, service_tier, priority, promised_pickup_at, promised_delivery_at, created_at, updated_at)
+		VALUES (:id, :submitted_by, :origin_lat, :origin_lng, :dest_lat, :dest_lng, :status, :assigned_drone_id, :payload_kg, :price_amount, :price_currency, :quote_id, :service_tier, :priority, :promised_pickup_at, :promised_delivery_at, :created_at, :updated_at)
+		ON CONFLICT (quote_id) DO NOTHING`
 
-	_, err := sqlx.NamedExecContext(ctx, ext, query, o)
-	return err
+	res, err := sqlx.NamedExecContext(ctx, ext, query, o)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
 }
 
 func (r *repo) GetByID(ctx context.Context, ext sqlx.ExtContext, id uuid.UUID) (*Order, error) {
@@ -55,55 +77,86 @@ func (r *repo) GetByIDForUpdate(ctx context.Context, ext sqlx.ExtContext, id uui
 	return &o, nil
 }
 
+// Update writes o over the row it was read from and advances its version.
+// If the row has been written since, nothing is written and the error is
+// a StaleWrite.
 func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, o *Order) error {
-	const query = `UPDATE orders SET status = :status, assigned_drone_id = :assigned_drone_id, origin_lat = :origin_lat, origin_lng = :origin_lng, dest_lat = :dest_lat, dest_lng = :dest_lng, updated_at = :updated_at WHERE id = :id`
-	_, err := sqlx.NamedExecContext(ctx, ext, query, o)
-	return err
-}
-
-func (r *repo) ListBySubmitter(ctx context.Context, ext sqlx.ExtContext, submittedBy string) ([]*Order, error) {
-	var orders []*Order
-	query := fmt.Sprintf(`SELECT %s FROM orders WHERE submitted_by = $1 ORDER BY created_at DESC`, columns)
-	err := sqlx.SelectContext(ctx, ext, &orders, query, submittedBy)
+	const query = `UPDATE orders SET status = :status, assigned_drone_id = :assigned_drone_id, origin_lat = :origin_lat, origin_lng = :origin_lng, dest_lat = :dest_lat, dest_lng = :dest_lng, priority = :priority, handed_off = :handed_off, promised_pickup_at = :promised_pickup_at, promised_delivery_at = :promised_delivery_at, repromised_pickup_at = :repromised_pickup_at, repromised_delivery_at = :repromised_delivery_at, assigned_at = :assigned_at, picked_up_at = :picked_up_at, delivered_at = :delivered_at, updated_at = :updated_at, version = version + 1 WHERE id = :id AND version = :version`
+	res, err := sqlx.NamedExecContext(ctx, ext, query, o)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return orders, nil
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domainerrors.StaleWrite("order", o.ID.String())
+	}
+	o.Version++
+	return nil
 }
 
-func (r *repo) ListAll(ctx context.Context, ext sqlx.ExtContext, status *Status, page, limit int) ([]*Order, int, error) {
-	offset := (page - 1) * limit
-	args := []any{}
-	argIdx := 1
+// listSchema maps list filters and sorts onto orders; the bounding box
+// applies to the pickup.
+var listSchema = listing.Schema{
+	Name:        "orders",
+	ID:          "id",
+	Status:      "status",
+	CreatedAt:   "created_at",
+	DroneID:     "assigned_drone_id",
+	SubmittedBy: "submitted_by",
+	Lat:         "origin_lat",
+	Lng:         "origin_lng",
+	Sorts: map[string]string{
+		"created_at": "created_at",
+		"updated_at": "updated_at",
+		"priority":   "priority",
+	},
+	DefaultSort: listing.Sort{Field: "created_at", Desc: true},
+}
 
-	where := ""
-	if status != nil {
-		where = fmt.Sprintf(" WHERE status = $%d", argIdx)
-		args = append(args, *status)
-		argIdx++
+// List returns one page of orders and the cursor for the next, or "" on
+// the last page.
+func (r *repo) List(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) ([]*Order, string, error) {
+	q, err := listSchema.Build(spec)
+	if err != nil {
+		return nil, "", err
+	}
+	var orders []*Order
+	query := fmt.Sprintf(`SELECT %s FROM orders%s`, columns, q.Page())
+	if err := sqlx.SelectContext(ctx, ext, &orders, query, q.PageArgs()...); err != nil {
+		return nil, "", err
 	}
+	orders, next := listing.Next(q, orders, sortKeys)
+	return orders, next, nil
+}
 
-	// Total count.
+// Count returns how many orders match spec's filters.
+func (r *repo) Count(ctx context.Context, ext sqlx.ExtContext, spec listing.Spec) (int, error) {
+	q, err := listSchema.Build(spec)
+	if err != nil {
+		return 0, err
+	}
 	var total int
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM orders%s`, where)
-	if err := sqlx.GetContext(ctx, ext, &total, countQuery, args...); err != nil {
-		return nil, 0, err
+	if err := sqlx.GetContext(ctx, ext, &total, `SELECT COUNT(*) FROM orders`+q.Where(), q.Args()...); err != nil {
+		return 0, err
 	}
+	return total, nil
+}
 
-	// Page results.
-	dataQuery := fmt.Sprintf(`SELECT %s FROM orders%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, columns, where, argIdx, argIdx+1)
-	args = append(args, limit, offset)
-
-	var orders []*Order
-	if err := sqlx.SelectContext(ctx, ext, &orders, dataQuery, args...); err != nil {
-		return nil, 0, err
+func sortKeys(o *Order, field string) (string, string) {
+	switch field {
+	case "updated_at":
+		return listing.FormatTime(o.UpdatedAt), o.ID.String()
+	case "priority":
+		return strconv.Itoa(o.Priority), o.ID.String()
 	}
-
-	return orders, total, nil
+	return listing.FormatTime(o.CreatedAt), o.ID.String()
 }
 
 func (r *repo) Cancel(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID, submittedBy string) error {
-	const query = `UPDATE orders SET status = 'CANCELLED', submitted_by = $2, updated_at = NOW()
+	const query = `UPDATE orders SET status = 'CANCELLED', submitted_by = $2, updated_at = NOW(), version = version + 1
 		WHERE id = $1 AND status NOT IN ('COMPLETED', 'CANCELLED')`
 	res, err := ext.ExecContext(ctx, query, orderID, submittedBy)
 	if err != nil {
@@ -128,3 +181,96 @@ func (r *repo) GetByDroneID(ctx context.Context, ext sqlx.ExtContext, droneID st
 	}
 	return &o, nil
 }
+
+// ListWaitlisted returns waitlisted orders, oldest first.
+func (r *repo) ListWaitlisted(ctx context.Context, ext sqlx.ExtContext, limit int) ([]*Order, error) {
+	var orders []*Order
+	query := fmt.Sprintf(`SELECT %s FROM orders WHERE status = $1 ORDER BY created_at ASC LIMIT $2`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &orders, query, StatusWaitlisted, limit); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *repo) CountWaitlisted(ctx context.Context, ext sqlx.ExtContext) (int, error) {
+	var n int
+	if err := sqlx.GetContext(ctx, ext, &n, `SELECT COUNT(*) FROM orders WHERE status = $1`, StatusWaitlisted); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// AverageServiceTime averages assignment-to-delivery time over the last
+// sample delivered orders and reports how many were averaged.
+func (r *repo) AverageServiceTime(ctx context.Context, ext sqlx.ExtContext, sample int) (time.Duration, int, error) {
+	const query = `SELECT COALESCE(EXTRACT(EPOCH FROM AVG(delivered_at - assigned_at)), 0) AS seconds, COUNT(*) AS count
+		FROM (SELECT assigned_at, delivered_at FROM orders
+			WHERE status = 'DELIVERED' AND assigned_at IS NOT NULL AND delivered_at IS NOT NULL
+			ORDER BY delivered_at DESC LIMIT $1) recent`
+	var row struct {
+		Seconds float64 `db:"seconds"`
+		Count   int     `db:"count"`
+	}
+	if err := sqlx.GetContext(ctx, ext, &row, query, sample); err != nil {
+		return 0, 0, err
+	}
+	return time.Duration(row.Seconds * float64(time.Second)), row.Count, nil
+}
+
+// ListOpen returns non-terminal orders, oldest first, starting after the
+// given order (nil for the first page).
+func (r *repo) ListOpen(ctx context.Context, ext sqlx.ExtContext, after *Order, limit int) ([]*Order, error) {
+	afterCreated, afterID := time.Time{}, uuid.Nil
+	if after != nil {
+		afterCreated, afterID = after.CreatedAt, after.ID
+	}
+	var orders []*Order
+	query := fmt.Sprintf(`SELECT %s FROM orders
+		WHERE status NOT IN ($1, $2, $3) AND (created_at, id) > ($4, $5)
+		ORDER BY created_at, id LIMIT $6`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &orders, query,
+		StatusDelivered, StatusFailed, StatusWithdrawn, afterCreated, afterID, limit); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Each streams the orders matching f, oldest first, calling fn for each
+// row as it is read. It stops at fn's first error.
+func (r *repo) Each(ctx context.Context, ext sqlx.ExtContext, f ExportFilter, fn func(*Order) error) error {
+	var conds []string
+	var args []any
+	if f.Status != nil {
+		args = append(args, *f.Status)
+		conds = append(conds, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		conds = append(conds, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	query := fmt.Sprintf(`SELECT %s FROM orders`, columns)
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY created_at, id"
+
+	rows, err := ext.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o Order
+		if err := rows.StructScan(&o); err != nil {
+			return err
+		}
+		if err := fn(&o); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
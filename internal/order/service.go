@@ -2,22 +2,28 @@ package order
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
 	"drone-delivery/internal/common"
 	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/listing"
 )
 
 type Service interface {
 	ValidateLocation(loc common.Location, label string) error
 	GetOrderDetails(ctx context.Context, orderID uuid.UUID, submittedBy string) (*Order, error)
+	GetOrder(ctx context.Context, orderID uuid.UUID) (*Order, error)
 	GetByDroneID(ctx context.Context, droneID string) (*Order, error)
-	ListMyOrders(ctx context.Context, submittedBy string) ([]*Order, error)
+	ListMyOrders(ctx context.Context, submittedBy string, spec listing.Spec) ([]*Order, string, error)
 	AwaitHandoffWithTx(ctx context.Context, tx sqlx.ExtContext, orderID uuid.UUID) error
-	ListAll(ctx context.Context, status *Status, page, limit int) ([]*Order, int, error)
-	AdminUpdateOrder(ctx context.Context, orderID uuid.UUID, origin, destination *common.Location) (*Order, error)
+	List(ctx context.Context, spec listing.Spec) ([]*Order, string, error)
+	Count(ctx context.Context, spec listing.Spec) (int, error)
+	AdminUpdateOrder(ctx context.Context, orderID uuid.UUID, origin, destination *common.Location, ifMatch *int64) (*Order, error)
+	AverageServiceTime(ctx context.Context, sample int) (time.Duration, int, error)
+	CountWaitlisted(ctx context.Context) (int, error)
 }
 
 type service struct {
@@ -61,6 +67,16 @@ func (s *service) GetOrderDetails(ctx context.Context, orderID uuid.UUID, submit
 	return o, nil
 }
 
+// -------------------------------------------------------------------------------------------------
+// GetOrder loads any order, whoever placed it; for admins.
+func (s *service) GetOrder(ctx context.Context, orderID uuid.UUID) (*Order, error) {
+	o, err := s.repo.GetByID(ctx, s.db, orderID)
+	if err != nil {
+		return nil, domainerrors.OrderNotFound(orderID.String())
+	}
+	return o, nil
+}
+
 // -------------------------------------------------------------------------------------------------
 func (s *service) GetByDroneID(ctx context.Context, droneID string) (*Order, error) {
 	o, err := s.repo.GetByDroneID(ctx, s.db, droneID)
@@ -71,21 +87,40 @@ func (s *service) GetByDroneID(ctx context.Context, droneID string) (*Order, err
 }
 
 // -------------------------------------------------------------------------------------------------
-func (s *service) ListMyOrders(ctx context.Context, submittedBy string) ([]*Order, error) {
-	return s.repo.ListBySubmitter(ctx, s.db, submittedBy)
+// ListMyOrders lists submittedBy's own orders; any submitted_by filter in
+// spec is replaced.
+func (s *service) ListMyOrders(ctx context.Context, submittedBy string, spec listing.Spec) ([]*Order, string, error) {
+	spec.SubmittedBy = submittedBy
+	return s.repo.List(ctx, s.db, spec)
 }
 
 // -------------------------------------------------------------------------------------------------
-func (s *service) ListAll(ctx context.Context, status *Status, page, limit int) ([]*Order, int, error) {
-	return s.repo.ListAll(ctx, s.db, status, page, limit)
+func (s *service) List(ctx context.Context, spec listing.Spec) ([]*Order, string, error) {
+	return s.repo.List(ctx, s.db, spec)
 }
 
 // -------------------------------------------------------------------------------------------------
-func (s *service) AdminUpdateOrder(ctx context.Context, orderID uuid.UUID, origin, destination *common.Location) (*Order, error) {
-	o, err := s.repo.GetByID(ctx, s.db, orderID)
+func (s *service) Count(ctx context.Context, spec listing.Spec) (int, error) {
+	return s.repo.Count(ctx, s.db, spec)
+}
+
+// -------------------------------------------------------------------------------------------------
+// AdminUpdateOrder moves the order's pickup or drop-off under a row lock,
+// refusing if ifMatch names a version other than the current one.
+func (s *service) AdminUpdateOrder(ctx context.Context, orderID uuid.UUID, origin, destination *common.Location, ifMatch *int64) (*Order, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	o, err := s.repo.GetByIDForUpdate(ctx, tx, orderID)
 	if err != nil {
 		return nil, domainerrors.OrderNotFound(orderID.String())
 	}
+	if err := o.CheckVersion(ifMatch); err != nil {
+		return nil, err
+	}
 
 	if origin != nil {
 		if err := s.ValidateLocation(*origin, "new origin"); err != nil {
@@ -104,9 +139,12 @@ func (s *service) AdminUpdateOrder(ctx context.Context, orderID uuid.UUID, origi
 		}
 	}
 
-	if err := s.repo.Update(ctx, s.db, o); err != nil {
+	if err := s.repo.Update(ctx, tx, o); err != nil {
 		return nil, domainerrors.NewInternal("failed to update order", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return nil, domainerrors.NewInternal("failed to commit transaction", err)
+	}
 	return o, nil
 }
 
@@ -121,3 +159,23 @@ func (s *service) AwaitHandoffWithTx(ctx context.Context, tx sqlx.ExtContext, or
 	}
 	return s.repo.Update(ctx, tx, o)
 }
+
+// -------------------------------------------------------------------------------------------------
+// AverageServiceTime is how long recent deliveries took from assignment to
+// drop-off, with the number of deliveries averaged.
+func (s *service) AverageServiceTime(ctx context.Context, sample int) (time.Duration, int, error) {
+	avg, n, err := s.repo.AverageServiceTime(ctx, s.db, sample)
+	if err != nil {
+		return 0, 0, domainerrors.NewInternal("failed to average service time", err)
+	}
+	return avg, n, nil
+}
+
+// -------------------------------------------------------------------------------------------------
+func (s *service) CountWaitlisted(ctx context.Context) (int, error) {
+	n, err := s.repo.CountWaitlisted(ctx, s.db)
+	if err != nil {
+		return 0, domainerrors.NewInternal("failed to count waitlisted orders", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,132 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	ClaimTimeout time.Duration // how long a claimed entry is ours; each provider call must end before its claim does
+}
+
+// Dispatcher drains the payment outbox, forwarding each committed state
+// change to the provider. Entries are retried until MaxAttempts, always
+// with the same idempotency key, so a retry of a call whose reply was lost
+// doesn't move the money twice.
+type Dispatcher struct {
+	db       *sqlx.DB
+	repo     Repository
+	provider Provider
+	cfg      DispatcherConfig
+}
+
+func NewDispatcher(db *sqlx.DB, repo Repository, provider Provider, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{db: db, repo: repo, provider: provider, cfg: cfg}
+}
+
+// Run processes the outbox until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.ProcessBatch(ctx); err != nil {
+				slog.ErrorContext(ctx, "payment outbox batch failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// ProcessBatch handles one batch of pending entries and returns how many were attempted.
+// The entries are claimed and committed first; each provider call then runs
+// outside any transaction, ends before its entry's claim does, and its
+// outcome is written only if the claim is still ours. An entry whose claim
+// lapses before its turn is skipped and claimed again later, as is one whose
+// outcome fails to save; the retry uses the same key.
+func (d *Dispatcher) ProcessBatch(ctx context.Context) (int, error) {
+	entries, err := d.repo.ClaimPending(ctx, d.db, d.cfg.BatchSize, d.cfg.ClaimTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox entries: %w", err)
+	}
+
+	attempted := 0
+	for _, e := range entries {
+		claim := *e.ClaimedUntil
+		if !time.Now().Before(claim) {
+			slog.WarnContext(ctx, "payment outbox claim lapsed before dispatch", slog.Int64("outbox_id", e.ID))
+			continue
+		}
+		d.dispatch(ctx, e, claim)
+		attempted++
+
+		// Record the outcome even if we are shutting down mid-batch
+		recorded, err := d.repo.RecordOutcome(context.WithoutCancel(ctx), d.db, e, claim)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to record payment outbox outcome",
+				slog.Int64("outbox_id", e.ID),
+				slog.String("error", err.Error()),
+			)
+		} else if !recorded {
+			slog.WarnContext(ctx, "payment outbox entry was claimed by another dispatcher; outcome dropped",
+				slog.Int64("outbox_id", e.ID),
+			)
+		}
+	}
+	return attempted, nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, e *OutboxEntry, claim time.Time) {
+	// A call must finish while the claim holds, or another dispatcher may
+	// send it concurrently
+	callCtx, cancel := context.WithDeadline(ctx, claim)
+	defer cancel()
+
+	key := e.IdempotencyKey()
+	var err error
+	switch e.Operation {
+	case OpCapture:
+		err = d.provider.Capture(callCtx, e.ProviderRef, e.Amount, key)
+	case OpVoid:
+		err = d.provider.Void(callCtx, e.ProviderRef, key)
+	case OpRefund:
+		err = d.provider.Refund(callCtx, e.ProviderRef, e.Amount, key)
+	default:
+		err = fmt.Errorf("unknown operation %s", e.Operation)
+	}
+
+	e.Attempts++
+	e.ClaimedUntil = nil
+	if err == nil {
+		now := time.Now()
+		e.Status = OutboxDone
+		e.LastError = nil
+		e.ProcessedAt = &now
+		return
+	}
+
+	msg := err.Error()
+	e.LastError = &msg
+	e.Status = OutboxPending
+	if e.Attempts >= d.cfg.MaxAttempts {
+		now := time.Now()
+		e.Status = OutboxFailed
+		e.ProcessedAt = &now
+	}
+	slog.WarnContext(ctx, "payment provider call failed",
+		slog.Int64("outbox_id", e.ID),
+		slog.String("operation", string(e.Operation)),
+		slog.Int("attempts", e.Attempts),
+		slog.String("error", msg),
+	)
+}
@@ -0,0 +1,172 @@
+package payment
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Status string
+
+const (
+	StatusAuthorized        Status = "AUTHORIZED"
+	StatusCaptured          Status = "CAPTURED"
+	StatusVoided            Status = "VOIDED"
+	StatusRefunded          Status = "REFUNDED"
+	StatusPartiallyRefunded Status = "PARTIALLY_REFUNDED"
+)
+
+type Operation string
+
+const (
+	OpCapture Operation = "CAPTURE"
+	OpVoid    Operation = "VOID"
+	OpRefund  Operation = "REFUND"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending    OutboxStatus = "PENDING"
+	OutboxInProgress OutboxStatus = "IN_PROGRESS" // claimed by a dispatcher until ClaimedUntil
+	OutboxDone       OutboxStatus = "DONE"
+	OutboxFailed     OutboxStatus = "FAILED"
+)
+
+// Payment tracks the money state of one order. Amounts are in minor units.
+type Payment struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	OrderID        uuid.UUID `db:"order_id" json:"order_id"`
+	Provider       string    `db:"provider" json:"provider"`
+	ProviderRef    string    `db:"provider_ref" json:"provider_ref"`
+	Status         Status    `db:"status" json:"status"`
+	Amount         int64     `db:"amount" json:"amount"`
+	CapturedAmount int64     `db:"captured_amount" json:"captured_amount"`
+	RefundedAmount int64     `db:"refunded_amount" json:"refunded_amount"`
+	Currency       string    `db:"currency" json:"currency"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// OutboxEntry is a provider call that must happen because the payment
+// changed state inside a delivery transaction.
+type OutboxEntry struct {
+	ID           int64        `db:"id" json:"id"`
+	PaymentID    uuid.UUID    `db:"payment_id" json:"payment_id"`
+	ProviderRef  string       `db:"provider_ref" json:"provider_ref"`
+	Operation    Operation    `db:"operation" json:"operation"`
+	Amount       int64        `db:"amount" json:"amount"`
+	Status       OutboxStatus `db:"status" json:"status"`
+	Attempts     int          `db:"attempts" json:"attempts"`
+	LastError    *string      `db:"last_error" json:"last_error,omitempty"`
+	ClaimedUntil *time.Time   `db:"claimed_until" json:"claimed_until,omitempty"`
+	CreatedAt    time.Time    `db:"created_at" json:"created_at"`
+	ProcessedAt  *time.Time   `db:"processed_at" json:"processed_at,omitempty"`
+}
+
+// IdempotencyKey identifies the entry's provider call across retries, so a
+// call that reached the provider but whose outcome was lost is not applied
+// twice when it is sent again.
+func (e *OutboxEntry) IdempotencyKey() string {
+	return "outbox-" + strconv.FormatInt(e.ID, 10)
+}
+
+// RefundPolicy decides how much of an authorized amount is returned to the
+// customer when a delivery fails after pickup.
+type RefundPolicy struct {
+	FailedRefundPercent int // 0-100
+}
+
+func NewAuthorized(orderID uuid.UUID, provider, providerRef string, amount int64, currency string) *Payment {
+	now := time.Now()
+	return &Payment{
+		ID:          uuid.New(),
+		OrderID:     orderID,
+		Provider:    provider,
+		ProviderRef: providerRef,
+		Status:      StatusAuthorized,
+		Amount:      amount,
+		Currency:    currency,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Capture collects the full authorized amount.
+func (p *Payment) Capture() (*OutboxEntry, error) {
+	if p.Status != StatusAuthorized {
+		return nil, domainerrors.PaymentInvalidTransition(string(p.Status), string(StatusCaptured))
+	}
+	p.Status = StatusCaptured
+	p.CapturedAmount = p.Amount
+	p.UpdatedAt = time.Now()
+	return p.outbox(OpCapture, p.Amount), nil
+}
+
+// Void releases the authorization without collecting anything.
+func (p *Payment) Void() (*OutboxEntry, error) {
+	if p.Status != StatusAuthorized {
+		return nil, domainerrors.PaymentInvalidTransition(string(p.Status), string(StatusVoided))
+	}
+	p.Status = StatusVoided
+	p.UpdatedAt = time.Now()
+	return p.outbox(OpVoid, 0), nil
+}
+
+// Refund returns part or all of a captured amount.
+func (p *Payment) Refund(amount int64) (*OutboxEntry, error) {
+	if p.Status != StatusCaptured && p.Status != StatusPartiallyRefunded {
+		return nil, domainerrors.PaymentInvalidTransition(string(p.Status), string(StatusRefunded))
+	}
+	if amount <= 0 || amount > p.CapturedAmount-p.RefundedAmount {
+		return nil, domainerrors.NewValidation("refund amount exceeds the refundable balance")
+	}
+	p.RefundedAmount += amount
+	if p.RefundedAmount == p.CapturedAmount {
+		p.Status = StatusRefunded
+	} else {
+		p.Status = StatusPartiallyRefunded
+	}
+	p.UpdatedAt = time.Now()
+	return p.outbox(OpRefund, amount), nil
+}
+
+// SettleFailed applies the refund policy to a failed delivery. An authorized
+// payment keeps only the non-refunded share; a captured one is refunded.
+func (p *Payment) SettleFailed(policy RefundPolicy) (*OutboxEntry, error) {
+	pct := min(max(policy.FailedRefundPercent, 0), 100)
+	refund := p.Amount * int64(pct) / 100
+
+	switch p.Status {
+	case StatusAuthorized:
+		if refund == p.Amount {
+			return p.Void()
+		}
+		retained := p.Amount - refund
+		p.Status = StatusCaptured
+		p.CapturedAmount = retained
+		p.UpdatedAt = time.Now()
+		return p.outbox(OpCapture, retained), nil
+	case StatusCaptured:
+		if refund == 0 {
+			return nil, nil
+		}
+		return p.Refund(refund)
+	default:
+		return nil, domainerrors.PaymentInvalidTransition(string(p.Status), string(StatusRefunded))
+	}
+}
+
+func (p *Payment) outbox(op Operation, amount int64) *OutboxEntry {
+	return &OutboxEntry{
+		PaymentID:   p.ID,
+		ProviderRef: p.ProviderRef,
+		Operation:   op,
+		Amount:      amount,
+		Status:      OutboxPending,
+		CreatedAt:   time.Now(),
+	}
+}
@@ -0,0 +1,149 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDeclined         = errors.New("payment method declined")
+	ErrUnknownReference = errors.New("unknown provider reference")
+)
+
+type AuthorizeRequest struct {
+	OrderID       uuid.UUID
+	Amount        int64
+	Currency      string
+	PaymentMethod string
+}
+
+// Provider is the boundary to a payment processor. Capture, Void and Refund
+// take an idempotency key: a call repeating a key the provider has already
+// seen gets the first call's outcome and moves no money.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (string, error)
+	Capture(ctx context.Context, providerRef string, amount int64, idempotencyKey string) error
+	Void(ctx context.Context, providerRef string, idempotencyKey string) error
+	Refund(ctx context.Context, providerRef string, amount int64, idempotencyKey string) error
+}
+
+// DeclinedPaymentMethod is rejected by FakeProvider so declines can be exercised locally.
+const DeclinedPaymentMethod = "pm_card_declined"
+
+type fakeAuthorization struct {
+	amount   int64
+	captured int64
+	refunded int64
+	voided   bool
+}
+
+// FakeProvider is an in-memory processor for local development and tests.
+type FakeProvider struct {
+	mu      sync.Mutex
+	auths   map[string]*fakeAuthorization
+	replies map[string]error // outcome by idempotency key
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		auths:   make(map[string]*fakeAuthorization),
+		replies: make(map[string]error),
+	}
+}
+
+// once runs op unless key has been seen, and returns the outcome of the
+// first call made with key. Callers hold f.mu.
+func (f *FakeProvider) once(key string, op func() error) error {
+	if err, ok := f.replies[key]; ok {
+		return err
+	}
+	err := op()
+	f.replies[key] = err
+	return err
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) Authorize(_ context.Context, req AuthorizeRequest) (string, error) {
+	if req.PaymentMethod == DeclinedPaymentMethod {
+		return "", ErrDeclined
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ref := "fake_auth_" + uuid.NewString()
+	f.auths[ref] = &fakeAuthorization{amount: req.Amount}
+	return ref, nil
+}
+
+func (f *FakeProvider) Capture(_ context.Context, providerRef string, amount int64, idempotencyKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.once(idempotencyKey, func() error {
+		a, ok := f.auths[providerRef]
+		if !ok {
+			return ErrUnknownReference
+		}
+		if a.voided || a.captured > 0 {
+			return fmt.Errorf("authorization %s cannot be captured", providerRef)
+		}
+		if amount > a.amount {
+			return fmt.Errorf("capture of %d exceeds authorized %d", amount, a.amount)
+		}
+		a.captured = amount
+		return nil
+	})
+}
+
+func (f *FakeProvider) Void(_ context.Context, providerRef string, idempotencyKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.once(idempotencyKey, func() error {
+		a, ok := f.auths[providerRef]
+		if !ok {
+			return ErrUnknownReference
+		}
+		if a.captured > 0 {
+			return fmt.Errorf("authorization %s already captured", providerRef)
+		}
+		a.voided = true
+		return nil
+	})
+}
+
+func (f *FakeProvider) Refund(_ context.Context, providerRef string, amount int64, idempotencyKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.once(idempotencyKey, func() error {
+		a, ok := f.auths[providerRef]
+		if !ok {
+			return ErrUnknownReference
+		}
+		if amount > a.captured-a.refunded {
+			return fmt.Errorf("refund of %d exceeds refundable %d", amount, a.captured-a.refunded)
+		}
+		a.refunded += amount
+		return nil
+	})
+}
+
+// Refunded reports how much of an authorization has been refunded.
+func (f *FakeProvider) Refunded(providerRef string) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if a, ok := f.auths[providerRef]; ok {
+		return a.refunded
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package payment
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const columns = `id, order_id, provider, provider_ref, status, amount, captured_amount, refunded_amount, currency, created_at, updated_at`
+
+const outboxColumns = `id, payment_id, provider_ref, operation, amount, status, attempts, last_error, claimed_until, created_at, processed_at`
+
+type Repository interface {
+	Create(ctx context.Context, ext sqlx.ExtContext, p *Payment) error
+	GetByOrderID(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID) (*Payment, error)
+	GetByOrderIDForUpdate(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID) (*Payment, error)
+	Update(ctx context.Context, ext sqlx.ExtContext, p *Payment) error
+	Enqueue(ctx context.Context, ext sqlx.ExtContext, e *OutboxEntry) error
+	ClaimPending(ctx context.Context, ext sqlx.ExtContext, limit int, lease time.Duration) ([]*OutboxEntry, error)
+	RecordOutcome(ctx context.Context, ext sqlx.ExtContext, e *OutboxEntry, claimedUntil time.Time) (bool, error)
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+func (r *repo) Create(ctx context.Context, ext sqlx.ExtContext, p *Payment) error {
+	const query = `INSERT INTO payments (id, order_id, provider, provider_ref, status, amount, captured_amount, refunded_amount, currency, created_at, updated_at)
+		VALUES (:id, :order_id, :provider, :provider_ref, :status, :amount, :captured_amount, :refunded_amount, :currency, :created_at, :updated_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, p)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetByOrderID(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID) (*Payment, error) {
+	var p Payment
+	query := fmt.Sprintf(`SELECT %s FROM payments WHERE order_id = $1`, columns)
+	if err := sqlx.GetContext(ctx, ext, &p, query, orderID); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) GetByOrderIDForUpdate(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID) (*Payment, error) {
+	var p Payment
+	query := fmt.Sprintf(`SELECT %s FROM payments WHERE order_id = $1 FOR UPDATE`, columns)
+	if err := sqlx.GetContext(ctx, ext, &p, query, orderID); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) Update(ctx context.Context, ext sqlx.ExtContext, p *Payment) error {
+	const query = `UPDATE payments SET status = :status, captured_amount = :captured_amount,
+		refunded_amount = :refunded_amount, updated_at = :updated_at WHERE id = :id`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, p)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) Enqueue(ctx context.Context, ext sqlx.ExtContext, e *OutboxEntry) error {
+	const query = `INSERT INTO payment_outbox (payment_id, provider_ref, operation, amount, status, attempts, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	return sqlx.GetContext(ctx, ext, &e.ID, query,
+		e.PaymentID, e.ProviderRef, e.Operation, e.Amount, e.Status, e.Attempts, e.CreatedAt)
+}
+
+// --------------------------------------------------------------
+// ClaimPending marks up to limit pending entries IN_PROGRESS for lease and
+// returns them, oldest first. It is one statement, so the claim commits
+// before the caller talks to the provider and no row lock is held across
+// the call. Entries whose claim has lapsed — their dispatcher died
+// mid-call — are claimed again.
+func (r *repo) ClaimPending(ctx context.Context, ext sqlx.ExtContext, limit int, lease time.Duration) ([]*OutboxEntry, error) {
+	var entries []*OutboxEntry
+	query := fmt.Sprintf(`UPDATE payment_outbox SET status = 'IN_PROGRESS',
+			claimed_until = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM payment_outbox
+			WHERE status = 'PENDING' OR (status = 'IN_PROGRESS' AND claimed_until < NOW())
+			ORDER BY id ASC LIMIT $1 FOR UPDATE SKIP LOCKED)
+		RETURNING %s`, outboxColumns)
+	if err := sqlx.SelectContext(ctx, ext, &entries, query, limit, lease.Milliseconds()); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entries, func(a, b *OutboxEntry) int { return cmp.Compare(a.ID, b.ID) })
+	return entries, nil
+}
+
+// --------------------------------------------------------------
+// RecordOutcome writes the result of a provider call for an entry claimed
+// until claimedUntil. It reports false, writing nothing, if the claim lapsed
+// and another dispatcher has since claimed or finished the entry.
+func (r *repo) RecordOutcome(ctx context.Context, ext sqlx.ExtContext, e *OutboxEntry, claimedUntil time.Time) (bool, error) {
+	const query = `UPDATE payment_outbox SET status = $1, attempts = $2, last_error = $3,
+			claimed_until = $4, processed_at = $5
+		WHERE id = $6 AND status = 'IN_PROGRESS' AND claimed_until = $7`
+	res, err := ext.ExecContext(ctx, query, e.Status, e.Attempts, e.LastError, e.ClaimedUntil, e.ProcessedAt, e.ID, claimedUntil)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
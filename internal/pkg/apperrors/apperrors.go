@@ -19,14 +19,19 @@ type ErrorBody struct {
 }
 
 var codeToStatus = map[string]int{
-	domainerrors.ErrNotFound:          http.StatusNotFound,
-	domainerrors.ErrInvalidTransition: http.StatusConflict,
-	domainerrors.ErrUnauthorized:      http.StatusUnauthorized,
-	domainerrors.ErrForbidden:         http.StatusForbidden,
-	domainerrors.ErrConflict:          http.StatusConflict,
-	domainerrors.ErrValidation:        http.StatusBadRequest,
-	domainerrors.ErrOutOfZone:         http.StatusBadRequest,
-	domainerrors.ErrInternal:          http.StatusInternalServerError,
+	domainerrors.ErrNotFound:           http.StatusNotFound,
+	domainerrors.ErrInvalidTransition:  http.StatusConflict,
+	domainerrors.ErrUnauthorized:       http.StatusUnauthorized,
+	domainerrors.ErrForbidden:          http.StatusForbidden,
+	domainerrors.ErrConflict:           http.StatusConflict,
+	domainerrors.ErrValidation:         http.StatusBadRequest,
+	domainerrors.ErrOutOfZone:          http.StatusBadRequest,
+	domainerrors.ErrPaymentDeclined:    http.StatusPaymentRequired,
+	domainerrors.ErrGrounded:           http.StatusServiceUnavailable,
+	domainerrors.ErrUnsafeWeather:      http.StatusServiceUnavailable,
+	domainerrors.ErrOverCapacity:       http.StatusServiceUnavailable,
+	domainerrors.ErrPreconditionFailed: http.StatusPreconditionFailed,
+	domainerrors.ErrInternal:           http.StatusInternalServerError,
 }
 
 func ToHTTPError(c *gin.Context, err error) {
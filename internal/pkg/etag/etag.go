@@ -0,0 +1,36 @@
+// Package etag renders row versions as entity tags and reads them back
+// from If-Match, so admin edits can refuse to overwrite a newer write.
+package etag
+
+import (
+	"strconv"
+	"strings"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// Format is version as a strong entity tag, e.g. "7".
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseIfMatch returns the version an If-Match header names, or nil when
+// the header is absent or "*" and any version will do. Weak tags and lists
+// are refused: a write must name exactly the version it was based on.
+func ParseIfMatch(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, domainerrors.NewValidation("If-Match must be a strong entity tag")
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return nil, domainerrors.NewValidation(`If-Match must be a single quoted version, e.g. "3"`)
+	}
+	v, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || v < 1 {
+		return nil, domainerrors.NewValidation(`If-Match must be a single quoted version, e.g. "3"`)
+	}
+	return &v, nil
+}
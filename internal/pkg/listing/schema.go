@@ -0,0 +1,168 @@
+package listing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// Schema maps a Spec onto one table. Each field is a column (or expression)
+// of the table; a filter whose column is empty isn't supported there.
+type Schema struct {
+	Name        string // the listed things, for error messages
+	ID          string
+	Status      string
+	CreatedAt   string
+	DroneID     string
+	SubmittedBy string
+	Lat, Lng    string
+	// Sorts maps each sortable field to its column. Sort columns must not
+	// be nullable.
+	Sorts       map[string]string
+	DefaultSort Sort
+}
+
+// Query is a Spec rendered as SQL, with placeholders numbered from $1.
+type Query struct {
+	Sort  Sort
+	Limit int
+
+	where    string
+	args     []any
+	page     string
+	pageArgs []any
+}
+
+// Where is the WHERE clause for the filters alone, or empty; it starts with
+// a space. Use it with Args for counts.
+func (q *Query) Where() string { return q.where }
+
+func (q *Query) Args() []any { return q.args }
+
+// Page is the WHERE, ORDER BY and LIMIT clauses for the page, reading one
+// row beyond Limit so Next can tell whether another page follows. Use it
+// with PageArgs.
+func (q *Query) Page() string { return q.page }
+
+func (q *Query) PageArgs() []any { return q.pageArgs }
+
+// Build renders spec against the schema, refusing filters and sorts the
+// schema doesn't support and cursors issued for another sort.
+func (s Schema) Build(spec Spec) (*Query, error) {
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	unsupported := func(filter string) error {
+		return domainerrors.NewValidation(fmt.Sprintf("%s can't be filtered by %s", s.Name, filter))
+	}
+
+	if spec.Status != "" {
+		if s.Status == "" {
+			return nil, unsupported("status")
+		}
+		conds = append(conds, fmt.Sprintf("%s = %s", s.Status, arg(spec.Status)))
+	}
+	if spec.From != nil || spec.To != nil {
+		if s.CreatedAt == "" {
+			return nil, unsupported("date")
+		}
+		if spec.From != nil {
+			conds = append(conds, fmt.Sprintf("%s >= %s", s.CreatedAt, arg(*spec.From)))
+		}
+		if spec.To != nil {
+			conds = append(conds, fmt.Sprintf("%s < %s", s.CreatedAt, arg(*spec.To)))
+		}
+	}
+	if spec.DroneID != "" {
+		if s.DroneID == "" {
+			return nil, unsupported("drone_id")
+		}
+		conds = append(conds, fmt.Sprintf("%s = %s", s.DroneID, arg(spec.DroneID)))
+	}
+	if spec.SubmittedBy != "" {
+		if s.SubmittedBy == "" {
+			return nil, unsupported("submitted_by")
+		}
+		conds = append(conds, fmt.Sprintf("%s = %s", s.SubmittedBy, arg(spec.SubmittedBy)))
+	}
+	if b := spec.BBox; b != nil {
+		if s.Lat == "" {
+			return nil, unsupported("bbox")
+		}
+		conds = append(conds, fmt.Sprintf("%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s",
+			s.Lat, arg(b.MinLat), arg(b.MaxLat), s.Lng, arg(b.MinLng), arg(b.MaxLng)))
+	}
+
+	q := &Query{Sort: spec.Sort, Limit: spec.Limit}
+	if q.Sort.Field == "" {
+		q.Sort = s.DefaultSort
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	col, ok := s.Sorts[q.Sort.Field]
+	if !ok {
+		fields := make([]string, 0, len(s.Sorts))
+		for f := range s.Sorts {
+			fields = append(fields, f)
+		}
+		sort.Strings(fields)
+		return nil, domainerrors.NewValidation(fmt.Sprintf("%s can be sorted by %s", s.Name, strings.Join(fields, ", ")))
+	}
+
+	q.where = clause(conds)
+	q.args = append([]any(nil), args...)
+
+	// Keyset: rows strictly after the cursor's (sort value, id)
+	if c := spec.Cursor; c != nil {
+		if c.Sort != q.Sort.String() || len(c.Keys) != 2 {
+			return nil, domainerrors.NewValidation("cursor was issued for a different sort")
+		}
+		op := ">"
+		if q.Sort.Desc {
+			op = "<"
+		}
+		if col == s.ID {
+			conds = append(conds, fmt.Sprintf("%s %s %s", col, op, arg(c.Keys[1])))
+		} else {
+			conds = append(conds, fmt.Sprintf("(%s, %s) %s (%s, %s)", col, s.ID, op, arg(c.Keys[0]), arg(c.Keys[1])))
+		}
+	}
+
+	dir := "ASC"
+	if q.Sort.Desc {
+		dir = "DESC"
+	}
+	order := fmt.Sprintf("%s %s, %s %s", col, dir, s.ID, dir)
+	if col == s.ID {
+		order = fmt.Sprintf("%s %s", col, dir)
+	}
+	q.page = fmt.Sprintf("%s ORDER BY %s LIMIT %s", clause(conds), order, arg(q.Limit+1))
+	q.pageArgs = args
+	return q, nil
+}
+
+// Next trims rows read with the page query to the limit and returns the
+// cursor for the following page, or "" when this is the last. keys returns
+// a row's value for the sort field and its id.
+func Next[T any](q *Query, rows []T, keys func(row T, field string) (string, string)) ([]T, string) {
+	if len(rows) <= q.Limit {
+		return rows, ""
+	}
+	rows = rows[:q.Limit]
+	value, id := keys(rows[len(rows)-1], q.Sort.Field)
+	c := &Cursor{Sort: q.Sort.String(), Keys: []string{value, id}}
+	return rows, c.Encode()
+}
+
+func clause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
@@ -0,0 +1,192 @@
+// Package listing describes a page of a list endpoint — filters, sort order
+// and an opaque cursor — and renders it as SQL for the repositories.
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Sort orders a list by one field; rows with equal values are ordered by id
+// in the same direction.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// String is the sort as written in the sort parameter: the field, with a
+// leading "-" when descending.
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// BBox bounds a location, inclusive on all sides.
+type BBox struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
+
+// Spec is one page of a list. Empty filters match everything; From and To
+// bound the creation time as [From, To).
+type Spec struct {
+	Status      string
+	From        *time.Time
+	To          *time.Time
+	DroneID     string
+	SubmittedBy string
+	BBox        *BBox
+	Sort        Sort // zero for the list's default order
+	Cursor      *Cursor
+	Limit       int
+}
+
+// Filtered reports whether any filter is set.
+func (s Spec) Filtered() bool {
+	return s.Status != "" || s.From != nil || s.To != nil || s.DroneID != "" || s.SubmittedBy != "" || s.BBox != nil
+}
+
+// Cursor is the position just after the last row of a page: the sort it was
+// issued for and that row's sort keys, the id last. At pins the clock for
+// orders that change with time.
+type Cursor struct {
+	Sort string     `json:"s"`
+	Keys []string   `json:"k"`
+	At   *time.Time `json:"at,omitempty"`
+}
+
+// Encode renders the cursor as the opaque string handed to clients.
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domainerrors.NewValidation("invalid cursor")
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || len(c.Keys) == 0 {
+		return nil, domainerrors.NewValidation("invalid cursor")
+	}
+	return &c, nil
+}
+
+// FormatTime renders a timestamp sort key.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// --------------------------------------------------------------
+// Parse reads a Spec from query parameters:
+//
+//	limit         page size, 1-100 (default 20)
+//	cursor        next_cursor from the previous page
+//	sort          a field, prefixed with "-" for descending
+//	status        exact status
+//	from, to      creation time, RFC 3339 or YYYY-MM-DD (a date to includes the whole day)
+//	drone_id      assigned drone
+//	submitted_by  submitting user
+//	bbox          min_lat,min_lng,max_lat,max_lng
+//
+// Whether a list supports a filter or sort is checked when it is built.
+func Parse(q url.Values) (Spec, error) {
+	spec := Spec{
+		Status:      q.Get("status"),
+		DroneID:     q.Get("drone_id"),
+		SubmittedBy: q.Get("submitted_by"),
+		Limit:       DefaultLimit,
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxLimit {
+			return Spec{}, domainerrors.NewValidation(fmt.Sprintf("limit must be between 1 and %d", MaxLimit))
+		}
+		spec.Limit = n
+	}
+	if v := q.Get("sort"); v != "" {
+		spec.Sort = Sort{Field: strings.TrimPrefix(v, "-"), Desc: strings.HasPrefix(v, "-")}
+	}
+	if v := q.Get("cursor"); v != "" {
+		c, err := DecodeCursor(v)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Cursor = c
+	}
+
+	if v := q.Get("from"); v != "" {
+		t, _, err := parseTime(v)
+		if err != nil {
+			return Spec{}, domainerrors.NewValidation("from must be an RFC 3339 time or a date (YYYY-MM-DD)")
+		}
+		spec.From = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, date, err := parseTime(v)
+		if err != nil {
+			return Spec{}, domainerrors.NewValidation("to must be an RFC 3339 time or a date (YYYY-MM-DD)")
+		}
+		if date {
+			t = t.AddDate(0, 0, 1)
+		}
+		spec.To = &t
+	}
+	if spec.From != nil && spec.To != nil && !spec.From.Before(*spec.To) {
+		return Spec{}, domainerrors.NewValidation("from must be before to")
+	}
+
+	if v := q.Get("bbox"); v != "" {
+		b, err := parseBBox(v)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.BBox = b
+	}
+	return spec, nil
+}
+
+// parseTime reads an RFC 3339 time or a UTC date, reporting which.
+func parseTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+func parseBBox(v string) (*BBox, error) {
+	invalid := domainerrors.NewValidation("bbox must be min_lat,min_lng,max_lat,max_lng")
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return nil, invalid
+	}
+	var n [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, invalid
+		}
+		n[i] = f
+	}
+	b := &BBox{MinLat: n[0], MinLng: n[1], MaxLat: n[2], MaxLng: n[3]}
+	if b.MinLat > b.MaxLat || b.MinLng > b.MaxLng {
+		return nil, invalid
+	}
+	return b, nil
+}
@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Fallback takes from primary and, when primary fails, from secondary, so
+// an outage of the shared store degrades the limits to per-instance ones
+// rather than lifting them.
+type Fallback struct {
+	primary   Limiter
+	secondary Limiter
+	degraded  atomic.Bool
+}
+
+func NewFallback(primary, secondary Limiter) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+func (f *Fallback) Take(ctx context.Context, key string, l Limit) (Decision, error) {
+	d, err := f.primary.Take(ctx, key, l)
+	if err == nil {
+		if f.degraded.CompareAndSwap(true, false) {
+			slog.InfoContext(ctx, "rate limiter recovered")
+		}
+		return d, nil
+	}
+	// Log the switch, not every request made while degraded
+	if f.degraded.CompareAndSwap(false, true) {
+		slog.WarnContext(ctx, "rate limiter degraded to in-process buckets",
+			slog.String("error", err.Error()),
+		)
+	}
+	return f.secondary.Take(ctx, key, l)
+}
+
+// Degraded reports whether the last take fell back to secondary.
+func (f *Fallback) Degraded() bool {
+	return f.degraded.Load()
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is how often Memory drops buckets that have refilled; a full
+// bucket behaves exactly like a missing one.
+const sweepEvery = time.Minute
+
+type bucket struct {
+	tokens float64
+	at     time.Time // when tokens was last brought up to date
+	full   time.Time // when the bucket will be full again
+}
+
+// Memory keeps token buckets in process. Each instance counts on its own,
+// so behind a load balancer the effective limit is multiplied by the
+// number of instances.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket)}
+}
+
+func (m *Memory) Take(_ context.Context, key string, l Limit) (Decision, error) {
+	return m.TakeAt(key, l, time.Now()), nil
+}
+
+// TakeAt is Take as of now.
+func (m *Memory) TakeAt(key string, l Limit, now time.Time) Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) >= sweepEvery {
+		for k, b := range m.buckets {
+			if !now.Before(b.full) {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	capacity := float64(l.Capacity())
+	interval := l.Interval()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, at: now}
+		m.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.at); elapsed > 0 {
+		b.tokens = min(capacity, b.tokens+float64(elapsed)/float64(interval))
+		b.at = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = b.at.Add(time.Duration((capacity - b.tokens) * float64(interval)))
+	return NewDecision(l, allowed, b.tokens)
+}
+
+// Len is the number of buckets held.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.buckets)
+}
@@ -0,0 +1,113 @@
+// Package ratelimit describes request quotas — how many requests a caller
+// may make per window, and who counts as one caller — and the token buckets
+// that enforce them.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// KeyBy names what a policy counts requests against.
+type KeyBy string
+
+const (
+	KeyIP     KeyBy = "ip"     // the client address
+	KeySub    KeyBy = "sub"    // the JWT subject: one bucket per user or drone
+	KeyRole   KeyBy = "role"   // the JWT role: one bucket shared by the whole role
+	KeyTenant KeyBy = "tenant" // the JWT tenant, or the subject for tokens without one
+)
+
+// ParseKeyBy reads a KeyBy as written in config.
+func ParseKeyBy(s string) (KeyBy, error) {
+	switch k := KeyBy(s); k {
+	case KeyIP, KeySub, KeyRole, KeyTenant:
+		return k, nil
+	}
+	return "", fmt.Errorf("unknown rate limit key %q (want ip, sub, role or tenant)", s)
+}
+
+// Limit is a token bucket: it holds up to Burst tokens, refills at Requests
+// per Window, and each request takes one. A Burst of zero means Requests,
+// i.e. a caller may spend a whole window's quota at once.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+	Burst    int
+}
+
+// Capacity is the most tokens the bucket holds.
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// Interval is how long the bucket takes to refill one token.
+func (l Limit) Interval() time.Duration {
+	return l.Window / time.Duration(l.Requests)
+}
+
+// Policy is a Limit applied per Key to one group of routes. Name keeps the
+// buckets of different policies apart.
+type Policy struct {
+	Name  string
+	Key   KeyBy
+	Limit Limit
+}
+
+// NewPolicy builds a policy from config values.
+func NewPolicy(name, key string, requests, windowSeconds, burst int) (Policy, error) {
+	by, err := ParseKeyBy(key)
+	if err != nil {
+		return Policy{}, fmt.Errorf("rate limit %s: %w", name, err)
+	}
+	if requests < 1 || windowSeconds < 1 || burst < 0 {
+		return Policy{}, fmt.Errorf("rate limit %s: requests and window must be positive, burst not negative", name)
+	}
+	return Policy{
+		Name: name,
+		Key:  by,
+		Limit: Limit{
+			Requests: requests,
+			Window:   time.Duration(windowSeconds) * time.Second,
+			Burst:    burst,
+		},
+	}, nil
+}
+
+// Decision is the outcome of taking a token.
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket's capacity and Remaining the whole tokens left.
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available; zero if allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// NewDecision describes a bucket left holding tokens after a take.
+func NewDecision(l Limit, allowed bool, tokens float64) Decision {
+	capacity := l.Capacity()
+	interval := float64(l.Interval())
+	d := Decision{
+		Allowed:   allowed,
+		Limit:     capacity,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil((float64(capacity) - tokens) * interval)),
+	}
+	if !allowed {
+		d.RetryAfter = time.Duration(math.Ceil((1 - tokens) * interval))
+	}
+	return d
+}
+
+// Limiter takes one token from the bucket under key.
+type Limiter interface {
+	Take(ctx context.Context, key string, l Limit) (Decision, error)
+}
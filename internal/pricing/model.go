@@ -0,0 +1,206 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+	_ "time/tzdata" // rule tables name IANA zones; the runtime image ships without tzdata
+
+	"drone-delivery/internal/common"
+)
+
+var ErrPayloadTooHeavy = errors.New("payload exceeds the maximum supported weight")
+
+// Amounts are expressed in minor currency units (e.g. halalas for SAR).
+
+type PayloadTier struct {
+	MaxKG     float64 `json:"max_kg"`
+	Surcharge int64   `json:"surcharge"`
+}
+
+type SurgeWindow struct {
+	StartHour  int     `json:"start_hour"` // inclusive, 0-23
+	EndHour    int     `json:"end_hour"`   // exclusive, 1-24
+	Multiplier float64 `json:"multiplier"`
+}
+
+type UtilizationTier struct {
+	MinUtilization float64 `json:"min_utilization"` // 0..1, busy / (idle + busy)
+	Multiplier     float64 `json:"multiplier"`
+}
+
+type Rules struct {
+	Currency         string            `json:"currency"`
+	Timezone         string            `json:"timezone"`
+	BaseFare         int64             `json:"base_fare"`
+	PerKM            int64             `json:"per_km"`
+	MinimumFare      int64             `json:"minimum_fare"`
+	PayloadTiers     []PayloadTier     `json:"payload_tiers"`
+	SurgeWindows     []SurgeWindow     `json:"surge_windows"`
+	UtilizationTiers []UtilizationTier `json:"utilization_tiers"`
+
+	location *time.Location
+}
+
+type PriceInput struct {
+	DistanceKM  float64
+	PayloadKG   float64
+	At          time.Time
+	Utilization float64
+}
+
+type Breakdown struct {
+	BaseFare              int64   `json:"base_fare"`
+	DistanceKM            float64 `json:"distance_km"`
+	DistanceCharge        int64   `json:"distance_charge"`
+	PayloadSurcharge      int64   `json:"payload_surcharge"`
+	SurgeMultiplier       float64 `json:"surge_multiplier"`
+	UtilizationMultiplier float64 `json:"utilization_multiplier"`
+	Total                 int64   `json:"total"`
+}
+
+type Quote struct {
+	ID          string          `json:"id"`
+	Origin      common.Location `json:"origin"`
+	Destination common.Location `json:"destination"`
+	PayloadKG   float64         `json:"payload_kg"`
+	Amount      int64           `json:"amount"`
+	Currency    string          `json:"currency"`
+	Breakdown   *Breakdown      `json:"breakdown,omitempty"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	Token       string          `json:"token,omitempty"`
+}
+
+func DefaultRules() *Rules {
+	r := &Rules{
+		Currency:    "SAR",
+		Timezone:    "Asia/Riyadh",
+		BaseFare:    1000,
+		PerKM:       250,
+		MinimumFare: 1500,
+		PayloadTiers: []PayloadTier{
+			{MaxKG: 1, Surcharge: 0},
+			{MaxKG: 3, Surcharge: 500},
+			{MaxKG: 5, Surcharge: 1200},
+		},
+		SurgeWindows: []SurgeWindow{
+			{StartHour: 7, EndHour: 9, Multiplier: 1.2},
+			{StartHour: 17, EndHour: 21, Multiplier: 1.3},
+		},
+		UtilizationTiers: []UtilizationTier{
+			{MinUtilization: 0.5, Multiplier: 1.1},
+			{MinUtilization: 0.8, Multiplier: 1.25},
+			{MinUtilization: 0.95, Multiplier: 1.5},
+		},
+	}
+	_ = r.normalize()
+	return r
+}
+
+// LoadRules reads a JSON rule table from path. An empty path yields DefaultRules.
+func LoadRules(path string) (*Rules, error) {
+	if path == "" {
+		return DefaultRules(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read pricing rules: %w", err)
+	}
+	var r Rules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse pricing rules: %w", err)
+	}
+	if err := r.normalize(); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r *Rules) normalize() error {
+	if r.Currency == "" {
+		r.Currency = "SAR"
+	}
+	loc := time.UTC
+	if r.Timezone != "" {
+		l, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return fmt.Errorf("pricing timezone: %w", err)
+		}
+		loc = l
+	}
+	r.location = loc
+
+	sort.Slice(r.PayloadTiers, func(i, j int) bool { return r.PayloadTiers[i].MaxKG < r.PayloadTiers[j].MaxKG })
+	sort.Slice(r.UtilizationTiers, func(i, j int) bool {
+		return r.UtilizationTiers[i].MinUtilization < r.UtilizationTiers[j].MinUtilization
+	})
+	for _, w := range r.SurgeWindows {
+		if w.StartHour < 0 || w.StartHour > 23 || w.EndHour < 1 || w.EndHour > 24 || w.StartHour >= w.EndHour {
+			return fmt.Errorf("invalid surge window %d-%d", w.StartHour, w.EndHour)
+		}
+	}
+	return nil
+}
+
+// MaxPayloadKG returns the heaviest payload the rule table can price, or 0 if unlimited.
+func (r *Rules) MaxPayloadKG() float64 {
+	if len(r.PayloadTiers) == 0 {
+		return 0
+	}
+	return r.PayloadTiers[len(r.PayloadTiers)-1].MaxKG
+}
+
+// Price applies the rule table to the input. It returns ErrPayloadTooHeavy
+// when the payload exceeds the heaviest configured tier.
+func (r *Rules) Price(in PriceInput) (*Breakdown, error) {
+	b := &Breakdown{
+		BaseFare:              r.BaseFare,
+		DistanceKM:            math.Round(in.DistanceKM*1000) / 1000,
+		DistanceCharge:        int64(math.Round(in.DistanceKM * float64(r.PerKM))),
+		SurgeMultiplier:       1,
+		UtilizationMultiplier: 1,
+	}
+
+	if len(r.PayloadTiers) > 0 {
+		matched := false
+		for _, t := range r.PayloadTiers {
+			if in.PayloadKG <= t.MaxKG {
+				b.PayloadSurcharge = t.Surcharge
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, ErrPayloadTooHeavy
+		}
+	}
+
+	loc := r.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	hour := in.At.In(loc).Hour()
+	for _, w := range r.SurgeWindows {
+		if hour >= w.StartHour && hour < w.EndHour && w.Multiplier > b.SurgeMultiplier {
+			b.SurgeMultiplier = w.Multiplier
+		}
+	}
+
+	for _, t := range r.UtilizationTiers {
+		if in.Utilization >= t.MinUtilization {
+			b.UtilizationMultiplier = t.Multiplier
+		}
+	}
+
+	subtotal := float64(b.BaseFare + b.DistanceCharge + b.PayloadSurcharge)
+	total := int64(math.Round(subtotal * b.SurgeMultiplier * b.UtilizationMultiplier))
+	if total < r.MinimumFare {
+		total = r.MinimumFare
+	}
+	b.Total = total
+	return b, nil
+}
@@ -0,0 +1,140 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// coordinateTolerance is how far (in degrees) an order may drift from its
+// quoted coordinates before the quote no longer applies (~1 m).
+const coordinateTolerance = 1e-5
+
+// FleetStats is satisfied by drone.Service.
+type FleetStats interface {
+	CountByStatus(ctx context.Context) (map[drone.Status]int, error)
+}
+
+type Request struct {
+	Origin      common.Location
+	Destination common.Location
+	PayloadKG   float64
+}
+
+type Service interface {
+	Quote(ctx context.Context, subject string, req Request) (*Quote, error)
+	Verify(subject, token string, req Request) (*Quote, error)
+	Agree(ctx context.Context, subject, token string, req Request) (*Quote, error)
+}
+
+type Config struct {
+	Secret       string
+	QuoteTTL     time.Duration
+	RequireQuote bool // reject orders placed without a quote token
+}
+
+type service struct {
+	rules  *Rules
+	fleet  FleetStats
+	signer *tokenSigner
+	cfg    Config
+}
+
+func NewService(rules *Rules, fleet FleetStats, cfg Config) Service {
+	return &service{rules: rules, fleet: fleet, signer: newTokenSigner(cfg.Secret), cfg: cfg}
+}
+
+// --------------------------------------------------------------
+func (s *service) Quote(ctx context.Context, subject string, req Request) (*Quote, error) {
+	utilization, err := s.utilization(ctx)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to read fleet utilization", err)
+	}
+
+	now := time.Now()
+	b, err := s.rules.Price(PriceInput{
+		DistanceKM:  common.HaversineDistance(req.Origin, req.Destination),
+		PayloadKG:   req.PayloadKG,
+		At:          now,
+		Utilization: utilization,
+	})
+	if errors.Is(err, ErrPayloadTooHeavy) {
+		return nil, domainerrors.NewValidation(err.Error())
+	}
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to price order", err)
+	}
+
+	q := &Quote{
+		ID:          uuid.New().String(),
+		Origin:      req.Origin,
+		Destination: req.Destination,
+		PayloadKG:   req.PayloadKG,
+		Amount:      b.Total,
+		Currency:    s.rules.Currency,
+		Breakdown:   b,
+		ExpiresAt:   now.Add(s.cfg.QuoteTTL),
+	}
+	token, err := s.signer.Sign(q, subject, now)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to sign quote", err)
+	}
+	q.Token = token
+	return q, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) Verify(subject, token string, req Request) (*Quote, error) {
+	q, owner, err := s.signer.Parse(token)
+	if errors.Is(err, ErrQuoteExpired) {
+		return nil, domainerrors.QuoteExpired()
+	}
+	if err != nil || owner != subject {
+		return nil, domainerrors.QuoteInvalid()
+	}
+
+	if !sameLocation(q.Origin, req.Origin) || !sameLocation(q.Destination, req.Destination) ||
+		math.Abs(q.PayloadKG-req.PayloadKG) > 1e-9 {
+		return nil, domainerrors.QuoteMismatch()
+	}
+	return q, nil
+}
+
+// --------------------------------------------------------------
+// Agree settles the price for an order being placed: a supplied token is
+// verified, otherwise a fresh quote is issued unless quotes are mandatory.
+func (s *service) Agree(ctx context.Context, subject, token string, req Request) (*Quote, error) {
+	if token != "" {
+		return s.Verify(subject, token, req)
+	}
+	if s.cfg.RequireQuote {
+		return nil, domainerrors.NewValidation("quote_token is required, request one from POST /orders/quote")
+	}
+	return s.Quote(ctx, subject, req)
+}
+
+func (s *service) utilization(ctx context.Context) (float64, error) {
+	counts, err := s.fleet.CountByStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	idle := counts[drone.StatusIdle]
+	busy := counts[drone.StatusEnRoutePickup] + counts[drone.StatusEnRouteDelivery]
+	// With no drone in service there is no capacity to be busy; admission
+	// refuses or waitlists the order, so it is not priced at the top surge.
+	if idle+busy == 0 {
+		return 0, nil
+	}
+	return float64(busy) / float64(idle+busy), nil
+}
+
+func sameLocation(a, b common.Location) bool {
+	return math.Abs(a.Lat-b.Lat) <= coordinateTolerance && math.Abs(a.Lng-b.Lng) <= coordinateTolerance
+}
@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"drone-delivery/internal/common"
+)
+
+var (
+	ErrQuoteInvalid = errors.New("quote token is invalid")
+	ErrQuoteExpired = errors.New("quote token has expired")
+)
+
+type quoteClaims struct {
+	Origin      common.Location `json:"origin"`
+	Destination common.Location `json:"destination"`
+	PayloadKG   float64         `json:"payload_kg"`
+	Amount      int64           `json:"amount"`
+	Currency    string          `json:"currency"`
+	jwt.RegisteredClaims
+}
+
+// tokenSigner issues and verifies HMAC-signed quote tokens.
+type tokenSigner struct {
+	secret []byte
+}
+
+func newTokenSigner(secret string) *tokenSigner {
+	return &tokenSigner{secret: []byte(secret)}
+}
+
+func (s *tokenSigner) Sign(q *Quote, subject string, issuedAt time.Time) (string, error) {
+	claims := quoteClaims{
+		Origin:      q.Origin,
+		Destination: q.Destination,
+		PayloadKG:   q.PayloadKG,
+		Amount:      q.Amount,
+		Currency:    q.Currency,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        q.ID,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(q.ExpiresAt),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+func (s *tokenSigner) Parse(token string) (*Quote, string, error) {
+	parsed, err := jwt.ParseWithClaims(token, &quoteClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrQuoteInvalid
+		}
+		return s.secret, nil
+	})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, "", ErrQuoteExpired
+		}
+		return nil, "", ErrQuoteInvalid
+	}
+
+	claims, ok := parsed.Claims.(*quoteClaims)
+	if !ok || !parsed.Valid || claims.ExpiresAt == nil {
+		return nil, "", ErrQuoteInvalid
+	}
+
+	q := &Quote{
+		ID:          claims.ID,
+		Origin:      claims.Origin,
+		Destination: claims.Destination,
+		PayloadKG:   claims.PayloadKG,
+		Amount:      claims.Amount,
+		Currency:    claims.Currency,
+		ExpiresAt:   claims.ExpiresAt.Time,
+		Token:       token,
+	}
+	return q, claims.Subject, nil
+}
@@ -15,6 +15,13 @@ type CachedDroneLocation struct {
 	Lat       float64   `json:"lat"`
 	Lng       float64   `json:"lng"`
 	Timestamp time.Time `json:"timestamp"`
+
+	// Flight state from the heartbeat, when reported
+	AltitudeM      *float64 `json:"altitude_m,omitempty"`
+	HeadingDeg     *float64 `json:"heading_deg,omitempty"`
+	GroundSpeedKMH *float64 `json:"ground_speed_kmh,omitempty"`
+	BatteryPct     *int     `json:"battery_pct,omitempty"`
+	FaultCodes     []string `json:"fault_codes,omitempty"`
 }
 
 type DroneLocationCache struct {
@@ -30,11 +37,12 @@ func NewDroneLocationCache(client *goredis.Client, ttlSeconds int) *DroneLocatio
 }
 
 func (c *DroneLocationCache) Set(ctx context.Context, droneID string, loc common.Location) error {
-	data := CachedDroneLocation{
-		Lat:       loc.Lat,
-		Lng:       loc.Lng,
-		Timestamp: time.Now(),
-	}
+	return c.SetState(ctx, droneID, CachedDroneLocation{Lat: loc.Lat, Lng: loc.Lng})
+}
+
+// SetState caches the full heartbeat payload, stamped with the current time.
+func (c *DroneLocationCache) SetState(ctx context.Context, droneID string, data CachedDroneLocation) error {
+	data.Timestamp = time.Now()
 	bytes, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshal drone location: %w", err)
@@ -61,3 +69,161 @@ func (c *DroneLocationCache) Get(ctx context.Context, droneID string) (*CachedDr
 func droneLocationKey(droneID string) string {
 	return fmt.Sprintf("drone:location:%s", droneID)
 }
+
+// NearbyDrone is one GEO search hit, nearest first.
+type NearbyDrone struct {
+	DroneID    string
+	DistanceKM float64
+}
+
+// The GEO index keeps one set per drone status, plus sorted sets of the
+// heartbeat time and row version each drone was last indexed at. The keys
+// share a hash tag so the scripts, which name every key they touch in KEYS,
+// also run on a cluster.
+
+// indexScript applies what is newer of an update: the position only if its
+// heartbeat is later than the indexed one, the status only if its version
+// is not older. Callers race — a status change indexes a row read from
+// Postgres, a heartbeat the state it just accepted — so either half may be
+// stale. A drone is in at most one status set.
+// KEYS = seen set, version set, status set, other status sets;
+// ARGV = drone id, lng, lat, seen (Unix ms), version.
+var indexScript = goredis.NewScript(`
+local fresher = tonumber(ARGV[4]) > tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1]) or -1)
+local current = tonumber(ARGV[5]) >= tonumber(redis.call('ZSCORE', KEYS[2], ARGV[1]) or -1)
+if not fresher and not current then
+	return 0
+end
+local held
+for i = 3, #KEYS do
+	if redis.call('ZSCORE', KEYS[i], ARGV[1]) then
+		held = KEYS[i]
+		break
+	end
+end
+local dest = held
+if current or not held then
+	dest = KEYS[3]
+end
+local lng, lat = ARGV[2], ARGV[3]
+if not fresher and held then
+	local pos = redis.call('GEOPOS', held, ARGV[1])[1]
+	lng, lat = pos[1], pos[2]
+end
+if held and held ~= dest then
+	redis.call('ZREM', held, ARGV[1])
+end
+redis.call('GEOADD', dest, lng, lat, ARGV[1])
+redis.call('ZADD', KEYS[1], 'GT', ARGV[4], ARGV[1])
+redis.call('ZADD', KEYS[2], 'GT', ARGV[5], ARGV[1])
+return 1
+`)
+
+// unindexScript removes a drone from whichever GEO set holds it.
+// KEYS = seen set, version set, all status sets; ARGV = drone id.
+var unindexScript = goredis.NewScript(`
+for i = 1, #KEYS do
+	redis.call('ZREM', KEYS[i], ARGV[1])
+end
+return 0
+`)
+
+// pruneScript removes up to ARGV[2] drones last indexed before ARGV[1]
+// (Unix ms) from every GEO set. KEYS = seen set, version set, all status
+// sets.
+var pruneScript = goredis.NewScript(`
+local stale = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[1], 'LIMIT', 0, ARGV[2])
+if #stale == 0 then
+	return 0
+end
+for i = 1, #KEYS do
+	redis.call('ZREM', KEYS[i], unpack(stale))
+end
+return #stale
+`)
+
+// Index records the drone's position in the GEO set for status, as of the
+// heartbeat at seenAt and the drone row at version. Each drone is in at most
+// one set, so a status change moves it. Older positions and statuses than
+// the indexed ones are ignored.
+func (c *DroneLocationCache) Index(ctx context.Context, droneID, status string, loc common.Location, seenAt time.Time, version int64) error {
+	keys := []string{droneGeoSeenKey, droneGeoVersionKey, droneGeoKeyPrefix + status}
+	found := false
+	for _, st := range droneGeoStatuses {
+		if st == status {
+			found = true
+			continue
+		}
+		keys = append(keys, droneGeoKeyPrefix+st)
+	}
+	if !found {
+		return fmt.Errorf("index drone location: unknown status %q", status)
+	}
+	err := indexScript.Run(ctx, c.client, keys, droneID, loc.Lng, loc.Lat, seenAt.UnixMilli(), version).Err()
+	if err != nil {
+		return fmt.Errorf("index drone location: %w", err)
+	}
+	return nil
+}
+
+// Unindex drops the drone from the GEO index.
+func (c *DroneLocationCache) Unindex(ctx context.Context, droneID string) error {
+	err := unindexScript.Run(ctx, c.client, droneGeoKeys(), droneID).Err()
+	if err != nil {
+		return fmt.Errorf("unindex drone location: %w", err)
+	}
+	return nil
+}
+
+// Prune drops up to limit drones whose last indexed heartbeat is older than
+// before, and returns how many it dropped.
+func (c *DroneLocationCache) Prune(ctx context.Context, before time.Time, limit int) (int, error) {
+	n, err := pruneScript.Run(ctx, c.client, droneGeoKeys(), before.UnixMilli(), limit).Int()
+	if err != nil {
+		return 0, fmt.Errorf("prune drone locations: %w", err)
+	}
+	return n, nil
+}
+
+// Nearby returns up to limit drones with the given status within radiusKM
+// of loc, nearest first.
+func (c *DroneLocationCache) Nearby(ctx context.Context, status string, loc common.Location, radiusKM float64, limit int) ([]NearbyDrone, error) {
+	hits, err := c.client.GeoSearchLocation(ctx, droneGeoKeyPrefix+status, &goredis.GeoSearchLocationQuery{
+		GeoSearchQuery: goredis.GeoSearchQuery{
+			Longitude:  loc.Lng,
+			Latitude:   loc.Lat,
+			Radius:     radiusKM,
+			RadiusUnit: "km",
+			Sort:       "ASC",
+			Count:      limit,
+		},
+		WithDist: true,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("search drone locations: %w", err)
+	}
+
+	out := make([]NearbyDrone, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, NearbyDrone{DroneID: h.Name, DistanceKM: h.Dist})
+	}
+	return out, nil
+}
+
+const (
+	droneGeoKeyPrefix  = "{drone:geo}:"
+	droneGeoSeenKey    = "{drone:geo}:seen"
+	droneGeoVersionKey = "{drone:geo}:version"
+)
+
+// droneGeoStatuses are the drone statuses with a GEO set.
+var droneGeoStatuses = []string{"IDLE", "EN_ROUTE_PICKUP", "EN_ROUTE_DELIVERY", "BROKEN", "RETIRED"}
+
+// droneGeoKeys is the seen and version sets followed by every status set.
+func droneGeoKeys() []string {
+	keys := []string{droneGeoSeenKey, droneGeoVersionKey}
+	for _, st := range droneGeoStatuses {
+		keys = append(keys, droneGeoKeyPrefix+st)
+	}
+	return keys
+}
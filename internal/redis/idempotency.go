@@ -8,35 +8,96 @@ import (
 	goredis "github.com/redis/go-redis/v9"
 )
 
+// IdempotencyStore holds one opaque record per user and Idempotency-Key.
+// A request claims its key with an in-flight record that expires after
+// lockTTL, so a crashed request frees the key; the finished response then
+// replaces it for ttl.
 type IdempotencyStore struct {
-	client *goredis.Client
-	ttl    time.Duration
+	client  *goredis.Client
+	ttl     time.Duration
+	lockTTL time.Duration
 }
 
-func NewIdempotencyStore(client *goredis.Client, ttlSeconds int) *IdempotencyStore {
+func NewIdempotencyStore(client *goredis.Client, ttlSeconds, lockSeconds int) *IdempotencyStore {
 	return &IdempotencyStore{
-		client: client,
-		ttl:    time.Duration(ttlSeconds) * time.Second,
+		client:  client,
+		ttl:     time.Duration(ttlSeconds) * time.Second,
+		lockTTL: time.Duration(lockSeconds) * time.Second,
 	}
 }
 
-func (s *IdempotencyStore) Check(ctx context.Context, userID, key string) ([]byte, bool, error) {
+// Claim stores claim under the key unless the key is already held, in
+// which case it returns the record holding it.
+func (s *IdempotencyStore) Claim(ctx context.Context, userID, key string, claim []byte) ([]byte, bool, error) {
 	k := idempotencyKey(userID, key)
-	bytes, err := s.client.Get(ctx, k).Bytes()
-	if err == goredis.Nil {
-		return nil, false, nil
+	// The holder can expire between SetNX and Get; then try again
+	for range 2 {
+		ok, err := s.client.SetNX(ctx, k, claim, s.lockTTL).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("claim idempotency key: %w", err)
+		}
+		if ok {
+			return nil, true, nil
+		}
+		held, err := s.client.Get(ctx, k).Bytes()
+		if err == goredis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("read idempotency key: %w", err)
+		}
+		return held, false, nil
 	}
-	if err != nil {
-		return nil, false, fmt.Errorf("check idempotency key: %w", err)
+	return nil, false, fmt.Errorf("claim idempotency key: key changed hands twice")
+}
+
+// completeScript swaps in the finished record only while the caller's
+// claim still holds the key.
+var completeScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return false`)
+
+// releaseScript deletes the key only while the caller's claim holds it.
+var releaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// extendScript renews the claim's expiry only while it holds the key.
+var extendScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// Extend gives a claim another lockTTL, for a request still running.
+func (s *IdempotencyStore) Extend(ctx context.Context, userID, key string, claim []byte) error {
+	k := idempotencyKey(userID, key)
+	if err := extendScript.Run(ctx, s.client, []string{k}, claim, s.lockTTL.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("extend idempotency key: %w", err)
 	}
-	return bytes, true, nil
+	return nil
+}
+
+// Complete replaces claim with the finished record. It does nothing if the
+// claim expired and the key has moved on.
+func (s *IdempotencyStore) Complete(ctx context.Context, userID, key string, claim, record []byte) error {
+	k := idempotencyKey(userID, key)
+	err := completeScript.Run(ctx, s.client, []string{k}, claim, record, s.ttl.Milliseconds()).Err()
+	if err != nil && err != goredis.Nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
 }
 
-func (s *IdempotencyStore) Set(ctx context.Context, userID, key string, response []byte) error {
+// Release frees a claimed key so the request can be retried.
+func (s *IdempotencyStore) Release(ctx context.Context, userID, key string, claim []byte) error {
 	k := idempotencyKey(userID, key)
-	_, err := s.client.SetNX(ctx, k, response, s.ttl).Result()
-	if err != nil {
-		return fmt.Errorf("set idempotency key: %w", err)
+	if err := releaseScript.Run(ctx, s.client, []string{k}, claim).Err(); err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
 	}
 	return nil
 }
@@ -4,41 +4,72 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"time"
 
 	goredis "github.com/redis/go-redis/v9"
+
+	"drone-delivery/internal/pkg/ratelimit"
 )
 
+// RateLimiter keeps one token bucket per key as a Redis hash of the tokens
+// left and when they were counted. Refill and take happen in one script on
+// the Redis clock, so instances share buckets without clock skew, and each
+// bucket is a fixed two fields however busy it is.
 type RateLimiter struct {
-	client        *goredis.Client
-	maxRequests   int
-	windowSeconds int
+	client *goredis.Client
 }
 
-func NewRateLimiter(client *goredis.Client, maxRequests, windowSeconds int) *RateLimiter {
-	return &RateLimiter{
-		client:        client,
-		maxRequests:   maxRequests,
-		windowSeconds: windowSeconds,
-	}
+func NewRateLimiter(client *goredis.Client) *RateLimiter {
+	return &RateLimiter{client: client}
 }
 
-func (r *RateLimiter) Allow(ctx context.Context, ip string) (bool, error) {
-	key := fmt.Sprintf("ratelimit:%s", ip)
-	now := time.Now()
-	windowStart := now.Add(-time.Duration(r.windowSeconds) * time.Second)
+// takeScript refills the bucket for the time since it was last touched,
+// takes a token if a whole one is there, and lets the key expire once the
+// bucket would be full again. ARGV is the capacity and the milliseconds to
+// refill one token; it returns whether the take was allowed and the tokens
+// left, as a string since Redis truncates Lua numbers to integers.
+var takeScript = goredis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) / interval)
+	ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
 
-	pipe := r.client.Pipeline()
-	pipe.ZRemRangeByScore(ctx, key, "0", strconv.FormatInt(windowStart.UnixMilli(), 10))
-	countCmd := pipe.ZCard(ctx, key)
-	pipe.ZAdd(ctx, key, goredis.Z{Score: float64(now.UnixMilli()), Member: now.UnixNano()})
-	pipe.Expire(ctx, key, time.Duration(r.windowSeconds)*time.Second)
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) * interval) + 1000)
+return {allowed, tostring(tokens)}`)
 
-	_, err := pipe.Exec(ctx)
+func (r *RateLimiter) Take(ctx context.Context, key string, l ratelimit.Limit) (ratelimit.Decision, error) {
+	interval := float64(l.Interval().Microseconds()) / 1000
+	res, err := takeScript.Run(ctx, r.client, []string{"ratelimit:" + key},
+		l.Capacity(), strconv.FormatFloat(interval, 'f', -1, 64)).Slice()
 	if err != nil {
-		return false, fmt.Errorf("rate limiter pipeline: %w", err)
+		return ratelimit.Decision{}, fmt.Errorf("rate limiter script: %w", err)
 	}
-
-	count := countCmd.Val()
-	return count < int64(r.maxRequests), nil
+	if len(res) != 2 {
+		return ratelimit.Decision{}, fmt.Errorf("rate limiter script: unexpected reply %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	s, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return ratelimit.Decision{}, fmt.Errorf("rate limiter script: tokens %q: %w", s, err)
+	}
+	return ratelimit.NewDecision(l, allowed == 1, tokens), nil
 }
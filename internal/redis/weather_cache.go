@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"drone-delivery/internal/common"
+)
+
+// WeatherCache wraps a WeatherProvider with a per-grid-cell Redis cache.
+// Every location in a cell shares the conditions fetched for the cell
+// center, so a burst of reservations in one area costs one provider call
+// per TTL. It satisfies common.WeatherProvider.
+type WeatherCache struct {
+	client   *goredis.Client
+	provider common.WeatherProvider
+	ttl      time.Duration
+	cellDeg  float64
+}
+
+func NewWeatherCache(client *goredis.Client, provider common.WeatherProvider, ttlSeconds int, cellDeg float64) *WeatherCache {
+	return &WeatherCache{
+		client:   client,
+		provider: provider,
+		ttl:      time.Duration(ttlSeconds) * time.Second,
+		cellDeg:  cellDeg,
+	}
+}
+
+// GetWeather serves the cell's cached conditions, fetching them on a miss.
+// Cache failures fall through to the provider.
+func (c *WeatherCache) GetWeather(ctx context.Context, loc common.Location) (*common.Weather, error) {
+	row, col := math.Floor(loc.Lat/c.cellDeg), math.Floor(loc.Lng/c.cellDeg)
+	key := weatherCellKey(c.cellDeg, int64(row), int64(col))
+
+	if bytes, err := c.client.Get(ctx, key).Bytes(); err == nil {
+		var w common.Weather
+		if err := json.Unmarshal(bytes, &w); err == nil {
+			return &w, nil
+		}
+	}
+
+	center := common.NewLocation((row+0.5)*c.cellDeg, (col+0.5)*c.cellDeg)
+	w, err := c.provider.GetWeather(ctx, center)
+	if err != nil {
+		return nil, err
+	}
+	if bytes, err := json.Marshal(w); err == nil {
+		c.client.Set(ctx, key, bytes, c.ttl)
+	}
+	return w, nil
+}
+
+func weatherCellKey(cellDeg float64, row, col int64) string {
+	return fmt.Sprintf("weather:cell:%g:%d:%d", cellDeg, row, col)
+}
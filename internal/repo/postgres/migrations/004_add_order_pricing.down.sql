@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS quote_id,
+    DROP COLUMN IF EXISTS price_currency,
+    DROP COLUMN IF EXISTS price_amount,
+    DROP COLUMN IF EXISTS payload_kg;
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    ADD COLUMN payload_kg DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN price_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN price_currency VARCHAR(3) NOT NULL DEFAULT 'SAR',
+    ADD COLUMN quote_id VARCHAR(64);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_payment_outbox_pending;
+DROP TABLE IF EXISTS payment_outbox;
+DROP INDEX IF EXISTS idx_payments_status;
+DROP TABLE IF EXISTS payments;
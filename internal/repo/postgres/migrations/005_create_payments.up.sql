@@ -0,0 +1,30 @@
+CREATE TABLE payments (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id),
+    provider VARCHAR(50) NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    amount BIGINT NOT NULL,
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payments_status ON payments(status);
+
+CREATE TABLE payment_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    provider_ref VARCHAR(255) NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_payment_outbox_pending ON payment_outbox(id) WHERE status = 'PENDING';
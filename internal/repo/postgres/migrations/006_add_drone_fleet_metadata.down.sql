@@ -0,0 +1,8 @@
+ALTER TABLE drones
+    DROP COLUMN IF EXISTS retired_at,
+    DROP COLUMN IF EXISTS service_hours,
+    DROP COLUMN IF EXISTS maintenance_notes,
+    DROP COLUMN IF EXISTS home_lng,
+    DROP COLUMN IF EXISTS home_lat,
+    DROP COLUMN IF EXISTS capabilities,
+    DROP COLUMN IF EXISTS model;
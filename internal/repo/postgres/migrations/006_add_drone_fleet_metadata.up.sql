@@ -0,0 +1,8 @@
+ALTER TABLE drones
+    ADD COLUMN model VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN capabilities TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN home_lat DOUBLE PRECISION,
+    ADD COLUMN home_lng DOUBLE PRECISION,
+    ADD COLUMN maintenance_notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN service_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN retired_at TIMESTAMPTZ;
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS maintenance_work_orders;
+DROP TABLE IF EXISTS maintenance_schedules;
+
+ALTER TABLE drones
+    DROP COLUMN IF EXISTS maintenance_due,
+    DROP COLUMN IF EXISTS cycles_since_service,
+    DROP COLUMN IF EXISTS km_since_service,
+    DROP COLUMN IF EXISTS hours_since_service,
+    DROP COLUMN IF EXISTS flight_cycles,
+    DROP COLUMN IF EXISTS flight_km;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS reserved_at;
@@ -0,0 +1,34 @@
+ALTER TABLE jobs
+    ADD COLUMN reserved_at TIMESTAMPTZ;
+
+ALTER TABLE drones
+    ADD COLUMN flight_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN flight_cycles INT NOT NULL DEFAULT 0,
+    ADD COLUMN hours_since_service DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN km_since_service DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN cycles_since_service INT NOT NULL DEFAULT 0,
+    ADD COLUMN maintenance_due BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE maintenance_schedules (
+    model VARCHAR(100) PRIMARY KEY,
+    max_flight_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_flight_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_cycles INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE maintenance_work_orders (
+    id UUID PRIMARY KEY,
+    drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+    kind VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    description TEXT NOT NULL DEFAULT '',
+    resolution TEXT NOT NULL DEFAULT '',
+    opened_by VARCHAR(255) NOT NULL,
+    completed_by VARCHAR(255),
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_work_orders_drone ON maintenance_work_orders(drone_id, opened_at DESC);
+CREATE INDEX idx_work_orders_open ON maintenance_work_orders(drone_id) WHERE status = 'OPEN';
@@ -0,0 +1,15 @@
+-- Daily partitions (drone_telemetry_YYYYMMDD) are created ahead of time and
+-- dropped after the retention window by telemetry.PartitionManager. The
+-- default partition only catches rows that arrive before their day exists.
+CREATE TABLE drone_telemetry (
+    drone_id VARCHAR(255) NOT NULL,
+    order_id UUID,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL
+) PARTITION BY RANGE (recorded_at);
+
+CREATE TABLE drone_telemetry_default PARTITION OF drone_telemetry DEFAULT;
+
+CREATE INDEX idx_drone_telemetry_drone ON drone_telemetry(drone_id, recorded_at);
+CREATE INDEX idx_drone_telemetry_order ON drone_telemetry(order_id, recorded_at) WHERE order_id IS NOT NULL;
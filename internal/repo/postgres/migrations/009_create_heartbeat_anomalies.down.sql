@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS drone_anomalies;
+
+ALTER TABLE drones
+    DROP COLUMN IF EXISTS quarantine_reason,
+    DROP COLUMN IF EXISTS quarantined,
+    DROP COLUMN IF EXISTS last_device_time,
+    DROP COLUMN IF EXISTS last_heartbeat_seq;
@@ -0,0 +1,36 @@
+ALTER TABLE drones
+    ADD COLUMN last_heartbeat_seq BIGINT,
+    ADD COLUMN last_device_time TIMESTAMPTZ,
+    ADD COLUMN quarantined BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN quarantine_reason TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE drone_anomalies (
+    id UUID PRIMARY KEY,
+    drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+    kind VARCHAR(30) NOT NULL,
+    rejected BOOLEAN NOT NULL,
+    sequence BIGINT,
+    device_time TIMESTAMPTZ,
+    prev_latitude DOUBLE PRECISION,
+    prev_longitude DOUBLE PRECISION,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    speed_kmh DOUBLE PRECISION,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_drone_anomalies_drone ON drone_anomalies(drone_id, detected_at DESC);
+
+CREATE TABLE alerts (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    drone_id VARCHAR(255),
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by VARCHAR(255)
+);
+
+CREATE INDEX idx_alerts_created ON alerts(created_at DESC);
+CREATE INDEX idx_alerts_unacknowledged ON alerts(created_at DESC) WHERE acknowledged_at IS NULL;
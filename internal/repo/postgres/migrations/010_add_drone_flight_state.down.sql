@@ -0,0 +1,6 @@
+ALTER TABLE drones
+    DROP COLUMN IF EXISTS fault_codes,
+    DROP COLUMN IF EXISTS battery_pct,
+    DROP COLUMN IF EXISTS ground_speed_kmh,
+    DROP COLUMN IF EXISTS heading_deg,
+    DROP COLUMN IF EXISTS altitude_m;
@@ -0,0 +1,8 @@
+-- Latest values reported by the drone's heartbeat; NULL when the drone did
+-- not report them.
+ALTER TABLE drones
+    ADD COLUMN altitude_m DOUBLE PRECISION,
+    ADD COLUMN heading_deg DOUBLE PRECISION,
+    ADD COLUMN ground_speed_kmh DOUBLE PRECISION,
+    ADD COLUMN battery_pct SMALLINT,
+    ADD COLUMN fault_codes TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,18 @@
+CREATE TABLE drone_commands (
+    id UUID PRIMARY KEY,
+    drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+    type VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    order_id UUID REFERENCES orders(id),
+    reason TEXT NOT NULL DEFAULT '',
+    issued_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ,
+    acknowledged_at TIMESTAMPTZ,
+    result_message TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_drone_commands_drone ON drone_commands(drone_id, created_at DESC);
+CREATE INDEX idx_drone_commands_outstanding ON drone_commands(drone_id, created_at)
+    WHERE status IN ('PENDING', 'DELIVERED');
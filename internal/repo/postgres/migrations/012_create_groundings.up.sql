@@ -0,0 +1,19 @@
+-- Flight bans. A FLEET grounding covers every drone; an AREA grounding covers
+-- the circle around (center_lat, center_lng).
+CREATE TABLE groundings (
+    id UUID PRIMARY KEY,
+    scope VARCHAR(10) NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    center_lat DOUBLE PRECISION,
+    center_lng DOUBLE PRECISION,
+    radius_km DOUBLE PRECISION,
+    reason TEXT NOT NULL,
+    order_policy VARCHAR(10) NOT NULL,
+    drone_action VARCHAR(30) NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    lifted_at TIMESTAMPTZ,
+    lifted_by VARCHAR(255)
+);
+
+CREATE INDEX idx_groundings_active ON groundings(created_at) WHERE lifted_at IS NULL;
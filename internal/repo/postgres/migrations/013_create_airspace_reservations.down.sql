@@ -0,0 +1 @@
+DROP TABLE IF EXISTS airspace_reservations;
@@ -0,0 +1,23 @@
+-- 4D corridor reservations: the straight-line legs start -> pickup -> dropoff
+-- flown in one altitude band between starts_at and ends_at.
+CREATE TABLE airspace_reservations (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+    start_lat DOUBLE PRECISION NOT NULL,
+    start_lng DOUBLE PRECISION NOT NULL,
+    pickup_lat DOUBLE PRECISION NOT NULL,
+    pickup_lng DOUBLE PRECISION NOT NULL,
+    dropoff_lat DOUBLE PRECISION NOT NULL,
+    dropoff_lng DOUBLE PRECISION NOT NULL,
+    band INT NOT NULL,
+    min_altitude_m DOUBLE PRECISION NOT NULL,
+    max_altitude_m DOUBLE PRECISION NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_airspace_reservations_active ON airspace_reservations(ends_at) WHERE released_at IS NULL;
+CREATE INDEX idx_airspace_reservations_drone ON airspace_reservations(drone_id) WHERE released_at IS NULL;
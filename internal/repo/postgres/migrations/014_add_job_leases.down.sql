@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_jobs_reserved_by_drone;
+DROP INDEX IF EXISTS idx_jobs_lease_expires;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS release_reason,
+    DROP COLUMN IF EXISTS lease_distance_km,
+    DROP COLUMN IF EXISTS lease_expires_at;
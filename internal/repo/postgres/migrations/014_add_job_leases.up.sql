@@ -0,0 +1,9 @@
+-- A reservation holds its job only until the drone should have reached the
+-- pickup; the lease sweeper reopens jobs whose lease ran out.
+ALTER TABLE jobs
+    ADD COLUMN lease_expires_at TIMESTAMPTZ,
+    ADD COLUMN lease_distance_km DOUBLE PRECISION,
+    ADD COLUMN release_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_jobs_lease_expires ON jobs(lease_expires_at) WHERE status = 'RESERVED';
+CREATE INDEX idx_jobs_reserved_by_drone ON jobs(reserved_by_drone_id) WHERE status = 'RESERVED';
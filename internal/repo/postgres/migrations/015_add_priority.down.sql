@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_jobs_open_queue;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS priority;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS service_tier;
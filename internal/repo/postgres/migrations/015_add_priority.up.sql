@@ -0,0 +1,10 @@
+-- Orders carry a service tier that sets their starting priority; jobs copy
+-- it and are dispatched highest priority first, then oldest first.
+ALTER TABLE orders
+    ADD COLUMN service_tier VARCHAR(20) NOT NULL DEFAULT 'STANDARD',
+    ADD COLUMN priority INT NOT NULL DEFAULT 0;
+
+ALTER TABLE jobs
+    ADD COLUMN priority INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_jobs_open_queue ON jobs(priority DESC, created_at) WHERE status = 'OPEN';
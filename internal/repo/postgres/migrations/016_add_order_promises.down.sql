@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_orders_waitlisted;
+DROP INDEX IF EXISTS idx_orders_delivered_at;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS picked_up_at,
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS promised_delivery_at,
+    DROP COLUMN IF EXISTS promised_pickup_at;
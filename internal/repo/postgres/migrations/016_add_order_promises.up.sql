@@ -0,0 +1,11 @@
+-- Orders are promised a pickup and delivery time at placement; the actual
+-- times are recorded alongside so promises can be checked afterwards.
+ALTER TABLE orders
+    ADD COLUMN promised_pickup_at TIMESTAMPTZ,
+    ADD COLUMN promised_delivery_at TIMESTAMPTZ,
+    ADD COLUMN assigned_at TIMESTAMPTZ,
+    ADD COLUMN picked_up_at TIMESTAMPTZ,
+    ADD COLUMN delivered_at TIMESTAMPTZ;
+
+CREATE INDEX idx_orders_delivered_at ON orders(delivered_at DESC) WHERE status = 'DELIVERED';
+CREATE INDEX idx_orders_waitlisted ON orders(created_at) WHERE status = 'WAITLISTED';
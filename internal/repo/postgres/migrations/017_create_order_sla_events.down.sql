@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_orders_open;
+DROP TABLE IF EXISTS order_sla_events;
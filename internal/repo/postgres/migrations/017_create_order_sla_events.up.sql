@@ -0,0 +1,15 @@
+-- SLA findings per order: each milestone (assign, pickup, delivery) is
+-- flagged at most once as at risk and once as breached.
+CREATE TABLE order_sla_events (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    service_tier VARCHAR(20) NOT NULL,
+    milestone VARCHAR(20) NOT NULL,
+    state VARCHAR(20) NOT NULL,
+    due_at TIMESTAMPTZ NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, milestone, state)
+);
+
+CREATE INDEX idx_order_sla_events_detected ON order_sla_events(state, detected_at DESC);
+CREATE INDEX idx_orders_open ON orders(created_at, id) WHERE status NOT IN ('DELIVERED', 'FAILED', 'WITHDRAWN');
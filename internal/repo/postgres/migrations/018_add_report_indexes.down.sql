@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_jobs_completed;
+DROP INDEX IF EXISTS idx_orders_created_at;
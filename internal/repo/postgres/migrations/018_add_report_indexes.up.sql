@@ -0,0 +1,3 @@
+-- Reports aggregate orders by placement time and drones by completed jobs.
+CREATE INDEX idx_orders_created_at ON orders(created_at);
+CREATE INDEX idx_jobs_completed ON jobs(updated_at) WHERE status = 'COMPLETED';
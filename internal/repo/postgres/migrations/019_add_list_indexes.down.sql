@@ -0,0 +1,23 @@
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+DROP INDEX IF EXISTS idx_jobs_drone_created;
+DROP INDEX IF EXISTS idx_jobs_status_created;
+DROP INDEX IF EXISTS idx_jobs_priority_id;
+DROP INDEX IF EXISTS idx_jobs_updated_id;
+DROP INDEX IF EXISTS idx_jobs_created_id;
+
+CREATE INDEX IF NOT EXISTS idx_drones_status ON drones(status);
+DROP INDEX IF EXISTS idx_drones_position;
+DROP INDEX IF EXISTS idx_drones_status_created;
+DROP INDEX IF EXISTS idx_drones_updated_id;
+DROP INDEX IF EXISTS idx_drones_created_id;
+
+CREATE INDEX IF NOT EXISTS idx_orders_submitted_by ON orders(submitted_by);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at);
+DROP INDEX IF EXISTS idx_orders_origin;
+DROP INDEX IF EXISTS idx_orders_drone_created;
+DROP INDEX IF EXISTS idx_orders_submitted_by_created;
+DROP INDEX IF EXISTS idx_orders_status_created;
+DROP INDEX IF EXISTS idx_orders_priority_id;
+DROP INDEX IF EXISTS idx_orders_updated_id;
+DROP INDEX IF EXISTS idx_orders_created_id;
@@ -0,0 +1,26 @@
+-- Keyset pagination for the order, drone and job lists: every sort column
+-- paired with id, and each equality filter followed by the default sort.
+-- Single-column indexes these make redundant are dropped.
+CREATE INDEX idx_orders_created_id ON orders(created_at, id);
+CREATE INDEX idx_orders_updated_id ON orders(updated_at, id);
+CREATE INDEX idx_orders_priority_id ON orders(priority, id);
+CREATE INDEX idx_orders_status_created ON orders(status, created_at, id);
+CREATE INDEX idx_orders_submitted_by_created ON orders(submitted_by, created_at, id);
+CREATE INDEX idx_orders_drone_created ON orders(assigned_drone_id, created_at, id);
+CREATE INDEX idx_orders_origin ON orders(origin_lat, origin_lng);
+DROP INDEX IF EXISTS idx_orders_created_at;
+DROP INDEX IF EXISTS idx_orders_status;
+DROP INDEX IF EXISTS idx_orders_submitted_by;
+
+CREATE INDEX idx_drones_created_id ON drones(created_at, id);
+CREATE INDEX idx_drones_updated_id ON drones(updated_at, id);
+CREATE INDEX idx_drones_status_created ON drones(status, created_at, id);
+CREATE INDEX idx_drones_position ON drones(latitude, longitude);
+DROP INDEX IF EXISTS idx_drones_status;
+
+CREATE INDEX idx_jobs_created_id ON jobs(created_at, id);
+CREATE INDEX idx_jobs_updated_id ON jobs(updated_at, id);
+CREATE INDEX idx_jobs_priority_id ON jobs(priority, id);
+CREATE INDEX idx_jobs_status_created ON jobs(status, created_at, id);
+CREATE INDEX idx_jobs_drone_created ON jobs(reserved_by_drone_id, created_at, id);
+DROP INDEX IF EXISTS idx_jobs_status;
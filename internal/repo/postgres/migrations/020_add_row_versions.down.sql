@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS version;
+ALTER TABLE drones DROP COLUMN IF EXISTS version;
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
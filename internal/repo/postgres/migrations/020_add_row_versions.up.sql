@@ -0,0 +1,6 @@
+-- Every write to an order, drone or job advances its version. Updates name
+-- the version they read, so a stale copy can't overwrite a newer write, and
+-- admin edits expose it as an ETag for If-Match.
+ALTER TABLE orders ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE drones ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE jobs ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
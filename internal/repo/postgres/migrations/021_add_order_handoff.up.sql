@@ -0,0 +1,10 @@
+-- An order that has been through a handoff stays in the handoff pool: if it
+-- is unassigned again it goes back to AWAITING_HANDOFF, not PENDING, since
+-- the parcel may already have left the origin.
+ALTER TABLE orders ADD COLUMN handed_off BOOLEAN NOT NULL DEFAULT false;
+
+-- A handoff cancels the order's job and opens a new one
+UPDATE orders o SET handed_off = true
+WHERE o.status = 'AWAITING_HANDOFF'
+   OR (o.status IN ('ASSIGNED', 'PICKED_UP')
+       AND EXISTS (SELECT 1 FROM jobs j WHERE j.order_id = o.id AND j.status = 'CANCELLED'));
@@ -0,0 +1,3 @@
+UPDATE payment_outbox SET status = 'PENDING' WHERE status = 'IN_PROGRESS';
+DROP INDEX IF EXISTS idx_payment_outbox_claimed;
+ALTER TABLE payment_outbox DROP COLUMN IF EXISTS claimed_until;
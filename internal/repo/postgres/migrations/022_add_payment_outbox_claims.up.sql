@@ -0,0 +1,5 @@
+-- The dispatcher claims entries (status IN_PROGRESS) and commits before it
+-- calls the provider; a claim that lapses is picked up again.
+ALTER TABLE payment_outbox ADD COLUMN claimed_until TIMESTAMPTZ;
+
+CREATE INDEX idx_payment_outbox_claimed ON payment_outbox(claimed_until) WHERE status = 'IN_PROGRESS';
@@ -0,0 +1,3 @@
+-- The open-jobs page is read in dispatch order straight from the database;
+-- without aging that order is this index.
+CREATE INDEX idx_jobs_open_dispatch ON jobs(priority DESC, created_at, id) WHERE status = 'OPEN';
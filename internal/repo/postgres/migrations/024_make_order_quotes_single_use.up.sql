@@ -0,0 +1,12 @@
+-- A quote prices exactly one order; placing a second order with the same
+-- token is refused. NULLs (orders placed before quotes) don't collide.
+-- Orders that reused a quote before this keep their price but lose the
+-- quote reference; the first order placed with it keeps it.
+UPDATE orders o SET quote_id = NULL
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY quote_id ORDER BY created_at, id) AS n
+    FROM orders WHERE quote_id IS NOT NULL
+) dup
+WHERE o.id = dup.id AND dup.n > 1;
+
+CREATE UNIQUE INDEX idx_orders_quote_id ON orders(quote_id);
@@ -0,0 +1 @@
+ALTER TABLE drones DROP COLUMN IF EXISTS last_boot_id;
@@ -0,0 +1,3 @@
+-- The firmware session of the last accepted heartbeat. A heartbeat from a
+-- new boot restarts the sequence and device clock checks.
+ALTER TABLE drones ADD COLUMN last_boot_id VARCHAR(64);
@@ -0,0 +1,2 @@
+-- Serves the bounding box of the open jobs nearest to a drone.
+CREATE INDEX idx_orders_origin ON orders(origin_lat, origin_lng);
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS repromised_pickup_at,
+    DROP COLUMN IF EXISTS repromised_delivery_at;
@@ -0,0 +1,5 @@
+-- A waitlisted order keeps the promise it was placed with; the one made when
+-- it is released for dispatch is stored alongside.
+ALTER TABLE orders
+    ADD COLUMN repromised_pickup_at TIMESTAMPTZ,
+    ADD COLUMN repromised_delivery_at TIMESTAMPTZ;
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"time"
+
+	"drone-delivery/internal/weather"
+)
+
+// Filter selects orders placed in [From, To), optionally only those picked
+// up inside Zone.
+type Filter struct {
+	From time.Time
+	To   time.Time
+	Zone *weather.Zone
+}
+
+// Durations summarises a set of order timings, in seconds.
+type Durations struct {
+	Count int     `db:"count" json:"count"`
+	Mean  float64 `db:"mean" json:"mean_seconds"`
+	P50   float64 `db:"p50" json:"p50_seconds"`
+	P90   float64 `db:"p90" json:"p90_seconds"`
+	P95   float64 `db:"p95" json:"p95_seconds"`
+}
+
+// Counts are orders by outcome.
+type Counts struct {
+	Placed    int `db:"placed" json:"placed"`
+	Delivered int `db:"delivered" json:"delivered"`
+	Failed    int `db:"failed" json:"failed"`
+	Withdrawn int `db:"withdrawn" json:"withdrawn"`
+	Open      int `db:"open" json:"open"`
+}
+
+// SuccessRate is the percentage of finished deliveries that succeeded.
+func (c Counts) SuccessRate() float64 {
+	if c.Delivered+c.Failed == 0 {
+		return 0
+	}
+	return percent(float64(c.Delivered), float64(c.Delivered+c.Failed))
+}
+
+// Handoffs counts replacement jobs created after a drone broke down.
+type Handoffs struct {
+	Handoffs int `db:"handoffs" json:"handoffs"`
+	Orders   int `db:"orders" json:"orders_handed_off"`
+}
+
+type Summary struct {
+	From          time.Time `json:"from"`
+	To            time.Time `json:"to"`
+	Zone          string    `json:"zone,omitempty"`
+	Counts        Counts    `json:"counts"`
+	SuccessRate   float64   `json:"success_rate"`
+	Handoffs      Handoffs  `json:"handoffs"`
+	TimeToAssign  Durations `json:"time_to_assign"`
+	TimeToDeliver Durations `json:"time_to_deliver"`
+}
+
+// Day is one UTC day of placements.
+type Day struct {
+	Day string `db:"day" json:"day"` // YYYY-MM-DD
+	Counts
+	SuccessRate         float64 `db:"-" json:"success_rate"`
+	MeanDeliverySeconds float64 `db:"mean_delivery_seconds" json:"mean_delivery_seconds"`
+}
+
+// DroneUtilization covers the jobs a drone completed in the range. Busy
+// time runs from reservation to completion.
+type DroneUtilization struct {
+	DroneID     string  `db:"drone_id" json:"drone_id"`
+	Jobs        int     `db:"jobs" json:"jobs"`
+	Delivered   int     `db:"delivered" json:"delivered"`
+	Failed      int     `db:"failed" json:"failed"`
+	BusyHours   float64 `db:"busy_hours" json:"busy_hours"`
+	Utilization float64 `db:"-" json:"utilization"` // percent of the range spent busy
+}
+
+// DemandCell counts orders picked up in one grid cell; Lat and Lng are its
+// center.
+type DemandCell struct {
+	Lat    float64 `db:"lat" json:"lat"`
+	Lng    float64 `db:"lng" json:"lng"`
+	Orders int     `db:"orders" json:"orders"`
+}
@@ -0,0 +1,215 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"drone-delivery/internal/pkg/apperrors"
+	"drone-delivery/internal/weather"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReportDays = 7
+	maxReportDays     = 92
+	defaultCellDeg    = 0.01
+)
+
+type Handler struct {
+	service Service
+	zones   map[string]weather.Zone
+}
+
+func NewHandler(service Service, zones []weather.Zone) *Handler {
+	byName := make(map[string]weather.Zone, len(zones))
+	for _, z := range zones {
+		byName[z.Name] = z
+	}
+	return &Handler{service: service, zones: byName}
+}
+
+// --------------------------------------------------------------
+// Summary returns order outcomes, success rate, handoffs and time-to-assign
+// and time-to-deliver percentiles for the range.
+func (h *Handler) Summary(c *gin.Context) {
+	f, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	s, err := h.service.Summary(c.Request.Context(), f)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if !wantsCSV(c) {
+		c.JSON(http.StatusOK, gin.H{"summary": s})
+		return
+	}
+	writeCSV(c, "summary", []string{"from", "to", "zone", "placed", "delivered", "failed", "withdrawn", "open",
+		"success_rate", "handoffs", "orders_handed_off",
+		"assign_count", "assign_mean_seconds", "assign_p50_seconds", "assign_p90_seconds", "assign_p95_seconds",
+		"deliver_count", "deliver_mean_seconds", "deliver_p50_seconds", "deliver_p90_seconds", "deliver_p95_seconds"},
+		[][]string{append(append([]string{
+			s.From.Format(time.RFC3339), s.To.Format(time.RFC3339), s.Zone,
+			itoa(s.Counts.Placed), itoa(s.Counts.Delivered), itoa(s.Counts.Failed), itoa(s.Counts.Withdrawn), itoa(s.Counts.Open),
+			ftoa(s.SuccessRate), itoa(s.Handoffs.Handoffs), itoa(s.Handoffs.Orders),
+		}, durationCells(s.TimeToAssign)...), durationCells(s.TimeToDeliver)...)})
+}
+
+// --------------------------------------------------------------
+// Daily returns order outcomes per UTC day of placement.
+func (h *Handler) Daily(c *gin.Context) {
+	f, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	days, err := h.service.Daily(c.Request.Context(), f)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if !wantsCSV(c) {
+		if days == nil {
+			days = []*Day{}
+		}
+		c.JSON(http.StatusOK, gin.H{"days": days})
+		return
+	}
+	rows := make([][]string, 0, len(days))
+	for _, d := range days {
+		rows = append(rows, []string{d.Day, itoa(d.Placed), itoa(d.Delivered), itoa(d.Failed), itoa(d.Withdrawn), itoa(d.Open),
+			ftoa(d.SuccessRate), ftoa(d.MeanDeliverySeconds)})
+	}
+	writeCSV(c, "daily", []string{"day", "placed", "delivered", "failed", "withdrawn", "open", "success_rate", "mean_delivery_seconds"}, rows)
+}
+
+// --------------------------------------------------------------
+// Drones returns per-drone utilization over jobs completed in the range.
+func (h *Handler) Drones(c *gin.Context) {
+	f, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	drones, err := h.service.Drones(c.Request.Context(), f)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if !wantsCSV(c) {
+		if drones == nil {
+			drones = []*DroneUtilization{}
+		}
+		c.JSON(http.StatusOK, gin.H{"drones": drones})
+		return
+	}
+	rows := make([][]string, 0, len(drones))
+	for _, d := range drones {
+		rows = append(rows, []string{d.DroneID, itoa(d.Jobs), itoa(d.Delivered), itoa(d.Failed), ftoa(d.BusyHours), ftoa(d.Utilization)})
+	}
+	writeCSV(c, "drones", []string{"drone_id", "jobs", "delivered", "failed", "busy_hours", "utilization"}, rows)
+}
+
+// --------------------------------------------------------------
+// Demand returns order counts per pickup grid cell (cell degrees, default
+// 0.01 — about 1 km).
+func (h *Handler) Demand(c *gin.Context) {
+	f, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	cell := defaultCellDeg
+	if v := c.Query("cell"); v != "" {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0.001 || n > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "cell must be between 0.001 and 1 degrees"}})
+			return
+		}
+		cell = n
+	}
+	cells, err := h.service.Demand(c.Request.Context(), f, cell)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	if !wantsCSV(c) {
+		if cells == nil {
+			cells = []*DemandCell{}
+		}
+		c.JSON(http.StatusOK, gin.H{"cell_deg": cell, "cells": cells})
+		return
+	}
+	rows := make([][]string, 0, len(cells))
+	for _, d := range cells {
+		rows = append(rows, []string{ftoa(d.Lat), ftoa(d.Lng), itoa(d.Orders)})
+	}
+	writeCSV(c, "demand", []string{"lat", "lng", "orders"}, rows)
+}
+
+// parseFilter reads from and to (inclusive UTC dates, YYYY-MM-DD,
+// defaulting to the last 7 days) and zone. It writes the 400 itself.
+func (h *Handler) parseFilter(c *gin.Context) (Filter, bool) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "to must be a date (YYYY-MM-DD)"}})
+			return Filter{}, false
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -(defaultReportDays - 1))
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "from must be a date (YYYY-MM-DD)"}})
+			return Filter{}, false
+		}
+		from = t
+	}
+	end := to.AddDate(0, 0, 1)
+	if !from.Before(end) || end.Sub(from) > maxReportDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "from must be on or before to, at most 92 days apart"}})
+		return Filter{}, false
+	}
+
+	f := Filter{From: from, To: end}
+	if name := c.Query("zone"); name != "" {
+		z, ok := h.zones[name]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": fmt.Sprintf("unknown zone %q", name)}})
+			return Filter{}, false
+		}
+		f.Zone = &z
+	}
+	return f, true
+}
+
+// wantsCSV reports whether the caller asked for CSV, with format=csv or an
+// Accept header of text/csv.
+func wantsCSV(c *gin.Context) bool {
+	if f := c.Query("format"); f != "" {
+		return f == "csv"
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, "text/csv") == "text/csv"
+}
+
+func writeCSV(c *gin.Context, name string, header []string, rows [][]string) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(header)
+	_ = w.WriteAll(rows)
+}
+
+func durationCells(d Durations) []string {
+	return []string{itoa(d.Count), ftoa(d.Mean), ftoa(d.P50), ftoa(d.P90), ftoa(d.P95)}
+}
+
+func itoa(n int) string { return strconv.Itoa(n) }
+
+func ftoa(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
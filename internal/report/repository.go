@@ -0,0 +1,159 @@
+package report
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	Counts(ctx context.Context, ext sqlx.ExtContext, f Filter) (Counts, error)
+	Handoffs(ctx context.Context, ext sqlx.ExtContext, f Filter) (Handoffs, error)
+	TimeToAssign(ctx context.Context, ext sqlx.ExtContext, f Filter) (Durations, error)
+	TimeToDeliver(ctx context.Context, ext sqlx.ExtContext, f Filter) (Durations, error)
+	Daily(ctx context.Context, ext sqlx.ExtContext, f Filter) ([]*Day, error)
+	Drones(ctx context.Context, ext sqlx.ExtContext, f Filter) ([]*DroneUtilization, error)
+	Demand(ctx context.Context, ext sqlx.ExtContext, f Filter, cellDeg float64) ([]*DemandCell, error)
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+const countColumns = `COUNT(*) AS placed,
+	COUNT(*) FILTER (WHERE o.status = 'DELIVERED') AS delivered,
+	COUNT(*) FILTER (WHERE o.status = 'FAILED') AS failed,
+	COUNT(*) FILTER (WHERE o.status = 'WITHDRAWN') AS withdrawn,
+	COUNT(*) FILTER (WHERE o.status NOT IN ('DELIVERED', 'FAILED', 'WITHDRAWN')) AS open`
+
+// orderFilter is the WHERE clause selecting orders (aliased o) by
+// placement time and pickup zone, with its arguments.
+func orderFilter(f Filter) (string, []any) {
+	where := `o.created_at >= $1 AND o.created_at < $2`
+	args := []any{f.From, f.To}
+	if f.Zone != nil {
+		where += ` AND ` + withinZone
+		args = append(args, f.Zone.Center.Lat, f.Zone.Center.Lng, f.Zone.RadiusKM)
+	}
+	return where, args
+}
+
+// withinZone is the haversine distance from the pickup to the zone center
+// ($3, $4) against its radius ($5).
+const withinZone = `6371 * 2 * ASIN(SQRT(
+	POWER(SIN(RADIANS(o.origin_lat - $3) / 2), 2) +
+	COS(RADIANS($3)) * COS(RADIANS(o.origin_lat)) * POWER(SIN(RADIANS(o.origin_lng - $4) / 2), 2)
+)) <= $5`
+
+// --------------------------------------------------------------
+func (r *repo) Counts(ctx context.Context, ext sqlx.ExtContext, f Filter) (Counts, error) {
+	where, args := orderFilter(f)
+	var c Counts
+	query := fmt.Sprintf(`SELECT %s FROM orders o WHERE %s`, countColumns, where)
+	if err := sqlx.GetContext(ctx, ext, &c, query, args...); err != nil {
+		return Counts{}, err
+	}
+	return c, nil
+}
+
+// --------------------------------------------------------------
+// Handoffs counts the extra jobs of the selected orders: an order gets a
+// new job each time its drone breaks down mid-delivery.
+func (r *repo) Handoffs(ctx context.Context, ext sqlx.ExtContext, f Filter) (Handoffs, error) {
+	where, args := orderFilter(f)
+	var h Handoffs
+	query := fmt.Sprintf(`SELECT COALESCE(SUM(n - 1), 0) AS handoffs, COUNT(*) FILTER (WHERE n > 1) AS orders
+		FROM (SELECT COUNT(*) AS n FROM jobs j JOIN orders o ON o.id = j.order_id
+			WHERE %s GROUP BY j.order_id) per_order`, where)
+	if err := sqlx.GetContext(ctx, ext, &h, query, args...); err != nil {
+		return Handoffs{}, err
+	}
+	return h, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) TimeToAssign(ctx context.Context, ext sqlx.ExtContext, f Filter) (Durations, error) {
+	return r.durations(ctx, ext, f, `o.assigned_at - o.created_at`, `o.assigned_at IS NOT NULL`)
+}
+
+// --------------------------------------------------------------
+func (r *repo) TimeToDeliver(ctx context.Context, ext sqlx.ExtContext, f Filter) (Durations, error) {
+	return r.durations(ctx, ext, f, `o.delivered_at - o.created_at`, `o.status = 'DELIVERED' AND o.delivered_at IS NOT NULL`)
+}
+
+// durations summarises interval over the selected orders matching cond.
+func (r *repo) durations(ctx context.Context, ext sqlx.ExtContext, f Filter, interval, cond string) (Durations, error) {
+	where, args := orderFilter(f)
+	var d Durations
+	query := fmt.Sprintf(`SELECT COUNT(s) AS count,
+			COALESCE(AVG(s), 0) AS mean,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY s), 0) AS p50,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY s), 0) AS p90,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY s), 0) AS p95
+		FROM (SELECT EXTRACT(EPOCH FROM (%s)) AS s FROM orders o WHERE %s AND %s) t`, interval, where, cond)
+	if err := sqlx.GetContext(ctx, ext, &d, query, args...); err != nil {
+		return Durations{}, err
+	}
+	return d, nil
+}
+
+// --------------------------------------------------------------
+// Daily groups the selected orders by UTC day of placement.
+func (r *repo) Daily(ctx context.Context, ext sqlx.ExtContext, f Filter) ([]*Day, error) {
+	where, args := orderFilter(f)
+	var out []*Day
+	query := fmt.Sprintf(`SELECT to_char(o.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, %s,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (o.delivered_at - o.created_at))) FILTER (WHERE o.status = 'DELIVERED'), 0) AS mean_delivery_seconds
+		FROM orders o WHERE %s GROUP BY day ORDER BY day`, countColumns, where)
+	if err := sqlx.SelectContext(ctx, ext, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------
+// Drones sums the jobs completed in [From, To) per drone, busiest first.
+// The zone, if any, applies to the jobs' pickups.
+func (r *repo) Drones(ctx context.Context, ext sqlx.ExtContext, f Filter) ([]*DroneUtilization, error) {
+	where := `j.status = 'COMPLETED' AND j.updated_at >= $1 AND j.updated_at < $2 AND j.reserved_by_drone_id IS NOT NULL`
+	args := []any{f.From, f.To}
+	if f.Zone != nil {
+		where += ` AND ` + withinZone
+		args = append(args, f.Zone.Center.Lat, f.Zone.Center.Lng, f.Zone.RadiusKM)
+	}
+	var out []*DroneUtilization
+	query := fmt.Sprintf(`SELECT j.reserved_by_drone_id AS drone_id,
+			COUNT(*) AS jobs,
+			COUNT(*) FILTER (WHERE o.status = 'DELIVERED') AS delivered,
+			COUNT(*) FILTER (WHERE o.status = 'FAILED') AS failed,
+			COALESCE(SUM(EXTRACT(EPOCH FROM (j.updated_at - j.reserved_at))), 0) / 3600 AS busy_hours
+		FROM jobs j JOIN orders o ON o.id = j.order_id
+		WHERE %s
+		GROUP BY j.reserved_by_drone_id ORDER BY busy_hours DESC, drone_id`, where)
+	if err := sqlx.SelectContext(ctx, ext, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------
+// Demand counts the selected orders per pickup cell of cellDeg degrees,
+// busiest first.
+func (r *repo) Demand(ctx context.Context, ext sqlx.ExtContext, f Filter, cellDeg float64) ([]*DemandCell, error) {
+	where, args := orderFilter(f)
+	n := len(args) + 1
+	args = append(args, cellDeg)
+	var out []*DemandCell
+	query := fmt.Sprintf(`SELECT (FLOOR(o.origin_lat / $%[1]d) + 0.5) * $%[1]d AS lat,
+			(FLOOR(o.origin_lng / $%[1]d) + 0.5) * $%[1]d AS lng,
+			COUNT(*) AS orders
+		FROM orders o WHERE %[2]s
+		GROUP BY 1, 2 ORDER BY orders DESC, lat, lng`, n, where)
+	if err := sqlx.SelectContext(ctx, ext, &out, query, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"context"
+	"math"
+
+	"github.com/jmoiron/sqlx"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+type Service interface {
+	Summary(ctx context.Context, f Filter) (*Summary, error)
+	Daily(ctx context.Context, f Filter) ([]*Day, error)
+	Drones(ctx context.Context, f Filter) ([]*DroneUtilization, error)
+	Demand(ctx context.Context, f Filter, cellDeg float64) ([]*DemandCell, error)
+}
+
+type service struct {
+	db   *sqlx.DB
+	repo Repository
+}
+
+func NewService(db *sqlx.DB, repo Repository) Service {
+	return &service{db: db, repo: repo}
+}
+
+// --------------------------------------------------------------
+func (s *service) Summary(ctx context.Context, f Filter) (*Summary, error) {
+	counts, err := s.repo.Counts(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to count orders", err)
+	}
+	handoffs, err := s.repo.Handoffs(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to count handoffs", err)
+	}
+	toAssign, err := s.repo.TimeToAssign(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to compute time to assign", err)
+	}
+	toDeliver, err := s.repo.TimeToDeliver(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to compute time to deliver", err)
+	}
+
+	sum := &Summary{
+		From:          f.From,
+		To:            f.To,
+		Counts:        counts,
+		SuccessRate:   counts.SuccessRate(),
+		Handoffs:      handoffs,
+		TimeToAssign:  toAssign,
+		TimeToDeliver: toDeliver,
+	}
+	if f.Zone != nil {
+		sum.Zone = f.Zone.Name
+	}
+	return sum, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) Daily(ctx context.Context, f Filter) ([]*Day, error) {
+	days, err := s.repo.Daily(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to compute daily report", err)
+	}
+	for _, d := range days {
+		d.SuccessRate = d.Counts.SuccessRate()
+	}
+	return days, nil
+}
+
+// --------------------------------------------------------------
+// Drones reports each drone's completed jobs and the share of the range it
+// spent on them.
+func (s *service) Drones(ctx context.Context, f Filter) ([]*DroneUtilization, error) {
+	drones, err := s.repo.Drones(ctx, s.db, f)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to compute drone utilization", err)
+	}
+	rangeHours := f.To.Sub(f.From).Hours()
+	for _, d := range drones {
+		d.Utilization = percent(d.BusyHours, rangeHours)
+	}
+	return drones, nil
+}
+
+// --------------------------------------------------------------
+func (s *service) Demand(ctx context.Context, f Filter, cellDeg float64) ([]*DemandCell, error) {
+	cells, err := s.repo.Demand(ctx, s.db, f, cellDeg)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to compute demand", err)
+	}
+	return cells, nil
+}
+
+// percent is part/whole as a percentage rounded to one decimal.
+func percent(part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(part/whole*1000) / 10
+}
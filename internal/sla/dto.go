@@ -0,0 +1,26 @@
+package sla
+
+import "time"
+
+// DayStats summarises the orders placed on one UTC day.
+type DayStats struct {
+	Day              string  `db:"day" json:"day"` // YYYY-MM-DD
+	Orders           int     `db:"orders" json:"orders"`
+	AtRisk           int     `db:"at_risk" json:"at_risk"`
+	Breached         int     `db:"breached" json:"breached"`
+	AssignBreached   int     `db:"assign_breached" json:"assign_breached"`
+	PickupBreached   int     `db:"pickup_breached" json:"pickup_breached"`
+	DeliveryBreached int     `db:"delivery_breached" json:"delivery_breached"`
+	BreachedPercent  float64 `db:"-" json:"breached_percent"`
+}
+
+// Report covers orders placed in [From, To).
+type Report struct {
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	Days     []*DayStats `json:"days"`
+	Breaches []*Event    `json:"breaches"`
+	// AtRisk lists open orders flagged at risk and not (yet) breached on
+	// the same milestone.
+	AtRisk []*Event `json:"at_risk"`
+}
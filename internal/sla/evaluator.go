@@ -0,0 +1,96 @@
+package sla
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/order"
+)
+
+type EvaluatorConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Evaluator periodically checks every open order against its SLA, catching
+// orders that are stuck rather than moving through delivery transitions.
+type Evaluator struct {
+	db      *sqlx.DB
+	orders  order.Repository
+	service Service
+	cfg     EvaluatorConfig
+}
+
+func NewEvaluator(db *sqlx.DB, orders order.Repository, service Service, cfg EvaluatorConfig) *Evaluator {
+	return &Evaluator{db: db, orders: orders, service: service, cfg: cfg}
+}
+
+// Run evaluates open orders until ctx is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := e.Evaluate(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "sla evaluation failed", slog.String("error", err.Error()))
+			} else if n > 0 {
+				slog.InfoContext(ctx, "sla findings recorded", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// Evaluate pages through open orders, oldest first, and records new
+// findings as of now, each order in its own transaction. Returns how many
+// findings were new. An order that fails to check is logged and retried on
+// the next run, so one bad row doesn't hide the orders behind it.
+func (e *Evaluator) Evaluate(ctx context.Context, now time.Time) (int, error) {
+	recorded := 0
+	var after *order.Order
+	for {
+		batch, err := e.orders.ListOpen(ctx, e.db, after, e.cfg.BatchSize)
+		if err != nil {
+			return recorded, fmt.Errorf("list open orders: %w", err)
+		}
+		for _, o := range batch {
+			n, err := e.check(ctx, o, now)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to check order sla",
+					slog.String("order_id", o.ID.String()),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			recorded += n
+		}
+		if len(batch) < e.cfg.BatchSize {
+			return recorded, nil
+		}
+		after = batch[len(batch)-1]
+	}
+}
+
+func (e *Evaluator) check(ctx context.Context, o *order.Order, now time.Time) (int, error) {
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	n, err := e.service.Check(ctx, tx, o, now)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, tx.Commit()
+}
@@ -0,0 +1,61 @@
+package sla
+
+import (
+	"net/http"
+	"time"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReportDays = 7
+	maxReportDays     = 92
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// --------------------------------------------------------------
+// Report returns per-day SLA figures for orders placed between from and to
+// (inclusive UTC dates, YYYY-MM-DD, defaulting to the last 7 days), the
+// breaches detected then and the orders currently at risk.
+func (h *Handler) Report(c *gin.Context) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "to must be a date (YYYY-MM-DD)"}})
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -(defaultReportDays - 1))
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "from must be a date (YYYY-MM-DD)"}})
+			return
+		}
+		from = t
+	}
+	end := to.AddDate(0, 0, 1)
+	if !from.Before(end) || end.Sub(from) > maxReportDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "from must be on or before to, at most 92 days apart"}})
+		return
+	}
+
+	r, err := h.service.Report(c.Request.Context(), from, end)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
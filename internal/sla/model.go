@@ -0,0 +1,115 @@
+package sla
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/order"
+)
+
+// Milestone is a step an order must reach within its tier's target,
+// counted from placement.
+type Milestone string
+
+const (
+	MilestoneAssign   Milestone = "ASSIGN"
+	MilestonePickup   Milestone = "PICKUP"
+	MilestoneDelivery Milestone = "DELIVERY"
+)
+
+type State string
+
+const (
+	// StateAtRisk means most of the target has elapsed and the milestone
+	// hasn't been reached.
+	StateAtRisk State = "AT_RISK"
+	// StateBreached means the milestone was reached late, or not at all by
+	// its deadline.
+	StateBreached State = "BREACHED"
+)
+
+// Targets are the longest times from placement to each milestone. A zero
+// target isn't tracked.
+type Targets struct {
+	Assign   time.Duration
+	Pickup   time.Duration
+	Delivery time.Duration
+}
+
+// Config holds the targets per service tier and when an order counts as at
+// risk.
+type Config struct {
+	Tiers       map[order.ServiceTier]Targets
+	AtRiskRatio float64 // share of a target elapsed before the order is at risk
+}
+
+// Targets returns the tier's targets, falling back to STANDARD.
+func (c Config) Targets(tier order.ServiceTier) Targets {
+	if t, ok := c.Tiers[tier]; ok {
+		return t
+	}
+	return c.Tiers[order.TierStandard]
+}
+
+// Event records an order flagged at risk or breached for one milestone.
+type Event struct {
+	ID          uuid.UUID         `db:"id" json:"id"`
+	OrderID     uuid.UUID         `db:"order_id" json:"order_id"`
+	ServiceTier order.ServiceTier `db:"service_tier" json:"service_tier"`
+	Milestone   Milestone         `db:"milestone" json:"milestone"`
+	State       State             `db:"state" json:"state"`
+	DueAt       time.Time         `db:"due_at" json:"due_at"`
+	DetectedAt  time.Time         `db:"detected_at" json:"detected_at"`
+}
+
+// Evaluate checks each milestone of o against t at now, using the order's
+// status timestamps. A reached milestone is breached if it was reached after
+// its deadline. An unreached one is breached once its deadline has passed,
+// or at risk once atRiskRatio of its target has elapsed; unreached
+// milestones of finished orders aren't judged.
+func Evaluate(o *order.Order, t Targets, atRiskRatio float64, now time.Time) []*Event {
+	milestones := []struct {
+		m      Milestone
+		target time.Duration
+		at     *time.Time
+	}{
+		{MilestoneAssign, t.Assign, o.AssignedAt},
+		{MilestonePickup, t.Pickup, o.PickedUpAt},
+		{MilestoneDelivery, t.Delivery, o.DeliveredAt},
+	}
+
+	var out []*Event
+	for _, ms := range milestones {
+		if ms.target <= 0 {
+			continue
+		}
+		due := o.CreatedAt.Add(ms.target)
+		var state State
+		switch {
+		case ms.at != nil:
+			if !ms.at.After(due) {
+				continue
+			}
+			state = StateBreached
+		case o.Status.IsTerminal():
+			continue
+		case now.After(due):
+			state = StateBreached
+		case now.Sub(o.CreatedAt) >= time.Duration(atRiskRatio*float64(ms.target)):
+			state = StateAtRisk
+		default:
+			continue
+		}
+		out = append(out, &Event{
+			ID:          uuid.New(),
+			OrderID:     o.ID,
+			ServiceTier: o.ServiceTier,
+			Milestone:   ms.m,
+			State:       state,
+			DueAt:       due,
+			DetectedAt:  now,
+		})
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package sla
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const columns = `id, order_id, service_tier, milestone, state, due_at, detected_at`
+
+type Repository interface {
+	Record(ctx context.Context, ext sqlx.ExtContext, e *Event) (bool, error)
+	DailyStats(ctx context.Context, ext sqlx.ExtContext, from, to time.Time) ([]*DayStats, error)
+	ListBreaches(ctx context.Context, ext sqlx.ExtContext, from, to time.Time, limit int) ([]*Event, error)
+	ListAtRisk(ctx context.Context, ext sqlx.ExtContext, limit int) ([]*Event, error)
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+// Record stores e unless the order's milestone was already flagged in the
+// same state, and reports whether it was new.
+func (r *repo) Record(ctx context.Context, ext sqlx.ExtContext, e *Event) (bool, error) {
+	const query = `INSERT INTO order_sla_events (id, order_id, service_tier, milestone, state, due_at, detected_at)
+		VALUES (:id, :order_id, :service_tier, :milestone, :state, :due_at, :detected_at)
+		ON CONFLICT (order_id, milestone, state) DO NOTHING`
+	res, err := sqlx.NamedExecContext(ctx, ext, query, e)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// --------------------------------------------------------------
+// DailyStats counts, per UTC day of placement, the orders placed in
+// [from, to) and how many of them were flagged.
+func (r *repo) DailyStats(ctx context.Context, ext sqlx.ExtContext, from, to time.Time) ([]*DayStats, error) {
+	const query = `SELECT to_char(o.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day,
+			COUNT(*) AS orders,
+			COUNT(*) FILTER (WHERE e.at_risk) AS at_risk,
+			COUNT(*) FILTER (WHERE e.assign OR e.pickup OR e.delivery) AS breached,
+			COUNT(*) FILTER (WHERE e.assign) AS assign_breached,
+			COUNT(*) FILTER (WHERE e.pickup) AS pickup_breached,
+			COUNT(*) FILTER (WHERE e.delivery) AS delivery_breached
+		FROM orders o
+		LEFT JOIN (
+			SELECT order_id,
+				bool_or(state = 'AT_RISK') AS at_risk,
+				bool_or(state = 'BREACHED' AND milestone = 'ASSIGN') AS assign,
+				bool_or(state = 'BREACHED' AND milestone = 'PICKUP') AS pickup,
+				bool_or(state = 'BREACHED' AND milestone = 'DELIVERY') AS delivery
+			FROM order_sla_events GROUP BY order_id
+		) e ON e.order_id = o.id
+		WHERE o.created_at >= $1 AND o.created_at < $2
+		GROUP BY day ORDER BY day`
+	var out []*DayStats
+	if err := sqlx.SelectContext(ctx, ext, &out, query, from, to); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------
+// ListBreaches returns breaches detected in [from, to), newest first.
+func (r *repo) ListBreaches(ctx context.Context, ext sqlx.ExtContext, from, to time.Time, limit int) ([]*Event, error) {
+	var out []*Event
+	query := fmt.Sprintf(`SELECT %s FROM order_sla_events
+		WHERE state = $1 AND detected_at >= $2 AND detected_at < $3
+		ORDER BY detected_at DESC LIMIT $4`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &out, query, StateBreached, from, to, limit); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// --------------------------------------------------------------
+// ListAtRisk returns at-risk events of open orders whose milestone hasn't
+// been breached, soonest due first.
+func (r *repo) ListAtRisk(ctx context.Context, ext sqlx.ExtContext, limit int) ([]*Event, error) {
+	var out []*Event
+	const query = `SELECT e.id, e.order_id, e.service_tier, e.milestone, e.state, e.due_at, e.detected_at
+		FROM order_sla_events e JOIN orders o ON o.id = e.order_id
+		WHERE e.state = $1 AND o.status NOT IN ('DELIVERED', 'FAILED', 'WITHDRAWN')
+			AND NOT EXISTS (SELECT 1 FROM order_sla_events b
+				WHERE b.order_id = e.order_id AND b.milestone = e.milestone AND b.state = $2)
+		ORDER BY e.due_at LIMIT $3`
+	if err := sqlx.SelectContext(ctx, ext, &out, query, StateAtRisk, StateBreached, limit); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,112 @@
+package sla
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/order"
+)
+
+const (
+	AlertKindAtRisk   = "SLA_AT_RISK"
+	AlertKindBreached = "SLA_BREACHED"
+
+	reportListLimit = 100
+)
+
+type Service interface {
+	Check(ctx context.Context, ext sqlx.ExtContext, o *order.Order, now time.Time) (int, error)
+	Report(ctx context.Context, from, to time.Time) (*Report, error)
+}
+
+// Alerter raises an operator alert inside ext. Satisfied by alert.Service.
+type Alerter interface {
+	Raise(ctx context.Context, ext sqlx.ExtContext, kind, severity, droneID, message string) error
+}
+
+type service struct {
+	db     *sqlx.DB
+	repo   Repository
+	alerts Alerter
+	cfg    Config
+}
+
+func NewService(db *sqlx.DB, repo Repository, alerts Alerter, cfg Config) Service {
+	return &service{db: db, repo: repo, alerts: alerts, cfg: cfg}
+}
+
+// --------------------------------------------------------------
+// Check evaluates o against its tier's targets inside ext and records each
+// new finding with an alert: a warning when at risk, critical when
+// breached. It returns how many findings were new.
+func (s *service) Check(ctx context.Context, ext sqlx.ExtContext, o *order.Order, now time.Time) (int, error) {
+	recorded := 0
+	for _, e := range Evaluate(o, s.cfg.Targets(o.ServiceTier), s.cfg.AtRiskRatio, now) {
+		created, err := s.repo.Record(ctx, ext, e)
+		if err != nil {
+			return recorded, domainerrors.NewInternal("failed to record SLA event", err)
+		}
+		if !created {
+			continue
+		}
+		recorded++
+
+		kind, severity := AlertKindBreached, "CRITICAL"
+		msg := fmt.Sprintf("order %s (%s) missed its %s target, due %s",
+			o.ID, o.ServiceTier, strings.ToLower(string(e.Milestone)), e.DueAt.UTC().Format(time.RFC3339))
+		if e.State == StateAtRisk {
+			kind, severity = AlertKindAtRisk, "WARNING"
+			msg = fmt.Sprintf("order %s (%s) is at risk of missing its %s target, due %s",
+				o.ID, o.ServiceTier, strings.ToLower(string(e.Milestone)), e.DueAt.UTC().Format(time.RFC3339))
+		}
+		var droneID string
+		if o.AssignedDroneID != nil {
+			droneID = *o.AssignedDroneID
+		}
+		if err := s.alerts.Raise(ctx, ext, kind, severity, droneID, msg); err != nil {
+			return recorded, domainerrors.NewInternal("failed to raise SLA alert", err)
+		}
+	}
+	return recorded, nil
+}
+
+// --------------------------------------------------------------
+// Report summarises orders placed in [from, to) per day, with the breaches
+// detected in that window and the orders currently at risk.
+func (s *service) Report(ctx context.Context, from, to time.Time) (*Report, error) {
+	days, err := s.repo.DailyStats(ctx, s.db, from, to)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to compute SLA stats", err)
+	}
+	for _, d := range days {
+		if d.Orders > 0 {
+			d.BreachedPercent = math.Round(float64(d.Breached)/float64(d.Orders)*1000) / 10
+		}
+	}
+	breaches, err := s.repo.ListBreaches(ctx, s.db, from, to, reportListLimit)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list SLA breaches", err)
+	}
+	atRisk, err := s.repo.ListAtRisk(ctx, s.db, reportListLimit)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to list orders at risk", err)
+	}
+
+	r := &Report{From: from, To: to, Days: days, Breaches: breaches, AtRisk: atRisk}
+	if r.Days == nil {
+		r.Days = []*DayStats{}
+	}
+	if r.Breaches == nil {
+		r.Breaches = []*Event{}
+	}
+	if r.AtRisk == nil {
+		r.AtRisk = []*Event{}
+	}
+	return r, nil
+}
@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const defaultTrackWindow = 24 * time.Hour
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// --------------------------------------------------------------
+// DroneTrack returns the drone's path between from and to (RFC 3339,
+// defaulting to the last 24 hours).
+func (h *Handler) DroneTrack(c *gin.Context) {
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "to must be an RFC 3339 timestamp"}})
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultTrackWindow)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "from must be an RFC 3339 timestamp"}})
+			return
+		}
+		from = t
+	}
+	maxPoints, ok := parseMaxPoints(c)
+	if !ok {
+		return
+	}
+
+	fc, err := h.service.DroneTrack(c.Request.Context(), c.Param("id"), from, to, maxPoints)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, fc)
+}
+
+// --------------------------------------------------------------
+func (h *Handler) OrderTrack(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "invalid order id"}})
+		return
+	}
+	maxPoints, ok := parseMaxPoints(c)
+	if !ok {
+		return
+	}
+
+	fc, err := h.service.OrderTrack(c.Request.Context(), id, maxPoints)
+	if err != nil {
+		apperrors.ToHTTPError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, fc)
+}
+
+func parseMaxPoints(c *gin.Context) (int, bool) {
+	v := c.Query("max_points")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": gin.H{"code": "VALIDATION", "message": "max_points must be an integer >= 2"}})
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,97 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Point is one recorded drone position.
+type Point struct {
+	DroneID    string     `db:"drone_id" json:"drone_id"`
+	OrderID    *uuid.UUID `db:"order_id" json:"order_id,omitempty"`
+	Latitude   float64    `db:"latitude" json:"latitude"`
+	Longitude  float64    `db:"longitude" json:"longitude"`
+	RecordedAt time.Time  `db:"recorded_at" json:"recorded_at"`
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection of flight tracks.
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+// Feature is one drone's track as a GeoJSON LineString. Timestamps are
+// parallel to the geometry's coordinates.
+type Feature struct {
+	Type       string          `json:"type"`
+	Geometry   LineString      `json:"geometry"`
+	Properties TrackProperties `json:"properties"`
+}
+
+type LineString struct {
+	Type        string       `json:"type"`
+	Coordinates [][2]float64 `json:"coordinates"` // [lng, lat]
+}
+
+type TrackProperties struct {
+	DroneID     string      `json:"drone_id"`
+	OrderID     *uuid.UUID  `json:"order_id,omitempty"`
+	Timestamps  []time.Time `json:"timestamps"`
+	TotalPoints int         `json:"total_points"`
+}
+
+// NewFeatureCollection groups time-ordered points into one LineString per
+// drone, downsampling each to at most maxPoints (0 keeps all).
+func NewFeatureCollection(points []*Point, orderID *uuid.UUID, maxPoints int) *FeatureCollection {
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+
+	byDrone := map[string][]*Point{}
+	var order []string
+	for _, p := range points {
+		if _, ok := byDrone[p.DroneID]; !ok {
+			order = append(order, p.DroneID)
+		}
+		byDrone[p.DroneID] = append(byDrone[p.DroneID], p)
+	}
+
+	for _, droneID := range order {
+		track := byDrone[droneID]
+		sampled := Downsample(track, maxPoints)
+
+		f := &Feature{
+			Type:     "Feature",
+			Geometry: LineString{Type: "LineString", Coordinates: make([][2]float64, 0, len(sampled))},
+			Properties: TrackProperties{
+				DroneID:     droneID,
+				OrderID:     orderID,
+				Timestamps:  make([]time.Time, 0, len(sampled)),
+				TotalPoints: len(track),
+			},
+		}
+		for _, p := range sampled {
+			f.Geometry.Coordinates = append(f.Geometry.Coordinates, [2]float64{p.Longitude, p.Latitude})
+			f.Properties.Timestamps = append(f.Properties.Timestamps, p.RecordedAt)
+		}
+		fc.Features = append(fc.Features, f)
+	}
+	return fc
+}
+
+// Downsample keeps at most maxPoints evenly spaced points, always including
+// the first and last. maxPoints <= 0 or a short track returns the input.
+func Downsample(points []*Point, maxPoints int) []*Point {
+	if maxPoints <= 0 || len(points) <= maxPoints {
+		return points
+	}
+	if maxPoints == 1 {
+		return points[len(points)-1:]
+	}
+
+	out := make([]*Point, 0, maxPoints)
+	step := float64(len(points)-1) / float64(maxPoints-1)
+	for i := range maxPoints {
+		out = append(out, points[int(float64(i)*step+0.5)])
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const partitionPrefix = "drone_telemetry_"
+
+type RetentionConfig struct {
+	RetentionDays   int
+	PartitionsAhead int // daily partitions created beyond today
+	Interval        time.Duration
+}
+
+// PartitionManager keeps daily drone_telemetry partitions ahead of time and
+// drops those older than the retention window.
+type PartitionManager struct {
+	db  *sqlx.DB
+	cfg RetentionConfig
+}
+
+func NewPartitionManager(db *sqlx.DB, cfg RetentionConfig) *PartitionManager {
+	return &PartitionManager{db: db, cfg: cfg}
+}
+
+// Run calls Maintain every interval until ctx is cancelled.
+func (m *PartitionManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Maintain(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "telemetry partition maintenance failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// Maintain creates partitions for today through PartitionsAhead days and
+// removes data older than RetentionDays.
+func (m *PartitionManager) Maintain(ctx context.Context, now time.Time) error {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	for i := 0; i <= m.cfg.PartitionsAhead; i++ {
+		day := today.AddDate(0, 0, i)
+		query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF drone_telemetry FOR VALUES FROM ('%s') TO ('%s')`,
+			partitionName(day), day.Format(time.RFC3339), day.AddDate(0, 0, 1).Format(time.RFC3339))
+		if _, err := m.db.ExecContext(ctx, query); err != nil {
+			// Rows for this day already landed in the default partition; they
+			// stay there until retention removes them.
+			slog.WarnContext(ctx, "could not create telemetry partition",
+				slog.String("partition", partitionName(day)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	cutoff := today.AddDate(0, 0, -m.cfg.RetentionDays)
+	var partitions []string
+	const list = `SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'drone_telemetry'`
+	if err := sqlx.SelectContext(ctx, m.db, &partitions, list); err != nil {
+		return fmt.Errorf("list telemetry partitions: %w", err)
+	}
+	for _, name := range partitions {
+		day, ok := partitionDay(name)
+		if !ok || !day.Before(cutoff) {
+			continue
+		}
+		if _, err := m.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, name)); err != nil {
+			return fmt.Errorf("drop telemetry partition %s: %w", name, err)
+		}
+	}
+
+	if _, err := m.db.ExecContext(ctx, `DELETE FROM drone_telemetry_default WHERE recorded_at < $1`, cutoff); err != nil {
+		return fmt.Errorf("prune default telemetry partition: %w", err)
+	}
+	return nil
+}
+
+func partitionName(day time.Time) string {
+	return partitionPrefix + day.Format("20060102")
+}
+
+func partitionDay(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, partitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	day, err := time.Parse("20060102", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/common"
+)
+
+type RecorderConfig struct {
+	BufferSize    int // points held in memory before new ones are dropped
+	BatchSize     int // points per INSERT; a full batch triggers an early flush
+	FlushInterval time.Duration
+}
+
+// Recorder buffers heartbeat positions in memory and writes them in batches
+// so the heartbeat path never waits on the telemetry table.
+type Recorder struct {
+	db   *sqlx.DB
+	repo Repository
+	cfg  RecorderConfig
+
+	mu      sync.Mutex
+	buf     []*Point
+	dropped int
+	kick    chan struct{}
+}
+
+func NewRecorder(db *sqlx.DB, repo Repository, cfg RecorderConfig) *Recorder {
+	return &Recorder{
+		db:   db,
+		repo: repo,
+		cfg:  cfg,
+		buf:  make([]*Point, 0, cfg.BatchSize),
+		kick: make(chan struct{}, 1),
+	}
+}
+
+// Record queues one position. It never blocks; when the buffer is full the
+// point is dropped and counted.
+func (r *Recorder) Record(droneID string, orderID *uuid.UUID, loc common.Location, at time.Time) {
+	r.mu.Lock()
+	if len(r.buf) >= r.cfg.BufferSize {
+		r.dropped++
+		r.mu.Unlock()
+		return
+	}
+	r.buf = append(r.buf, &Point{
+		DroneID:    droneID,
+		OrderID:    orderID,
+		Latitude:   loc.Lat,
+		Longitude:  loc.Lng,
+		RecordedAt: at,
+	})
+	full := len(r.buf) >= r.cfg.BatchSize
+	r.mu.Unlock()
+
+	if full {
+		select {
+		case r.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run flushes on every interval or full batch until ctx is cancelled, then
+// flushes whatever is left.
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			r.flushLogged(shutdownCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			r.flushLogged(ctx)
+		case <-r.kick:
+			r.flushLogged(ctx)
+		}
+	}
+}
+
+// Flush writes all buffered points. Points from a failed batch are dropped.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	points := r.buf
+	dropped := r.dropped
+	r.buf = make([]*Point, 0, r.cfg.BatchSize)
+	r.dropped = 0
+	r.mu.Unlock()
+
+	if dropped > 0 {
+		slog.WarnContext(ctx, "telemetry buffer full, points dropped", slog.Int("dropped", dropped))
+	}
+
+	for start := 0; start < len(points); start += r.cfg.BatchSize {
+		end := min(start+r.cfg.BatchSize, len(points))
+		if err := r.repo.InsertBatch(ctx, r.db, points[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) flushLogged(ctx context.Context) {
+	if err := r.Flush(ctx); err != nil {
+		slog.ErrorContext(ctx, "telemetry flush failed", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const columns = `drone_id, order_id, latitude, longitude, recorded_at`
+
+type Repository interface {
+	InsertBatch(ctx context.Context, ext sqlx.ExtContext, points []*Point) error
+	ListByDrone(ctx context.Context, ext sqlx.ExtContext, droneID string, from, to time.Time) ([]*Point, error)
+	ListByOrder(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID) ([]*Point, error)
+}
+
+type repo struct{}
+
+func NewRepository() Repository {
+	return &repo{}
+}
+
+// --------------------------------------------------------------
+func (r *repo) InsertBatch(ctx context.Context, ext sqlx.ExtContext, points []*Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	const query = `INSERT INTO drone_telemetry (drone_id, order_id, latitude, longitude, recorded_at)
+		VALUES (:drone_id, :order_id, :latitude, :longitude, :recorded_at)`
+	_, err := sqlx.NamedExecContext(ctx, ext, query, points)
+	return err
+}
+
+// --------------------------------------------------------------
+func (r *repo) ListByDrone(ctx context.Context, ext sqlx.ExtContext, droneID string, from, to time.Time) ([]*Point, error) {
+	var points []*Point
+	query := fmt.Sprintf(`SELECT %s FROM drone_telemetry
+		WHERE drone_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+		ORDER BY recorded_at ASC`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &points, query, droneID, from, to); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// --------------------------------------------------------------
+func (r *repo) ListByOrder(ctx context.Context, ext sqlx.ExtContext, orderID uuid.UUID) ([]*Point, error) {
+	var points []*Point
+	query := fmt.Sprintf(`SELECT %s FROM drone_telemetry WHERE order_id = $1 ORDER BY recorded_at ASC`, columns)
+	if err := sqlx.SelectContext(ctx, ext, &points, query, orderID); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
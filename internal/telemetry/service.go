@@ -0,0 +1,53 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// MaxTrackWindow bounds a single drone track query.
+const MaxTrackWindow = 7 * 24 * time.Hour
+
+type Service interface {
+	DroneTrack(ctx context.Context, droneID string, from, to time.Time, maxPoints int) (*FeatureCollection, error)
+	OrderTrack(ctx context.Context, orderID uuid.UUID, maxPoints int) (*FeatureCollection, error)
+}
+
+type service struct {
+	db   *sqlx.DB
+	repo Repository
+}
+
+func NewService(db *sqlx.DB, repo Repository) Service {
+	return &service{db: db, repo: repo}
+}
+
+// --------------------------------------------------------------
+func (s *service) DroneTrack(ctx context.Context, droneID string, from, to time.Time, maxPoints int) (*FeatureCollection, error) {
+	if !from.Before(to) {
+		return nil, domainerrors.NewValidation("from must be before to")
+	}
+	if to.Sub(from) > MaxTrackWindow {
+		return nil, domainerrors.NewValidation("track window must not exceed 7 days")
+	}
+
+	points, err := s.repo.ListByDrone(ctx, s.db, droneID, from, to)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load drone track", err)
+	}
+	return NewFeatureCollection(points, nil, maxPoints), nil
+}
+
+// --------------------------------------------------------------
+func (s *service) OrderTrack(ctx context.Context, orderID uuid.UUID, maxPoints int) (*FeatureCollection, error) {
+	points, err := s.repo.ListByOrder(ctx, s.db, orderID)
+	if err != nil {
+		return nil, domainerrors.NewInternal("failed to load order track", err)
+	}
+	return NewFeatureCollection(points, &orderID, maxPoints), nil
+}
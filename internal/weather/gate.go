@@ -0,0 +1,35 @@
+package weather
+
+import (
+	"context"
+
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+// Gate refuses flights when the weather at any of their points is outside
+// the limits. It satisfies delivery.FlightGuard.
+type Gate struct {
+	provider common.WeatherProvider
+	limits   Limits
+}
+
+func NewGate(provider common.WeatherProvider, limits Limits) *Gate {
+	return &Gate{provider: provider, limits: limits}
+}
+
+// CheckFlight returns an UNSAFE_WEATHER error when any of points (drone
+// position, pickup, drop-off) has unsafe conditions. If the weather can't be
+// read the flight is refused too: an unknown sky is not a safe one.
+func (g *Gate) CheckFlight(ctx context.Context, points ...common.Location) error {
+	for _, p := range points {
+		w, err := g.provider.GetWeather(ctx, p)
+		if err != nil {
+			return domainerrors.UnsafeWeather("conditions unavailable")
+		}
+		if reason := g.limits.Violation(w); reason != "" {
+			return domainerrors.UnsafeWeather(reason)
+		}
+	}
+	return nil
+}
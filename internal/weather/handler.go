@@ -0,0 +1,38 @@
+package weather
+
+import (
+	"net/http"
+
+	"drone-delivery/internal/common"
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/pkg/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	provider common.WeatherProvider
+	limits   Limits
+	zones    []Zone
+}
+
+func NewHandler(provider common.WeatherProvider, limits Limits, zones []Zone) *Handler {
+	return &Handler{provider: provider, limits: limits, zones: zones}
+}
+
+// --------------------------------------------------------------
+// Zones lists the service zones with the current weather at their centers
+// and whether it is within the flight limits.
+func (h *Handler) Zones(c *gin.Context) {
+	out := make([]ZoneConditions, 0, len(h.zones))
+	for _, z := range h.zones {
+		w, err := h.provider.GetWeather(c.Request.Context(), z.Center)
+		if err != nil {
+			apperrors.ToHTTPError(c, domainerrors.NewInternal("failed to read weather", err))
+			return
+		}
+		reason := h.limits.Violation(w)
+		out = append(out, ZoneConditions{Zone: z, Weather: w, Flyable: reason == "", UnsafeReason: reason})
+	}
+	c.JSON(http.StatusOK, gin.H{"zones": out})
+}
@@ -0,0 +1,39 @@
+package weather
+
+import (
+	"fmt"
+
+	"drone-delivery/internal/common"
+)
+
+// Limits are the conditions beyond which drones don't take off.
+type Limits struct {
+	MaxWindKMH      float64
+	MinVisibilityKM float64
+}
+
+// Violation explains why w is unsafe, or returns "" when it is flyable.
+func (l Limits) Violation(w *common.Weather) string {
+	if w.WindSpeedKMH > l.MaxWindKMH {
+		return fmt.Sprintf("wind %.0f km/h exceeds %.0f km/h", w.WindSpeedKMH, l.MaxWindKMH)
+	}
+	if w.VisibilityKM < l.MinVisibilityKM {
+		return fmt.Sprintf("visibility %.1f km is below %.1f km", w.VisibilityKM, l.MinVisibilityKM)
+	}
+	return ""
+}
+
+// Zone is a named service area whose conditions are reported to admins.
+type Zone struct {
+	Name     string          `json:"name"`
+	Center   common.Location `json:"center"`
+	RadiusKM float64         `json:"radius_km"`
+}
+
+// ZoneConditions is a zone with the weather at its center.
+type ZoneConditions struct {
+	Zone
+	Weather      *common.Weather `json:"weather"`
+	Flyable      bool            `json:"flyable"`
+	UnsafeReason string          `json:"unsafe_reason,omitempty"`
+}
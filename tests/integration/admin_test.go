@@ -1,9 +1,14 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 	"testing"
+
+	"drone-delivery/internal/job"
 )
 
 func TestAdmin_ListOrders(t *testing.T) {
@@ -196,3 +201,301 @@ func TestAdmin_NonAdminCantAccess(t *testing.T) {
 		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestAdmin_AssignJob_ToIdleDrone(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	// Register the target drone
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/jobs/%s/assign", jobID), map[string]string{"drone_id": "drone-1"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Drone sees the order
+	w = doRequest(app, http.MethodGet, "/drone/me/order", nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp := parseJSON(t, w)
+	if resp["order"].(map[string]any)["id"] != orderID {
+		t.Fatalf("expected drone to hold order %s", orderID)
+	}
+}
+
+func TestAdmin_AssignJob_UnknownDrone(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	_, jobID := placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/jobs/%s/assign", jobID), map[string]string{"drone_id": "ghost"}, aToken)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdmin_AssignJob_BusyDrone(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	_, firstJob := placeTestOrder(t, app, userToken)
+	_, secondJob := placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": firstJob}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/admin/jobs/%s/assign", secondJob), map[string]string{"drone_id": "drone-1"}, aToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdmin_UnassignOrder(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/unassign", orderID), nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	order := parseJSON(t, w)["order"].(map[string]any)
+	if order["status"] != "PENDING" {
+		t.Fatalf("expected PENDING, got %s", order["status"])
+	}
+
+	// Job is open again and the drone is free to take it
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("re-reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdmin_UnassignOrder_AfterPickup(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/unassign", orderID), nil, aToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdmin_ReassignOrder(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	dr1Token := droneToken(t, app, "drone-1")
+	dr2Token := droneToken(t, app, "drone-2")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, dr2Token)
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, dr1Token)
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/reassign", orderID), map[string]string{"drone_id": "drone-2"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	order := parseJSON(t, w)["order"].(map[string]any)
+	if order["status"] != "ASSIGNED" || order["assigned_drone_id"] != "drone-2" {
+		t.Fatalf("expected ASSIGNED to drone-2, got %s/%v", order["status"], order["assigned_drone_id"])
+	}
+
+	// The old drone no longer holds an order
+	w = doRequest(app, http.MethodGet, "/drone/me/order", nil, dr1Token)
+	if w.Code == http.StatusOK {
+		t.Fatalf("expected drone-1 to have no order, got %s", w.Body.String())
+	}
+}
+
+// dispatchRecorder is a delivery.Notifier that records what it hears.
+type dispatchRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *dispatchRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *dispatchRecorder) JobAssigned(_ context.Context, droneID string, _ *job.Job) {
+	r.record("assigned:" + droneID)
+}
+
+func (r *dispatchRecorder) JobReleased(_ context.Context, droneID string, _ *job.Job) {
+	r.record("released:" + droneID)
+}
+
+func (r *dispatchRecorder) HandoffStarted(_ context.Context, droneID string) {
+	r.record("handoff:" + droneID)
+}
+
+func TestAdmin_UnassignAndReassignNotifyDrones(t *testing.T) {
+	events := &dispatchRecorder{}
+	app := setupTestApp(t, withNotifier(events))
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	dr1Token := droneToken(t, app, "drone-1")
+	dr2Token := droneToken(t, app, "drone-2")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, dr2Token)
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, dr1Token)
+	doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/reassign", orderID), map[string]string{"drone_id": "drone-2"}, aToken)
+	doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/unassign", orderID), nil, aToken)
+
+	want := []string{"assigned:drone-1", "released:drone-1", "assigned:drone-2", "released:drone-2"}
+	if !slices.Equal(events.events, want) {
+		t.Fatalf("expected %v, got %v", want, events.events)
+	}
+}
+
+func TestAdmin_UnassignAfterHandoff_KeepsHandoff(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	dr1Token := droneToken(t, app, "drone-1")
+	dr2Token := droneToken(t, app, "drone-2")
+	dr3Token := droneToken(t, app, "drone-3")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, dr1Token)
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.73, "longitude": 46.69}, dr2Token)
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.74, "longitude": 46.70}, dr3Token)
+
+	// Drone-1 picks the parcel up and breaks down with it
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, dr1Token)
+	doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, dr1Token)
+	if w := doRequest(app, http.MethodPost, "/drone/me/broken", nil, dr1Token); w.Code != http.StatusOK {
+		t.Fatalf("broken: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Drone-2 takes the handoff job, then an admin moves it to drone-3
+	var handoffJobID string
+	for _, j := range parseJSON(t, doRequest(app, http.MethodGet, "/drone/jobs", nil, dr2Token))["jobs"].([]any) {
+		if jm := j.(map[string]any); jm["order_id"] == orderID {
+			handoffJobID = jm["id"].(string)
+		}
+	}
+	if w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": handoffJobID}, dr2Token); w.Code != http.StatusOK {
+		t.Fatalf("reserve handoff: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/reassign", orderID), map[string]string{"drone_id": "drone-3"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reassign: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Unassigning returns it to the handoff pool, not to pending
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/admin/orders/%s/unassign", orderID), nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unassign: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if status := parseJSON(t, w)["order"].(map[string]any)["status"]; status != "AWAITING_HANDOFF" {
+		t.Fatalf("expected AWAITING_HANDOFF, got %s", status)
+	}
+
+	// The parcel is in the air, so the customer can't withdraw it
+	w = doRequest(app, http.MethodDelete, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("withdraw: expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdmin_RegisterDrone(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	body := map[string]any{
+		"id":           "drone-7",
+		"model":        "DX-4",
+		"capabilities": []string{"cold-chain"},
+		"home_base":    map[string]float64{"lat": 24.71, "lng": 46.67},
+	}
+	w := doRequest(app, http.MethodPost, "/admin/drones", body, aToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	d := parseJSON(t, w)["drone"].(map[string]any)
+	if d["model"] != "DX-4" || d["status"] != "IDLE" {
+		t.Fatalf("unexpected drone: %v", d)
+	}
+
+	// Registering the same id again conflicts
+	w = doRequest(app, http.MethodPost, "/admin/drones", body, aToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdmin_UpdateDrone(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	doRequest(app, http.MethodPost, "/admin/drones", map[string]any{"id": "drone-7", "model": "DX-4"}, aToken)
+
+	w := doRequest(app, http.MethodPatch, "/admin/drones/drone-7", map[string]any{
+		"maintenance_notes": "replaced rotor 2",
+		"service_hours":     12.5,
+	}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	d := parseJSON(t, w)["drone"].(map[string]any)
+	if d["model"] != "DX-4" || d["maintenance_notes"] != "replaced rotor 2" || d["service_hours"].(float64) != 12.5 {
+		t.Fatalf("unexpected drone: %v", d)
+	}
+}
+
+func TestAdmin_RetireDrone_RefusesHeartbeatAndReservation(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-7")
+
+	doRequest(app, http.MethodPost, "/admin/drones", map[string]any{"id": "drone-7"}, aToken)
+	_, jobID := placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodDelete, "/admin/drones/drone-7", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("heartbeat: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("reserve: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// History is kept
+	w = doRequest(app, http.MethodGet, "/admin/drones?status=RETIRED", nil, aToken)
+	if len(parseJSON(t, w)["drones"].([]any)) != 1 {
+		t.Fatalf("expected retired drone to be listed: %s", w.Body.String())
+	}
+}
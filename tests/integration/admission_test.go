@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"drone-delivery/internal/admission"
+)
+
+func placeAdmissionOrder(t *testing.T, app *testApp, token string) (int, map[string]any) {
+	t.Helper()
+	w := doRequest(app, http.MethodPost, "/orders", map[string]any{
+		"origin":      validOrigin(),
+		"destination": validDestination(),
+	}, token)
+	return w.Code, parseJSON(t, w)
+}
+
+func TestAdmission_IdleFleetPromisesDelivery(t *testing.T) {
+	app := setupTestApp(t, withAdmission(2*time.Hour, admission.PolicyWaitlist))
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+
+	code, body := placeAdmissionOrder(t, app, userToken)
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", code, body)
+	}
+	o := body["order"].(map[string]any)
+	if o["status"] != "PENDING" {
+		t.Fatalf("expected PENDING, got %v", o["status"])
+	}
+	if o["promised_pickup_at"] == nil || o["promised_delivery_at"] == nil {
+		t.Fatalf("expected promised times on the order, got %v", o)
+	}
+	promise := body["promise"].(map[string]any)
+	if promise["waitlisted"] != false || promise["idle_drones"] != 1.0 {
+		t.Fatalf("expected an immediate promise from one idle drone, got %v", promise)
+	}
+	if ids := openJobOrder(t, app, drToken); len(ids) != 1 || ids[0] != o["id"] {
+		t.Fatalf("expected a job for the admitted order, got %v", ids)
+	}
+}
+
+func TestAdmission_NoFleetWaitlistsThenPromotes(t *testing.T) {
+	app := setupTestApp(t, withAdmission(2*time.Hour, admission.PolicyWaitlist))
+	userToken := enduserToken(t, app, "user-1")
+
+	code, body := placeAdmissionOrder(t, app, userToken)
+	if code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", code, body)
+	}
+	o := body["order"].(map[string]any)
+	if o["status"] != "WAITLISTED" {
+		t.Fatalf("expected WAITLISTED, got %v", o["status"])
+	}
+	orderID := o["id"].(string)
+	var jobs int
+	if err := app.DB.Get(&jobs, `SELECT COUNT(*) FROM jobs WHERE order_id = $1`, orderID); err != nil {
+		t.Fatalf("count jobs: %v", err)
+	}
+	if jobs != 0 {
+		t.Fatalf("expected no job for a waitlisted order, got %d", jobs)
+	}
+
+	// Still no drone: nothing to promote
+	if n, err := app.Waitlist.Promote(context.Background()); err != nil || n != 0 {
+		t.Fatalf("early promote: expected nothing, got %d (%v)", n, err)
+	}
+
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	if n, err := app.Waitlist.Promote(context.Background()); err != nil || n != 1 {
+		t.Fatalf("promote: expected 1, got %d (%v)", n, err)
+	}
+
+	w := doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	o = parseJSON(t, w)["order"].(map[string]any)
+	if o["status"] != "PENDING" || o["repromised_delivery_at"] == nil {
+		t.Fatalf("expected a PENDING order with a new promise, got %v", o)
+	}
+	if o["promised_delivery_at"] != nil {
+		t.Fatalf("expected the original promise (none, with no fleet) to be kept, got %v", o["promised_delivery_at"])
+	}
+	if ids := openJobOrder(t, app, drToken); len(ids) != 1 || ids[0] != orderID {
+		t.Fatalf("expected the promoted order's job to be open, got %v", ids)
+	}
+}
+
+func TestAdmission_NewOrderQueuesBehindWaitlist(t *testing.T) {
+	app := setupTestApp(t, withAdmission(2*time.Hour, admission.PolicyWaitlist))
+	userToken := enduserToken(t, app, "user-1")
+
+	placeAdmissionOrder(t, app, userToken)
+	_, body := placeAdmissionOrder(t, app, userToken)
+	if ahead := body["promise"].(map[string]any)["queue_ahead"]; ahead != 1.0 {
+		t.Fatalf("expected the waitlisted order to be ahead, got %v", ahead)
+	}
+}
+
+func TestAdmission_WaitlistedOrderCanBeWithdrawn(t *testing.T) {
+	app := setupTestApp(t, withAdmission(2*time.Hour, admission.PolicyWaitlist))
+	userToken := enduserToken(t, app, "user-1")
+
+	_, body := placeAdmissionOrder(t, app, userToken)
+	orderID := body["order"].(map[string]any)["id"].(string)
+
+	w := doRequest(app, http.MethodDelete, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("withdraw: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	if n, err := app.Waitlist.Promote(context.Background()); err != nil || n != 0 {
+		t.Fatalf("promote: expected nothing, got %d (%v)", n, err)
+	}
+}
+
+func TestAdmission_RejectPolicyRefusesOverCapacity(t *testing.T) {
+	app := setupTestApp(t, withAdmission(2*time.Hour, admission.PolicyReject))
+	userToken := enduserToken(t, app, "user-1")
+
+	code, body := placeAdmissionOrder(t, app, userToken)
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d: %v", code, body)
+	}
+	if c := body["error"].(map[string]any)["code"]; c != "OVER_CAPACITY" {
+		t.Fatalf("expected OVER_CAPACITY, got %v", c)
+	}
+}
+
+func TestAdmission_QuarantinedDroneIsNotCapacity(t *testing.T) {
+	app := setupTestApp(t, withAdmission(2*time.Hour, admission.PolicyReject))
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	w := doRequest(app, http.MethodPost, "/admin/drones/drone-1/quarantine", map[string]string{"reason": "spoofing suspected"}, adminToken(t, app))
+	if w.Code != http.StatusOK {
+		t.Fatalf("quarantine: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	code, body := placeAdmissionOrder(t, app, userToken)
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 with only a quarantined drone, got %d: %v", code, body)
+	}
+}
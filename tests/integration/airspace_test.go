@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAirspace_LanesFillThenRelease(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	heartbeat := map[string]float64{"latitude": 24.72, "longitude": 46.68}
+
+	orders := make([]string, 5)
+	jobs := make([]string, 5)
+	tokens := make([]string, 5)
+	for i := range jobs {
+		orders[i], jobs[i] = placeTestOrder(t, app, userToken)
+		tokens[i] = droneToken(t, app, fmt.Sprintf("drone-%d", i+1))
+		doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeat, tokens[i])
+	}
+
+	// Same corridor, so each drone gets the next band up
+	for i := 0; i < 4; i++ {
+		w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobs[i]}, tokens[i])
+		if w.Code != http.StatusOK {
+			t.Fatalf("reserve %d: expected 200, got %d: %s", i, w.Code, w.Body.String())
+		}
+		res := parseJSON(t, w)["airspace"].(map[string]any)
+		if band := res["band"]; band != float64(i) {
+			t.Fatalf("reserve %d: expected band %d, got %v", i, i, band)
+		}
+		if minAlt := res["min_altitude_m"]; minAlt != 60+20*float64(i) {
+			t.Fatalf("reserve %d: expected floor %dm, got %v", i, 60+20*i, minAlt)
+		}
+	}
+
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobs[4]}, tokens[4])
+	if w.Code != http.StatusConflict {
+		t.Fatalf("fifth crossing flight: expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/airspace/reservations", nil, adminToken(t, app))
+	if n := len(parseJSON(t, w)["reservations"].([]any)); n != 4 {
+		t.Fatalf("expected 4 active reservations, got %d", n)
+	}
+
+	// Delivering frees band 0
+	doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orders[0]), nil, tokens[0])
+	w = doRequest(app, http.MethodPatch, fmt.Sprintf("/drone/orders/%s/complete", orders[0]), map[string]string{"status": "delivered"}, tokens[0])
+	if w.Code != http.StatusOK {
+		t.Fatalf("complete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodGet, "/drone/me/airspace", nil, tokens[0])
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("released corridor: expected 404, got %d", w.Code)
+	}
+
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobs[4]}, tokens[4])
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve after release: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if band := parseJSON(t, w)["airspace"].(map[string]any)["band"]; band != 0.0 {
+		t.Fatalf("expected the freed band 0, got %v", band)
+	}
+}
+
+func TestAirspace_BrokenDroneReleasesCorridor(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	_, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+
+	w := doRequest(app, http.MethodGet, "/drone/me/airspace", nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("airspace: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	doRequest(app, http.MethodPost, "/drone/me/broken", nil, drToken)
+	w = doRequest(app, http.MethodGet, "/admin/airspace/reservations", nil, adminToken(t, app))
+	if n := len(parseJSON(t, w)["reservations"].([]any)); n != 0 {
+		t.Fatalf("expected the broken drone's corridor to be released, got %d", n)
+	}
+}
+
+func TestAirspace_DelayedDepartureDelaysLease(t *testing.T) {
+	app := setupTestApp(t, withAirspaceDelay(2*time.Hour))
+	userToken := enduserToken(t, app, "user-1")
+	heartbeat := map[string]float64{"latitude": 24.72, "longitude": 46.68}
+
+	// Fill every band, so the fifth flight waits for the first to clear
+	var jobID string
+	for i := 0; i < 5; i++ {
+		_, jobID = placeTestOrder(t, app, userToken)
+		token := droneToken(t, app, fmt.Sprintf("drone-%d", i+1))
+		doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeat, token)
+		w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("reserve %d: expected 200, got %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+
+	var startsAt time.Time
+	if err := app.DB.Get(&startsAt, `SELECT starts_at FROM airspace_reservations WHERE drone_id = 'drone-5'`); err != nil {
+		t.Fatalf("get departure slot: %v", err)
+	}
+	if !startsAt.After(time.Now().Add(5 * time.Minute)) {
+		t.Fatalf("expected a delayed departure, got %v", startsAt)
+	}
+	lease, _ := jobLease(t, app, jobID)
+	if lease == nil || lease.Before(startsAt.Add(10*time.Minute)) {
+		t.Fatalf("expected the lease to count from departure at %v, got %v", startsAt, lease)
+	}
+}
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func heartbeatBody(lat, lng float64, seq int64, ts time.Time) map[string]any {
+	return map[string]any{"latitude": lat, "longitude": lng, "sequence": seq, "timestamp": ts.Format(time.RFC3339Nano)}
+}
+
+func TestAnomaly_ReplayedHeartbeatRejected(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+	now := time.Now()
+
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeatBody(24.72, 46.68, 1, now), drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("first heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeatBody(24.72, 46.68, 1, now.Add(time.Second)), drToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("replay: expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/drones/drone-1/anomalies", nil, aToken)
+	anomalies := parseJSON(t, w)["anomalies"].([]any)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got %d", len(anomalies))
+	}
+	a := anomalies[0].(map[string]any)
+	if a["kind"] != "REPLAY" || a["rejected"] != true {
+		t.Fatalf("expected rejected REPLAY, got %v", a)
+	}
+}
+
+func TestAnomaly_ImpossibleSpeedRaisesAlert(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+	now := time.Now()
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeatBody(24.70, 46.60, 1, now), drToken)
+	// ~11 km in 10 seconds
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeatBody(24.80, 46.60, 2, now.Add(10*time.Second)), drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("speed anomaly should still be accepted, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/alerts?drone_id=drone-1&unacknowledged=true", nil, aToken)
+	alerts := parseJSON(t, w)["alerts"].([]any)
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+	alert := alerts[0].(map[string]any)
+	if alert["kind"] != "IMPOSSIBLE_SPEED" || alert["severity"] != "CRITICAL" {
+		t.Fatalf("unexpected alert: %v", alert)
+	}
+
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/admin/alerts/%s/acknowledge", alert["id"]), nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("acknowledge: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/admin/alerts/%s/acknowledge", alert["id"]), nil, aToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second acknowledge: expected 409, got %d", w.Code)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/alerts?unacknowledged=true", nil, aToken)
+	if n := len(parseJSON(t, w)["alerts"].([]any)); n != 0 {
+		t.Fatalf("expected no unacknowledged alerts, got %d", n)
+	}
+}
+
+func TestAnomaly_QuarantineBlocksReservation(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	w := doRequest(app, http.MethodPost, "/admin/drones/drone-1/quarantine", map[string]string{"reason": "spoofing suspected"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("quarantine: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	_, jobID := placeTestOrder(t, app, userToken)
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("reserve while quarantined: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodDelete, "/admin/drones/drone-1/quarantine", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("lift: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve after lift: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
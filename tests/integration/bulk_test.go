@@ -0,0 +1,227 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// doUpload posts a raw body with the given content type.
+func doUpload(app *testApp, path, contentType, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("idem-%d", time.Now().UnixNano()))
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBulk_ExportOrders(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	placeTestOrder(t, app, userToken)
+	placeTestOrder(t, app, userToken)
+	withdrawnID, _ := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodDelete, fmt.Sprintf("/orders/%s", withdrawnID), nil, userToken)
+
+	w := doRequest(app, http.MethodGet, "/admin/orders/export", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("csv export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("expected text/csv, got %q", ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 4 || records[0][0] != "id" {
+		t.Fatalf("expected a header and 3 orders, got %d rows", len(records))
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/orders/export?format=ndjson&status=WITHDRAWN", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ndjson export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var ids []string
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var o map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &o); err != nil {
+			t.Fatalf("parse ndjson line: %v", err)
+		}
+		ids = append(ids, o["id"].(string))
+	}
+	if len(ids) != 1 || ids[0] != withdrawnID {
+		t.Fatalf("expected only the withdrawn order, got %v", ids)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/orders/export?format=xml", nil, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format: expected 400, got %d", w.Code)
+	}
+}
+
+func TestBulk_ExportDrones(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	for _, id := range []string{"drone-2", "drone-1"} {
+		doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, droneToken(t, app, id))
+	}
+
+	w := doRequest(app, http.MethodGet, "/admin/drones/export?format=csv", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 3 || records[1][0] != "drone-1" || records[2][0] != "drone-2" {
+		t.Fatalf("expected a header and drones by id, got %v", records)
+	}
+}
+
+func TestBulk_ImportReportsEachRow(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	body := strings.Join([]string{
+		"reference,origin_lat,origin_lng,dest_lat,dest_lng,payload_kg,service_tier",
+		"a,24.72,46.68,24.73,46.69,1.5,",
+		"b,10.0,10.0,24.73,46.69,,",
+		"c,24.72,46.68,24.73,46.69,,EXPRESS",
+		"d,north,46.68,24.73,46.69,,",
+		"e,24.72,46.68,24.73,46.69,,OVERNIGHT",
+	}, "\n")
+	w := doUpload(app, "/orders/import", "text/csv", body, userToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	report := parseJSON(t, w)["import"].(map[string]any)
+	if report["total"] != 5.0 || report["created"] != 2.0 || report["failed"] != 3.0 {
+		t.Fatalf("unexpected totals: %v", report)
+	}
+	results := report["results"].([]any)
+	wantErr := map[string]string{"b": "OUT_OF_ZONE", "d": "VALIDATION", "e": "VALIDATION"}
+	for _, r := range results {
+		res := r.(map[string]any)
+		ref := res["reference"].(string)
+		if code, ok := wantErr[ref]; ok {
+			if e, _ := res["error"].(map[string]any); e == nil || e["code"] != code {
+				t.Fatalf("row %s: expected %s, got %v", ref, code, res["error"])
+			}
+			continue
+		}
+		if res["order_id"] == nil || res["order_status"] != "PENDING" {
+			t.Fatalf("row %s: expected a PENDING order, got %v", ref, res)
+		}
+	}
+
+	// The created orders belong to the uploader and have jobs
+	if ids := openJobOrder(t, app, drToken); len(ids) != 2 {
+		t.Fatalf("expected 2 open jobs, got %v", ids)
+	}
+	w = doRequest(app, http.MethodGet, "/orders", nil, userToken)
+	if n := len(parseJSON(t, w)["orders"].([]any)); n != 2 {
+		t.Fatalf("expected 2 orders for user-1, got %d", n)
+	}
+}
+
+func TestBulk_ImportNDJSON(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	body := `{"origin":{"lat":24.72,"lng":46.68},"destination":{"lat":24.73,"lng":46.69},"reference":"x"}
+{"origin":{"lat":24.72,"lng":46.68},"destination":{"lat":24.73,"lng":46.69},"service_tier":"EXPRESS"}
+not json
+`
+	w := doUpload(app, "/orders/import", "application/x-ndjson", body, userToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	report := parseJSON(t, w)["import"].(map[string]any)
+	if report["created"] != 2.0 || report["failed"] != 1.0 {
+		t.Fatalf("unexpected totals: %v", report)
+	}
+}
+
+func TestBulk_ImportAuthorizesEachRow(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	body := "origin_lat,origin_lng,dest_lat,dest_lng\n24.72,46.68,24.73,46.69\n24.72,46.68,24.74,46.70\n"
+	w := doUpload(app, "/orders/import?payment_method=pm_card_visa", "text/csv", body, userToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, r := range parseJSON(t, w)["import"].(map[string]any)["results"].([]any) {
+		id, _ := r.(map[string]any)["order_id"].(string)
+		if id == "" {
+			t.Fatalf("expected every row imported, got %v", r)
+		}
+		if status := paymentStatus(t, app, id); status != "AUTHORIZED" {
+			t.Fatalf("order %s: expected an AUTHORIZED payment, got %s", id, status)
+		}
+	}
+
+	// A declined payment method fails every row and creates nothing
+	w = doUpload(app, "/orders/import?payment_method=pm_card_declined", "text/csv", body, userToken)
+	report := parseJSON(t, w)["import"].(map[string]any)
+	if report["created"] != 0.0 {
+		t.Fatalf("expected no orders with a declined card, got %v", report)
+	}
+	for _, r := range report["results"].([]any) {
+		if e, _ := r.(map[string]any)["error"].(map[string]any); e == nil || e["code"] != "PAYMENT_DECLINED" {
+			t.Fatalf("expected PAYMENT_DECLINED, got %v", r)
+		}
+	}
+}
+
+func TestBulk_ImportRejectsWholeUpload(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	w := doUpload(app, "/orders/import", "text/csv", "origin_lat,origin_lng,dest_lat\n24.72,46.68,24.73\n", userToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("missing column: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The harness allows 5 rows
+	rows := []string{"origin_lat,origin_lng,dest_lat,dest_lng"}
+	for range 6 {
+		rows = append(rows, "24.72,46.68,24.73,46.69")
+	}
+	w = doUpload(app, "/orders/import", "text/csv", strings.Join(rows, "\n"), userToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("too many rows: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodGet, "/orders", nil, userToken)
+	if n := len(parseJSON(t, w)["orders"].([]any)); n != 0 {
+		t.Fatalf("expected nothing created, got %d orders", n)
+	}
+}
+
+func TestBulk_ExportEscapesFormulas(t *testing.T) {
+	app := setupTestApp(t)
+	placeTestOrder(t, app, enduserToken(t, app, "=HYPERLINK(\"http://evil.example\")"))
+
+	w := doRequest(app, http.MethodGet, "/admin/orders/export", nil, adminToken(t, app))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 2 || records[1][1] != "'=HYPERLINK(\"http://evil.example\")" {
+		t.Fatalf("expected the submitter escaped, got %v", records)
+	}
+}
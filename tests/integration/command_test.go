@@ -0,0 +1,203 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"drone-delivery/internal/command"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func issueCommand(t *testing.T, app *testApp, droneID, typ string) string {
+	t.Helper()
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/admin/drones/%s/commands", droneID),
+		map[string]string{"type": typ, "reason": "test"}, adminToken(t, app))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("issue %s: expected 201, got %d: %s", typ, w.Code, w.Body.String())
+	}
+	return parseJSON(t, w)["command"].(map[string]any)["id"].(string)
+}
+
+func TestCommand_AbortReturnsAssignedOrderToPending(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	heartbeat := map[string]float64{"latitude": 24.72, "longitude": 46.68}
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeat, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	cmdID := issueCommand(t, app, "drone-1", "ABORT_MISSION")
+
+	// Delivered in the heartbeat response
+	w = doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeat, drToken)
+	cmds := parseJSON(t, w)["commands"].([]any)
+	if len(cmds) != 1 {
+		t.Fatalf("expected 1 command in heartbeat response, got %d", len(cmds))
+	}
+	cmd := cmds[0].(map[string]any)
+	if cmd["id"] != cmdID || cmd["status"] != "DELIVERED" || cmd["order_id"] != orderID {
+		t.Fatalf("unexpected command: %v", cmd)
+	}
+
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/drone/me/commands/%s/ack", cmdID), map[string]string{"result": "EXECUTED"}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if status := parseJSON(t, w)["order"].(map[string]any)["status"]; status != "PENDING" {
+		t.Fatalf("expected order PENDING after abort, got %v", status)
+	}
+	w = doRequest(app, http.MethodPost, "/drone/me/heartbeat", heartbeat, drToken)
+	resp := parseJSON(t, w)
+	if resp["drone_status"] != "IDLE" {
+		t.Fatalf("expected drone IDLE after abort, got %v", resp["drone_status"])
+	}
+	if n := len(resp["commands"].([]any)); n != 0 {
+		t.Fatalf("acknowledged command should not be redelivered, got %d", n)
+	}
+
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/drone/me/commands/%s/ack", cmdID), map[string]string{"result": "EXECUTED"}, drToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second ack: expected 409, got %d", w.Code)
+	}
+}
+
+func TestCommand_LongPollWakesOnIssue(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+
+	aToken := adminToken(t, app)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		doRequest(app, http.MethodPost, "/admin/drones/drone-1/commands", map[string]string{"type": "HOLD_POSITION"}, aToken)
+	}()
+
+	start := time.Now()
+	w := doRequest(app, http.MethodGet, "/drone/me/commands?wait=2", nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("poll: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	cmds := parseJSON(t, w)["commands"].([]any)
+	if len(cmds) != 1 || cmds[0].(map[string]any)["type"] != "HOLD_POSITION" {
+		t.Fatalf("expected HOLD_POSITION, got %v", cmds)
+	}
+	if time.Since(start) > 1500*time.Millisecond {
+		t.Fatalf("long-poll should return when the command is issued, took %v", time.Since(start))
+	}
+}
+
+func TestCommand_RejectRaisesAlert(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+
+	w := doRequest(app, http.MethodPost, "/admin/drones/drone-1/commands", map[string]string{"type": "ABORT_MISSION"}, aToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("abort without a mission: expected 409, got %d", w.Code)
+	}
+	w = doRequest(app, http.MethodPost, "/admin/drones/drone-1/commands", map[string]string{"type": "DANCE"}, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown type: expected 400, got %d", w.Code)
+	}
+
+	cmdID := issueCommand(t, app, "drone-1", "RETURN_TO_BASE")
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/drone/me/commands/%s/ack", cmdID),
+		map[string]string{"result": "REJECTED", "message": "low battery"}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/alerts?kind=COMMAND_REJECTED", nil, aToken)
+	if n := len(parseJSON(t, w)["alerts"].([]any)); n != 1 {
+		t.Fatalf("expected 1 COMMAND_REJECTED alert, got %d", n)
+	}
+	w = doRequest(app, http.MethodGet, "/admin/drones/drone-1/commands", nil, aToken)
+	cmd := parseJSON(t, w)["commands"].([]any)[0].(map[string]any)
+	if cmd["status"] != "REJECTED" || cmd["result_message"] != "low battery" {
+		t.Fatalf("unexpected command: %v", cmd)
+	}
+
+	// Another drone can't acknowledge it
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/drone/me/commands/%s/ack", cmdID),
+		map[string]string{"result": "EXECUTED"}, droneToken(t, app, "drone-2"))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("foreign ack: expected 404, got %d", w.Code)
+	}
+}
+
+func TestCommand_LandImmediatelyHandsOffOrder(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+
+	cmdID := issueCommand(t, app, "drone-1", "LAND_IMMEDIATELY")
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/drone/me/commands/%s/ack", cmdID), map[string]string{"result": "EXECUTED"}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ack: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if status := parseJSON(t, w)["order"].(map[string]any)["status"]; status != "AWAITING_HANDOFF" {
+		t.Fatalf("expected AWAITING_HANDOFF after landing, got %v", status)
+	}
+}
+
+// lateFailingExecutor applies a landing and then fails, as if the ack broke
+// after the delivery state machine had moved.
+type lateFailingExecutor struct {
+	command.Executor
+}
+
+func (e lateFailingExecutor) HandleDroneBrokenWithTx(ctx context.Context, tx *sqlx.Tx, droneID string) (func(), error) {
+	if _, err := e.Executor.HandleDroneBrokenWithTx(ctx, tx, droneID); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("connection reset")
+}
+
+func TestCommand_FailedAckRollsBackTheExecution(t *testing.T) {
+	app := setupTestApp(t, withExecutor(func(e command.Executor) command.Executor {
+		return lateFailingExecutor{e}
+	}))
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+
+	cmdID := issueCommand(t, app, "drone-1", "LAND_IMMEDIATELY")
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/drone/me/commands/%s/ack", cmdID), map[string]string{"result": "EXECUTED"}, drToken)
+	if w.Code == http.StatusOK {
+		t.Fatalf("ack: expected failure, got 200: %s", w.Body.String())
+	}
+
+	// Neither the landing nor the ack sticks, so a retried ack applies it once
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if status := parseJSON(t, w)["order"].(map[string]any)["status"]; status != "PICKED_UP" {
+		t.Fatalf("expected order still PICKED_UP, got %v", status)
+	}
+	w = doRequest(app, http.MethodGet, "/drone/me/commands", nil, drToken)
+	if cmds := parseJSON(t, w)["commands"].([]any); len(cmds) != 1 {
+		t.Fatalf("expected the command still outstanding, got %d", len(cmds))
+	}
+}
@@ -0,0 +1,77 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFlightState_ShownInAdminListing(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]any{
+		"latitude": 24.72, "longitude": 46.68,
+		"altitude_m": 120.5, "heading_deg": 90, "ground_speed_kmh": 42, "battery_pct": 76,
+		"fault_codes": []string{"LOW_SIGNAL"},
+	}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/drones", nil, aToken)
+	d := parseJSON(t, w)["drones"].([]any)[0].(map[string]any)
+	if d["altitude_m"] != 120.5 || d["battery_pct"] != float64(76) || d["heading_deg"] != float64(90) {
+		t.Fatalf("unexpected flight state: %v", d)
+	}
+	if faults := d["fault_codes"].([]any); len(faults) != 1 || faults[0] != "LOW_SIGNAL" {
+		t.Fatalf("expected LOW_SIGNAL fault, got %v", faults)
+	}
+}
+
+func TestFlightState_InvalidBatteryRejected(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]any{
+		"latitude": 24.72, "longitude": 46.68, "battery_pct": 140,
+	}, drToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestFlightState_CriticalFaultTriggersBrokenFlow(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]any{
+		"latitude": 24.72, "longitude": 46.68, "fault_codes": []string{"MOTOR_FAILURE"},
+	}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if status := parseJSON(t, w)["drone_status"]; status != "BROKEN" {
+		t.Fatalf("expected BROKEN, got %v", status)
+	}
+
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	order := parseJSON(t, w)["order"].(map[string]any)
+	if order["status"] != "AWAITING_HANDOFF" {
+		t.Fatalf("expected AWAITING_HANDOFF, got %v", order["status"])
+	}
+
+	// The fault persisting on later heartbeats doesn't re-run the flow
+	w = doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]any{
+		"latitude": 24.72, "longitude": 46.68, "fault_codes": []string{"MOTOR_FAILURE"},
+	}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("repeat heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
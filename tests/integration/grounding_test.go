@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func groundFlights(t *testing.T, app *testApp, body map[string]any) map[string]any {
+	t.Helper()
+	w := doRequest(app, http.MethodPost, "/admin/groundings", body, adminToken(t, app))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("ground: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	return parseJSON(t, w)
+}
+
+func errorCode(t *testing.T, resp map[string]any) any {
+	t.Helper()
+	e, ok := resp["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected an error body, got %v", resp)
+	}
+	return e["code"]
+}
+
+func TestGrounding_FleetStopsDispatchUntilLifted(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	_, jobID := placeTestOrder(t, app, userToken)
+
+	resp := groundFlights(t, app, map[string]any{"scope": "FLEET", "reason": "storm"})
+	groundingID := resp["grounding"].(map[string]any)["id"].(string)
+
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("reserve while grounded: expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if code := errorCode(t, parseJSON(t, w)); code != "GROUNDED" {
+		t.Fatalf("expected GROUNDED, got %v", code)
+	}
+
+	w = doRequest(app, http.MethodGet, "/drone/jobs", nil, drToken)
+	if n := len(parseJSON(t, w)["jobs"].([]any)); n != 0 {
+		t.Fatalf("expected no open jobs while grounded, got %d", n)
+	}
+
+	// QUEUE is the default policy: orders are still accepted
+	body := map[string]any{"origin": validOrigin(), "destination": validDestination()}
+	w = doRequest(app, http.MethodPost, "/orders", body, userToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("order under QUEUE grounding: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodDelete, fmt.Sprintf("/admin/groundings/%s", groundingID), nil, adminToken(t, app))
+	if w.Code != http.StatusOK {
+		t.Fatalf("lift: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodDelete, fmt.Sprintf("/admin/groundings/%s", groundingID), nil, adminToken(t, app))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("second lift: expected 409, got %d", w.Code)
+	}
+
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve after lift: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGrounding_RecallsDronesInFlight(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+
+	resp := groundFlights(t, app, map[string]any{"scope": "FLEET", "reason": "storm", "drone_action": "LAND_IMMEDIATELY"})
+	commanded := resp["commanded_drones"].([]any)
+	if len(commanded) != 1 || commanded[0] != "drone-1" {
+		t.Fatalf("expected drone-1 to be commanded, got %v", commanded)
+	}
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("grab while grounded: expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/drone/me/commands", nil, drToken)
+	cmds := parseJSON(t, w)["commands"].([]any)
+	if len(cmds) != 1 || cmds[0].(map[string]any)["type"] != "LAND_IMMEDIATELY" {
+		t.Fatalf("expected a LAND_IMMEDIATELY command, got %v", cmds)
+	}
+}
+
+func TestGrounding_AreaRejectPolicy(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	// Covers validOrigin() but not the far side of the zone
+	groundFlights(t, app, map[string]any{
+		"scope":        "AREA",
+		"reason":       "air show",
+		"center":       validOrigin(),
+		"radius_km":    0.5,
+		"order_policy": "REJECT",
+	})
+
+	body := map[string]any{"origin": validOrigin(), "destination": validDestination()}
+	w := doRequest(app, http.MethodPost, "/orders", body, userToken)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("order inside REJECT area: expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body = map[string]any{
+		"origin":      map[string]float64{"lat": 24.80, "lng": 46.75},
+		"destination": map[string]float64{"lat": 24.81, "lng": 46.76},
+	}
+	w = doRequest(app, http.MethodPost, "/orders", body, userToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("order outside area: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/groundings?active=true", nil, adminToken(t, app))
+	if n := len(parseJSON(t, w)["groundings"].([]any)); n != 1 {
+		t.Fatalf("expected 1 active grounding, got %d", n)
+	}
+}
+
+func TestGrounding_Validation(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	cases := []map[string]any{
+		{"scope": "AREA", "reason": "x"},
+		{"scope": "FLEET", "reason": "x", "radius_km": 2},
+		{"scope": "FLEET", "reason": "x", "drone_action": "HOLD_POSITION"},
+		{"scope": "MOON", "reason": "x"},
+	}
+	for _, body := range cases {
+		w := doRequest(app, http.MethodPost, "/admin/groundings", body, aToken)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected 400, got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+}
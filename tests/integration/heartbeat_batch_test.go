@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func sample(lat, lng float64, seq int64, ts time.Time) map[string]any {
+	return map[string]any{"latitude": lat, "longitude": lng, "sequence": seq, "timestamp": ts.Format(time.RFC3339Nano)}
+}
+
+func TestHeartbeatBatch_AcksEachSample(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+	now := time.Now().Add(-time.Minute)
+
+	w := doRequest(app, http.MethodPost, "/drone/me/telemetry", map[string]any{"samples": []any{
+		sample(24.720, 46.68, 1, now),
+		sample(24.721, 46.68, 2, now.Add(5*time.Second)),
+		sample(24.721, 46.68, 2, now.Add(6*time.Second)), // replay
+		map[string]any{"latitude": 24.722, "longitude": 46.68, "sequence": 3},
+		sample(24.722, 46.68, 4, now.Add(10*time.Second)),
+	}}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp := parseJSON(t, w)
+	if resp["accepted"] != float64(3) || resp["rejected"] != float64(2) {
+		t.Fatalf("expected 3 accepted / 2 rejected, got %v", resp)
+	}
+	acks := resp["acks"].([]any)
+	for i, want := range []bool{true, true, false, false, true} {
+		if got := acks[i].(map[string]any)["accepted"]; got != want {
+			t.Fatalf("sample %d: expected accepted=%v, got %v", i, want, got)
+		}
+	}
+
+	// Postgres catches up on flush
+	if err := app.Heartbeats.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	w = doRequest(app, http.MethodGet, "/admin/drones", nil, aToken)
+	d := parseJSON(t, w)["drones"].([]any)[0].(map[string]any)
+	if d["latitude"] != 24.722 || d["last_heartbeat_seq"] != float64(4) {
+		t.Fatalf("expected latest sample persisted, got %v", d)
+	}
+}
+
+func TestHeartbeatBatch_ReplayAcrossPaths(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	now := time.Now().Add(-time.Minute)
+
+	doRequest(app, http.MethodPost, "/drone/me/telemetry", map[string]any{"samples": []any{
+		sample(24.72, 46.68, 10, now),
+	}}, drToken)
+
+	// Not flushed yet, but a single heartbeat still sees the buffered sequence
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", sample(24.72, 46.68, 10, now.Add(time.Second)), drToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for replayed sequence, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHeartbeatBatch_ConcurrentBatchesAcceptEachSequenceOnce(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	now := time.Now().Add(-time.Minute)
+	batch := map[string]any{"samples": []any{
+		sample(24.720, 46.68, 1, now),
+		sample(24.721, 46.68, 2, now.Add(5*time.Second)),
+		sample(24.722, 46.68, 3, now.Add(10*time.Second)),
+	}}
+
+	const n = 4
+	responses := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = doRequest(app, http.MethodPost, "/drone/me/telemetry", batch, drToken)
+		}()
+	}
+	wg.Wait()
+
+	accepted := 0.0
+	for _, w := range responses {
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		accepted += parseJSON(t, w)["accepted"].(float64)
+	}
+	if accepted != 3 {
+		t.Fatalf("expected each sequence accepted once across the batches, got %v", accepted)
+	}
+}
+
+func TestHeartbeatBatch_GzipProtobuf(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	ts := time.Now().Add(-time.Minute)
+
+	var batch []byte
+	for i := range 3 {
+		var hb []byte
+		hb = protowire.AppendTag(hb, 1, protowire.Fixed64Type)
+		hb = protowire.AppendFixed64(hb, math.Float64bits(24.72))
+		hb = protowire.AppendTag(hb, 2, protowire.Fixed64Type)
+		hb = protowire.AppendFixed64(hb, math.Float64bits(46.68))
+		hb = protowire.AppendTag(hb, 3, protowire.VarintType)
+		hb = protowire.AppendVarint(hb, uint64(i+1))
+		hb = protowire.AppendTag(hb, 4, protowire.VarintType)
+		hb = protowire.AppendVarint(hb, uint64(ts.Add(time.Duration(i)*time.Second).UnixMilli()))
+		batch = protowire.AppendTag(batch, 1, protowire.BytesType)
+		batch = protowire.AppendBytes(batch, hb)
+	}
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	gz.Write(batch)
+	gz.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/drone/me/telemetry", &body)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("Authorization", "Bearer "+drToken)
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Fatalf("expected protobuf ack, got %s", ct)
+	}
+	num, typ, n := protowire.ConsumeTag(w.Body.Bytes())
+	if n < 0 || num != 1 || typ != protowire.VarintType {
+		t.Fatalf("expected accepted count first, got field %d", num)
+	}
+	if accepted, _ := protowire.ConsumeVarint(w.Body.Bytes()[n:]); accepted != 3 {
+		t.Fatalf("expected 3 accepted, got %d", accepted)
+	}
+}
+
+func TestHeartbeatBatch_TooManySamples(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	now := time.Now().Add(-time.Hour)
+
+	samples := make([]any, 51)
+	for i := range samples {
+		samples[i] = sample(24.72, 46.68, int64(i+1), now.Add(time.Duration(i)*time.Second))
+	}
+	w := doRequest(app, http.MethodPost, "/drone/me/telemetry", map[string]any{"samples": samples}, drToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
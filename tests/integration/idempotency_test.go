@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// doKeyed sends a JSON request with a fixed Idempotency-Key.
+func doKeyed(app *testApp, method, path, key string, body any, token string) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ConcurrentReserveRunsOnce(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+
+	_, jobID := placeTestOrder(t, app, userToken)
+	body := map[string]string{"job_id": jobID}
+
+	const n = 8
+	responses := make([]*httptest.ResponseRecorder, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = doKeyed(app, http.MethodPost, "/drone/jobs/reserve", "reserve-once", body, drToken)
+		}()
+	}
+	wg.Wait()
+
+	executed := 0
+	for _, w := range responses {
+		switch {
+		case w.Code == http.StatusOK && w.Header().Get("Idempotent-Replayed") == "":
+			executed++
+		case w.Code == http.StatusOK, w.Code == http.StatusConflict && bytes.Contains(w.Body.Bytes(), []byte("REQUEST_IN_PROGRESS")):
+		default:
+			t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if executed != 1 {
+		t.Fatalf("expected the reservation to run exactly once, ran %d times", executed)
+	}
+
+	// Once it has finished, a retry replays the original response
+	w := doKeyed(app, http.MethodPost, "/drone/jobs/reserve", "reserve-once", body, drToken)
+	if w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected a replayed 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestIdempotency_ReplayKeepsCreatedStatus(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	body := map[string]any{"origin": validOrigin(), "destination": validDestination()}
+	first := doKeyed(app, http.MethodPost, "/orders", "place-once", body, userToken)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("place order: expected 201, got %d: %s", first.Code, first.Body.String())
+	}
+	second := doKeyed(app, http.MethodPost, "/orders", "place-once", body, userToken)
+	if second.Code != http.StatusCreated {
+		t.Fatalf("replay: expected 201, got %d: %s", second.Code, second.Body.String())
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("replay returned a different body")
+	}
+
+	w := doRequest(app, http.MethodGet, "/orders", nil, userToken)
+	if orders := parseJSON(t, w)["orders"].([]any); len(orders) != 1 {
+		t.Fatalf("expected one order placed, got %d", len(orders))
+	}
+}
+
+func TestIdempotency_KeyReuseWithOtherPayload(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	body := map[string]any{"origin": validOrigin(), "destination": validDestination()}
+	if w := doKeyed(app, http.MethodPost, "/orders", "reused", body, userToken); w.Code != http.StatusCreated {
+		t.Fatalf("place order: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body["destination"] = map[string]float64{"lat": 24.74, "lng": 46.70}
+	w := doKeyed(app, http.MethodPost, "/orders", "reused", body, userToken)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key, got %d: %s", w.Code, w.Body.String())
+	}
+}
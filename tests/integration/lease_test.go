@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// jobLease reads the job's lease deadline and release reason.
+func jobLease(t *testing.T, app *testApp, jobID string) (*time.Time, string) {
+	t.Helper()
+	var row struct {
+		LeaseExpiresAt *time.Time `db:"lease_expires_at"`
+		ReleaseReason  string     `db:"release_reason"`
+	}
+	if err := app.DB.Get(&row, `SELECT lease_expires_at, release_reason FROM jobs WHERE id = $1`, jobID); err != nil {
+		t.Fatalf("get job lease: %v", err)
+	}
+	return row.LeaseExpiresAt, row.ReleaseReason
+}
+
+func TestLease_ExpiredReservationIsReverted(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if lease := parseJSON(t, w)["job"].(map[string]any)["lease_expires_at"]; lease == nil {
+		t.Fatal("expected the reserved job to carry a lease")
+	}
+
+	// Still within the lease
+	if n, err := app.Leases.Sweep(context.Background(), time.Now()); err != nil || n != 0 {
+		t.Fatalf("early sweep: expected nothing reverted, got %d (%v)", n, err)
+	}
+
+	n, err := app.Leases.Sweep(context.Background(), time.Now().Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("sweep: expected 1 reverted, got %d (%v)", n, err)
+	}
+
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if status := parseJSON(t, w)["order"].(map[string]any)["status"]; status != "PENDING" {
+		t.Fatalf("expected order back to PENDING, got %v", status)
+	}
+	var droneStatus string
+	if err := app.DB.Get(&droneStatus, `SELECT status FROM drones WHERE id = 'drone-1'`); err != nil {
+		t.Fatalf("get drone status: %v", err)
+	}
+	if droneStatus != "IDLE" {
+		t.Fatalf("expected drone IDLE, got %s", droneStatus)
+	}
+	// The drone is told to stop flying to the pickup
+	w = doRequest(app, http.MethodGet, "/drone/me/commands", nil, drToken)
+	cmds := parseJSON(t, w)["commands"].([]any)
+	if len(cmds) != 1 || cmds[0].(map[string]any)["type"] != "RETURN_TO_BASE" {
+		t.Fatalf("expected a RETURN_TO_BASE command for the drone, got %v", cmds)
+	}
+	lease, reason := jobLease(t, app, jobID)
+	if lease != nil {
+		t.Fatal("expected the lease to be cleared")
+	}
+	if reason == "" {
+		t.Fatal("expected a release reason on the reopened job")
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/airspace/reservations", nil, adminToken(t, app))
+	if n := len(parseJSON(t, w)["reservations"].([]any)); n != 0 {
+		t.Fatalf("expected the corridor to be released, got %d", n)
+	}
+
+	// The job can be reserved again
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("re-reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestLease_PickupEndsLease(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+
+	w := doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("grab: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if lease, _ := jobLease(t, app, jobID); lease != nil {
+		t.Fatal("expected the pickup to end the lease")
+	}
+
+	if n, err := app.Leases.Sweep(context.Background(), time.Now().Add(time.Hour)); err != nil || n != 0 {
+		t.Fatalf("sweep: expected nothing reverted, got %d (%v)", n, err)
+	}
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", orderID), nil, userToken)
+	if status := parseJSON(t, w)["order"].(map[string]any)["status"]; status != "PICKED_UP" {
+		t.Fatalf("expected order still PICKED_UP, got %v", status)
+	}
+}
+
+func TestLease_HeartbeatProgressExtendsLease(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	// About 9 km north of the pickup
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.80, "longitude": 46.68}, drToken)
+	_, jobID := placeTestOrder(t, app, userToken)
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	// A slow flight has used up most of the lease
+	app.DB.MustExec(`UPDATE jobs SET lease_expires_at = NOW() + INTERVAL '1 minute' WHERE id = $1`, jobID)
+	before, _ := jobLease(t, app, jobID)
+
+	// Hovering in place doesn't extend it
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.80, "longitude": 46.68}, drToken)
+	if same, _ := jobLease(t, app, jobID); same == nil || !same.Equal(*before) {
+		t.Fatalf("expected lease unchanged while hovering, got %v -> %v", before, same)
+	}
+
+	// Halfway there, the deadline is reset from the remaining distance
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.76, "longitude": 46.68}, drToken)
+	after, _ := jobLease(t, app, jobID)
+	if after == nil || !after.After(before.Add(5*time.Minute)) {
+		t.Fatalf("expected lease extended well past %v, got %v", before, after)
+	}
+}
@@ -0,0 +1,172 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// walkPages follows next_cursor from path, starting at cursor, until the
+// last page, returning the ids of the items under key in order.
+func walkPages(t *testing.T, app *testApp, path, cursor, key, token string) []string {
+	t.Helper()
+	var ids []string
+	for range 50 {
+		p := path
+		if cursor != "" {
+			p += "&cursor=" + url.QueryEscape(cursor)
+		}
+		w := doRequest(app, http.MethodGet, p, nil, token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", p, w.Code, w.Body.String())
+		}
+		resp := parseJSON(t, w)
+		for _, item := range resp[key].([]any) {
+			ids = append(ids, item.(map[string]any)["id"].(string))
+		}
+		cursor, _ = resp["next_cursor"].(string)
+		if cursor == "" {
+			return ids
+		}
+	}
+	t.Fatalf("%s: too many pages", path)
+	return nil
+}
+
+func TestListing_AdminOrdersCursorWalk(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	var placed []string
+	for range 5 {
+		id, _ := placeTestOrder(t, app, userToken)
+		placed = append(placed, id)
+	}
+
+	w := doRequest(app, http.MethodGet, "/admin/orders?limit=2", nil, aToken)
+	resp := parseJSON(t, w)
+	if resp["total"] != 5.0 || len(resp["orders"].([]any)) != 2 {
+		t.Fatalf("expected 2 of 5 orders, got %v", resp)
+	}
+	first := resp["orders"].([]any)[0].(map[string]any)["id"]
+	if first != placed[4] {
+		t.Fatalf("expected newest first, got %v", first)
+	}
+
+	// An order placed mid-walk doesn't shift the pages that follow
+	cursor := resp["next_cursor"].(string)
+	placeTestOrder(t, app, userToken)
+	rest := walkPages(t, app, "/admin/orders?limit=2", cursor, "orders", aToken)
+	if len(rest) != 3 || rest[0] != placed[2] || rest[2] != placed[0] {
+		t.Fatalf("expected the 3 older orders, got %v", rest)
+	}
+
+	ascending := walkPages(t, app, "/admin/orders?limit=4&sort=created_at", "", "orders", aToken)
+	if len(ascending) != 6 || ascending[0] != placed[0] {
+		t.Fatalf("expected all 6 oldest first, got %v", ascending)
+	}
+}
+
+func TestListing_OrderFilters(t *testing.T) {
+	app := setupTestApp(t)
+	user1 := enduserToken(t, app, "user-1")
+	user2 := enduserToken(t, app, "user-2")
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	assignedID, jobID := placeTestOrder(t, app, user1)
+	doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	placeTestOrder(t, app, user2)
+	w := doRequest(app, http.MethodPost, "/orders", map[string]any{
+		"origin":      map[string]float64{"lat": 24.70, "lng": 46.70},
+		"destination": validDestination(),
+	}, user2)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("place order: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	southID := parseJSON(t, w)["order"].(map[string]any)["id"].(string)
+
+	tests := []struct {
+		query string
+		want  []string
+		total float64
+	}{
+		{"drone_id=drone-1", []string{assignedID}, 1},
+		{"submitted_by=user-2&bbox=24.69,46.69,24.71,46.71", []string{southID}, 1},
+		{"status=ASSIGNED", []string{assignedID}, 1},
+	}
+	for _, tt := range tests {
+		w := doRequest(app, http.MethodGet, "/admin/orders?"+tt.query, nil, aToken)
+		resp := parseJSON(t, w)
+		orders := resp["orders"].([]any)
+		if len(orders) != len(tt.want) || orders[0].(map[string]any)["id"] != tt.want[0] || resp["total"] != tt.total {
+			t.Fatalf("%s: expected %v, got %v", tt.query, tt.want, resp)
+		}
+	}
+
+	// A user only ever sees their own orders
+	mine := walkPages(t, app, "/orders?limit=1&submitted_by=user-1", "", "orders", user2)
+	if len(mine) != 2 {
+		t.Fatalf("expected user-2's 2 orders, got %v", mine)
+	}
+
+	for _, q := range []string{"limit=500", "sort=color", "bbox=1,2", "cursor=%21"} {
+		if w := doRequest(app, http.MethodGet, "/admin/orders?"+q, nil, aToken); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
+
+func TestListing_DronesAndJobs(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+
+	for _, id := range []string{"drone-3", "drone-1", "drone-2"} {
+		doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, droneToken(t, app, id))
+	}
+	drones := walkPages(t, app, "/admin/drones?limit=2&sort=id", "", "drones", aToken)
+	if len(drones) != 3 || drones[0] != "drone-1" || drones[2] != "drone-3" {
+		t.Fatalf("expected drones by id, got %v", drones)
+	}
+
+	var placed []string
+	for range 3 {
+		id, _ := placeTestOrder(t, app, userToken)
+		placed = append(placed, id)
+	}
+
+	// Open jobs page in dispatch order: equal priority, oldest first
+	var queue []string
+	cursor := ""
+	for {
+		path := "/drone/jobs?limit=2"
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor)
+		}
+		resp := parseJSON(t, doRequest(app, http.MethodGet, path, nil, drToken))
+		for _, j := range resp["jobs"].([]any) {
+			queue = append(queue, j.(map[string]any)["order_id"].(string))
+		}
+		if cursor = resp["next_cursor"].(string); cursor == "" {
+			break
+		}
+	}
+	if len(queue) != 3 || queue[0] != placed[0] || queue[2] != placed[2] {
+		t.Fatalf("expected the 3 jobs oldest first, got %v", queue)
+	}
+	if w := doRequest(app, http.MethodGet, "/drone/jobs?status=OPEN", nil, drToken); w.Code != http.StatusBadRequest {
+		t.Fatalf("filtered open jobs: expected 400, got %d", w.Code)
+	}
+
+	w := doRequest(app, http.MethodGet, "/admin/jobs?status=OPEN&submitted_by=user-1", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin jobs: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if total := parseJSON(t, w)["total"]; total != 3.0 {
+		t.Fatalf("expected 3 open jobs for user-1, got %v", total)
+	}
+}
@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// deliverOrder runs reserve → grab → complete for jobID with the given drone.
+func deliverOrder(t *testing.T, app *testApp, drToken, orderID, jobID string) {
+	t.Helper()
+
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("grab: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPatch, fmt.Sprintf("/drone/orders/%s/complete", orderID), map[string]string{"status": "delivered"}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("complete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMaintenance_CycleLimitLocksOutDrone(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	w := doRequest(app, http.MethodPut, "/admin/maintenance/schedules/DX-4", map[string]any{"max_cycles": 1}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("set schedule: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	doRequest(app, http.MethodPost, "/admin/drones", map[string]any{"id": "drone-1", "model": "DX-4"}, aToken)
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	deliverOrder(t, app, drToken, orderID, jobID)
+
+	// One cycle reaches the limit: the next reservation is refused
+	_, nextJob := placeTestOrder(t, app, userToken)
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": nextJob}, drToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while maintenance is due, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Routine service lifts the lockout
+	w = doRequest(app, http.MethodPost, "/admin/drones/drone-1/work-orders", map[string]any{"kind": "ROUTINE", "description": "100-cycle check"}, aToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("open work order: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	woID := parseJSON(t, w)["work_order"].(map[string]any)["id"].(string)
+
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/admin/work-orders/%s/complete", woID), map[string]any{"resolution": "ok"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("complete work order: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": nextJob}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected reservation after service, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMaintenance_BrokenAndFixedLinkRepairWorkOrder(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	doRequest(app, http.MethodPost, "/drone/me/broken", nil, drToken)
+
+	w := doRequest(app, http.MethodGet, "/admin/work-orders?drone_id=drone-1&status=OPEN", nil, aToken)
+	orders := parseJSON(t, w)["work_orders"].([]any)
+	if len(orders) != 1 || orders[0].(map[string]any)["kind"] != "REPAIR" {
+		t.Fatalf("expected one open REPAIR work order, got %s", w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodPatch, "/admin/drones/drone-1/status", map[string]string{"status": "fixed"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("mark fixed: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/work-orders?drone_id=drone-1&status=COMPLETED", nil, aToken)
+	if len(parseJSON(t, w)["work_orders"].([]any)) != 1 {
+		t.Fatalf("expected the repair work order to be completed, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNearby_IdleDronesByDistance(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	nearToken := droneToken(t, app, "drone-near")
+	farToken := droneToken(t, app, "drone-far")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.80, "longitude": 46.70}, farToken)
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, nearToken)
+
+	w := doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=20", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	drones := parseJSON(t, w)["drones"].([]any)
+	if len(drones) != 2 {
+		t.Fatalf("expected 2 drones, got %d", len(drones))
+	}
+	if id := drones[0].(map[string]any)["id"]; id != "drone-near" {
+		t.Fatalf("expected drone-near first, got %v", id)
+	}
+
+	// A tighter radius only reaches the near drone
+	w = doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=2", nil, aToken)
+	if n := len(parseJSON(t, w)["drones"].([]any)); n != 1 {
+		t.Fatalf("expected 1 drone within 2 km, got %d", n)
+	}
+}
+
+func TestNearby_ReservationMovesDroneOutOfIdleSet(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	_, jobID := placeTestOrder(t, app, userToken)
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=10", nil, aToken)
+	if n := len(parseJSON(t, w)["drones"].([]any)); n != 0 {
+		t.Fatalf("expected no idle drones after reservation, got %d", n)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=10&status=EN_ROUTE_PICKUP", nil, aToken)
+	if n := len(parseJSON(t, w)["drones"].([]any)); n != 1 {
+		t.Fatalf("expected 1 EN_ROUTE_PICKUP drone, got %d", n)
+	}
+}
+
+func TestNearby_SilentDroneIsPruned(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+
+	// Still fresh: nothing to prune
+	if n, err := app.Index.Prune(context.Background(), time.Now()); err != nil || n != 0 {
+		t.Fatalf("prune: expected 0, got %d (%v)", n, err)
+	}
+
+	// Two minutes without a heartbeat is past the max age
+	if n, err := app.Index.Prune(context.Background(), time.Now().Add(2*time.Minute)); err != nil || n != 1 {
+		t.Fatalf("prune: expected 1, got %d (%v)", n, err)
+	}
+	w := doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=10", nil, aToken)
+	if n := len(parseJSON(t, w)["drones"].([]any)); n != 0 {
+		t.Fatalf("expected the silent drone gone from the index, got %d", n)
+	}
+
+	// The next heartbeat puts it back
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	w = doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=10", nil, aToken)
+	if n := len(parseJSON(t, w)["drones"].([]any)); n != 1 {
+		t.Fatalf("expected the drone back after a heartbeat, got %d", n)
+	}
+}
+
+func TestNearby_InvalidRadius(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	w := doRequest(app, http.MethodGet, "/admin/drones/nearby?lat=24.7136&lng=46.6753&radius=0", nil, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNearby_JobsSortedByPriorityThenDistance(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	far := doRequest(app, http.MethodPost, "/orders", map[string]any{
+		"origin":      map[string]float64{"lat": 24.80, "lng": 46.70},
+		"destination": validDestination(),
+	}, userToken)
+	near := doRequest(app, http.MethodPost, "/orders", map[string]any{
+		"origin":      map[string]float64{"lat": 24.72, "lng": 46.68},
+		"destination": validDestination(),
+	}, userToken)
+	if far.Code != http.StatusCreated || near.Code != http.StatusCreated {
+		t.Fatalf("place orders: got %d and %d", far.Code, near.Code)
+	}
+	nearOrderID := parseJSON(t, near)["order"].(map[string]any)["id"]
+
+	w := doRequest(app, http.MethodGet, "/drone/jobs?sort=distance", nil, drToken)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 before any heartbeat, got %d: %s", w.Code, w.Body.String())
+	}
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.7136, "longitude": 46.6753}, drToken)
+
+	w = doRequest(app, http.MethodGet, "/drone/jobs?sort=distance", nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	jobs := parseJSON(t, w)["jobs"].([]any)
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	first := jobs[0].(map[string]any)
+	if first["order_id"] != nearOrderID {
+		t.Fatalf("expected nearest job first, got order %v", first["order_id"])
+	}
+	if _, ok := first["distance_km"]; !ok {
+		t.Fatal("expected distance_km on sorted jobs")
+	}
+	if v, _ := first["version"].(float64); v < 1 {
+		t.Fatalf("expected the job's version, got %v", first["version"])
+	}
+
+	w = doRequest(app, http.MethodGet, "/drone/jobs?sort=distance&limit=1", nil, drToken)
+	if jobs := parseJSON(t, w)["jobs"].([]any); len(jobs) != 1 {
+		t.Fatalf("expected limit to cap the jobs, got %d", len(jobs))
+	}
+
+	// Distance only breaks ties: a more urgent job comes first however far
+	farOrderID := parseJSON(t, far)["order"].(map[string]any)["id"]
+	app.DB.MustExec(`UPDATE jobs SET priority = priority + 10 WHERE order_id = $1`, farOrderID)
+	w = doRequest(app, http.MethodGet, "/drone/jobs?sort=distance", nil, drToken)
+	if first := parseJSON(t, w)["jobs"].([]any)[0].(map[string]any); first["order_id"] != farOrderID {
+		t.Fatalf("expected the higher-priority job first, got order %v", first["order_id"])
+	}
+}
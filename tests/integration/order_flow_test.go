@@ -199,6 +199,11 @@ func TestOrderFlow_FullDeliveryLifecycle(t *testing.T) {
 	if orderData["status"] != "DELIVERED" {
 		t.Fatalf("expected DELIVERED, got %s", orderData["status"])
 	}
+
+	// 10. Verify payment was captured
+	if status := paymentStatus(t, app, orderID); status != "CAPTURED" {
+		t.Fatalf("expected payment CAPTURED, got %s", status)
+	}
 }
 
 func TestOrderFlow_FailedDelivery(t *testing.T) {
@@ -228,6 +233,26 @@ func TestOrderFlow_FailedDelivery(t *testing.T) {
 	if order["status"] != "FAILED" {
 		t.Fatalf("expected FAILED, got %s", order["status"])
 	}
+
+	// Default refund policy returns the full amount
+	if status := paymentStatus(t, app, orderID); status != "VOIDED" {
+		t.Fatalf("expected payment VOIDED, got %s", status)
+	}
+}
+
+func TestOrderFlow_PlaceOrder_PaymentDeclined(t *testing.T) {
+	app := setupTestApp(t)
+	token := enduserToken(t, app, "user-1")
+
+	body := map[string]any{
+		"origin":         validOrigin(),
+		"destination":    validDestination(),
+		"payment_method": "pm_card_declined",
+	}
+	w := doRequest(app, http.MethodPost, "/orders", body, token)
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("expected 402, got %d: %s", w.Code, w.Body.String())
+	}
 }
 
 func TestOrderFlow_DroneHeartbeat(t *testing.T) {
@@ -256,3 +281,96 @@ func TestOrderFlow_DroneHeartbeat_OutOfZone(t *testing.T) {
 		t.Fatalf("expected 400 for out-of-zone heartbeat, got %d: %s", w.Code, w.Body.String())
 	}
 }
+
+func TestOrderFlow_QuoteThenPlaceOrder(t *testing.T) {
+	app := setupTestApp(t)
+	token := enduserToken(t, app, "user-1")
+
+	quoteBody := map[string]any{
+		"origin":      validOrigin(),
+		"destination": validDestination(),
+		"payload_kg":  2.0,
+	}
+	w := doRequest(app, http.MethodPost, "/orders/quote", quoteBody, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("quote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	quote := parseJSON(t, w)["quote"].(map[string]any)
+	amount := quote["amount"].(float64)
+	if amount <= 0 {
+		t.Fatalf("expected positive quote amount, got %v", amount)
+	}
+
+	body := map[string]any{
+		"origin":      validOrigin(),
+		"destination": validDestination(),
+		"payload_kg":  2.0,
+		"quote_token": quote["token"],
+	}
+	w = doRequest(app, http.MethodPost, "/orders", body, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("place: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	order := parseJSON(t, w)["order"].(map[string]any)
+	if order["price_amount"].(float64) != amount {
+		t.Fatalf("expected agreed price %v, got %v", amount, order["price_amount"])
+	}
+	if order["quote_id"] != quote["id"] {
+		t.Fatalf("expected quote id %v, got %v", quote["id"], order["quote_id"])
+	}
+}
+
+func TestOrderFlow_QuoteIsSingleUse(t *testing.T) {
+	app := setupTestApp(t)
+	token := enduserToken(t, app, "user-1")
+
+	w := doRequest(app, http.MethodPost, "/orders/quote", map[string]any{
+		"origin":      validOrigin(),
+		"destination": validDestination(),
+	}, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("quote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	quote := parseJSON(t, w)["quote"].(map[string]any)
+
+	body := map[string]any{
+		"origin":      validOrigin(),
+		"destination": validDestination(),
+		"quote_token": quote["token"],
+	}
+	w = doRequest(app, http.MethodPost, "/orders", body, token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("place: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The same token cannot price a second order
+	w = doRequest(app, http.MethodPost, "/orders", body, token)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a reused quote, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestOrderFlow_PlaceOrder_QuoteMismatch(t *testing.T) {
+	app := setupTestApp(t)
+	token := enduserToken(t, app, "user-1")
+
+	quoteBody := map[string]any{
+		"origin":      validOrigin(),
+		"destination": validDestination(),
+	}
+	w := doRequest(app, http.MethodPost, "/orders/quote", quoteBody, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("quote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	quote := parseJSON(t, w)["quote"].(map[string]any)
+
+	body := map[string]any{
+		"origin":      validOrigin(),
+		"destination": map[string]float64{"lat": 24.80, "lng": 46.75},
+		"quote_token": quote["token"],
+	}
+	w = doRequest(app, http.MethodPost, "/orders", body, token)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for mismatched quote, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"drone-delivery/internal/payment"
+)
+
+func TestPayment_DispatcherClaimsBeforeCallingProvider(t *testing.T) {
+	app := setupTestApp(t)
+	token := enduserToken(t, app, "user-1")
+	ctx := context.Background()
+
+	orderID, _ := placeTestOrder(t, app, token)
+	if w := doRequest(app, http.MethodDelete, fmt.Sprintf("/orders/%s", orderID), nil, token); w.Code != http.StatusOK {
+		t.Fatalf("withdraw: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Another dispatcher holds the void; it is left alone
+	app.DB.MustExec(`UPDATE payment_outbox SET status = 'IN_PROGRESS', claimed_until = NOW() + INTERVAL '1 minute'`)
+	if n, err := app.Payments.ProcessBatch(ctx); err != nil || n != 0 {
+		t.Fatalf("expected a held claim to be skipped, got %d, %v", n, err)
+	}
+
+	// That dispatcher died mid-call; once the claim lapses it is sent again
+	app.DB.MustExec(`UPDATE payment_outbox SET claimed_until = NOW() - INTERVAL '1 second'`)
+	if n, err := app.Payments.ProcessBatch(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the lapsed claim to be retried, got %d, %v", n, err)
+	}
+
+	var entry struct {
+		Status       string  `db:"status"`
+		Attempts     int     `db:"attempts"`
+		ClaimedUntil *string `db:"claimed_until"`
+	}
+	if err := app.DB.Get(&entry, `SELECT status, attempts, claimed_until FROM payment_outbox`); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != "DONE" || entry.Attempts != 1 || entry.ClaimedUntil != nil {
+		t.Fatalf("expected DONE after one attempt with the claim cleared, got %+v", entry)
+	}
+}
+
+func TestPayment_LateOutcomeDoesNotOverwriteNewClaim(t *testing.T) {
+	app := setupTestApp(t)
+	token := enduserToken(t, app, "user-1")
+	ctx := context.Background()
+	repo := payment.NewRepository()
+
+	orderID, _ := placeTestOrder(t, app, token)
+	if w := doRequest(app, http.MethodDelete, fmt.Sprintf("/orders/%s", orderID), nil, token); w.Code != http.StatusOK {
+		t.Fatalf("withdraw: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	entries, err := repo.ClaimPending(ctx, app.DB, 10, time.Minute)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("claim: expected 1 entry, got %d (%v)", len(entries), err)
+	}
+	e := entries[0]
+	claim := *e.ClaimedUntil
+
+	// The claim lapsed and another dispatcher took the entry over
+	app.DB.MustExec(`UPDATE payment_outbox SET claimed_until = NOW() + INTERVAL '5 minutes'`)
+
+	e.Status = payment.OutboxPending
+	e.Attempts++
+	e.ClaimedUntil = nil
+	recorded, err := repo.RecordOutcome(ctx, app.DB, e, claim)
+	if err != nil || recorded {
+		t.Fatalf("expected the late outcome to be dropped, got %v (%v)", recorded, err)
+	}
+
+	var status string
+	if err := app.DB.Get(&status, `SELECT status FROM payment_outbox`); err != nil {
+		t.Fatal(err)
+	}
+	if status != "IN_PROGRESS" {
+		t.Fatalf("expected the new claim to stand, got %s", status)
+	}
+}
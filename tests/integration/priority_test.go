@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// openJobOrder lists open jobs as the drone sees them and returns their
+// order IDs in queue order.
+func openJobOrder(t *testing.T, app *testApp, token string) []string {
+	t.Helper()
+	w := doRequest(app, http.MethodGet, "/drone/jobs", nil, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list jobs: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var ids []string
+	for _, j := range parseJSON(t, w)["jobs"].([]any) {
+		ids = append(ids, j.(map[string]any)["order_id"].(string))
+	}
+	return ids
+}
+
+func TestPriority_ExpressJumpsTheQueue(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	standardID, standardJobID := placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodPost, "/orders", map[string]any{
+		"origin":       validOrigin(),
+		"destination":  validDestination(),
+		"service_tier": "EXPRESS",
+	}, userToken)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("place express order: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	express := parseJSON(t, w)["order"].(map[string]any)
+	if express["service_tier"] != "EXPRESS" || express["priority"] == 0.0 {
+		t.Fatalf("expected an EXPRESS order with raised priority, got %v at %v", express["service_tier"], express["priority"])
+	}
+	expressID := express["id"].(string)
+
+	if ids := openJobOrder(t, app, drToken); len(ids) != 2 || ids[0] != expressID || ids[1] != standardID {
+		t.Fatalf("expected express then standard, got %v", ids)
+	}
+
+	// An admin bumps the standard job above it
+	aToken := adminToken(t, app)
+	w = doRequest(app, http.MethodPatch, fmt.Sprintf("/admin/jobs/%s/priority", standardJobID), map[string]int{"priority": 50}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bump: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if p := parseJSON(t, w)["job"].(map[string]any)["priority"]; p != 50.0 {
+		t.Fatalf("expected priority 50, got %v", p)
+	}
+	if ids := openJobOrder(t, app, drToken); ids[0] != standardID {
+		t.Fatalf("expected the bumped job first, got %v", ids)
+	}
+
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/orders/%s", standardID), nil, userToken)
+	if p := parseJSON(t, w)["order"].(map[string]any)["priority"]; p != 50.0 {
+		t.Fatalf("expected the order to record priority 50, got %v", p)
+	}
+}
+
+func TestPriority_Validation(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	w := doRequest(app, http.MethodPost, "/orders", map[string]any{
+		"origin":       validOrigin(),
+		"destination":  validDestination(),
+		"service_tier": "OVERNIGHT",
+	}, userToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unknown tier: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	_, jobID := placeTestOrder(t, app, userToken)
+	w = doRequest(app, http.MethodPatch, fmt.Sprintf("/admin/jobs/%s/priority", jobID), map[string]int{"priority": 101}, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("out of range: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPatch, fmt.Sprintf("/admin/jobs/%s/priority", jobID), map[string]any{}, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("missing priority: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPatch, "/admin/jobs/no-such-job/priority", map[string]int{"priority": 5}, aToken)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown job: expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
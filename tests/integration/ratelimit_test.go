@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"drone-delivery/internal/pkg/ratelimit"
+	"drone-delivery/internal/redis"
+)
+
+func TestRateLimit_RedisTokenBucket(t *testing.T) {
+	app := setupTestApp(t)
+	limiter := redis.NewRateLimiter(app.Redis)
+	l := ratelimit.Limit{Requests: 60, Window: time.Minute, Burst: 3}
+	ctx := context.Background()
+
+	for i := range 3 {
+		d, err := limiter.Take(ctx, "test:sub:drone-1", l)
+		if err != nil {
+			t.Fatalf("take %d: %v", i, err)
+		}
+		if !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("take %d: expected allowed with %d left, got %+v", i, 2-i, d)
+		}
+	}
+	d, err := limiter.Take(ctx, "test:sub:drone-1", l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Allowed || d.RetryAfter <= 0 || d.RetryAfter > time.Second {
+		t.Fatalf("expected refusal with up to 1s to wait, got %+v", d)
+	}
+
+	// Another key has its own bucket
+	if d, _ := limiter.Take(ctx, "test:sub:drone-2", l); !d.Allowed {
+		t.Fatalf("expected drone-2 to be allowed")
+	}
+
+	// The bucket is one hash that expires once it would be full again
+	if n, _ := app.Redis.HLen(ctx, "ratelimit:test:sub:drone-1").Result(); n != 2 {
+		t.Fatalf("expected two fields in the bucket, got %d", n)
+	}
+	if ttl, _ := app.Redis.PTTL(ctx, "ratelimit:test:sub:drone-1").Result(); ttl <= 0 || ttl > 5*time.Second {
+		t.Fatalf("expected the bucket to expire within the refill time, got %s", ttl)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	if d, _ := limiter.Take(ctx, "test:sub:drone-1", l); !d.Allowed {
+		t.Fatalf("expected a token after a second, got %+v", d)
+	}
+}
+
+func TestRateLimit_HeadersOnResponses(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	w := doRequest(app, http.MethodGet, "/orders", nil, userToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("RateLimit-Limit") != "1000" || w.Header().Get("RateLimit-Remaining") != "999" {
+		t.Fatalf("unexpected rate limit headers %v", w.Header())
+	}
+	if w.Header().Get("RateLimit-Policy") != "1000;w=60" {
+		t.Fatalf("unexpected policy %q", w.Header().Get("RateLimit-Policy"))
+	}
+}
@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReports_SummarizeOrderOutcomes(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	deliveredID, deliveredJob := placeTestOrder(t, app, userToken)
+	deliverOrder(t, app, drToken, deliveredID, deliveredJob)
+	withdrawnID, _ := placeTestOrder(t, app, userToken)
+	doRequest(app, http.MethodDelete, fmt.Sprintf("/orders/%s", withdrawnID), nil, userToken)
+	placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodGet, "/admin/reports/summary?zone=delivery", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("summary: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	summary := parseJSON(t, w)["summary"].(map[string]any)
+	counts := summary["counts"].(map[string]any)
+	if counts["placed"] != 3.0 || counts["delivered"] != 1.0 || counts["withdrawn"] != 1.0 || counts["open"] != 1.0 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+	if summary["success_rate"] != 100.0 {
+		t.Fatalf("expected 100%% success, got %v", summary["success_rate"])
+	}
+	if n := summary["time_to_assign"].(map[string]any)["count"]; n != 1.0 {
+		t.Fatalf("expected one assignment timed, got %v", n)
+	}
+	if n := summary["time_to_deliver"].(map[string]any)["count"]; n != 1.0 {
+		t.Fatalf("expected one delivery timed, got %v", n)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/reports/drones", nil, aToken)
+	drones := parseJSON(t, w)["drones"].([]any)
+	if len(drones) != 1 || drones[0].(map[string]any)["drone_id"] != "drone-1" || drones[0].(map[string]any)["delivered"] != 1.0 {
+		t.Fatalf("expected drone-1 with one delivery, got %v", drones)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/reports/demand", nil, aToken)
+	cells := parseJSON(t, w)["cells"].([]any)
+	if len(cells) != 1 || cells[0].(map[string]any)["orders"] != 3.0 {
+		t.Fatalf("expected all 3 orders in one pickup cell, got %v", cells)
+	}
+}
+
+func TestReports_DailyAsCSV(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	placeTestOrder(t, app, userToken)
+	placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodGet, "/admin/reports/daily?format=csv", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("daily: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("expected CSV, got %s", ct)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "day" || records[1][1] != "2" {
+		t.Fatalf("expected a header and one day with 2 orders, got %v", records)
+	}
+}
+
+func TestReports_Validation(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	for _, path := range []string{
+		"/admin/reports/summary?zone=atlantis",
+		"/admin/reports/daily?from=2025-03-01&to=2025-02-01",
+		"/admin/reports/demand?cell=5",
+	} {
+		w := doRequest(app, http.MethodGet, path, nil, aToken)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"drone-delivery/internal/order"
+	"drone-delivery/internal/sla"
+)
+
+// slaState returns the milestone → state findings recorded for an order.
+func slaState(t *testing.T, app *testApp, orderID string) map[string][]string {
+	t.Helper()
+	var rows []struct {
+		Milestone string `db:"milestone"`
+		State     string `db:"state"`
+	}
+	if err := app.DB.Select(&rows, `SELECT milestone, state FROM order_sla_events WHERE order_id = $1 ORDER BY detected_at`, orderID); err != nil {
+		t.Fatalf("get sla events: %v", err)
+	}
+	out := map[string][]string{}
+	for _, r := range rows {
+		out[r.Milestone] = append(out[r.Milestone], r.State)
+	}
+	return out
+}
+
+// flakySLA finds one thing on every order but fails to check one of them.
+type flakySLA struct {
+	sla.Service
+	fail string
+}
+
+func (f flakySLA) Check(_ context.Context, _ sqlx.ExtContext, o *order.Order, _ time.Time) (int, error) {
+	if o.ID.String() == f.fail {
+		return 0, errors.New("connection reset")
+	}
+	return 1, nil
+}
+
+func TestSLA_EvaluatorSkipsAnOrderThatFails(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+
+	var orderIDs []string
+	for range 3 {
+		id, _ := placeTestOrder(t, app, userToken)
+		orderIDs = append(orderIDs, id)
+	}
+
+	// The oldest order fails; the two behind it, one in the next batch, are still checked
+	e := sla.NewEvaluator(app.DB, order.NewRepository(), flakySLA{fail: orderIDs[0]}, sla.EvaluatorConfig{Interval: time.Minute, BatchSize: 2})
+	n, err := e.Evaluate(context.Background(), time.Now())
+	if err != nil || n != 2 {
+		t.Fatalf("evaluate: expected 2 findings past the failure, got %d (%v)", n, err)
+	}
+}
+
+func TestSLA_EvaluatorFlagsStuckOrders(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	// Three orders, more than one evaluator batch
+	var orderIDs []string
+	for i := 0; i < 3; i++ {
+		id, _ := placeTestOrder(t, app, userToken)
+		orderIDs = append(orderIDs, id)
+	}
+
+	// 25 minutes on, a standard order is at risk of missing assignment
+	n, err := app.SLA.Evaluate(context.Background(), time.Now().Add(25*time.Minute))
+	if err != nil || n != 3 {
+		t.Fatalf("evaluate: expected 3 findings, got %d (%v)", n, err)
+	}
+	// 35 minutes on, assignment is breached and nothing new is at risk
+	n, err = app.SLA.Evaluate(context.Background(), time.Now().Add(35*time.Minute))
+	if err != nil || n != 3 {
+		t.Fatalf("evaluate: expected 3 breaches, got %d (%v)", n, err)
+	}
+	if n, _ := app.SLA.Evaluate(context.Background(), time.Now().Add(35*time.Minute)); n != 0 {
+		t.Fatalf("re-evaluate: expected nothing new, got %d", n)
+	}
+	if got := slaState(t, app, orderIDs[0])["ASSIGN"]; len(got) != 2 || got[0] != "AT_RISK" || got[1] != "BREACHED" {
+		t.Fatalf("expected assign at risk then breached, got %v", got)
+	}
+
+	w := doRequest(app, http.MethodGet, "/admin/alerts?kind=SLA_BREACHED", nil, aToken)
+	if alerts := parseJSON(t, w)["alerts"].([]any); len(alerts) != 3 {
+		t.Fatalf("expected 3 breach alerts, got %d", len(alerts))
+	}
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+	w = doRequest(app, http.MethodGet, "/admin/sla?to="+tomorrow, nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("report: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	report := parseJSON(t, w)
+	days := report["days"].([]any)
+	if len(days) != 1 {
+		t.Fatalf("expected one day of orders, got %d", len(days))
+	}
+	day := days[0].(map[string]any)
+	if day["orders"] != 3.0 || day["assign_breached"] != 3.0 || day["breached_percent"] != 100.0 {
+		t.Fatalf("expected 3 of 3 orders breached on assignment, got %v", day)
+	}
+	if n := len(report["breaches"].([]any)); n != 3 {
+		t.Fatalf("expected 3 breaches listed, got %d", n)
+	}
+	if n := len(report["at_risk"].([]any)); n != 0 {
+		t.Fatalf("expected breached milestones not to be listed at risk, got %d", n)
+	}
+}
+
+func TestSLA_LateDeliveryIsRecordedOnCompletion(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	// Placed three hours ago
+	app.DB.MustExec(`UPDATE orders SET created_at = NOW() - INTERVAL '3 hours' WHERE id = $1`, orderID)
+
+	deliverOrder(t, app, drToken, orderID, jobID)
+
+	got := slaState(t, app, orderID)
+	for _, m := range []string{"ASSIGN", "PICKUP", "DELIVERY"} {
+		if len(got[m]) != 1 || got[m][0] != "BREACHED" {
+			t.Fatalf("expected %s breached, got %v", m, got)
+		}
+	}
+}
+
+func TestSLA_ReportValidation(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	for _, q := range []string{"?from=yesterday", "?from=2025-02-01&to=2025-01-01", "?from=2024-01-01&to=2025-01-01"} {
+		w := doRequest(app, http.MethodGet, "/admin/sla"+q, nil, aToken)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", q, w.Code, w.Body.String())
+		}
+	}
+}
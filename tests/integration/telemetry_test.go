@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestTelemetry_DroneAndOrderTrack(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.71, "longitude": 46.67}, drToken)
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, lat := range []float64{24.72, 24.73, 24.74} {
+		doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": lat, "longitude": 46.68}, drToken)
+	}
+
+	if err := app.Telemetry.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/drones/drone-1/track", nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("drone track: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	features := parseJSON(t, w)["features"].([]any)
+	if len(features) != 1 {
+		t.Fatalf("expected 1 feature, got %d", len(features))
+	}
+	coords := features[0].(map[string]any)["geometry"].(map[string]any)["coordinates"].([]any)
+	if len(coords) != 4 {
+		t.Fatalf("expected 4 points on drone track, got %d", len(coords))
+	}
+
+	// Only the heartbeats sent while carrying the order belong to its track
+	w = doRequest(app, http.MethodGet, fmt.Sprintf("/admin/orders/%s/track?max_points=2", orderID), nil, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("order track: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	feature := parseJSON(t, w)["features"].([]any)[0].(map[string]any)
+	props := feature["properties"].(map[string]any)
+	if props["total_points"].(float64) != 3 {
+		t.Fatalf("expected 3 points on order track, got %v", props["total_points"])
+	}
+	if n := len(feature["geometry"].(map[string]any)["coordinates"].([]any)); n != 2 {
+		t.Fatalf("expected track downsampled to 2 points, got %d", n)
+	}
+}
+
+func TestTelemetry_DroneTrack_InvalidWindow(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	w := doRequest(app, http.MethodGet, "/admin/drones/drone-1/track?from=2026-01-10T00:00:00Z&to=2026-01-01T00:00:00Z", nil, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -13,15 +13,29 @@ import (
 	"time"
 
 	"drone-delivery/internal/admin"
+	"drone-delivery/internal/admission"
+	"drone-delivery/internal/airspace"
+	"drone-delivery/internal/alert"
 	"drone-delivery/internal/auth"
+	"drone-delivery/internal/bulk"
+	"drone-delivery/internal/command"
 	"drone-delivery/internal/common"
 	"drone-delivery/internal/delivery"
 	"drone-delivery/internal/drone"
+	"drone-delivery/internal/grounding"
 	"drone-delivery/internal/job"
 	jwtpkg "drone-delivery/internal/jwt"
+	"drone-delivery/internal/maintenance"
 	"drone-delivery/internal/middleware"
 	"drone-delivery/internal/order"
+	"drone-delivery/internal/payment"
+	"drone-delivery/internal/pkg/ratelimit"
+	"drone-delivery/internal/pricing"
 	"drone-delivery/internal/redis"
+	"drone-delivery/internal/report"
+	"drone-delivery/internal/sla"
+	"drone-delivery/internal/telemetry"
+	"drone-delivery/internal/weather"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -35,6 +49,62 @@ type testApp struct {
 	Redis  *goredis.Client
 	Router *gin.Engine
 	JWT    *jwtpkg.Service
+
+	Telemetry  *telemetry.Recorder
+	Heartbeats *drone.HeartbeatWriter
+	// Weather is the fixture behind the weather cache; set it before the
+	// first lookup in a test, since conditions are then cached per cell.
+	Weather *common.FixtureWeatherProvider
+	// Leases is swept by hand; tests pass a future time to expire leases.
+	Leases *delivery.LeaseSweeper
+	// Waitlist is promoted by hand.
+	Waitlist *delivery.WaitlistPromoter
+	// SLA is evaluated by hand; tests pass a future time to age orders.
+	SLA *sla.Evaluator
+	// Payments drains the payment outbox by hand.
+	Payments *payment.Dispatcher
+	// Index is pruned by hand; tests pass a future time to age heartbeats.
+	Index *drone.IndexPruner
+}
+
+// testOption adjusts the wiring of a test app.
+type testOption func(*testConfig)
+
+type testConfig struct {
+	admission     admission.Config
+	executor      func(command.Executor) command.Executor
+	airspaceDelay time.Duration
+	notifier      delivery.Notifier
+}
+
+// withAdmission turns on admission control with the given SLA and policy.
+func withAdmission(sla time.Duration, policy admission.Policy) testOption {
+	return func(c *testConfig) {
+		c.admission.SLA = sla
+		c.admission.Policy = policy
+	}
+}
+
+// withExecutor wraps the delivery service that applies executed commands.
+func withExecutor(wrap func(command.Executor) command.Executor) testOption {
+	return func(c *testConfig) {
+		c.executor = wrap
+	}
+}
+
+// withAirspaceDelay lets a departure be pushed back up to d instead of a
+// full corridor being refused.
+func withAirspaceDelay(d time.Duration) testOption {
+	return func(c *testConfig) {
+		c.airspaceDelay = d
+	}
+}
+
+// withNotifier hands dispatch events to n, as the MQTT gateway would.
+func withNotifier(n delivery.Notifier) testOption {
+	return func(c *testConfig) {
+		c.notifier = n
+	}
 }
 
 // orderQueryAdapter bridges order.Service to drone.OrderQuerier.
@@ -46,6 +116,15 @@ func (a *orderQueryAdapter) GetByDroneID(ctx context.Context, droneID string) (a
 	return a.svc.GetByDroneID(ctx, droneID)
 }
 
+// commandInboxAdapter bridges command.Service to drone.CommandInbox.
+type commandInboxAdapter struct {
+	svc command.Service
+}
+
+func (a *commandInboxAdapter) Deliver(ctx context.Context, droneID string) (any, error) {
+	return a.svc.Deliver(ctx, droneID)
+}
+
 const (
 	zoneCenter  = 24.7136
 	zoneCenterL = 46.6753
@@ -59,10 +138,27 @@ func skipIfNoInfra(t *testing.T) {
 	}
 }
 
-func setupTestApp(t *testing.T) *testApp {
+func setupTestApp(t *testing.T, opts ...testOption) *testApp {
 	t.Helper()
 	skipIfNoInfra(t)
 
+	// Admission control is off unless a test asks for it: most tests place
+	// orders without any drone in service.
+	tc := testConfig{admission: admission.Config{
+		Policy:      admission.PolicyWaitlist,
+		ServiceTime: 30 * time.Minute,
+		MinSamples:  5,
+		SampleSize:  50,
+		PickupLead:  10 * time.Minute,
+		SpeedKMH:    30,
+	}}
+	for _, opt := range opts {
+		opt(&tc)
+	}
+	if tc.executor == nil {
+		tc.executor = func(e command.Executor) command.Executor { return e }
+	}
+
 	gin.SetMode(gin.TestMode)
 
 	// Postgres
@@ -92,15 +188,48 @@ func setupTestApp(t *testing.T) *testApp {
 	// Infrastructure
 	jwtService := jwtpkg.NewService("test-secret", 24*time.Hour)
 	droneCache := redis.NewDroneLocationCache(rdb, 60)
-	idempotencyStore := redis.NewIdempotencyStore(rdb, 300)
-	rateLimiter := redis.NewRateLimiter(rdb, 1000, 60) // generous for tests
+	idempotencyStore := redis.NewIdempotencyStore(rdb, 300, 30)
+	rateLimiter := ratelimit.NewFallback(redis.NewRateLimiter(rdb), ratelimit.NewMemory())
+	// Generous for tests
+	ratePolicy := func(name string, key ratelimit.KeyBy) ratelimit.Policy {
+		return ratelimit.Policy{Name: name, Key: key, Limit: ratelimit.Limit{Requests: 1000, Window: time.Minute}}
+	}
 	mapboxClient := common.NewMapboxClient("https://api.mapbox.com", "")
+	weatherFixture := &common.FixtureWeatherProvider{Default: common.Weather{WindSpeedKMH: 5, VisibilityKM: 10}}
+	weatherProvider := redis.NewWeatherCache(rdb, weatherFixture, 60, 0.1)
+	weatherLimits := weather.Limits{MaxWindKMH: 35, MinVisibilityKM: 1}
 
 	// Repositories
 	orderRepo := order.NewRepository()
 	droneRepo := drone.NewRepository()
 	jobRepo := job.NewRepository()
-	deliveryRepo := delivery.NewRepository(orderRepo, jobRepo, droneRepo)
+	paymentRepo := payment.NewRepository()
+	maintenanceRepo := maintenance.NewRepository(maintenance.Schedule{MaxFlightHours: 50, MaxFlightKM: 1500, MaxCycles: 200})
+	groundingRepo := grounding.NewRepository()
+	groundingGuard := grounding.NewGuard(db, groundingRepo)
+	// Four lanes and, by default, no re-timing: a fifth crossing flight is
+	// refused
+	airspaceService := airspace.NewService(db, airspace.NewRepository(), airspace.Config{
+		Lanes:        4,
+		LaneBaseM:    60,
+		LaneHeightM:  20,
+		SeparationKM: 0.2,
+		SpeedKMH:     30,
+		Buffer:       5 * time.Minute,
+		MaxDelay:     tc.airspaceDelay,
+	})
+	alertService := alert.NewService(db, alert.NewRepository())
+	slaService := sla.NewService(db, sla.NewRepository(), alertService, sla.Config{
+		Tiers: map[order.ServiceTier]sla.Targets{
+			order.TierStandard: {Assign: 30 * time.Minute, Pickup: time.Hour, Delivery: 2 * time.Hour},
+			order.TierExpress:  {Assign: 10 * time.Minute, Pickup: 30 * time.Minute, Delivery: time.Hour},
+		},
+		AtRiskRatio: 0.8,
+	})
+	heartbeatWriter := drone.NewHeartbeatWriter(db, droneRepo, drone.WriterConfig{FlushInterval: time.Hour})
+	droneIndex := heartbeatWriter.BufferedIndex(droneCache)
+	deliveryRepo := delivery.NewRepository(orderRepo, jobRepo, droneRepo, paymentRepo, maintenanceRepo, droneIndex, payment.RefundPolicy{FailedRefundPercent: 100}, delivery.FlightGuards{groundingGuard, weather.NewGate(weatherProvider, weatherLimits)}, airspaceService, job.LeasePolicy{SpeedKMH: 30, Grace: 10 * time.Minute}, slaService)
+	slaEvaluator := sla.NewEvaluator(db, orderRepo, slaService, sla.EvaluatorConfig{Interval: time.Minute, BatchSize: 2})
 
 	// Services
 	orderService := order.NewOrderService(orderRepo, db, order.ZoneConfig{
@@ -110,66 +239,163 @@ func setupTestApp(t *testing.T) *testApp {
 	}, mapboxClient)
 
 	center := common.NewLocation(zoneCenter, zoneCenterL)
-	droneService := drone.NewDroneService(droneRepo, db, droneCache, center, zoneRadius)
-	jobService := job.NewService(jobRepo, db)
-	deliveryService := delivery.NewService(db, deliveryRepo)
-	adminService := admin.NewService(orderService, droneService, deliveryService)
+	telemetryRepo := telemetry.NewRepository()
+	telemetryRecorder := telemetry.NewRecorder(db, telemetryRepo, telemetry.RecorderConfig{
+		BufferSize:    1000,
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	})
+	paymentProvider := payment.NewFakeProvider()
+	paymentDispatcher := payment.NewDispatcher(db, paymentRepo, paymentProvider, payment.DispatcherConfig{
+		PollInterval: time.Hour,
+		BatchSize:    50,
+		MaxAttempts:  3,
+		ClaimTimeout: time.Minute,
+	})
+	deliveryService := delivery.NewService(db, deliveryRepo, paymentProvider, tc.notifier)
+	droneService := drone.NewDroneService(droneRepo, db, droneCache, telemetryRecorder, alertService, deliveryService, deliveryService, heartbeatWriter, center, zoneRadius, drone.HeartbeatPolicy{
+		MaxSpeedKMH:        120,
+		MaxClockSkew:       30 * time.Second,
+		AutoQuarantine:     false,
+		CriticalFaultCodes: []string{"MOTOR_FAILURE"},
+		MaxBatchSamples:    50,
+	})
+	pricingService := pricing.NewService(pricing.DefaultRules(), droneService, pricing.Config{
+		Secret:   "test-secret",
+		QuoteTTL: 10 * time.Minute,
+	})
+	jobService := job.NewService(jobRepo, db, job.QueuePolicy{AgingStep: 2 * time.Minute}, 2*zoneRadius)
+	admissionService := admission.NewService(droneService, jobService, orderService, tc.admission)
+	waitlistPromoter := delivery.NewWaitlistPromoter(db, deliveryRepo, orderRepo, admissionService, delivery.WaitlistPromoterConfig{Interval: time.Minute, BatchSize: 50})
+	commandService := command.NewService(db, command.NewRepository(), droneService, tc.executor(deliveryService), alertService, nil, command.Config{TTL: 5 * time.Minute})
+	leaseSweeper := delivery.NewLeaseSweeper(db, deliveryRepo, jobRepo, commandService, delivery.LeaseSweeperConfig{Interval: time.Minute, BatchSize: 100})
+	groundingService := grounding.NewService(db, groundingRepo, droneService, commandService, grounding.Config{
+		OrderPolicy: grounding.OrderPolicyQueue,
+		DroneAction: command.TypeReturnToBase,
+	})
+	maintenanceService := maintenance.NewService(db, maintenanceRepo, droneRepo, droneIndex)
+	indexPruner := drone.NewIndexPruner(droneCache, drone.IndexPrunerConfig{Interval: time.Minute, MaxAge: time.Minute})
+	adminService := admin.NewService(orderService, droneService, deliveryService, maintenanceService)
 	authService := auth.NewAuthService(jwtService)
 
 	// Handlers
 	authHandler := auth.NewHandler(authService)
-	orderHandler := order.NewHandler(orderService, deliveryService, droneService)
-	droneHandler := drone.NewHandler(droneService, &orderQueryAdapter{svc: orderService}, deliveryService)
-	jobHandler := job.NewHandler(jobService, deliveryService)
+	orderHandler := order.NewHandler(orderService, deliveryService, droneService, pricingService, groundingGuard, weatherProvider, admissionService)
+	droneHandler := drone.NewHandler(droneService, &orderQueryAdapter{svc: orderService}, deliveryService, &commandInboxAdapter{svc: commandService}, 64*1024)
+	jobHandler := job.NewHandler(jobService, deliveryService, droneService, groundingGuard, airspaceService)
 	adminHandler := admin.NewHandler(adminService, orderService, droneService)
+	maintenanceHandler := maintenance.NewHandler(maintenanceService)
+	alertHandler := alert.NewHandler(alertService)
+	telemetryHandler := telemetry.NewHandler(telemetry.NewService(db, telemetryRepo))
+	commandHandler := command.NewHandler(commandService, 2*time.Second)
+	groundingHandler := grounding.NewHandler(groundingService)
+	airspaceHandler := airspace.NewHandler(airspaceService)
+	zones := []weather.Zone{{Name: "delivery", Center: center, RadiusKM: zoneRadius}}
+	weatherHandler := weather.NewHandler(weatherProvider, weatherLimits, zones)
+	reportHandler := report.NewHandler(report.NewService(db, report.NewRepository()), zones)
+	bulkHandler := bulk.NewHandler(
+		bulk.NewExporter(db, orderRepo, droneRepo),
+		bulk.NewImporter(orderService, deliveryService, pricingService, groundingGuard, admissionService, bulk.ImportConfig{MaxRows: 5, BatchSize: 2}),
+		64*1024,
+	)
 
 	// Router
 	r := gin.New()
 	r.Use(middleware.Recovery())
-	r.Use(middleware.RateLimit(rateLimiter))
+	r.Use(middleware.RateLimit(rateLimiter, ratePolicy("global", ratelimit.KeyIP)))
 	r.Use(middleware.Auth(jwtService))
 
 	// Auth
 	authGroup := r.Group("/auth")
+	authGroup.Use(middleware.RateLimit(rateLimiter, ratePolicy("auth", ratelimit.KeyIP)))
 	authGroup.POST("/token", authHandler.GenerateToken)
 
 	// Enduser
 	enduserGroup := r.Group("")
 	enduserGroup.Use(middleware.RoleGuard("enduser"))
+	enduserGroup.Use(middleware.RateLimit(rateLimiter, ratePolicy("enduser", ratelimit.KeySub)))
 	enduserGroup.GET("/orders", orderHandler.ListMyOrders)
 	enduserGroup.GET("/orders/:id", orderHandler.GetOrderDetails)
+	enduserGroup.POST("/orders/quote", orderHandler.QuoteOrder)
 	enduserMutations := enduserGroup.Group("")
 	enduserMutations.Use(middleware.Bulkhead(50))
-	enduserMutations.Use(middleware.Idempotency(idempotencyStore))
+	enduserMutations.Use(middleware.Idempotency(idempotencyStore, 4<<20, 30*time.Second))
 	enduserMutations.POST("/orders", orderHandler.PlaceOrder)
+	enduserMutations.POST("/orders/import", bulkHandler.ImportOrders)
 	enduserMutations.DELETE("/orders/:id", orderHandler.WithdrawOrder)
 
 	// Drone
 	droneGroup := r.Group("/drone")
 	droneGroup.Use(middleware.RoleGuard("drone"))
 	heartbeat := droneGroup.Group("")
+	heartbeat.Use(middleware.RateLimit(rateLimiter, ratePolicy("heartbeat", ratelimit.KeySub)))
 	heartbeat.Use(middleware.Bulkhead(100))
 	heartbeat.POST("/me/heartbeat", droneHandler.Heartbeat)
-	droneGroup.GET("/jobs", jobHandler.ListOpenJobs)
-	droneGroup.GET("/me/order", droneHandler.GetCurrentOrder)
-	mutations := droneGroup.Group("")
+	heartbeat.POST("/me/telemetry", droneHandler.Telemetry)
+	droneAPI := droneGroup.Group("")
+	droneAPI.Use(middleware.RateLimit(rateLimiter, ratePolicy("drone", ratelimit.KeySub)))
+	droneAPI.GET("/jobs", jobHandler.ListOpenJobs)
+	droneAPI.GET("/me/order", droneHandler.GetCurrentOrder)
+	droneAPI.GET("/me/commands", commandHandler.Poll)
+	droneAPI.GET("/me/airspace", airspaceHandler.Mine)
+	mutations := droneAPI.Group("")
 	mutations.Use(middleware.Bulkhead(50))
-	mutations.Use(middleware.Idempotency(idempotencyStore))
+	mutations.Use(middleware.Idempotency(idempotencyStore, 4<<20, 30*time.Second))
 	mutations.POST("/jobs/reserve", jobHandler.ReserveJob)
 	mutations.POST("/orders/:id/grab", jobHandler.GrabOrder)
 	mutations.PATCH("/orders/:id/complete", jobHandler.CompleteDelivery)
 	mutations.POST("/me/broken", droneHandler.ReportBroken)
+	mutations.POST("/me/commands/:id/ack", commandHandler.Acknowledge)
 
 	// Admin
 	adminGroup := r.Group("/admin")
 	adminGroup.Use(middleware.RoleGuard("admin"))
+	adminGroup.Use(middleware.RateLimit(rateLimiter, ratePolicy("admin", ratelimit.KeySub)))
 	adminGroup.Use(middleware.Bulkhead(20))
 	adminGroup.GET("/orders", adminHandler.ListOrders)
+	adminGroup.GET("/orders/:id", adminHandler.GetOrder)
 	adminGroup.PATCH("/orders/:id", adminHandler.UpdateOrder)
 	adminGroup.GET("/drones", adminHandler.ListDrones)
+	adminGroup.GET("/drones/nearby", adminHandler.NearbyDrones)
+	adminGroup.GET("/drones/:id", adminHandler.GetDrone)
 	adminGroup.PATCH("/drones/:id/status", adminHandler.UpdateDroneStatus)
-
-	app := &testApp{DB: db, Redis: rdb, Router: r, JWT: jwtService}
+	adminGroup.POST("/drones", adminHandler.RegisterDrone)
+	adminGroup.PATCH("/drones/:id", adminHandler.UpdateDrone)
+	adminGroup.DELETE("/drones/:id", adminHandler.RetireDrone)
+	adminGroup.GET("/jobs", jobHandler.ListJobs)
+	adminGroup.GET("/jobs/:id", jobHandler.GetJob)
+	adminGroup.POST("/jobs/:id/assign", adminHandler.AssignJob)
+	adminGroup.PATCH("/jobs/:id/priority", adminHandler.SetJobPriority)
+	adminGroup.POST("/orders/:id/unassign", adminHandler.UnassignOrder)
+	adminGroup.POST("/orders/:id/reassign", adminHandler.ReassignOrder)
+	adminGroup.GET("/maintenance/schedules", maintenanceHandler.ListSchedules)
+	adminGroup.PUT("/maintenance/schedules/:model", maintenanceHandler.SetSchedule)
+	adminGroup.GET("/work-orders", maintenanceHandler.ListWorkOrders)
+	adminGroup.POST("/drones/:id/work-orders", maintenanceHandler.OpenWorkOrder)
+	adminGroup.POST("/work-orders/:id/complete", maintenanceHandler.CompleteWorkOrder)
+	adminGroup.GET("/drones/:id/track", telemetryHandler.DroneTrack)
+	adminGroup.GET("/orders/:id/track", telemetryHandler.OrderTrack)
+	adminGroup.GET("/drones/:id/anomalies", adminHandler.ListDroneAnomalies)
+	adminGroup.POST("/drones/:id/quarantine", adminHandler.QuarantineDrone)
+	adminGroup.DELETE("/drones/:id/quarantine", adminHandler.LiftQuarantine)
+	adminGroup.GET("/alerts", alertHandler.List)
+	adminGroup.POST("/alerts/:id/acknowledge", alertHandler.Acknowledge)
+	adminGroup.POST("/drones/:id/commands", commandHandler.Issue)
+	adminGroup.GET("/drones/:id/commands", commandHandler.List)
+	adminGroup.POST("/groundings", groundingHandler.Ground)
+	adminGroup.GET("/groundings", groundingHandler.List)
+	adminGroup.DELETE("/groundings/:id", groundingHandler.Lift)
+	adminGroup.GET("/zones", weatherHandler.Zones)
+	adminGroup.GET("/airspace/reservations", airspaceHandler.ListActive)
+	adminGroup.GET("/sla", sla.NewHandler(slaService).Report)
+	adminGroup.GET("/reports/summary", reportHandler.Summary)
+	adminGroup.GET("/reports/daily", reportHandler.Daily)
+	adminGroup.GET("/reports/drones", reportHandler.Drones)
+	adminGroup.GET("/reports/demand", reportHandler.Demand)
+	adminGroup.GET("/orders/export", bulkHandler.ExportOrders)
+	adminGroup.GET("/drones/export", bulkHandler.ExportDrones)
+
+	app := &testApp{DB: db, Redis: rdb, Router: r, JWT: jwtService, Telemetry: telemetryRecorder, Heartbeats: heartbeatWriter, Weather: weatherFixture, Leases: leaseSweeper, Waitlist: waitlistPromoter, SLA: slaEvaluator, Payments: paymentDispatcher, Index: indexPruner}
 
 	t.Cleanup(func() {
 		cleanTestData(t, db)
@@ -185,6 +411,17 @@ func createTestSchema(t *testing.T, db *sqlx.DB) {
 	t.Helper()
 
 	// Drop existing tables (in dependency order)
+	db.MustExec(`DROP TABLE IF EXISTS order_sla_events CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS airspace_reservations CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS groundings CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS drone_commands CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS alerts CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS drone_anomalies CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS drone_telemetry CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS maintenance_work_orders CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS maintenance_schedules CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS payment_outbox CASCADE`)
+	db.MustExec(`DROP TABLE IF EXISTS payments CASCADE`)
 	db.MustExec(`DROP TABLE IF EXISTS jobs CASCADE`)
 	db.MustExec(`DROP TABLE IF EXISTS drones CASCADE`)
 	db.MustExec(`DROP TABLE IF EXISTS orders CASCADE`)
@@ -199,6 +436,21 @@ func createTestSchema(t *testing.T, db *sqlx.DB) {
 		dest_lng DOUBLE PRECISION NOT NULL,
 		status VARCHAR(50) NOT NULL DEFAULT 'PENDING',
 		assigned_drone_id VARCHAR(255),
+		payload_kg DOUBLE PRECISION NOT NULL DEFAULT 0,
+		price_amount BIGINT NOT NULL DEFAULT 0,
+		price_currency VARCHAR(3) NOT NULL DEFAULT 'SAR',
+		quote_id VARCHAR(64) UNIQUE,
+		service_tier VARCHAR(20) NOT NULL DEFAULT 'STANDARD',
+		priority INT NOT NULL DEFAULT 0,
+		handed_off BOOLEAN NOT NULL DEFAULT false,
+		promised_pickup_at TIMESTAMPTZ,
+		promised_delivery_at TIMESTAMPTZ,
+		repromised_pickup_at TIMESTAMPTZ,
+		repromised_delivery_at TIMESTAMPTZ,
+		assigned_at TIMESTAMPTZ,
+		picked_up_at TIMESTAMPTZ,
+		delivered_at TIMESTAMPTZ,
+		version BIGINT NOT NULL DEFAULT 1,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	)`)
@@ -210,6 +462,30 @@ func createTestSchema(t *testing.T, db *sqlx.DB) {
 		longitude DOUBLE PRECISION DEFAULT 0,
 		current_order_id UUID REFERENCES orders(id),
 		last_heartbeat TIMESTAMPTZ,
+		model VARCHAR(100) NOT NULL DEFAULT '',
+		capabilities TEXT[] NOT NULL DEFAULT '{}',
+		home_lat DOUBLE PRECISION,
+		home_lng DOUBLE PRECISION,
+		maintenance_notes TEXT NOT NULL DEFAULT '',
+		service_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+		retired_at TIMESTAMPTZ,
+		flight_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+		flight_cycles INT NOT NULL DEFAULT 0,
+		hours_since_service DOUBLE PRECISION NOT NULL DEFAULT 0,
+		km_since_service DOUBLE PRECISION NOT NULL DEFAULT 0,
+		cycles_since_service INT NOT NULL DEFAULT 0,
+		maintenance_due BOOLEAN NOT NULL DEFAULT FALSE,
+		last_heartbeat_seq BIGINT,
+		last_device_time TIMESTAMPTZ,
+		last_boot_id VARCHAR(64),
+		quarantined BOOLEAN NOT NULL DEFAULT FALSE,
+		quarantine_reason TEXT NOT NULL DEFAULT '',
+		altitude_m DOUBLE PRECISION,
+		heading_deg DOUBLE PRECISION,
+		ground_speed_kmh DOUBLE PRECISION,
+		battery_pct SMALLINT,
+		fault_codes TEXT[] NOT NULL DEFAULT '{}',
+		version BIGINT NOT NULL DEFAULT 1,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	)`)
@@ -219,13 +495,175 @@ func createTestSchema(t *testing.T, db *sqlx.DB) {
 		order_id UUID NOT NULL REFERENCES orders(id),
 		status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
 		reserved_by_drone_id VARCHAR(255) REFERENCES drones(id),
+		reserved_at TIMESTAMPTZ,
+		priority INT NOT NULL DEFAULT 0,
+		lease_expires_at TIMESTAMPTZ,
+		lease_distance_km DOUBLE PRECISION,
+		release_reason TEXT NOT NULL DEFAULT '',
+		version BIGINT NOT NULL DEFAULT 1,
 		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
 		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 	)`)
+
+	db.MustExec(`CREATE TABLE payments (
+		id UUID PRIMARY KEY,
+		order_id UUID NOT NULL UNIQUE REFERENCES orders(id),
+		provider VARCHAR(50) NOT NULL,
+		provider_ref VARCHAR(255) NOT NULL,
+		status VARCHAR(30) NOT NULL,
+		amount BIGINT NOT NULL,
+		captured_amount BIGINT NOT NULL DEFAULT 0,
+		refunded_amount BIGINT NOT NULL DEFAULT 0,
+		currency VARCHAR(3) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+
+	db.MustExec(`CREATE TABLE payment_outbox (
+		id BIGSERIAL PRIMARY KEY,
+		payment_id UUID NOT NULL REFERENCES payments(id),
+		provider_ref VARCHAR(255) NOT NULL,
+		operation VARCHAR(20) NOT NULL,
+		amount BIGINT NOT NULL DEFAULT 0,
+		status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT,
+		claimed_until TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		processed_at TIMESTAMPTZ
+	)`)
+
+	db.MustExec(`CREATE TABLE maintenance_schedules (
+		model VARCHAR(100) PRIMARY KEY,
+		max_flight_hours DOUBLE PRECISION NOT NULL DEFAULT 0,
+		max_flight_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+		max_cycles INT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+
+	db.MustExec(`CREATE TABLE maintenance_work_orders (
+		id UUID PRIMARY KEY,
+		drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+		kind VARCHAR(20) NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+		description TEXT NOT NULL DEFAULT '',
+		resolution TEXT NOT NULL DEFAULT '',
+		opened_by VARCHAR(255) NOT NULL,
+		completed_by VARCHAR(255),
+		opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		completed_at TIMESTAMPTZ
+	)`)
+
+	db.MustExec(`CREATE TABLE drone_telemetry (
+		drone_id VARCHAR(255) NOT NULL,
+		order_id UUID,
+		latitude DOUBLE PRECISION NOT NULL,
+		longitude DOUBLE PRECISION NOT NULL,
+		recorded_at TIMESTAMPTZ NOT NULL
+	) PARTITION BY RANGE (recorded_at)`)
+	db.MustExec(`CREATE TABLE drone_telemetry_default PARTITION OF drone_telemetry DEFAULT`)
+
+	db.MustExec(`CREATE TABLE drone_anomalies (
+		id UUID PRIMARY KEY,
+		drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+		kind VARCHAR(30) NOT NULL,
+		rejected BOOLEAN NOT NULL,
+		sequence BIGINT,
+		device_time TIMESTAMPTZ,
+		prev_latitude DOUBLE PRECISION,
+		prev_longitude DOUBLE PRECISION,
+		latitude DOUBLE PRECISION NOT NULL,
+		longitude DOUBLE PRECISION NOT NULL,
+		speed_kmh DOUBLE PRECISION,
+		detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+
+	db.MustExec(`CREATE TABLE alerts (
+		id UUID PRIMARY KEY,
+		kind VARCHAR(50) NOT NULL,
+		severity VARCHAR(20) NOT NULL,
+		drone_id VARCHAR(255),
+		message TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		acknowledged_at TIMESTAMPTZ,
+		acknowledged_by VARCHAR(255)
+	)`)
+
+	db.MustExec(`CREATE TABLE drone_commands (
+		id UUID PRIMARY KEY,
+		drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+		type VARCHAR(30) NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+		order_id UUID REFERENCES orders(id),
+		reason TEXT NOT NULL DEFAULT '',
+		issued_by VARCHAR(255) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		expires_at TIMESTAMPTZ NOT NULL,
+		delivered_at TIMESTAMPTZ,
+		acknowledged_at TIMESTAMPTZ,
+		result_message TEXT NOT NULL DEFAULT ''
+	)`)
+
+	db.MustExec(`CREATE TABLE groundings (
+		id UUID PRIMARY KEY,
+		scope VARCHAR(10) NOT NULL,
+		name VARCHAR(100) NOT NULL DEFAULT '',
+		center_lat DOUBLE PRECISION,
+		center_lng DOUBLE PRECISION,
+		radius_km DOUBLE PRECISION,
+		reason TEXT NOT NULL,
+		order_policy VARCHAR(10) NOT NULL,
+		drone_action VARCHAR(30) NOT NULL,
+		created_by VARCHAR(255) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		lifted_at TIMESTAMPTZ,
+		lifted_by VARCHAR(255)
+	)`)
+
+	db.MustExec(`CREATE TABLE airspace_reservations (
+		id UUID PRIMARY KEY,
+		order_id UUID NOT NULL REFERENCES orders(id),
+		drone_id VARCHAR(255) NOT NULL REFERENCES drones(id),
+		start_lat DOUBLE PRECISION NOT NULL,
+		start_lng DOUBLE PRECISION NOT NULL,
+		pickup_lat DOUBLE PRECISION NOT NULL,
+		pickup_lng DOUBLE PRECISION NOT NULL,
+		dropoff_lat DOUBLE PRECISION NOT NULL,
+		dropoff_lng DOUBLE PRECISION NOT NULL,
+		band INT NOT NULL,
+		min_altitude_m DOUBLE PRECISION NOT NULL,
+		max_altitude_m DOUBLE PRECISION NOT NULL,
+		starts_at TIMESTAMPTZ NOT NULL,
+		ends_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		released_at TIMESTAMPTZ
+	)`)
+
+	db.MustExec(`CREATE TABLE order_sla_events (
+		id UUID PRIMARY KEY,
+		order_id UUID NOT NULL REFERENCES orders(id),
+		service_tier VARCHAR(20) NOT NULL,
+		milestone VARCHAR(20) NOT NULL,
+		state VARCHAR(20) NOT NULL,
+		due_at TIMESTAMPTZ NOT NULL,
+		detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE (order_id, milestone, state)
+	)`)
 }
 
 func cleanTestData(t *testing.T, db *sqlx.DB) {
 	t.Helper()
+	db.Exec(`DELETE FROM order_sla_events`)
+	db.Exec(`DELETE FROM airspace_reservations`)
+	db.Exec(`DELETE FROM groundings`)
+	db.Exec(`DELETE FROM drone_commands`)
+	db.Exec(`DELETE FROM alerts`)
+	db.Exec(`DELETE FROM drone_anomalies`)
+	db.Exec(`DELETE FROM drone_telemetry`)
+	db.Exec(`DELETE FROM maintenance_work_orders`)
+	db.Exec(`DELETE FROM maintenance_schedules`)
+	db.Exec(`DELETE FROM payment_outbox`)
+	db.Exec(`DELETE FROM payments`)
 	db.Exec(`DELETE FROM jobs`)
 	db.Exec(`DELETE FROM drones`)
 	db.Exec(`DELETE FROM orders`)
@@ -355,3 +793,12 @@ func placeTestOrder(t *testing.T, app *testApp, token string) (orderID string, j
 
 	return orderID, jobID
 }
+
+func paymentStatus(t *testing.T, app *testApp, orderID string) string {
+	t.Helper()
+	var status string
+	if err := app.DB.Get(&status, `SELECT status FROM payments WHERE order_id = $1`, orderID); err != nil {
+		t.Fatalf("load payment for order %s: %v", orderID, err)
+	}
+	return status
+}
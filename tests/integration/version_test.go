@@ -0,0 +1,227 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// doIfMatch sends a JSON PATCH carrying an If-Match header.
+func doIfMatch(app *testApp, path, ifMatch string, body any, token string) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPatch, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("If-Match", ifMatch)
+	w := httptest.NewRecorder()
+	app.Router.ServeHTTP(w, req)
+	return w
+}
+
+// etagOf fetches path and returns its ETag.
+func etagOf(t *testing.T, app *testApp, path, token string) string {
+	t.Helper()
+	w := doRequest(app, http.MethodGet, path, nil, token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get %s: expected 200, got %d: %s", path, w.Code, w.Body.String())
+	}
+	tag := w.Header().Get("ETag")
+	if tag == "" {
+		t.Fatalf("get %s: no ETag", path)
+	}
+	return tag
+}
+
+func TestVersion_OrderETagOnGet(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	orderID, _ := placeTestOrder(t, app, userToken)
+
+	if tag := etagOf(t, app, "/orders/"+orderID, userToken); tag != `"1"` {
+		t.Fatalf("expected ETag \"1\" for a new order, got %s", tag)
+	}
+	if tag := etagOf(t, app, "/admin/orders/"+orderID, aToken); tag != `"1"` {
+		t.Fatalf("expected admin ETag \"1\", got %s", tag)
+	}
+}
+
+func TestVersion_AdminUpdateOrderHonoursIfMatch(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	orderID, _ := placeTestOrder(t, app, userToken)
+	path := "/admin/orders/" + orderID
+	tag := etagOf(t, app, path, aToken)
+
+	move := map[string]any{"origin": map[string]float64{"lat": 24.74, "lng": 46.70}}
+	w := doIfMatch(app, path, tag, move, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("matching If-Match: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if next := w.Header().Get("ETag"); next != `"2"` {
+		t.Fatalf("expected ETag \"2\" after the edit, got %s", next)
+	}
+
+	// The same tag is now stale
+	back := map[string]any{"origin": validOrigin()}
+	w = doIfMatch(app, path, tag, back, aToken)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d: %s", w.Code, w.Body.String())
+	}
+	if code := parseJSON(t, w)["error"].(map[string]any)["code"]; code != "PRECONDITION_FAILED" {
+		t.Fatalf("expected PRECONDITION_FAILED, got %v", code)
+	}
+
+	w = doRequest(app, http.MethodGet, path, nil, aToken)
+	if lat := parseJSON(t, w)["order"].(map[string]any)["origin_lat"]; lat != 24.74 {
+		t.Fatalf("stale edit must not be applied, origin_lat is %v", lat)
+	}
+}
+
+func TestVersion_StaleEditCannotUndoGrab(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+
+	orderID, jobID := placeTestOrder(t, app, userToken)
+	path := "/admin/orders/" + orderID
+	tag := etagOf(t, app, path, aToken)
+
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reserve: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doRequest(app, http.MethodPost, fmt.Sprintf("/drone/orders/%s/grab", orderID), nil, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("grab: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	move := map[string]any{"destination": map[string]float64{"lat": 24.74, "lng": 46.70}}
+	w = doIfMatch(app, path, tag, move, aToken)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("edit based on the pre-grab order: expected 412, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(app, http.MethodGet, path, nil, aToken)
+	o := parseJSON(t, w)["order"].(map[string]any)
+	if o["status"] != "PICKED_UP" {
+		t.Fatalf("expected PICKED_UP to survive, got %v", o["status"])
+	}
+	if o["version"].(float64) < 3 {
+		t.Fatalf("expected reserve and grab to advance the version, got %v", o["version"])
+	}
+}
+
+func TestVersion_DroneProfileIfMatch(t *testing.T) {
+	app := setupTestApp(t)
+	aToken := adminToken(t, app)
+
+	doRequest(app, http.MethodPost, "/admin/drones", map[string]any{"id": "drone-7", "model": "DX-4"}, aToken)
+	path := "/admin/drones/drone-7"
+	tag := etagOf(t, app, path, aToken)
+
+	w := doIfMatch(app, path, tag, map[string]any{"maintenance_notes": "new props"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("matching If-Match: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") == tag {
+		t.Fatalf("expected a new ETag after the edit")
+	}
+
+	w = doIfMatch(app, path, tag, map[string]any{"maintenance_notes": "old props"}, aToken)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVersion_HeartbeatsKeepDroneETag(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	path := "/admin/drones/drone-1"
+	tag := etagOf(t, app, path, aToken)
+
+	// The drone keeps flying while the admin edits
+	w := doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.7201, "longitude": 46.6801}, drToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if next := etagOf(t, app, path, aToken); next != tag {
+		t.Fatalf("expected a heartbeat to keep ETag %s, got %s", tag, next)
+	}
+
+	w = doIfMatch(app, path, tag, map[string]any{"maintenance_notes": "new props"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("If-Match read before the heartbeat: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVersion_DroneStatusIfMatch(t *testing.T) {
+	app := setupTestApp(t)
+	drToken := droneToken(t, app, "drone-1")
+	aToken := adminToken(t, app)
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	path := "/admin/drones/drone-1/status"
+	tag := etagOf(t, app, "/admin/drones/drone-1", aToken)
+
+	w := doIfMatch(app, path, tag, map[string]string{"status": "broken"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("matching If-Match: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	broken := w.Header().Get("ETag")
+	if broken == "" || broken == tag {
+		t.Fatalf("expected a new ETag after marking broken, got %q", broken)
+	}
+
+	// A fix decided on the pre-break copy is refused
+	w = doIfMatch(app, path, tag, map[string]string{"status": "fixed"}, aToken)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doIfMatch(app, path, broken, map[string]string{"status": "fixed"}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("current If-Match: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVersion_JobPriorityIfMatch(t *testing.T) {
+	app := setupTestApp(t)
+	userToken := enduserToken(t, app, "user-1")
+	aToken := adminToken(t, app)
+
+	_, jobID := placeTestOrder(t, app, userToken)
+	tag := etagOf(t, app, "/admin/jobs/"+jobID, aToken)
+	path := fmt.Sprintf("/admin/jobs/%s/priority", jobID)
+
+	w := doIfMatch(app, path, `W/"1"`, map[string]int{"priority": 5}, aToken)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("weak If-Match: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doIfMatch(app, path, tag, map[string]int{"priority": 5}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("matching If-Match: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doIfMatch(app, path, tag, map[string]int{"priority": 1}, aToken)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Without If-Match the edit is unconditional
+	w = doRequest(app, http.MethodPatch, path, map[string]int{"priority": 1}, aToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("no If-Match: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"drone-delivery/internal/common"
+)
+
+func TestWeather_UnsafeConditionsBlockReservation(t *testing.T) {
+	app := setupTestApp(t)
+	// Conditions are looked up per grid cell, so the storm spans the zone
+	app.Weather.Areas = []common.WeatherArea{{
+		Name:     "haboob",
+		Center:   common.NewLocation(zoneCenter, zoneCenterL),
+		RadiusKM: 20,
+		Weather:  common.Weather{WindSpeedKMH: 55, WindFromDeg: 270, VisibilityKM: 0.4},
+	}}
+	userToken := enduserToken(t, app, "user-1")
+	drToken := droneToken(t, app, "drone-1")
+
+	doRequest(app, http.MethodPost, "/drone/me/heartbeat", map[string]float64{"latitude": 24.72, "longitude": 46.68}, drToken)
+	_, jobID := placeTestOrder(t, app, userToken)
+
+	w := doRequest(app, http.MethodPost, "/drone/jobs/reserve", map[string]string{"job_id": jobID}, drToken)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("reserve in a storm: expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if code := errorCode(t, parseJSON(t, w)); code != "UNSAFE_WEATHER" {
+		t.Fatalf("expected UNSAFE_WEATHER, got %v", code)
+	}
+
+	w = doRequest(app, http.MethodGet, "/admin/zones", nil, adminToken(t, app))
+	if w.Code != http.StatusOK {
+		t.Fatalf("zones: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	zones := parseJSON(t, w)["zones"].([]any)
+	zone := zones[0].(map[string]any)
+	if zone["flyable"] != false || zone["unsafe_reason"] == "" {
+		t.Fatalf("expected the zone to be unflyable, got %v", zone)
+	}
+	if wind := zone["weather"].(map[string]any)["wind_speed_kmh"]; wind != 55.0 {
+		t.Fatalf("expected 55 km/h wind, got %v", wind)
+	}
+}
+
+func TestWeather_CalmZoneIsFlyable(t *testing.T) {
+	app := setupTestApp(t)
+
+	w := doRequest(app, http.MethodGet, "/admin/zones", nil, adminToken(t, app))
+	zones := parseJSON(t, w)["zones"].([]any)
+	if len(zones) != 1 {
+		t.Fatalf("expected 1 zone, got %d", len(zones))
+	}
+	zone := zones[0].(map[string]any)
+	if zone["name"] != "delivery" || zone["flyable"] != true {
+		t.Fatalf("expected a flyable delivery zone, got %v", zone)
+	}
+}
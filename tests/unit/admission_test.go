@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"drone-delivery/internal/admission"
+	"drone-delivery/internal/common"
+)
+
+var (
+	admissionOrigin = common.NewLocation(24.7136, 46.6753)
+	admissionDest   = common.NewLocation(24.7500, 46.6753) // about 4 km north
+)
+
+func admissionConfig() admission.Config {
+	return admission.Config{
+		SLA:         2 * time.Hour,
+		Policy:      admission.PolicyWaitlist,
+		ServiceTime: 30 * time.Minute,
+		PickupLead:  10 * time.Minute,
+		SpeedKMH:    30,
+	}
+}
+
+func TestEstimate_IdleDroneServesImmediately(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := admissionConfig()
+
+	p := admission.Estimate(admission.Capacity{QueueAhead: 1, IdleDrones: 2, Fleet: 3, ServiceTime: cfg.ServiceTime}, admissionOrigin, admissionDest, now, cfg)
+
+	if p.PickupBy == nil || !p.PickupBy.Equal(now.Add(cfg.PickupLead)) {
+		t.Fatalf("expected pickup after the lead time only, got %v", p.PickupBy)
+	}
+	flight := p.DeliveryBy.Sub(*p.PickupBy)
+	if flight < 7*time.Minute || flight > 9*time.Minute {
+		t.Fatalf("expected about 8 minutes for 4 km at 30 km/h, got %s", flight)
+	}
+}
+
+func TestEstimate_QueueWaitsInRounds(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := admissionConfig()
+
+	tests := []struct {
+		name  string
+		c     admission.Capacity
+		wantW time.Duration
+	}{
+		{"behind every idle drone", admission.Capacity{QueueAhead: 1, IdleDrones: 1, Fleet: 2}, 30 * time.Minute},
+		{"still the first round", admission.Capacity{QueueAhead: 2, IdleDrones: 0, Fleet: 3}, 30 * time.Minute},
+		{"second round", admission.Capacity{QueueAhead: 3, IdleDrones: 0, Fleet: 3}, time.Hour},
+		{"all busy, one drone", admission.Capacity{QueueAhead: 4, IdleDrones: 0, Fleet: 1}, 150 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.c.ServiceTime = cfg.ServiceTime
+			p := admission.Estimate(tt.c, admissionOrigin, admissionDest, now, cfg)
+			if got := p.PickupBy.Sub(now) - cfg.PickupLead; got != tt.wantW {
+				t.Fatalf("expected a %s wait, got %s", tt.wantW, got)
+			}
+		})
+	}
+}
+
+func TestEstimate_NoFleetHasNoTimes(t *testing.T) {
+	p := admission.Estimate(admission.Capacity{QueueAhead: 3}, admissionOrigin, admissionDest, time.Now(), admissionConfig())
+	if p.PickupBy != nil || p.DeliveryBy != nil {
+		t.Fatalf("expected no promised times without a fleet, got %v / %v", p.PickupBy, p.DeliveryBy)
+	}
+	if p.QueueAhead != 3 {
+		t.Fatalf("expected the queue to be reported, got %d", p.QueueAhead)
+	}
+}
+
+func TestConfig_Exceeds(t *testing.T) {
+	now := time.Now()
+	cfg := admissionConfig()
+	soon, late := now.Add(time.Hour), now.Add(3*time.Hour)
+
+	if cfg.Exceeds(&admission.Promise{DeliveryBy: &soon}, now) {
+		t.Fatal("expected a promise within the SLA to pass")
+	}
+	if !cfg.Exceeds(&admission.Promise{DeliveryBy: &late}, now) {
+		t.Fatal("expected a promise beyond the SLA to exceed it")
+	}
+	if !cfg.Exceeds(&admission.Promise{}, now) {
+		t.Fatal("expected a promise without times to exceed the SLA")
+	}
+
+	cfg.SLA = 0
+	if cfg.Exceeds(&admission.Promise{}, now) {
+		t.Fatal("expected no SLA to admit everything")
+	}
+}
+
+func TestPolicy_Valid(t *testing.T) {
+	if !admission.PolicyWaitlist.Valid() || !admission.PolicyReject.Valid() {
+		t.Fatal("expected WAITLIST and REJECT to be valid")
+	}
+	if admission.Policy("QUEUE").Valid() {
+		t.Fatal("expected an unknown policy to be invalid")
+	}
+}
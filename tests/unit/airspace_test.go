@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"drone-delivery/internal/airspace"
+	"drone-delivery/internal/common"
+)
+
+var airspaceCfg = airspace.Config{
+	Lanes:        2,
+	LaneBaseM:    60,
+	LaneHeightM:  20,
+	SeparationKM: 0.2,
+	SpeedKMH:     30,
+	Buffer:       5 * time.Minute,
+	MaxDelay:     time.Hour,
+}
+
+// planEastWest books a flight along latitude 24.72 from 46.66 to 46.70.
+func planEastWest(t *testing.T, active []*airspace.Reservation, droneID string, now time.Time, cfg airspace.Config) *airspace.Reservation {
+	t.Helper()
+	r, err := airspace.Plan(active, uuid.New(), droneID,
+		common.NewLocation(24.72, 46.66), common.NewLocation(24.72, 46.67), common.NewLocation(24.72, 46.70), now, cfg)
+	if err != nil {
+		t.Fatalf("plan %s: %v", droneID, err)
+	}
+	return r
+}
+
+func TestAirspacePlan_WindowFromDistance(t *testing.T) {
+	now := time.Now()
+	r := planEastWest(t, nil, "drone-1", now, airspaceCfg)
+
+	// ~4 km at 30 km/h is ~8 minutes, plus the 5 minute buffer
+	window := r.EndsAt.Sub(r.StartsAt)
+	if window < 12*time.Minute || window > 14*time.Minute {
+		t.Fatalf("expected a ~13 minute window, got %s", window)
+	}
+	if r.Band != 0 || r.MinAltitudeM != 60 || r.MaxAltitudeM != 80 || !r.StartsAt.Equal(now) {
+		t.Fatalf("expected band 0 at 60-80m departing now, got %+v", r)
+	}
+}
+
+func TestAirspacePlan_CrossingFlightTakesNextBand(t *testing.T) {
+	now := time.Now()
+	first := planEastWest(t, nil, "drone-1", now, airspaceCfg)
+
+	// North-south across the first corridor
+	second, err := airspace.Plan([]*airspace.Reservation{first}, uuid.New(), "drone-2",
+		common.NewLocation(24.70, 46.68), common.NewLocation(24.71, 46.68), common.NewLocation(24.74, 46.68), now, airspaceCfg)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if second.Band != 1 || !second.StartsAt.Equal(now) {
+		t.Fatalf("expected band 1 departing now, got band %d at %s", second.Band, second.StartsAt)
+	}
+}
+
+func TestAirspacePlan_DistantFlightSharesBand(t *testing.T) {
+	now := time.Now()
+	first := planEastWest(t, nil, "drone-1", now, airspaceCfg)
+
+	far, err := airspace.Plan([]*airspace.Reservation{first}, uuid.New(), "drone-2",
+		common.NewLocation(24.80, 46.75), common.NewLocation(24.80, 46.76), common.NewLocation(24.81, 46.77), now, airspaceCfg)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if far.Band != 0 {
+		t.Fatalf("a corridor ~10 km away should share band 0, got %d", far.Band)
+	}
+}
+
+func TestAirspacePlan_RetimesWhenBandsAreFull(t *testing.T) {
+	now := time.Now()
+	a := planEastWest(t, nil, "drone-1", now, airspaceCfg)
+	b := planEastWest(t, []*airspace.Reservation{a}, "drone-2", now, airspaceCfg)
+
+	c := planEastWest(t, []*airspace.Reservation{a, b}, "drone-3", now, airspaceCfg)
+	if !c.StartsAt.Equal(a.EndsAt) {
+		t.Fatalf("expected departure pushed back to %s, got %s", a.EndsAt, c.StartsAt)
+	}
+}
+
+func TestAirspacePlan_RejectsBeyondMaxDelay(t *testing.T) {
+	now := time.Now()
+	cfg := airspaceCfg
+	cfg.MaxDelay = time.Minute
+	a := planEastWest(t, nil, "drone-1", now, cfg)
+	b := planEastWest(t, []*airspace.Reservation{a}, "drone-2", now, cfg)
+
+	_, err := airspace.Plan([]*airspace.Reservation{a, b}, uuid.New(), "drone-3",
+		common.NewLocation(24.72, 46.66), common.NewLocation(24.72, 46.67), common.NewLocation(24.72, 46.70), now, cfg)
+	if err == nil {
+		t.Fatal("expected a conflict when no band frees up within the max delay")
+	}
+}
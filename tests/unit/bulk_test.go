@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"drone-delivery/internal/bulk"
+	domainerrors "drone-delivery/internal/errors"
+)
+
+func TestFormat_Valid(t *testing.T) {
+	for _, f := range []bulk.Format{bulk.FormatCSV, bulk.FormatNDJSON} {
+		if !f.Valid() {
+			t.Fatalf("expected %s to be valid", f)
+		}
+	}
+	if bulk.Format("xml").Valid() {
+		t.Fatal("expected xml to be invalid")
+	}
+	if ct := bulk.FormatNDJSON.ContentType(); ct != "application/x-ndjson" {
+		t.Fatalf("unexpected NDJSON content type %q", ct)
+	}
+}
+
+// Uploads refused as a whole fail before any order is attempted, so the
+// importer needs no dependencies.
+func TestImporter_RejectsWholeUpload(t *testing.T) {
+	im := bulk.NewImporter(nil, nil, nil, nil, nil, bulk.ImportConfig{MaxRows: 2, BatchSize: 1})
+
+	tests := []struct {
+		name   string
+		format bulk.Format
+		body   string
+	}{
+		{"empty csv", bulk.FormatCSV, ""},
+		{"missing column", bulk.FormatCSV, "origin_lat,origin_lng,dest_lat\n24.72,46.68,24.73\n"},
+		{"too many csv rows", bulk.FormatCSV, "origin_lat,origin_lng,dest_lat,dest_lng\n1,1,1,1\n1,1,1,1\n1,1,1,1\n"},
+		{"empty ndjson", bulk.FormatNDJSON, "\n\n"},
+		{"too many ndjson rows", bulk.FormatNDJSON, "{}\n{}\n{}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := im.ImportOrders(context.Background(), "user-1", "card", strings.NewReader(tt.body), tt.format)
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Code != domainerrors.ErrValidation {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"drone-delivery/internal/command"
+)
+
+func TestCommand_TypeValid(t *testing.T) {
+	for _, typ := range []command.Type{command.TypeAbortMission, command.TypeReturnToBase, command.TypeHoldPosition, command.TypeLandImmediately} {
+		if !typ.Valid() {
+			t.Errorf("%s should be valid", typ)
+		}
+	}
+	if command.Type("DANCE").Valid() {
+		t.Error("DANCE should not be valid")
+	}
+}
+
+func TestCommand_DeliverThenAcknowledge(t *testing.T) {
+	c := command.New("drone-1", command.TypeHoldPosition, nil, "", "admin", time.Minute)
+	now := time.Now()
+
+	c.MarkDelivered(now)
+	if c.Status != command.StatusDelivered || c.DeliveredAt == nil {
+		t.Fatalf("expected DELIVERED, got %s", c.Status)
+	}
+	// Redelivery keeps the first delivery time
+	c.MarkDelivered(now.Add(time.Second))
+	if !c.DeliveredAt.Equal(now) {
+		t.Fatalf("delivered_at changed on redelivery")
+	}
+
+	if err := c.Acknowledge(command.StatusExecuted, "holding", now); err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if c.Status != command.StatusExecuted || c.ResultMessage != "holding" {
+		t.Fatalf("unexpected command after ack: %+v", c)
+	}
+	if err := c.Acknowledge(command.StatusRejected, "", now); err == nil {
+		t.Fatal("second acknowledge should fail")
+	}
+}
+
+func TestCommand_AcknowledgeRequiresResult(t *testing.T) {
+	c := command.New("drone-1", command.TypeReturnToBase, nil, "", "admin", time.Minute)
+	if err := c.Acknowledge(command.StatusDelivered, "", time.Now()); err == nil {
+		t.Fatal("DELIVERED is not an acknowledgement result")
+	}
+	if c.Status != command.StatusPending {
+		t.Fatalf("status should be unchanged, got %s", c.Status)
+	}
+}
+
+func TestCommand_ExpiredCannotBeAcknowledged(t *testing.T) {
+	c := command.New("drone-1", command.TypeLandImmediately, nil, "", "admin", time.Minute)
+	later := c.CreatedAt.Add(2 * time.Minute)
+
+	if !c.Expired(later) {
+		t.Fatal("command should be expired")
+	}
+	if err := c.Acknowledge(command.StatusExecuted, "", later); err == nil {
+		t.Fatal("expired command should not be acknowledged")
+	}
+	c.Expire()
+	if c.Status != command.StatusExpired {
+		t.Fatalf("expected EXPIRED, got %s", c.Status)
+	}
+}
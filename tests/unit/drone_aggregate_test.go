@@ -2,7 +2,9 @@ package unit
 
 import (
 	"testing"
+	"time"
 
+	"drone-delivery/internal/common"
 	"drone-delivery/internal/drone"
 	domainerrors "drone-delivery/internal/errors"
 
@@ -84,6 +86,42 @@ func TestDrone_StartDelivery_FromIdle_Fails(t *testing.T) {
 
 // --- GoIdle ---
 
+func TestDrone_Unassign_FromEnRoutePickup(t *testing.T) {
+	d := newIdleDrone()
+	orderID := uuid.New()
+	_ = d.Reserve(orderID)
+
+	if err := d.Unassign(orderID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Status != drone.StatusIdle {
+		t.Fatalf("expected IDLE, got %s", d.Status)
+	}
+	if d.CurrentOrderID != nil {
+		t.Fatal("expected current order to be cleared")
+	}
+}
+
+func TestDrone_Unassign_OtherOrder_Fails(t *testing.T) {
+	d := newIdleDrone()
+	_ = d.Reserve(uuid.New())
+
+	if err := d.Unassign(uuid.New()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDrone_Unassign_WhileDelivering_Fails(t *testing.T) {
+	d := newIdleDrone()
+	orderID := uuid.New()
+	_ = d.Reserve(orderID)
+	_ = d.StartDelivery()
+
+	if err := d.Unassign(orderID); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestDrone_GoIdle(t *testing.T) {
 	d := newIdleDrone()
 	_ = d.Reserve(uuid.New())
@@ -172,6 +210,74 @@ func TestDrone_MarkFixed_FromIdle_Fails(t *testing.T) {
 	}
 }
 
+// --- Retire ---
+
+func TestDrone_Retire_FromIdle(t *testing.T) {
+	d := newIdleDrone()
+	if err := d.Retire(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Status != drone.StatusRetired {
+		t.Fatalf("expected RETIRED, got %s", d.Status)
+	}
+	if d.RetiredAt == nil {
+		t.Fatal("expected RetiredAt to be set")
+	}
+}
+
+func TestDrone_Retire_WhileOnJob_Fails(t *testing.T) {
+	d := newIdleDrone()
+	_ = d.Reserve(uuid.New())
+	if err := d.Retire(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDrone_Reserve_Retired_Fails(t *testing.T) {
+	d := newIdleDrone()
+	_ = d.Retire()
+
+	err := d.Reserve(uuid.New())
+	de, ok := err.(*domainerrors.DomainError)
+	if !ok || de.Code != domainerrors.ErrForbidden {
+		t.Fatalf("expected FORBIDDEN, got %v", err)
+	}
+}
+
+// --- Profile ---
+
+func TestDrone_Register_AppliesProfile(t *testing.T) {
+	model := "DX-4"
+	d := drone.Register("drone-9", drone.Profile{
+		Model:        &model,
+		Capabilities: []string{"cold-chain"},
+		HomeBase:     &common.Location{Lat: 24.71, Lng: 46.67},
+	})
+
+	if d.Model != "DX-4" || len(d.Capabilities) != 1 {
+		t.Fatalf("profile not applied: %+v", d)
+	}
+	home := d.HomeBase()
+	if home == nil || home.Lat != 24.71 {
+		t.Fatalf("expected home base, got %v", home)
+	}
+}
+
+func TestDrone_ApplyProfile_KeepsUnsetFields(t *testing.T) {
+	model := "DX-4"
+	d := drone.Register("drone-9", drone.Profile{Model: &model})
+
+	notes := "replaced rotor 2"
+	d.ApplyProfile(drone.Profile{MaintenanceNotes: &notes})
+
+	if d.Model != "DX-4" {
+		t.Fatalf("expected model to be kept, got %q", d.Model)
+	}
+	if d.MaintenanceNotes != notes {
+		t.Fatalf("expected notes %q, got %q", notes, d.MaintenanceNotes)
+	}
+}
+
 // --- UpdateLocation ---
 
 func TestDrone_UpdateLocation(t *testing.T) {
@@ -239,3 +345,170 @@ func TestDrone_BrokenAndFixedCycle(t *testing.T) {
 		t.Fatalf("Reserve after fix: %v", err)
 	}
 }
+
+// --- Heartbeat integrity ---
+
+func heartbeatAt(lat, lng float64, seq int64, ts time.Time) drone.HeartbeatRequest {
+	return drone.HeartbeatRequest{Latitude: lat, Longitude: lng, Sequence: &seq, Timestamp: &ts}
+}
+
+func TestDrone_InspectHeartbeat_ReplayRejected(t *testing.T) {
+	d := newIdleDrone()
+	now := time.Now()
+	d.AcceptHeartbeat(heartbeatAt(24.7, 46.6, 5, now))
+
+	a := d.InspectHeartbeat(heartbeatAt(24.7, 46.6, 5, now.Add(time.Second)), now, 120)
+	if a == nil || a.Kind != drone.AnomalyReplay || !a.Rejected {
+		t.Fatalf("expected rejected REPLAY, got %+v", a)
+	}
+}
+
+func TestDrone_InspectHeartbeat_OutOfOrderRejected(t *testing.T) {
+	d := newIdleDrone()
+	now := time.Now()
+	d.AcceptHeartbeat(heartbeatAt(24.7, 46.6, 5, now))
+
+	a := d.InspectHeartbeat(heartbeatAt(24.7, 46.6, 6, now.Add(-time.Minute)), now, 120)
+	if a == nil || a.Kind != drone.AnomalyOutOfOrder || !a.Rejected {
+		t.Fatalf("expected rejected OUT_OF_ORDER, got %+v", a)
+	}
+}
+
+func TestDrone_InspectHeartbeat_RebootStartsAfresh(t *testing.T) {
+	d := newIdleDrone()
+	now := time.Now()
+	first, second := "boot-1", "boot-2"
+	hb := heartbeatAt(24.7, 46.6, 500, now)
+	hb.BootID = &first
+	d.AcceptHeartbeat(hb)
+
+	// After a reboot the sequence restarts at 0 and the clock is behind
+	reboot := heartbeatAt(24.7, 46.6, 0, now.Add(-time.Hour))
+	reboot.BootID = &second
+	if a := d.InspectHeartbeat(reboot, now, 120); a != nil {
+		t.Fatalf("expected a new boot to be accepted, got %+v", a)
+	}
+	d.AcceptHeartbeat(reboot)
+
+	// The new boot is checked from its own baseline
+	next := heartbeatAt(24.7, 46.6, 1, now.Add(-time.Hour+time.Second))
+	if a := d.InspectHeartbeat(next, now, 120); a != nil {
+		t.Fatalf("expected the next heartbeat of the boot to be accepted, got %+v", a)
+	}
+	d.AcceptHeartbeat(next)
+	if a := d.InspectHeartbeat(reboot, now, 120); a == nil || a.Kind != drone.AnomalyReplay {
+		t.Fatalf("expected a replay within the boot to be rejected, got %+v", a)
+	}
+}
+
+func TestDrone_InspectHeartbeat_ImpossibleSpeedFlagged(t *testing.T) {
+	d := newIdleDrone()
+	now := time.Now()
+	d.AcceptHeartbeat(heartbeatAt(24.70, 46.60, 1, now))
+
+	// ~11 km in 10 seconds
+	a := d.InspectHeartbeat(heartbeatAt(24.80, 46.60, 2, now.Add(10*time.Second)), now, 120)
+	if a == nil || a.Kind != drone.AnomalyImpossibleSpeed {
+		t.Fatalf("expected IMPOSSIBLE_SPEED, got %+v", a)
+	}
+	if a.Rejected {
+		t.Fatal("speed anomalies should not reject the heartbeat")
+	}
+	if a.SpeedKMH == nil || *a.SpeedKMH <= 120 {
+		t.Fatalf("expected speed above 120, got %v", a.SpeedKMH)
+	}
+}
+
+func TestDrone_InspectHeartbeat_PlausibleMoveClean(t *testing.T) {
+	d := newIdleDrone()
+	now := time.Now()
+	d.AcceptHeartbeat(heartbeatAt(24.70, 46.60, 1, now))
+
+	// ~1.1 km in one minute is 67 km/h
+	if a := d.InspectHeartbeat(heartbeatAt(24.71, 46.60, 2, now.Add(time.Minute)), now, 120); a != nil {
+		t.Fatalf("expected no anomaly, got %+v", a)
+	}
+}
+
+func TestDrone_InspectHeartbeat_GPSJitterIgnored(t *testing.T) {
+	d := newIdleDrone()
+	now := time.Now()
+	d.AcceptHeartbeat(heartbeatAt(24.70, 46.60, 1, now))
+
+	if a := d.InspectHeartbeat(heartbeatAt(24.7001, 46.6001, 2, now), now, 120); a != nil {
+		t.Fatalf("expected jitter to be ignored, got %+v", a)
+	}
+}
+
+func TestDrone_Reserve_Quarantined_Fails(t *testing.T) {
+	d := newIdleDrone()
+	d.Quarantine("spoofing suspected")
+
+	err := d.Reserve(uuid.New())
+	de, ok := err.(*domainerrors.DomainError)
+	if !ok || de.Code != domainerrors.ErrForbidden {
+		t.Fatalf("expected FORBIDDEN, got %v", err)
+	}
+
+	if err := d.LiftQuarantine(); err != nil {
+		t.Fatalf("LiftQuarantine: %v", err)
+	}
+	if err := d.Reserve(uuid.New()); err != nil {
+		t.Fatalf("Reserve after lift: %v", err)
+	}
+}
+
+// --- Flight state ---
+
+func TestHeartbeat_ValidateFlightState(t *testing.T) {
+	heading, battery, alt := 360.0, 101, 20000.0
+	cases := map[string]drone.HeartbeatRequest{
+		"heading":  {HeadingDeg: &heading},
+		"battery":  {BatteryPct: &battery},
+		"altitude": {AltitudeM: &alt},
+		"fault":    {FaultCodes: []string{""}},
+	}
+	for name, hb := range cases {
+		if err := hb.ValidateFlightState(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	if err := (drone.HeartbeatRequest{Latitude: 24.7, Longitude: 46.6}).ValidateFlightState(); err != nil {
+		t.Fatalf("lat/lng-only heartbeat should be valid: %v", err)
+	}
+}
+
+func TestDrone_AcceptHeartbeat_ReplacesFlightState(t *testing.T) {
+	d := newIdleDrone()
+	battery := 80
+	d.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.7, Longitude: 46.6, BatteryPct: &battery, FaultCodes: []string{"low_signal"}})
+
+	if d.BatteryPct == nil || *d.BatteryPct != 80 {
+		t.Fatalf("expected battery 80, got %v", d.BatteryPct)
+	}
+	if len(d.FaultCodes) != 1 || d.FaultCodes[0] != "LOW_SIGNAL" {
+		t.Fatalf("expected upper-cased fault code, got %v", d.FaultCodes)
+	}
+
+	// An old client reporting only a position clears the stale state
+	d.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.7, Longitude: 46.6})
+	if d.BatteryPct != nil || len(d.FaultCodes) != 0 {
+		t.Fatalf("expected flight state cleared, got battery=%v faults=%v", d.BatteryPct, d.FaultCodes)
+	}
+}
+
+func TestDrone_CriticalFault(t *testing.T) {
+	d := newIdleDrone()
+	critical := []string{"MOTOR_FAILURE"}
+
+	d.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.7, Longitude: 46.6, FaultCodes: []string{"LOW_SIGNAL"}})
+	if _, ok := d.CriticalFault(critical); ok {
+		t.Fatal("non-critical fault should not match")
+	}
+
+	d.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.7, Longitude: 46.6, FaultCodes: []string{"LOW_SIGNAL", "motor_failure"}})
+	if code, ok := d.CriticalFault(critical); !ok || code != "MOTOR_FAILURE" {
+		t.Fatalf("expected MOTOR_FAILURE, got %q %v", code, ok)
+	}
+}
@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/job"
+	"drone-delivery/internal/order"
+	"drone-delivery/internal/pkg/etag"
+)
+
+func TestETag_FormatRoundTrips(t *testing.T) {
+	tag := etag.Format(7)
+	if tag != `"7"` {
+		t.Fatalf("expected \"7\", got %s", tag)
+	}
+	v, err := etag.ParseIfMatch(tag)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v == nil || *v != 7 {
+		t.Fatalf("expected version 7, got %v", v)
+	}
+}
+
+func TestETag_IfMatchAbsentOrWildcardAcceptsAny(t *testing.T) {
+	for _, h := range []string{"", " ", "*"} {
+		v, err := etag.ParseIfMatch(h)
+		if err != nil || v != nil {
+			t.Fatalf("If-Match %q: expected no version, got %v, %v", h, v, err)
+		}
+	}
+}
+
+func TestETag_IfMatchRejectsMalformed(t *testing.T) {
+	for _, h := range []string{`7`, `W/"7"`, `"7", "8"`, `"abc"`, `"0"`, `"-1"`, `"`} {
+		_, err := etag.ParseIfMatch(h)
+		var de *domainerrors.DomainError
+		if !errors.As(err, &de) || de.Code != domainerrors.ErrValidation {
+			t.Fatalf("If-Match %q: expected validation error, got %v", h, err)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	o := order.NewOrder("user-1", common.NewLocation(24.72, 46.68), common.NewLocation(24.73, 46.69))
+	d := drone.New("drone-1")
+	j := job.NewJob(o.ID.String())
+	checks := map[string]func(*int64) error{
+		"order": o.CheckVersion,
+		"drone": d.CheckVersion,
+		"job":   j.CheckVersion,
+	}
+
+	current, stale := int64(1), int64(2)
+	for name, check := range checks {
+		if err := check(nil); err != nil {
+			t.Fatalf("%s: nil If-Match should pass, got %v", name, err)
+		}
+		if err := check(&current); err != nil {
+			t.Fatalf("%s: current version should pass, got %v", name, err)
+		}
+		err := check(&stale)
+		var de *domainerrors.DomainError
+		if !errors.As(err, &de) || de.Code != domainerrors.ErrPreconditionFailed {
+			t.Fatalf("%s: expected precondition failed, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"drone-delivery/internal/drone"
+	"drone-delivery/internal/gateway"
+	"drone-delivery/internal/job"
+	"drone-delivery/internal/jwt"
+)
+
+type heartbeatCall struct {
+	droneID string
+	hb      drone.HeartbeatRequest
+}
+
+type fakeHeartbeats struct {
+	calls chan heartbeatCall
+}
+
+func (f *fakeHeartbeats) Heartbeat(ctx context.Context, droneID string, hb drone.HeartbeatRequest) (*drone.Drone, error) {
+	f.calls <- heartbeatCall{droneID: droneID, hb: hb}
+	return drone.New(droneID), nil
+}
+
+func startGateway(t *testing.T) (*gateway.Gateway, *jwt.Service, *fakeHeartbeats) {
+	t.Helper()
+	tokens := jwt.NewService("test-secret", time.Hour)
+	gw, err := gateway.New(gateway.Config{Addr: "127.0.0.1:0"}, tokens)
+	if err != nil {
+		t.Fatalf("new gateway: %v", err)
+	}
+	hbs := &fakeHeartbeats{calls: make(chan heartbeatCall, 10)}
+	if err := gw.RouteTelemetry(hbs); err != nil {
+		t.Fatalf("route telemetry: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go gw.Run(ctx)
+	t.Cleanup(cancel)
+	return gw, tokens, hbs
+}
+
+func connectDrone(gw *gateway.Gateway, droneID, password string) (paho.Client, error) {
+	opts := paho.NewClientOptions().
+		AddBroker("tcp://" + gw.Addr()).
+		SetClientID(droneID).
+		SetUsername(droneID).
+		SetPassword(password).
+		SetConnectRetry(false)
+	c := paho.NewClient(opts)
+	tok := c.Connect()
+	if !tok.WaitTimeout(5 * time.Second) {
+		return nil, context.DeadlineExceeded
+	}
+	return c, tok.Error()
+}
+
+func TestGateway_TelemetryBecomesHeartbeat(t *testing.T) {
+	gw, tokens, hbs := startGateway(t)
+	token, _ := tokens.GenerateToken("drone-1", "drone")
+
+	c, err := connectDrone(gw, "drone-1", token)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer c.Disconnect(0)
+
+	payload, _ := json.Marshal(map[string]any{"latitude": 24.72, "longitude": 46.68, "battery_pct": 90})
+	c.Publish("drones/drone-1/telemetry", 1, false, payload).WaitTimeout(5 * time.Second)
+
+	select {
+	case call := <-hbs.calls:
+		if call.droneID != "drone-1" || call.hb.Latitude != 24.72 || call.hb.BatteryPct == nil {
+			t.Fatalf("unexpected heartbeat %+v", call)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("heartbeat not received")
+	}
+}
+
+func TestGateway_DroneCannotPublishForAnotherDrone(t *testing.T) {
+	gw, tokens, hbs := startGateway(t)
+	token, _ := tokens.GenerateToken("drone-1", "drone")
+
+	c, err := connectDrone(gw, "drone-1", token)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer c.Disconnect(0)
+
+	payload, _ := json.Marshal(map[string]any{"latitude": 24.72, "longitude": 46.68})
+	c.Publish("drones/drone-2/telemetry", 1, false, payload).WaitTimeout(5 * time.Second)
+
+	select {
+	case call := <-hbs.calls:
+		t.Fatalf("expected publish to be denied, got heartbeat for %s", call.droneID)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestGateway_RejectsBadCredentials(t *testing.T) {
+	gw, tokens, _ := startGateway(t)
+
+	if _, err := connectDrone(gw, "drone-1", "not-a-token"); err == nil {
+		t.Fatal("expected invalid token to be refused")
+	}
+
+	// A valid token for a different drone is refused too
+	token, _ := tokens.GenerateToken("drone-2", "drone")
+	if _, err := connectDrone(gw, "drone-1", token); err == nil {
+		t.Fatal("expected mismatched username to be refused")
+	}
+
+	admin, _ := tokens.GenerateToken("drone-1", "admin")
+	if _, err := connectDrone(gw, "drone-1", admin); err == nil {
+		t.Fatal("expected non-drone role to be refused")
+	}
+}
+
+func TestGateway_AssignmentPublishedToDrone(t *testing.T) {
+	gw, tokens, _ := startGateway(t)
+	token, _ := tokens.GenerateToken("drone-1", "drone")
+
+	c, err := connectDrone(gw, "drone-1", token)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer c.Disconnect(0)
+
+	received := make(chan gateway.Command, 1)
+	sub := c.Subscribe("drones/drone-1/commands", 1, func(_ paho.Client, m paho.Message) {
+		var cmd gateway.Command
+		_ = json.Unmarshal(m.Payload(), &cmd)
+		received <- cmd
+	})
+	if !sub.WaitTimeout(5*time.Second) || sub.Error() != nil {
+		t.Fatalf("subscribe: %v", sub.Error())
+	}
+
+	j := job.NewJob("order-1")
+	gw.JobAssigned(context.Background(), "drone-1", j)
+
+	select {
+	case cmd := <-received:
+		if cmd.Type != gateway.CommandAssignment || cmd.JobID != j.ID || cmd.OrderID != "order-1" {
+			t.Fatalf("unexpected command %+v", cmd)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not received")
+	}
+}
@@ -110,3 +110,18 @@ func TestValidateLatLng_BothInvalid(t *testing.T) {
 		t.Fatal("expected error for both invalid")
 	}
 }
+
+func TestBoundingBox_ContainsRadius(t *testing.T) {
+	center := common.NewLocation(24.7136, 46.6753)
+	sw, ne := common.BoundingBox(center, 10)
+
+	// Points 10 km due north and due east sit on the box's edges
+	for _, p := range []common.Location{common.NewLocation(ne.Lat, center.Lng), common.NewLocation(center.Lat, ne.Lng)} {
+		if d := common.HaversineDistance(center, p); d < 9.99 {
+			t.Fatalf("expected box edge at least 10 km away, got %.3f km", d)
+		}
+	}
+	if sw.Lat >= center.Lat || sw.Lng >= center.Lng {
+		t.Fatalf("expected south-west corner below and left of center, got %v", sw)
+	}
+}
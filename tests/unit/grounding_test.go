@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"testing"
+
+	"drone-delivery/internal/command"
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/grounding"
+)
+
+func areaRequest(radius float64) grounding.GroundRequest {
+	center := common.NewLocation(24.72, 46.68)
+	return grounding.GroundRequest{
+		Scope:       grounding.ScopeArea,
+		Center:      &center,
+		RadiusKM:    &radius,
+		Reason:      "air show",
+		OrderPolicy: grounding.OrderPolicyQueue,
+		DroneAction: command.TypeReturnToBase,
+	}
+}
+
+func TestGrounding_FleetCoversEverything(t *testing.T) {
+	g, err := grounding.New(grounding.GroundRequest{
+		Scope:       grounding.ScopeFleet,
+		Reason:      "storm",
+		OrderPolicy: grounding.OrderPolicyReject,
+		DroneAction: command.TypeLandImmediately,
+	}, "admin")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if !g.Covers(common.NewLocation(-33.86, 151.21)) || !g.CoversAny(nil) {
+		t.Fatal("a fleet grounding covers every point")
+	}
+}
+
+func TestGrounding_AreaCoversRadius(t *testing.T) {
+	g, err := grounding.New(areaRequest(2), "admin")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	inside := common.NewLocation(24.725, 46.685)
+	outside := common.NewLocation(24.80, 46.75)
+	if !g.Covers(inside) {
+		t.Error("point within 2km should be covered")
+	}
+	if g.Covers(outside) {
+		t.Error("point ~11km away should not be covered")
+	}
+	if !g.CoversAny([]common.Location{outside, inside}) {
+		t.Error("CoversAny should match the inside point")
+	}
+}
+
+func TestGrounding_NewValidation(t *testing.T) {
+	radius := 1.0
+	noCenter := areaRequest(1)
+	noCenter.Center = nil
+	zeroRadius := areaRequest(0)
+	fleetWithRadius := grounding.GroundRequest{Scope: grounding.ScopeFleet, RadiusKM: &radius, Reason: "x", OrderPolicy: grounding.OrderPolicyQueue, DroneAction: command.TypeReturnToBase}
+	badPolicy := areaRequest(1)
+	badPolicy.OrderPolicy = "DROP"
+	badAction := areaRequest(1)
+	badAction.DroneAction = command.TypeHoldPosition
+
+	for name, req := range map[string]grounding.GroundRequest{
+		"area without center": noCenter,
+		"zero radius":         zeroRadius,
+		"fleet with radius":   fleetWithRadius,
+		"unknown policy":      badPolicy,
+		"hold position":       badAction,
+	} {
+		if _, err := grounding.New(req, "admin"); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
+
+func TestGrounding_LiftOnce(t *testing.T) {
+	g, err := grounding.New(areaRequest(1), "admin")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if err := g.Lift("ops"); err != nil {
+		t.Fatalf("lift: %v", err)
+	}
+	if g.IsActive() || *g.LiftedBy != "ops" {
+		t.Fatalf("expected lifted by ops, got %+v", g)
+	}
+	if err := g.Lift("ops"); err == nil {
+		t.Fatal("second lift should fail")
+	}
+}
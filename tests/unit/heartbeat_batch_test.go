@@ -0,0 +1,168 @@
+package unit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/drone"
+)
+
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func TestUnmarshalBatch_DecodesSamples(t *testing.T) {
+	ts := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var hb []byte
+	hb = appendDouble(hb, 1, 24.72)
+	hb = appendDouble(hb, 2, 46.68)
+	hb = appendVarint(hb, 3, 7)
+	hb = appendVarint(hb, 4, uint64(ts.UnixMilli()))
+	hb = appendDouble(hb, 5, 120)
+	hb = appendVarint(hb, 8, 64)
+	hb = protowire.AppendTag(hb, 9, protowire.BytesType)
+	hb = protowire.AppendString(hb, "LOW_SIGNAL")
+	hb = appendVarint(hb, 99, 1) // unknown field is skipped
+
+	var batch []byte
+	for range 2 {
+		batch = protowire.AppendTag(batch, 1, protowire.BytesType)
+		batch = protowire.AppendBytes(batch, hb)
+	}
+
+	samples, err := drone.UnmarshalBatch(batch)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+	s := samples[0]
+	if s.Latitude != 24.72 || s.Longitude != 46.68 {
+		t.Fatalf("unexpected position %v,%v", s.Latitude, s.Longitude)
+	}
+	if s.Sequence == nil || *s.Sequence != 7 || s.Timestamp == nil || !s.Timestamp.Equal(ts) {
+		t.Fatalf("unexpected sequence/timestamp %v %v", s.Sequence, s.Timestamp)
+	}
+	if s.AltitudeM == nil || *s.AltitudeM != 120 || s.BatteryPct == nil || *s.BatteryPct != 64 {
+		t.Fatalf("unexpected flight state %+v", s)
+	}
+	if s.HeadingDeg != nil {
+		t.Fatal("unset optional field should stay nil")
+	}
+	if len(s.FaultCodes) != 1 || s.FaultCodes[0] != "LOW_SIGNAL" {
+		t.Fatalf("unexpected fault codes %v", s.FaultCodes)
+	}
+}
+
+func TestUnmarshalBatch_Malformed(t *testing.T) {
+	if _, err := drone.UnmarshalBatch([]byte{0x0a, 0x05, 0x09}); err == nil {
+		t.Fatal("expected error for truncated message")
+	}
+}
+
+func TestMarshalAck_EncodesAcks(t *testing.T) {
+	seq := int64(3)
+	b, err := drone.MarshalAck(&drone.BatchAck{
+		Accepted:    1,
+		Rejected:    1,
+		Acks:        []drone.SampleAck{{Index: 0, Sequence: &seq, Accepted: true}, {Index: 1, Reason: "replayed"}},
+		DroneStatus: drone.StatusIdle,
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var acks int
+	var status string
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		switch {
+		case num == 3 && typ == protowire.BytesType:
+			_, n = protowire.ConsumeBytes(b)
+			acks++
+		case num == 4 && typ == protowire.BytesType:
+			status, n = protowire.ConsumeString(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			t.Fatal("ack is not valid protobuf")
+		}
+		b = b[n:]
+	}
+	if acks != 2 || status != "IDLE" {
+		t.Fatalf("expected 2 acks and IDLE, got %d %q", acks, status)
+	}
+}
+
+func TestHeartbeatWriter_OverlayAppliesNewerState(t *testing.T) {
+	w := drone.NewHeartbeatWriter(nil, nil, drone.WriterConfig{FlushInterval: time.Hour})
+
+	buffered := drone.New("drone-1")
+	buffered.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.8, Longitude: 46.7})
+	w.Put(buffered)
+
+	// A row loaded before the buffered heartbeat picks it up
+	stale := drone.New("drone-1")
+	w.Overlay(stale)
+	if stale.Latitude != 24.8 || stale.LastHeartbeat == nil {
+		t.Fatalf("expected buffered position, got %v", stale.Latitude)
+	}
+
+	// A row with a later heartbeat keeps its own
+	fresh := drone.New("drone-1")
+	fresh.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.9, Longitude: 46.7})
+	w.Overlay(fresh)
+	if fresh.Latitude != 24.9 {
+		t.Fatalf("expected newer row to win, got %v", fresh.Latitude)
+	}
+}
+
+type recordedIndex struct {
+	loc    common.Location
+	seenAt time.Time
+}
+
+func (r *recordedIndex) Index(_ context.Context, _, _ string, loc common.Location, seenAt time.Time, _ int64) error {
+	r.loc, r.seenAt = loc, seenAt
+	return nil
+}
+
+func (r *recordedIndex) Unindex(context.Context, string) error { return nil }
+
+func TestHeartbeatWriter_BufferedIndexUsesNewerPosition(t *testing.T) {
+	w := drone.NewHeartbeatWriter(nil, nil, drone.WriterConfig{FlushInterval: time.Hour})
+	rec := &recordedIndex{}
+	index := w.BufferedIndex(rec)
+
+	buffered := drone.New("drone-1")
+	buffered.AcceptHeartbeat(drone.HeartbeatRequest{Latitude: 24.8, Longitude: 46.7})
+	w.Put(buffered)
+
+	// A status change committed from a row flushed before that heartbeat
+	flushed := common.NewLocation(24.7, 46.7)
+	_ = index.Index(context.Background(), "drone-1", "EN_ROUTE_PICKUP", flushed, buffered.LastHeartbeat.Add(-time.Second), 2)
+	if rec.loc.Lat != 24.8 || !rec.seenAt.Equal(*buffered.LastHeartbeat) {
+		t.Fatalf("expected buffered position, got %v at %v", rec.loc, rec.seenAt)
+	}
+
+	// A drone with nothing buffered keeps the given position
+	_ = index.Index(context.Background(), "drone-2", "IDLE", flushed, time.Now(), 1)
+	if rec.loc.Lat != 24.7 {
+		t.Fatalf("expected given position, got %v", rec.loc)
+	}
+}
@@ -0,0 +1,255 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"drone-delivery/internal/middleware"
+)
+
+// memIdempotencyStore is an in-memory idempotency store with the same
+// claim semantics as the Redis one.
+type memIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string][]byte
+	extends atomic.Int32
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{records: map[string][]byte{}}
+}
+
+func (s *memIdempotencyStore) Claim(_ context.Context, userID, key string, claim []byte) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if held, ok := s.records[userID+":"+key]; ok {
+		return held, false, nil
+	}
+	s.records[userID+":"+key] = claim
+	return nil, true, nil
+}
+
+func (s *memIdempotencyStore) Extend(_ context.Context, userID, key string, claim []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(s.records[userID+":"+key], claim) {
+		s.extends.Add(1)
+	}
+	return nil
+}
+
+func (s *memIdempotencyStore) Complete(_ context.Context, userID, key string, claim, record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(s.records[userID+":"+key], claim) {
+		s.records[userID+":"+key] = record
+	}
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(_ context.Context, userID, key string, claim []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(s.records[userID+":"+key], claim) {
+		delete(s.records, userID+":"+key)
+	}
+	return nil
+}
+
+// idempotentRouter serves POST /things through the middleware; handler
+// decides the response.
+func idempotentRouter(store *memIdempotencyStore, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("sub", "user-1") })
+	r.Use(middleware.Idempotency(store, 1024, 0))
+	r.POST("/things", handler)
+	return r
+}
+
+func postThing(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysStatusHeadersAndBody(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotentRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		n := calls.Add(1)
+		c.Header("Location", "/things/1")
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+
+	first := postThing(r, "k1", `{"name":"a"}`)
+	second := postThing(r, "k1", `{"name":"a"}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls.Load())
+	}
+	if second.Code != http.StatusCreated {
+		t.Fatalf("expected the replay to keep 201, got %d", second.Code)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("expected the same body, got %s then %s", first.Body.String(), second.Body.String())
+	}
+	if second.Header().Get("Location") != "/things/1" {
+		t.Fatalf("expected the Location header replayed, got %q", second.Header().Get("Location"))
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected the replay to be marked")
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("the original response must not be marked as a replay")
+	}
+}
+
+func TestIdempotency_ReplayKeepsLiveRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var remaining atomic.Int32
+	remaining.Store(10)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("sub", "user-1")
+		c.Header("RateLimit-Remaining", strconv.Itoa(int(remaining.Add(-1))))
+	})
+	r.Use(middleware.Idempotency(newMemIdempotencyStore(), 1024, 0))
+	r.POST("/things", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) })
+
+	postThing(r, "k1", `{}`)
+	second := postThing(r, "k1", `{}`)
+
+	if got := second.Header().Values("RateLimit-Remaining"); len(got) != 1 || got[0] != "8" {
+		t.Fatalf("expected only the live RateLimit-Remaining 8, got %v", got)
+	}
+}
+
+func TestIdempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	r := idempotentRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	postThing(r, "k1", `{"name":"a"}`)
+	w := postThing(r, "k1", `{"name":"b"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Fatalf("expected IDEMPOTENCY_KEY_REUSED, got %s", w.Body.String())
+	}
+}
+
+func TestIdempotency_InFlightDuplicateConflicts(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	r := idempotentRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		calls.Add(1)
+		close(entered)
+		<-release
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postThing(r, "k1", `{}`) }()
+	<-entered
+
+	w := postThing(r, "k1", `{}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while the first request runs, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After on the 409")
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusOK {
+		t.Fatalf("expected the first request to succeed, got %d", first.Code)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotency_FailureFreesTheKey(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotentRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	if w := postThing(r, "k1", `{}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the first attempt to fail, got %d", w.Code)
+	}
+	if w := postThing(r, "k1", `{}`); w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("expected the retry to run, got %d", w.Code)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected two runs, got %d", calls.Load())
+	}
+}
+
+func TestIdempotency_PanicFreesTheKey(t *testing.T) {
+	store := newMemIdempotencyStore()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) { c.AbortWithStatus(http.StatusInternalServerError) }))
+	r.Use(func(c *gin.Context) { c.Set("sub", "user-1") })
+	r.Use(middleware.Idempotency(store, 1024, 0))
+	r.POST("/things", func(c *gin.Context) { panic("boom") })
+
+	postThing(r, "k1", `{}`)
+	if len(store.records) != 0 {
+		t.Fatalf("expected the claim to be released after a panic")
+	}
+}
+
+func TestIdempotency_SlowRequestKeepsItsClaim(t *testing.T) {
+	store := newMemIdempotencyStore()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("sub", "user-1") })
+	r.Use(middleware.Idempotency(store, 1024, 30*time.Millisecond))
+	r.POST("/things", func(c *gin.Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	if w := postThing(r, "k1", `{}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if store.extends.Load() == 0 {
+		t.Fatalf("expected the claim to be renewed while the handler ran")
+	}
+	n := store.extends.Load()
+	time.Sleep(50 * time.Millisecond)
+	if store.extends.Load() != n {
+		t.Fatalf("expected renewal to stop once the response was recorded")
+	}
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	r := idempotentRouter(newMemIdempotencyStore(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	w := postThing(r, "k1", strings.Repeat("x", 2048))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", w.Code)
+	}
+}
@@ -1,10 +1,13 @@
 package unit
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	domainerrors "drone-delivery/internal/errors"
 	"drone-delivery/internal/job"
+	"drone-delivery/internal/pkg/listing"
 )
 
 func newOpenJob() *job.Job {
@@ -60,6 +63,93 @@ func TestJob_Reserve_AlreadyReserved_Fails(t *testing.T) {
 	}
 }
 
+// --- Release ---
+
+func TestJob_Release_FromReserved(t *testing.T) {
+	j := newOpenJob()
+	_ = j.Reserve("drone-1")
+	j.Lease(2, time.Now().Add(time.Hour))
+	if err := j.Release("unassigned by admin"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if j.Status != job.StatusOpen {
+		t.Fatalf("expected OPEN, got %s", j.Status)
+	}
+	if j.ReservedByDroneID != nil {
+		t.Fatal("expected reserved drone to be cleared")
+	}
+	if j.LeaseExpiresAt != nil || j.LeaseDistanceKM != nil {
+		t.Fatal("expected lease to be cleared")
+	}
+	if j.ReleaseReason != "unassigned by admin" {
+		t.Fatalf("expected release reason, got %q", j.ReleaseReason)
+	}
+}
+
+func TestJob_Release_FromOpen_Fails(t *testing.T) {
+	j := newOpenJob()
+	if err := j.Release("test"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// --- Lease ---
+
+func TestLeasePolicy_Deadline(t *testing.T) {
+	p := job.LeasePolicy{SpeedKMH: 30, Grace: 10 * time.Minute}
+	now := time.Now()
+
+	// 15 km at 30 km/h is 30 minutes, plus grace
+	if got := p.Deadline(15, now); !got.Equal(now.Add(40 * time.Minute)) {
+		t.Fatalf("expected now+40m, got now+%v", got.Sub(now))
+	}
+}
+
+func TestJob_ExtendLease_OnlyWhenCloser(t *testing.T) {
+	now := time.Now()
+	j := newOpenJob()
+	_ = j.Reserve("drone-1")
+	j.Lease(2, now.Add(20*time.Minute))
+
+	// Hovering in place doesn't earn more time
+	if j.ExtendLease(1.99, now.Add(30*time.Minute)) {
+		t.Fatal("expected no extension without real progress")
+	}
+	if !j.ExtendLease(1, now.Add(30*time.Minute)) {
+		t.Fatal("expected extension after closing in")
+	}
+	if !j.LeaseExpiresAt.Equal(now.Add(30 * time.Minute)) {
+		t.Fatalf("expected deadline now+30m, got now+%v", j.LeaseExpiresAt.Sub(now))
+	}
+
+	// Progress never shortens the deadline
+	if !j.ExtendLease(0.5, now.Add(25*time.Minute)) {
+		t.Fatal("expected progress to be recorded")
+	}
+	if !j.LeaseExpiresAt.Equal(now.Add(30 * time.Minute)) {
+		t.Fatalf("expected deadline to stay at now+30m, got now+%v", j.LeaseExpiresAt.Sub(now))
+	}
+}
+
+func TestJob_LeaseExpired(t *testing.T) {
+	now := time.Now()
+	j := newOpenJob()
+	_ = j.Reserve("drone-1")
+	j.Lease(1, now.Add(time.Minute))
+
+	if j.LeaseExpired(now) {
+		t.Fatal("lease should still be running")
+	}
+	if !j.LeaseExpired(now.Add(2 * time.Minute)) {
+		t.Fatal("lease should have expired")
+	}
+
+	j.EndLease()
+	if j.LeaseExpired(now.Add(2 * time.Minute)) {
+		t.Fatal("an ended lease never expires")
+	}
+}
+
 func TestJob_Reserve_FromCompleted_Fails(t *testing.T) {
 	j := newOpenJob()
 	_ = j.Reserve("drone-1")
@@ -179,3 +269,111 @@ func TestJob_ReserveThenCancel(t *testing.T) {
 		t.Fatalf("expected CANCELLED, got %s", j.Status)
 	}
 }
+
+// --- Dispatch queue ---
+
+func TestQueuePolicy_PriorityThenAge(t *testing.T) {
+	now := time.Now()
+	older := job.NewJob("order-older")
+	older.CreatedAt = now.Add(-time.Minute)
+	newer := job.NewJob("order-newer")
+	newer.CreatedAt = now
+	express := job.NewJob("order-express")
+	express.CreatedAt = now
+	express.Priority = 10
+	jobs := []*job.Job{newer, older, express}
+
+	job.QueuePolicy{}.Sort(jobs, now)
+
+	if jobs[0] != express || jobs[1] != older || jobs[2] != newer {
+		t.Fatalf("expected express, older, newer; got %s, %s, %s", jobs[0].OrderID, jobs[1].OrderID, jobs[2].OrderID)
+	}
+}
+
+func TestQueuePolicy_AgingPreventsStarvation(t *testing.T) {
+	now := time.Now()
+	p := job.QueuePolicy{AgingStep: 2 * time.Minute}
+
+	waiting := job.NewJob("order-waiting")
+	waiting.CreatedAt = now.Add(-25 * time.Minute)
+	express := job.NewJob("order-express")
+	express.CreatedAt = now
+	express.Priority = 10
+
+	if got := p.EffectivePriority(waiting, now); got != 12 {
+		t.Fatalf("expected 12 levels gained after 25m, got %d", got)
+	}
+	jobs := []*job.Job{express, waiting}
+	p.Sort(jobs, now)
+	if jobs[0] != waiting {
+		t.Fatal("expected the long-waiting job to overtake a fresh express job")
+	}
+}
+
+func TestQueuePolicy_CursorKeepsTheFirstPagesClock(t *testing.T) {
+	// Postgres keeps microseconds
+	start := time.Now().Truncate(time.Microsecond)
+	p := job.QueuePolicy{AgingStep: time.Minute}
+	var jobs []*job.Job
+	for i := range 3 {
+		j := job.NewJob(fmt.Sprintf("order-%d", i))
+		j.CreatedAt = start.Add(time.Duration(i-10) * time.Minute)
+		jobs = append(jobs, j)
+	}
+
+	page, err := p.PageFor(nil, 2, start)
+	if err != nil || page.After != nil || page.Limit != 2 || page.AgingStep != time.Minute {
+		t.Fatalf("first page: got %+v, %v", page, err)
+	}
+	// The repository reads one row past the limit
+	got, next := p.Next(page, jobs)
+	if len(got) != 2 || next == "" {
+		t.Fatalf("expected 2 jobs and a cursor, got %d, %q", len(got), next)
+	}
+
+	// Time passes before the next page is asked for
+	cursor, err := listing.DecodeCursor(next)
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	page, err = p.PageFor(cursor, 2, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if !page.At.Equal(start) {
+		t.Fatalf("expected priorities aged as of the first page, got %s", page.At)
+	}
+	if a := page.After; a == nil || a.ID != jobs[1].ID || a.Priority != 9 || !a.CreatedAt.Equal(jobs[1].CreatedAt) {
+		t.Fatalf("expected to resume after job 1 at priority 9, got %+v", page.After)
+	}
+	if got, next := p.Next(page, jobs[2:]); len(got) != 1 || next != "" {
+		t.Fatalf("last page: got %d jobs, cursor %q", len(got), next)
+	}
+
+	if _, err := p.PageFor(&listing.Cursor{Sort: "-created_at", Keys: []string{"x", "y"}}, 2, start); err == nil {
+		t.Fatal("expected a cursor from another sort to be refused")
+	}
+}
+
+func TestJob_SetPriority(t *testing.T) {
+	j := newOpenJob()
+	if err := j.SetPriority(50); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if j.Priority != 50 {
+		t.Fatalf("expected 50, got %d", j.Priority)
+	}
+
+	if err := j.SetPriority(job.MaxPriority + 1); err == nil {
+		t.Fatal("expected error above MaxPriority")
+	}
+	if err := j.SetPriority(-1); err == nil {
+		t.Fatal("expected error below 0")
+	}
+
+	_ = j.Reserve("drone-1")
+	_ = j.Complete()
+	if err := j.SetPriority(60); err == nil {
+		t.Fatal("expected error on a completed job")
+	}
+}
@@ -0,0 +1,173 @@
+package unit
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"drone-delivery/internal/pkg/listing"
+)
+
+var testSchema = listing.Schema{
+	Name:      "things",
+	ID:        "id",
+	Status:    "status",
+	CreatedAt: "created_at",
+	Lat:       "lat",
+	Lng:       "lng",
+	Sorts: map[string]string{
+		"created_at": "created_at",
+		"id":         "id",
+	},
+	DefaultSort: listing.Sort{Field: "created_at", Desc: true},
+}
+
+func TestParse_Defaults(t *testing.T) {
+	spec, err := listing.Parse(url.Values{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Limit != listing.DefaultLimit || spec.Filtered() || spec.Cursor != nil || spec.Sort != (listing.Sort{}) {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+}
+
+func TestParse_FiltersAndSort(t *testing.T) {
+	spec, err := listing.Parse(url.Values{
+		"limit":  {"5"},
+		"sort":   {"-updated_at"},
+		"status": {"PENDING"},
+		"from":   {"2026-03-01T12:00:00Z"},
+		"to":     {"2026-03-02"},
+		"bbox":   {"24.6,46.6,24.8,46.8"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Limit != 5 || spec.Sort != (listing.Sort{Field: "updated_at", Desc: true}) || spec.Status != "PENDING" {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+	if !spec.From.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected from %v", spec.From)
+	}
+	if !spec.To.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a date to include the whole day, got %v", spec.To)
+	}
+	if *spec.BBox != (listing.BBox{MinLat: 24.6, MinLng: 46.6, MaxLat: 24.8, MaxLng: 46.8}) {
+		t.Fatalf("unexpected bbox %+v", spec.BBox)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"101"}},
+		{"cursor": {"not a cursor"}},
+		{"from": {"yesterday"}},
+		{"from": {"2026-03-02"}, "to": {"2026-03-01"}},
+		{"bbox": {"1,2,3"}},
+		{"bbox": {"24.8,46.6,24.6,46.8"}},
+	}
+	for _, q := range tests {
+		if _, err := listing.Parse(q); err == nil {
+			t.Fatalf("expected %v to be refused", q)
+		}
+	}
+}
+
+func TestSchema_Build(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	q, err := testSchema.Build(listing.Spec{
+		Status: "IDLE",
+		From:   &from,
+		BBox:   &listing.BBox{MinLat: 1, MinLng: 2, MaxLat: 3, MaxLng: 4},
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantWhere := " WHERE status = $1 AND created_at >= $2 AND lat BETWEEN $3 AND $4 AND lng BETWEEN $5 AND $6"
+	if q.Where() != wantWhere {
+		t.Fatalf("where: got %q", q.Where())
+	}
+	if want := wantWhere + " ORDER BY created_at DESC, id DESC LIMIT $7"; q.Page() != want {
+		t.Fatalf("page: got %q", q.Page())
+	}
+	if want := []any{"IDLE", from, 1.0, 3.0, 2.0, 4.0, 11}; !reflect.DeepEqual(q.PageArgs(), want) {
+		t.Fatalf("page args: got %v", q.PageArgs())
+	}
+	if len(q.Args()) != 6 {
+		t.Fatalf("expected the count to take only the filter args, got %v", q.Args())
+	}
+}
+
+func TestSchema_BuildRefusesUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		spec listing.Spec
+		want string
+	}{
+		{"filter", listing.Spec{SubmittedBy: "user-1"}, "submitted_by"},
+		{"sort", listing.Spec{Sort: listing.Sort{Field: "priority"}}, "sorted by created_at, id"},
+		{"cursor for another sort", listing.Spec{Cursor: &listing.Cursor{Sort: "id", Keys: []string{"a", "a"}}}, "different sort"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testSchema.Build(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNext_CursorContinuesAfterLastRow(t *testing.T) {
+	type row struct {
+		id      string
+		created time.Time
+	}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := []row{{"c", now}, {"b", now}, {"a", now.Add(-time.Hour)}}
+	keys := func(r row, field string) (string, string) { return listing.FormatTime(r.created), r.id }
+
+	q, _ := testSchema.Build(listing.Spec{Limit: 2})
+	page, next := listing.Next(q, rows, keys)
+	if len(page) != 2 || next == "" {
+		t.Fatalf("expected a full page and a cursor, got %d rows and %q", len(page), next)
+	}
+
+	cursor, err := listing.DecodeCursor(next)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	q, err = testSchema.Build(listing.Spec{Cursor: cursor, Limit: 2})
+	if err != nil {
+		t.Fatalf("build with cursor: %v", err)
+	}
+	if want := " WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT $3"; q.Page() != want {
+		t.Fatalf("page: got %q", q.Page())
+	}
+	if args := q.PageArgs(); args[0] != listing.FormatTime(now) || args[1] != "b" {
+		t.Fatalf("expected the cursor to carry the last row's keys, got %v", args)
+	}
+
+	if _, next := listing.Next(q, rows[2:], keys); next != "" {
+		t.Fatalf("expected no cursor on the last page, got %q", next)
+	}
+}
+
+func TestSchema_BuildSortByID(t *testing.T) {
+	q, err := testSchema.Build(listing.Spec{
+		Sort:   listing.Sort{Field: "id"},
+		Cursor: &listing.Cursor{Sort: "id", Keys: []string{"drone-2", "drone-2"}},
+		Limit:  5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := " WHERE id > $1 ORDER BY id ASC LIMIT $2"; q.Page() != want {
+		t.Fatalf("page: got %q", q.Page())
+	}
+}
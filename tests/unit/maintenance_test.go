@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"testing"
+
+	"drone-delivery/internal/drone"
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/maintenance"
+
+	"github.com/google/uuid"
+)
+
+func TestSchedule_IsDue_ByCycles(t *testing.T) {
+	d := newIdleDrone()
+	s := maintenance.Schedule{MaxCycles: 2}
+
+	d.RecordFlight(3, 0.2)
+	if s.IsDue(d) {
+		t.Fatal("expected not due after one cycle")
+	}
+	d.RecordFlight(3, 0.2)
+	if !s.IsDue(d) {
+		t.Fatal("expected due after two cycles")
+	}
+}
+
+func TestSchedule_IsDue_ZeroLimitsNeverDue(t *testing.T) {
+	d := newIdleDrone()
+	for range 100 {
+		d.RecordFlight(50, 5)
+	}
+	if (maintenance.Schedule{}).IsDue(d) {
+		t.Fatal("expected zero schedule to never be due")
+	}
+}
+
+func TestDrone_RecordFlight_AccumulatesCounters(t *testing.T) {
+	d := newIdleDrone()
+	d.RecordFlight(4, 0.5)
+	d.RecordFlight(6, 0.25)
+
+	if d.FlightCycles != 2 || d.FlightKM != 10 || d.ServiceHours != 0.75 {
+		t.Fatalf("unexpected totals: cycles=%d km=%f hours=%f", d.FlightCycles, d.FlightKM, d.ServiceHours)
+	}
+	if d.CyclesSinceService != 2 || d.KMSinceService != 10 {
+		t.Fatalf("unexpected since-service counters: %+v", d)
+	}
+}
+
+func TestDrone_Reserve_MaintenanceDue_Fails(t *testing.T) {
+	d := newIdleDrone()
+	d.FlagMaintenanceDue()
+
+	err := d.Reserve(uuid.New())
+	de, ok := err.(*domainerrors.DomainError)
+	if !ok || de.Code != domainerrors.ErrConflict {
+		t.Fatalf("expected CONFLICT, got %v", err)
+	}
+}
+
+func TestDrone_CompleteMaintenance_ResetsSinceService(t *testing.T) {
+	d := newIdleDrone()
+	d.RecordFlight(4, 0.5)
+	d.FlagMaintenanceDue()
+
+	d.CompleteMaintenance()
+
+	if d.MaintenanceDue || d.CyclesSinceService != 0 || d.KMSinceService != 0 || d.HoursSinceService != 0 {
+		t.Fatalf("expected since-service counters reset, got %+v", d)
+	}
+	if d.FlightCycles != 1 {
+		t.Fatal("expected lifetime counters to be kept")
+	}
+	if d.Status != drone.StatusIdle {
+		t.Fatalf("expected IDLE, got %s", d.Status)
+	}
+}
+
+func TestWorkOrder_Complete(t *testing.T) {
+	w := maintenance.NewWorkOrder("drone-1", maintenance.KindRepair, "rotor", "admin")
+
+	if err := w.Complete("admin", "replaced"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Status != maintenance.StatusCompleted || w.CompletedAt == nil {
+		t.Fatalf("expected COMPLETED with timestamp, got %s", w.Status)
+	}
+	if err := w.Complete("admin", "again"); err == nil {
+		t.Fatal("expected error completing twice")
+	}
+}
@@ -2,6 +2,7 @@ package unit
 
 import (
 	"testing"
+	"time"
 
 	"drone-delivery/internal/common"
 	domainerrors "drone-delivery/internal/errors"
@@ -153,6 +154,48 @@ func TestOrder_Assign_FromDelivered_Fails(t *testing.T) {
 	}
 }
 
+// --- Unassign ---
+
+func TestOrder_Unassign_FromAssigned(t *testing.T) {
+	o := newPendingOrder()
+	_ = o.Assign("drone-1")
+	if err := o.Unassign(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Status != order.StatusPending {
+		t.Fatalf("expected PENDING, got %s", o.Status)
+	}
+	if o.AssignedDroneID != nil {
+		t.Fatal("expected drone to be cleared")
+	}
+}
+
+func TestOrder_Unassign_AfterHandoff(t *testing.T) {
+	o := newPendingOrder()
+	_ = o.Assign("drone-1")
+	_ = o.MarkPickedUp()
+	_ = o.AwaitHandoff()
+	_ = o.Assign("drone-2")
+	if err := o.Unassign(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.Status != order.StatusAwaitingHandoff {
+		t.Fatalf("expected AWAITING_HANDOFF, got %s", o.Status)
+	}
+	if err := o.Withdraw(); err == nil {
+		t.Fatal("expected withdraw to be refused after a handoff")
+	}
+}
+
+func TestOrder_Unassign_FromPickedUp_Fails(t *testing.T) {
+	o := newPendingOrder()
+	_ = o.Assign("drone-1")
+	_ = o.MarkPickedUp()
+	if err := o.Unassign(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 // --- MarkPickedUp ---
 
 func TestOrder_MarkPickedUp_FromAssigned(t *testing.T) {
@@ -351,3 +394,89 @@ func TestOrder_HandoffReassignLifecycle(t *testing.T) {
 		t.Fatalf("expected DELIVERED, got %s", o.Status)
 	}
 }
+
+func TestOrder_SetServiceTier(t *testing.T) {
+	o := newPendingOrder()
+	if o.ServiceTier != order.TierStandard || o.Priority != 0 {
+		t.Fatalf("expected STANDARD at priority 0, got %s at %d", o.ServiceTier, o.Priority)
+	}
+
+	o.SetServiceTier(order.TierExpress)
+	if o.ServiceTier != order.TierExpress || o.Priority != order.TierExpress.Priority() {
+		t.Fatalf("expected EXPRESS at its tier priority, got %s at %d", o.ServiceTier, o.Priority)
+	}
+	if o.Priority <= order.TierStandard.Priority() {
+		t.Fatal("expected express to outrank standard")
+	}
+
+	o.SetServiceTier("")
+	if o.ServiceTier != order.TierStandard {
+		t.Fatalf("expected an empty tier to mean STANDARD, got %s", o.ServiceTier)
+	}
+}
+
+func TestOrder_WaitlistAndPromote(t *testing.T) {
+	o := newPendingOrder()
+	placed := time.Now().Add(3 * time.Hour)
+	o.SetPromise(&placed, &placed)
+	if err := o.Waitlist(); err != nil {
+		t.Fatalf("waitlist: %v", err)
+	}
+	if o.Status != order.StatusWaitlisted {
+		t.Fatalf("expected WAITLISTED, got %s", o.Status)
+	}
+	if err := o.Assign("drone-1"); err == nil {
+		t.Fatal("expected a waitlisted order not to be assignable")
+	}
+
+	pickup := time.Now().Add(20 * time.Minute)
+	delivery := pickup.Add(10 * time.Minute)
+	if err := o.Promote(&pickup, &delivery); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+	if o.Status != order.StatusPending {
+		t.Fatalf("expected PENDING, got %s", o.Status)
+	}
+	if o.RepromisedPickupAt == nil || !o.RepromisedPickupAt.Equal(pickup) || o.RepromisedDeliveryAt == nil || !o.RepromisedDeliveryAt.Equal(delivery) {
+		t.Fatal("expected the promotion to carry the new promise")
+	}
+	if o.PromisedDeliveryAt == nil || !o.PromisedDeliveryAt.Equal(placed) {
+		t.Fatal("expected the original promise to be kept")
+	}
+	if err := o.Promote(&pickup, &delivery); err == nil {
+		t.Fatal("expected promoting a pending order to fail")
+	}
+}
+
+func TestOrder_Withdraw_FromWaitlisted(t *testing.T) {
+	o := newPendingOrder()
+	_ = o.Waitlist()
+	if err := o.Withdraw(); err != nil {
+		t.Fatalf("withdraw: %v", err)
+	}
+	if o.Status != order.StatusWithdrawn {
+		t.Fatalf("expected WITHDRAWN, got %s", o.Status)
+	}
+}
+
+func TestOrder_RecordsActualTimes(t *testing.T) {
+	o := newPendingOrder()
+	_ = o.Assign("drone-1")
+	if o.AssignedAt == nil {
+		t.Fatal("expected assignment time")
+	}
+	_ = o.Unassign()
+	if o.AssignedAt != nil {
+		t.Fatal("expected unassign to clear the assignment time")
+	}
+
+	_ = o.Assign("drone-1")
+	_ = o.MarkPickedUp()
+	_ = o.MarkDelivered()
+	if o.PickedUpAt == nil || o.DeliveredAt == nil {
+		t.Fatal("expected pickup and delivery times")
+	}
+	if o.DeliveredAt.Before(*o.AssignedAt) {
+		t.Fatal("expected delivery after assignment")
+	}
+}
@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	domainerrors "drone-delivery/internal/errors"
+	"drone-delivery/internal/payment"
+
+	"github.com/google/uuid"
+)
+
+func newAuthorizedPayment() *payment.Payment {
+	return payment.NewAuthorized(uuid.New(), "fake", "ref-1", 5000, "SAR")
+}
+
+func TestPayment_Capture_FromAuthorized(t *testing.T) {
+	p := newAuthorizedPayment()
+
+	entry, err := p.Capture()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Status != payment.StatusCaptured || p.CapturedAmount != 5000 {
+		t.Fatalf("expected CAPTURED 5000, got %s %d", p.Status, p.CapturedAmount)
+	}
+	if entry.Operation != payment.OpCapture || entry.Amount != 5000 {
+		t.Fatalf("expected CAPTURE 5000 outbox entry, got %s %d", entry.Operation, entry.Amount)
+	}
+}
+
+func TestPayment_Void_AfterCapture_Fails(t *testing.T) {
+	p := newAuthorizedPayment()
+	_, _ = p.Capture()
+
+	_, err := p.Void()
+	de, ok := err.(*domainerrors.DomainError)
+	if !ok || de.Code != domainerrors.ErrInvalidTransition {
+		t.Fatalf("expected INVALID_TRANSITION, got %v", err)
+	}
+}
+
+func TestPayment_SettleFailed_FullRefundVoids(t *testing.T) {
+	p := newAuthorizedPayment()
+
+	entry, err := p.SettleFailed(payment.RefundPolicy{FailedRefundPercent: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Status != payment.StatusVoided || entry.Operation != payment.OpVoid {
+		t.Fatalf("expected VOIDED with VOID entry, got %s %s", p.Status, entry.Operation)
+	}
+}
+
+func TestPayment_SettleFailed_PartialRefundCapturesRetained(t *testing.T) {
+	p := newAuthorizedPayment()
+
+	entry, err := p.SettleFailed(payment.RefundPolicy{FailedRefundPercent: 80})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Status != payment.StatusCaptured || p.CapturedAmount != 1000 {
+		t.Fatalf("expected CAPTURED 1000, got %s %d", p.Status, p.CapturedAmount)
+	}
+	if entry.Operation != payment.OpCapture || entry.Amount != 1000 {
+		t.Fatalf("expected CAPTURE 1000 outbox entry, got %s %d", entry.Operation, entry.Amount)
+	}
+}
+
+func TestPayment_Refund_Partial(t *testing.T) {
+	p := newAuthorizedPayment()
+	_, _ = p.Capture()
+
+	if _, err := p.Refund(2000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Status != payment.StatusPartiallyRefunded {
+		t.Fatalf("expected PARTIALLY_REFUNDED, got %s", p.Status)
+	}
+	if _, err := p.Refund(3000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Status != payment.StatusRefunded {
+		t.Fatalf("expected REFUNDED, got %s", p.Status)
+	}
+	if _, err := p.Refund(1); err == nil {
+		t.Fatal("expected error refunding beyond captured amount")
+	}
+}
+
+func TestFakeProvider_RepeatedKeyIsNotAppliedTwice(t *testing.T) {
+	f := payment.NewFakeProvider()
+	ctx := context.Background()
+	ref, err := f.Authorize(ctx, payment.AuthorizeRequest{Amount: 5000, PaymentMethod: "pm_card_visa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Capture(ctx, ref, 5000, "outbox-1"); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+
+	// A refund whose reply was lost is sent again with its key
+	for i := range 2 {
+		if err := f.Refund(ctx, ref, 3000, "outbox-2"); err != nil {
+			t.Fatalf("refund %d: %v", i, err)
+		}
+	}
+	if got := f.Refunded(ref); got != 3000 {
+		t.Fatalf("expected 3000 refunded once, got %d", got)
+	}
+	if err := f.Refund(ctx, ref, 3000, "outbox-3"); err == nil {
+		t.Fatalf("expected a new key to be refused past the refundable amount")
+	}
+}
@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/drone"
+	"drone-delivery/internal/pricing"
+)
+
+// offPeak is 12:00 Riyadh time, outside the default surge windows.
+var offPeak = time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+func TestPricing_BaseAndDistance(t *testing.T) {
+	rules := pricing.DefaultRules()
+
+	b, err := rules.Price(pricing.PriceInput{DistanceKM: 10, PayloadKG: 0.5, At: offPeak})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 1000 base + 10 km * 250
+	if b.Total != 3500 {
+		t.Fatalf("expected 3500, got %d", b.Total)
+	}
+	if b.SurgeMultiplier != 1 || b.UtilizationMultiplier != 1 {
+		t.Fatalf("expected no multipliers, got surge=%f util=%f", b.SurgeMultiplier, b.UtilizationMultiplier)
+	}
+}
+
+func TestPricing_MinimumFare(t *testing.T) {
+	rules := pricing.DefaultRules()
+
+	b, err := rules.Price(pricing.PriceInput{DistanceKM: 0.1, At: offPeak})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.Total != rules.MinimumFare {
+		t.Fatalf("expected minimum fare %d, got %d", rules.MinimumFare, b.Total)
+	}
+}
+
+func TestPricing_PayloadSurcharge(t *testing.T) {
+	rules := pricing.DefaultRules()
+
+	b, err := rules.Price(pricing.PriceInput{DistanceKM: 10, PayloadKG: 2.5, At: offPeak})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.PayloadSurcharge != 500 {
+		t.Fatalf("expected 500 surcharge, got %d", b.PayloadSurcharge)
+	}
+}
+
+func TestPricing_PayloadTooHeavy(t *testing.T) {
+	rules := pricing.DefaultRules()
+
+	_, err := rules.Price(pricing.PriceInput{DistanceKM: 10, PayloadKG: 50, At: offPeak})
+	if !errors.Is(err, pricing.ErrPayloadTooHeavy) {
+		t.Fatalf("expected ErrPayloadTooHeavy, got %v", err)
+	}
+}
+
+func TestPricing_TimeOfDaySurge(t *testing.T) {
+	rules := pricing.DefaultRules()
+	evening := time.Date(2025, 1, 15, 15, 30, 0, 0, time.UTC) // 18:30 Riyadh
+
+	b, err := rules.Price(pricing.PriceInput{DistanceKM: 10, At: evening})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.SurgeMultiplier != 1.3 {
+		t.Fatalf("expected 1.3 surge, got %f", b.SurgeMultiplier)
+	}
+	if b.Total != 4550 {
+		t.Fatalf("expected 4550, got %d", b.Total)
+	}
+}
+
+func TestPricing_UtilizationTiers(t *testing.T) {
+	rules := pricing.DefaultRules()
+
+	cases := []struct {
+		utilization float64
+		want        float64
+	}{
+		{0.2, 1},
+		{0.5, 1.1},
+		{0.85, 1.25},
+		{1, 1.5},
+	}
+	for _, tc := range cases {
+		b, err := rules.Price(pricing.PriceInput{DistanceKM: 10, At: offPeak, Utilization: tc.utilization})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b.UtilizationMultiplier != tc.want {
+			t.Fatalf("utilization %f: expected multiplier %f, got %f", tc.utilization, tc.want, b.UtilizationMultiplier)
+		}
+	}
+}
+
+type fleetCounts map[drone.Status]int
+
+func (f fleetCounts) CountByStatus(context.Context) (map[drone.Status]int, error) {
+	return f, nil
+}
+
+func TestPricing_EmptyFleetIsNotSurged(t *testing.T) {
+	req := pricing.Request{Origin: common.NewLocation(24.70, 46.67), Destination: common.NewLocation(24.75, 46.70)}
+	quote := func(fleet fleetCounts) float64 {
+		svc := pricing.NewService(pricing.DefaultRules(), fleet, pricing.Config{Secret: "test", QuoteTTL: time.Minute})
+		q, err := svc.Quote(context.Background(), "user-1", req)
+		if err != nil {
+			t.Fatalf("quote: %v", err)
+		}
+		return q.Breakdown.UtilizationMultiplier
+	}
+
+	if m := quote(fleetCounts{}); m != 1 {
+		t.Fatalf("expected no utilization surge without drones in service, got %f", m)
+	}
+	if m := quote(fleetCounts{drone.StatusEnRouteDelivery: 2}); m != 1.5 {
+		t.Fatalf("expected top surge with every drone busy, got %f", m)
+	}
+}
@@ -0,0 +1,211 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"drone-delivery/internal/jwt"
+	"drone-delivery/internal/middleware"
+	"drone-delivery/internal/pkg/ratelimit"
+)
+
+func TestRateLimit_BucketRefillsAtRate(t *testing.T) {
+	m := ratelimit.NewMemory()
+	l := ratelimit.Limit{Requests: 60, Window: time.Minute, Burst: 3}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range 3 {
+		if d := m.TakeAt("k", l, now); !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("take %d: expected allowed with %d left, got %+v", i, 2-i, d)
+		}
+	}
+	d := m.TakeAt("k", l, now)
+	if d.Allowed {
+		t.Fatalf("expected the burst to be spent")
+	}
+	if d.RetryAfter != time.Second {
+		t.Fatalf("expected a token in 1s, got %s", d.RetryAfter)
+	}
+	if d.Reset != 3*time.Second {
+		t.Fatalf("expected the bucket full in 3s, got %s", d.Reset)
+	}
+
+	// Half a token is not enough
+	if d := m.TakeAt("k", l, now.Add(500*time.Millisecond)); d.Allowed || d.RetryAfter != 500*time.Millisecond {
+		t.Fatalf("expected refusal with 500ms to wait, got %+v", d)
+	}
+	if d := m.TakeAt("k", l, now.Add(time.Second)); !d.Allowed {
+		t.Fatalf("expected a refilled token after 1s")
+	}
+	// Refill stops at the burst
+	if d := m.TakeAt("k", l, now.Add(time.Hour)); d.Remaining != 2 {
+		t.Fatalf("expected the bucket capped at 3, %d left after a take", d.Remaining)
+	}
+}
+
+func TestRateLimit_IdleBucketsAreDropped(t *testing.T) {
+	m := ratelimit.NewMemory()
+	l := ratelimit.Limit{Requests: 10, Window: time.Second}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	m.TakeAt("a", l, now)
+	m.TakeAt("b", l, now.Add(time.Minute))
+	if m.Len() != 1 {
+		t.Fatalf("expected the refilled bucket dropped, %d held", m.Len())
+	}
+}
+
+func TestRateLimit_NewPolicyValidates(t *testing.T) {
+	if _, err := ratelimit.NewPolicy("p", "cookie", 10, 60, 0); err == nil {
+		t.Fatalf("expected an unknown key to be refused")
+	}
+	if _, err := ratelimit.NewPolicy("p", "sub", 0, 60, 0); err == nil {
+		t.Fatalf("expected zero requests to be refused")
+	}
+	p, err := ratelimit.NewPolicy("p", "tenant", 10, 60, 0)
+	if err != nil || p.Key != ratelimit.KeyTenant || p.Limit.Capacity() != 10 {
+		t.Fatalf("unexpected policy %+v, %v", p, err)
+	}
+}
+
+// limitedRouter serves GET /things under policy; each request is made by
+// the sub in its X-Sub header, from one shared IP.
+func limitedRouter(limiter ratelimit.Limiter, policy ratelimit.Policy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if sub := c.GetHeader("X-Sub"); sub != "" {
+			c.Set("sub", sub)
+		}
+		if tenant := c.GetHeader("X-Tenant"); tenant != "" {
+			c.Set("tenant", tenant)
+		}
+	})
+	r.Use(middleware.RateLimit(limiter, policy))
+	r.GET("/things", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+	return r
+}
+
+func getThings(r *gin.Engine, sub, tenant string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/things", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	req.Header.Set("X-Sub", sub)
+	req.Header.Set("X-Tenant", tenant)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_HeadersAndRetryAfter(t *testing.T) {
+	policy := ratelimit.Policy{Name: "test", Key: ratelimit.KeySub, Limit: ratelimit.Limit{Requests: 2, Window: time.Minute}}
+	r := limitedRouter(ratelimit.NewMemory(), policy)
+
+	w := getThings(r, "drone-1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "1" {
+		t.Fatalf("unexpected headers %v", w.Header())
+	}
+	if w.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Fatalf("expected policy 2;w=60, got %q", w.Header().Get("RateLimit-Policy"))
+	}
+	if w.Header().Get("Retry-After") != "" {
+		t.Fatalf("Retry-After belongs on refusals only")
+	}
+
+	getThings(r, "drone-1", "")
+	w = getThings(r, "drone-1", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected Retry-After 30, got %q", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" || w.Header().Get("RateLimit-Reset") != "60" {
+		t.Fatalf("unexpected headers on refusal %v", w.Header())
+	}
+}
+
+func TestRateLimit_SubjectsBehindOneIPAreSeparate(t *testing.T) {
+	policy := ratelimit.Policy{Name: "test", Key: ratelimit.KeySub, Limit: ratelimit.Limit{Requests: 1, Window: time.Minute}}
+	r := limitedRouter(ratelimit.NewMemory(), policy)
+
+	if w := getThings(r, "drone-1", ""); w.Code != http.StatusOK {
+		t.Fatalf("drone-1: expected 200, got %d", w.Code)
+	}
+	if w := getThings(r, "drone-2", ""); w.Code != http.StatusOK {
+		t.Fatalf("drone-2 behind the same IP: expected 200, got %d", w.Code)
+	}
+	if w := getThings(r, "drone-1", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("drone-1 again: expected 429, got %d", w.Code)
+	}
+}
+
+func TestRateLimit_TenantSharesABucket(t *testing.T) {
+	policy := ratelimit.Policy{Name: "test", Key: ratelimit.KeyTenant, Limit: ratelimit.Limit{Requests: 1, Window: time.Minute}}
+	r := limitedRouter(ratelimit.NewMemory(), policy)
+
+	getThings(r, "drone-1", "acme")
+	if w := getThings(r, "drone-2", "acme"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("same tenant: expected 429, got %d", w.Code)
+	}
+	// Without a tenant the subject is the caller
+	if w := getThings(r, "drone-3", ""); w.Code != http.StatusOK {
+		t.Fatalf("no tenant: expected 200, got %d", w.Code)
+	}
+}
+
+func TestRateLimit_IPCeilingShedsBeforeAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := ratelimit.Policy{Name: "global", Key: ratelimit.KeyIP, Limit: ratelimit.Limit{Requests: 2, Window: time.Minute}}
+	r := gin.New()
+	r.Use(middleware.RateLimit(ratelimit.NewMemory(), policy))
+	r.Use(middleware.Auth(jwt.NewService("test-secret", time.Hour)))
+	r.GET("/things", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+
+	junk := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		req.RemoteAddr = "203.0.113.7:4000"
+		req.Header.Set("Authorization", "Bearer not-a-token")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	for i := range 2 {
+		if code := junk(); code != http.StatusUnauthorized {
+			t.Fatalf("request %d: expected 401 from auth, got %d", i, code)
+		}
+	}
+	if code := junk(); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the IP to be shed before auth, got %d", code)
+	}
+}
+
+type downLimiter struct{}
+
+func (downLimiter) Take(context.Context, string, ratelimit.Limit) (ratelimit.Decision, error) {
+	return ratelimit.Decision{}, errors.New("connection refused")
+}
+
+func TestRateLimit_FallsBackToMemoryWhenStoreIsDown(t *testing.T) {
+	limiter := ratelimit.NewFallback(downLimiter{}, ratelimit.NewMemory())
+	policy := ratelimit.Policy{Name: "test", Key: ratelimit.KeySub, Limit: ratelimit.Limit{Requests: 1, Window: time.Minute}}
+	r := limitedRouter(limiter, policy)
+
+	if w := getThings(r, "drone-1", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !limiter.Degraded() {
+		t.Fatalf("expected the limiter to report it is degraded")
+	}
+	if w := getThings(r, "drone-1", ""); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the in-memory bucket to still limit, got %d", w.Code)
+	}
+}
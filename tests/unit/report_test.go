@@ -0,0 +1,26 @@
+package unit
+
+import (
+	"testing"
+
+	"drone-delivery/internal/report"
+)
+
+func TestCounts_SuccessRate(t *testing.T) {
+	tests := []struct {
+		name string
+		c    report.Counts
+		want float64
+	}{
+		{"nothing finished", report.Counts{Placed: 3, Open: 2, Withdrawn: 1}, 0},
+		{"all delivered", report.Counts{Delivered: 4}, 100},
+		{"withdrawn orders don't count", report.Counts{Delivered: 2, Failed: 1, Withdrawn: 5}, 66.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.SuccessRate(); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
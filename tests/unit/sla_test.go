@@ -0,0 +1,85 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"drone-delivery/internal/order"
+	"drone-delivery/internal/sla"
+)
+
+var slaTargets = sla.Targets{Assign: 10 * time.Minute, Pickup: 30 * time.Minute, Delivery: time.Hour}
+
+func slaFindings(events []*sla.Event) map[sla.Milestone]sla.State {
+	out := map[sla.Milestone]sla.State{}
+	for _, e := range events {
+		out[e.Milestone] = e.State
+	}
+	return out
+}
+
+func TestSLAEvaluate_FreshOrderHasNoFindings(t *testing.T) {
+	o := newPendingOrder()
+	if events := sla.Evaluate(o, slaTargets, 0.8, o.CreatedAt.Add(5*time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no findings, got %v", slaFindings(events))
+	}
+}
+
+func TestSLAEvaluate_UnreachedMilestones(t *testing.T) {
+	o := newPendingOrder()
+
+	// 9 minutes in: assignment is at risk (80% of 10m), the rest are fine
+	got := slaFindings(sla.Evaluate(o, slaTargets, 0.8, o.CreatedAt.Add(9*time.Minute)))
+	if len(got) != 1 || got[sla.MilestoneAssign] != sla.StateAtRisk {
+		t.Fatalf("expected assign at risk only, got %v", got)
+	}
+
+	// 25 minutes in: assignment is breached, pickup at risk
+	got = slaFindings(sla.Evaluate(o, slaTargets, 0.8, o.CreatedAt.Add(25*time.Minute)))
+	if got[sla.MilestoneAssign] != sla.StateBreached || got[sla.MilestonePickup] != sla.StateAtRisk {
+		t.Fatalf("expected assign breached and pickup at risk, got %v", got)
+	}
+	if _, ok := got[sla.MilestoneDelivery]; ok {
+		t.Fatalf("expected delivery not yet flagged, got %v", got)
+	}
+}
+
+func TestSLAEvaluate_ReachedMilestones(t *testing.T) {
+	o := newPendingOrder()
+	assigned := o.CreatedAt.Add(5 * time.Minute)
+	pickedUp := o.CreatedAt.Add(40 * time.Minute)
+	o.AssignedAt, o.PickedUpAt = &assigned, &pickedUp
+	o.Status = order.StatusPickedUp
+
+	got := slaFindings(sla.Evaluate(o, slaTargets, 0.8, o.CreatedAt.Add(41*time.Minute)))
+	if _, ok := got[sla.MilestoneAssign]; ok {
+		t.Fatalf("expected an on-time assignment not to be flagged, got %v", got)
+	}
+	if got[sla.MilestonePickup] != sla.StateBreached {
+		t.Fatalf("expected a late pickup to be breached, got %v", got)
+	}
+}
+
+func TestSLAEvaluate_FinishedOrderOnlyJudgesReachedMilestones(t *testing.T) {
+	o := newPendingOrder()
+	_ = o.Withdraw()
+
+	if events := sla.Evaluate(o, slaTargets, 0.8, o.CreatedAt.Add(3*time.Hour)); len(events) != 0 {
+		t.Fatalf("expected a withdrawn order not to be flagged, got %v", slaFindings(events))
+	}
+}
+
+func TestSLAEvaluate_ZeroTargetIsUntracked(t *testing.T) {
+	o := newPendingOrder()
+	got := slaFindings(sla.Evaluate(o, sla.Targets{Delivery: time.Hour}, 0.8, o.CreatedAt.Add(50*time.Minute)))
+	if len(got) != 1 || got[sla.MilestoneDelivery] != sla.StateAtRisk {
+		t.Fatalf("expected only delivery tracked, got %v", got)
+	}
+}
+
+func TestSLAConfig_TargetsFallBackToStandard(t *testing.T) {
+	cfg := sla.Config{Tiers: map[order.ServiceTier]sla.Targets{order.TierStandard: slaTargets}}
+	if cfg.Targets(order.TierExpress) != slaTargets {
+		t.Fatal("expected a tier without targets to use STANDARD's")
+	}
+}
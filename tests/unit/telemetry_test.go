@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"drone-delivery/internal/telemetry"
+)
+
+func trackPoints(droneID string, n int) []*telemetry.Point {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	points := make([]*telemetry.Point, n)
+	for i := range n {
+		points[i] = &telemetry.Point{
+			DroneID:    droneID,
+			Latitude:   24.7 + float64(i)*0.001,
+			Longitude:  46.6,
+			RecordedAt: start.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return points
+}
+
+func TestDownsample_KeepsFirstAndLast(t *testing.T) {
+	points := trackPoints("drone-1", 100)
+
+	out := telemetry.Downsample(points, 10)
+	if len(out) != 10 {
+		t.Fatalf("expected 10 points, got %d", len(out))
+	}
+	if out[0] != points[0] || out[9] != points[99] {
+		t.Fatal("expected first and last points to be kept")
+	}
+}
+
+func TestDownsample_ShortTrackUnchanged(t *testing.T) {
+	points := trackPoints("drone-1", 5)
+
+	if out := telemetry.Downsample(points, 10); len(out) != 5 {
+		t.Fatalf("expected 5 points, got %d", len(out))
+	}
+	if out := telemetry.Downsample(points, 0); len(out) != 5 {
+		t.Fatalf("expected 0 to keep all points, got %d", len(out))
+	}
+}
+
+func TestNewFeatureCollection_OneFeaturePerDrone(t *testing.T) {
+	points := append(trackPoints("drone-1", 3), trackPoints("drone-2", 2)...)
+
+	fc := telemetry.NewFeatureCollection(points, nil, 0)
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("expected 2 features, got %+v", fc)
+	}
+	f := fc.Features[0]
+	if f.Properties.DroneID != "drone-1" || len(f.Geometry.Coordinates) != 3 {
+		t.Fatalf("unexpected first feature: %+v", f.Properties)
+	}
+	if c := f.Geometry.Coordinates[0]; c[0] != 46.6 || c[1] != 24.7 {
+		t.Fatalf("expected [lng, lat] coordinates, got %v", c)
+	}
+	if len(f.Properties.Timestamps) != len(f.Geometry.Coordinates) {
+		t.Fatal("timestamps must be parallel to coordinates")
+	}
+}
@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"drone-delivery/internal/common"
+	"drone-delivery/internal/weather"
+)
+
+func TestBearingDegrees_CardinalDirections(t *testing.T) {
+	origin := common.NewLocation(24.7, 46.7)
+	cases := map[string]struct {
+		to   common.Location
+		want float64
+	}{
+		"north": {common.NewLocation(24.8, 46.7), 0},
+		"east":  {common.NewLocation(24.7, 46.8), 90},
+		"south": {common.NewLocation(24.6, 46.7), 180},
+		"west":  {common.NewLocation(24.7, 46.6), 270},
+	}
+	for name, tc := range cases {
+		if got := common.BearingDegrees(origin, tc.to); math.Abs(got-tc.want) > 0.1 {
+			t.Errorf("%s: expected bearing ~%.0f, got %f", name, tc.want, got)
+		}
+	}
+}
+
+func TestWeather_Headwind(t *testing.T) {
+	from := common.NewLocation(24.7, 46.7)
+	north := common.NewLocation(24.8, 46.7)
+
+	// Wind from the north blows straight against a northbound flight
+	w := common.Weather{WindSpeedKMH: 20, WindFromDeg: 0}
+	if hw := w.HeadwindKMH(from, north); math.Abs(hw-20) > 0.1 {
+		t.Fatalf("expected 20 km/h headwind, got %f", hw)
+	}
+	w.WindFromDeg = 180
+	if hw := w.HeadwindKMH(from, north); math.Abs(hw+20) > 0.1 {
+		t.Fatalf("expected 20 km/h tailwind, got %f", hw)
+	}
+	w.WindFromDeg = 90
+	if hw := w.HeadwindKMH(from, north); math.Abs(hw) > 0.1 {
+		t.Fatalf("crosswind should have no headwind component, got %f", hw)
+	}
+}
+
+func TestFlightETA_AccountsForWind(t *testing.T) {
+	from := common.NewLocation(24.7, 46.7)
+	to := common.NewLocation(24.8, 46.7) // ~11 km north
+
+	still := common.FlightETAMinutes(from, to, 30, nil)
+	headwind := common.FlightETAMinutes(from, to, 30, &common.Weather{WindSpeedKMH: 10, WindFromDeg: 0})
+	tailwind := common.FlightETAMinutes(from, to, 30, &common.Weather{WindSpeedKMH: 10, WindFromDeg: 180})
+
+	if math.Abs(headwind-still*1.5) > 0.1 {
+		t.Fatalf("20 km/h ground speed should take 1.5x still air (%f), got %f", still, headwind)
+	}
+	if tailwind >= still {
+		t.Fatalf("tailwind ETA %f should beat still air %f", tailwind, still)
+	}
+
+	// A headwind stronger than airspeed is capped at a quarter of airspeed
+	gale := common.FlightETAMinutes(from, to, 30, &common.Weather{WindSpeedKMH: 60, WindFromDeg: 0})
+	if math.Abs(gale-still*4) > 0.1 {
+		t.Fatalf("expected ETA capped at 4x still air, got %f", gale)
+	}
+}
+
+func TestWeatherLimits_Violation(t *testing.T) {
+	limits := weather.Limits{MaxWindKMH: 35, MinVisibilityKM: 1}
+
+	if reason := limits.Violation(&common.Weather{WindSpeedKMH: 20, VisibilityKM: 5}); reason != "" {
+		t.Fatalf("expected flyable, got %q", reason)
+	}
+	if reason := limits.Violation(&common.Weather{WindSpeedKMH: 50, VisibilityKM: 5}); reason == "" {
+		t.Fatal("50 km/h wind should be unsafe")
+	}
+	if reason := limits.Violation(&common.Weather{WindSpeedKMH: 5, VisibilityKM: 0.3}); reason == "" {
+		t.Fatal("dust with 0.3 km visibility should be unsafe")
+	}
+}
+
+func TestFixtureWeatherProvider_AreaOverridesDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weather.json")
+	fixture := `{
+		"default": {"wind_speed_kmh": 8, "wind_from_deg": 315, "visibility_km": 10},
+		"areas": [{"name": "dust", "center": {"lat": 24.8, "lng": 46.7}, "radius_km": 5,
+			"weather": {"wind_speed_kmh": 45, "wind_from_deg": 270, "visibility_km": 0.5}}]
+	}`
+	if err := os.WriteFile(path, []byte(fixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := common.NewFixtureWeatherProvider(path)
+	if err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+
+	ctx := context.Background()
+	w, _ := p.GetWeather(ctx, common.NewLocation(24.81, 46.71))
+	if w.WindSpeedKMH != 45 || w.VisibilityKM != 0.5 {
+		t.Fatalf("expected dust area conditions, got %+v", w)
+	}
+	w, _ = p.GetWeather(ctx, common.NewLocation(24.5, 46.5))
+	if w.WindSpeedKMH != 8 || w.ObservedAt.IsZero() {
+		t.Fatalf("expected default conditions, got %+v", w)
+	}
+}
+
+func TestFixtureWeatherProvider_EmptyPathIsCalm(t *testing.T) {
+	p, err := common.NewFixtureWeatherProvider("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _ := p.GetWeather(context.Background(), common.NewLocation(24.7, 46.7))
+	if reason := (weather.Limits{MaxWindKMH: 35, MinVisibilityKM: 1}).Violation(w); reason != "" {
+		t.Fatalf("default conditions should be flyable, got %q", reason)
+	}
+}